@@ -0,0 +1,226 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"sort"
+
+	"github.com/google/licensecheck/internal/match"
+)
+
+// approxCandidates is the number of licenses that approxRegion
+// tries to align with each region.
+const approxCandidates = 5
+
+// approxGaps looks for licenses with scattered small edits
+// in the parts of text not covered by list, which must be sorted by Start,
+// allowing edits to up to 100-percent percent of a license's words
+// (see Options.ApproxPercent).
+func (s *Scanner) approxGaps(text []byte, list []Match, percent int) []merged {
+	d := s.newDict()
+	words := d.InsertSplit(string(text))
+	var found []merged
+	unmatched(words, list, func(lo, hi int) {
+		found = s.approxRegion(found, d, text, words, lo, hi, percent)
+	})
+	return found
+}
+
+// approxRegion appends to found the licenses in the unmatched words[lo:hi]
+// of text, which were inserted in d, that can be edited into the license's
+// canonical text by changing at most 100-percent percent of the license's words.
+// Each match spans only the words aligned with the license;
+// approxRegion then looks for more licenses in the words before and after it.
+func (s *Scanner) approxRegion(found []merged, d *match.Dict, text []byte, words []match.Word, lo, hi, percent int) []merged {
+	if hi-lo < mergeMinWords {
+		return found
+	}
+	region := words[lo:hi]
+	tw := wordIDsOf(region)
+	var best *merged
+	var bestLo, bestHi int
+	for _, id := range s.containedLicenses(text[region[0].Lo:region[len(region)-1].Hi], approxCandidates) {
+		if mg, first, last := s.approxLicense(d, region, tw, id, percent); mg != nil && (best == nil || mg.words > best.words) {
+			best, bestLo, bestHi = mg, lo+first, lo+last+1
+		}
+	}
+	if best == nil {
+		return found
+	}
+	extendLines(&best.m, text, region, lo == 0, hi == len(words))
+	found = s.approxRegion(found, d, text, words, lo, bestLo, percent)
+	found = append(found, *best)
+	return s.approxRegion(found, d, text, words, bestHi, hi, percent)
+}
+
+// approxLicense aligns words, which were inserted in d and have IDs tw,
+// with the license id. If some run of words can be edited into the license's
+// canonical text by changing at most 100-percent percent of the license's words,
+// approxLicense returns a match of that run, which is words[first:last+1].
+// Otherwise it returns a nil match.
+func (s *Scanner) approxLicense(d *match.Dict, words []match.Word, tw []match.WordID, id string, percent int) (mg *merged, first, last int) {
+	l, ok := s.license(id)
+	if !ok {
+		return nil, 0, 0
+	}
+	ltext, ok := s.Text(id)
+	if !ok {
+		return nil, 0, 0
+	}
+	lwords := d.InsertSplit(ltext)
+	lw := wordIDsOf(lwords)
+	max := (100 - percent) * len(lw) / 100
+
+	// Common words like "the" in text around the license can be aligned
+	// with the license too, stretching the alignment far outside it.
+	// Keep the densest run of aligned words that fits in a window
+	// the size of the license, and then align again within that window,
+	// widened to leave room for the license words that the run misses.
+	pairs := densestPairs(diffWords(lw, tw, 0, 0, nil), len(lw)+max)
+	if len(pairs) == 0 {
+		return nil, 0, 0
+	}
+	lo := pairs[0][1] - pairs[0][0] - max
+	if lo < 0 {
+		lo = 0
+	}
+	hi := pairs[len(pairs)-1][1] + len(lw) - pairs[len(pairs)-1][0] + max
+	if hi > len(tw) {
+		hi = len(tw)
+	}
+	pairs = diffWords(lw, tw[lo:hi], 0, lo, nil)
+
+	// Text within the edit distance shares at least percent
+	// of the license's words, in order, so skip the work if it cannot.
+	if len(pairs) == 0 || 100*len(pairs) < percent*len(lw) {
+		return nil, 0, 0
+	}
+	first, last = pairs[0][1], pairs[len(pairs)-1][1]
+	if editDistance(tw[first:last+1], lw, max) > max {
+		return nil, 0, 0
+	}
+	m := l.match()
+	m.Start, m.End = int(words[first].Lo), int(words[last].Hi)
+	m.LicenseStart, m.LicenseEnd = int(lwords[pairs[0][0]].Lo), int(lwords[pairs[len(pairs)-1][0]].Hi)
+	m.MatchedWords, m.LicenseWords = len(pairs), len(lw)
+	m.IsApprox = true
+	return &merged{m: m, words: len(pairs)}, first, last
+}
+
+// densestPairs returns the longest run of pairs, as returned by diffWords,
+// whose second indexes span fewer than width words.
+func densestPairs(pairs [][2]int, width int) [][2]int {
+	bestI, bestJ := 0, 0
+	i := 0
+	for j := range pairs {
+		for pairs[j][1]-pairs[i][1] >= width {
+			i++
+		}
+		if j+1-i > bestJ-bestI {
+			bestI, bestJ = i, j+1
+		}
+	}
+	return pairs[bestI:bestJ]
+}
+
+// containedLicenses returns the IDs of up to n licenses whose canonical texts
+// are most nearly contained in text, most contained first.
+// Unlike Nearest, which compares whole texts, it ranks the licenses by
+// the fraction of each license's shingles that appear in text,
+// so that unrelated text around a license does not hide it.
+// The fraction is estimated from the license's MinHash signature:
+// the shingle that gives each position of the signature its value
+// is a random one of the license's shingles.
+func (s *Scanner) containedLicenses(text []byte, n int) []string {
+	s.initBuiltin()
+	s.nearestOnce.Do(s.initNearest)
+
+	have := make(map[uint64]bool)
+	shingles(text, func(h uint64) { have[h] = true })
+	type candidate struct {
+		id    string
+		found int
+	}
+	var cands []candidate
+	for _, l := range s.nearest {
+		found := 0
+		for i, x := range l.sig {
+			if x != ^uint64(0) && have[minHashShingle(x, i)] {
+				found++
+			}
+		}
+		if found > 0 {
+			cands = append(cands, candidate{l.id, found})
+		}
+	}
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].found > cands[j].found })
+	if len(cands) > n {
+		cands = cands[:n]
+	}
+	ids := make([]string, len(cands))
+	for i, c := range cands {
+		ids[i] = c.id
+	}
+	return ids
+}
+
+// editDistance returns the edit distance between a and b:
+// the fewest words to insert, delete, or replace to turn a into b.
+// If the distance is more than max, editDistance returns max+1.
+// It considers only edits within max words of the diagonal,
+// taking time proportional to max times the length of the texts.
+func editDistance(a, b []match.WordID, max int) int {
+	if len(a)-len(b) > max || len(b)-len(a) > max {
+		return max + 1
+	}
+
+	// row[k] holds the distance between a[:i] and b[:j] for j = i+k-max,
+	// capped at max+1.
+	width := 2*max + 1
+	row := make([]int, width)
+	next := make([]int, width)
+	for k := range row {
+		row[k] = k - max
+		if row[k] < 0 {
+			row[k] = max + 1
+		}
+	}
+	for i := 1; i <= len(a); i++ {
+		least := max + 1
+		for k := range next {
+			j := i + k - max
+			dist := max + 1
+			switch {
+			case j < 0 || j > len(b):
+				// Outside the table.
+			case j == 0:
+				dist = i
+			default:
+				dist = row[k] // replace a[i-1] with b[j-1], or keep it
+				if a[i-1] != b[j-1] {
+					dist++
+				}
+				if k+1 < width && row[k+1]+1 < dist {
+					dist = row[k+1] + 1 // delete a[i-1]
+				}
+				if k > 0 && next[k-1]+1 < dist {
+					dist = next[k-1] + 1 // insert b[j-1]
+				}
+			}
+			if dist > max+1 {
+				dist = max + 1
+			}
+			next[k] = dist
+			if dist < least {
+				least = dist
+			}
+		}
+		if least > max {
+			return max + 1
+		}
+		row, next = next, row
+	}
+	return row[len(b)-len(a)+max]
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import "fmt"
+
+// A Category is the kind of work a license is written for.
+// Most licenses are written for software but can be applied to anything;
+// others, like the Open Data Commons licenses, use terms that only make sense
+// for a particular kind of work, so that audits of, say, datasets
+// can tell the licenses meant for them from the rest.
+type Category int
+
+const (
+	// CategoryGeneral is a license for software or for works of any kind.
+	CategoryGeneral Category = iota
+
+	// CategoryData is a license for data and databases.
+	// Examples: CDLA-Permissive-2.0, ODbL-1.0, O-UDA-1.0.
+	CategoryData
+
+	// CategoryFont is a license for fonts.
+	// Examples: OFL-1.1, Ubuntu-font-1.0.
+	CategoryFont
+
+	// CategoryHardware is a license for hardware designs.
+	// Examples: CERN-OHL-S-2.0, SHL-2.1, TAPR-OHL-1.0.
+	CategoryHardware
+)
+
+var categoryNames = []string{
+	CategoryGeneral:  "general",
+	CategoryData:     "data",
+	CategoryFont:     "font",
+	CategoryHardware: "hardware",
+}
+
+// String returns the lower-case name of c, such as "data".
+func (c Category) String() string {
+	if 0 <= c && int(c) < len(categoryNames) {
+		return categoryNames[c]
+	}
+	return fmt.Sprintf("Category(%d)", int(c))
+}
+
+// ParseCategory parses s, a name returned by Category's String method,
+// into a Category.
+func ParseCategory(s string) (Category, error) {
+	for c, name := range categoryNames {
+		if s == name {
+			return Category(c), nil
+		}
+	}
+	return 0, fmt.Errorf("parsing %q: unknown Category", s)
+}
+
+// MarshalText implements encoding.TextMarshaler,
+// encoding c in the form returned by String.
+func (c Category) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler,
+// decoding text in the form accepted by ParseCategory.
+func (c *Category) UnmarshalText(text []byte) error {
+	cat, err := ParseCategory(string(text))
+	if err != nil {
+		return err
+	}
+	*c = cat
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCategoryString(t *testing.T) {
+	for c := CategoryGeneral; int(c) < len(categoryNames); c++ {
+		s := c.String()
+		back, err := ParseCategory(s)
+		if err != nil || back != c {
+			t.Errorf("ParseCategory(%q) = %v, %v, want %v", s, back, err, c)
+		}
+	}
+	if s := Category(99).String(); s != "Category(99)" {
+		t.Errorf("Category(99).String() = %q, want Category(99)", s)
+	}
+	if _, err := ParseCategory("Data"); err == nil {
+		t.Errorf("ParseCategory(Data) = nil, want error")
+	}
+
+	js, err := json.Marshal(Match{ID: "ODbL-1.0", Category: CategoryData})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m Match
+	if err := json.Unmarshal(js, &m); err != nil || m.Category != CategoryData {
+		t.Errorf("json round trip of %s: Category = %v, %v, want data", js, m.Category, err)
+	}
+}
+
+var licenseCategoryTests = map[string]Category{
+	"CDLA-Permissive-1.0": CategoryData,
+	"CERN-OHL-1.1":        CategoryHardware,
+	"CERN-OHL-1.2":        CategoryHardware,
+	"CERN-OHL-P-2.0":      CategoryHardware,
+	"CERN-OHL-S-2.0":      CategoryHardware,
+	"CERN-OHL-W-2.0":      CategoryHardware,
+	"CDLA-Permissive-2.0": CategoryData,
+	"CDLA-Sharing-1.0":    CategoryData,
+	"IPA":                 CategoryFont,
+	"ODbL-1.0":            CategoryData,
+	"ODC-By-1.0":          CategoryData,
+	"OFL-1.0":             CategoryFont,
+	"OFL-1.1":             CategoryFont,
+	"O-UDA-1.0":           CategoryData,
+	"PDDL-1.0":            CategoryData,
+	"SHL-0.5":             CategoryHardware,
+	"SHL-0.51":            CategoryHardware,
+	"SHL-2.0":             CategoryHardware,
+	"SHL-2.1":             CategoryHardware,
+	"TAPR-OHL-1.0":        CategoryHardware,
+	"Ubuntu-font-1.0":     CategoryFont,
+}
+
+func TestLicenseCategory(t *testing.T) {
+	skipProfile(t)
+	for _, l := range BuiltinLicenses() {
+		if l.Category != licenseCategoryTests[l.ID] {
+			t.Errorf("%s: Category = %v, want %v", l.ID, l.Category, licenseCategoryTests[l.ID])
+		}
+	}
+
+	text, _ := Text("ODbL-1.0")
+	cov := Scan([]byte(text))
+	if len(cov.Match) != 1 || cov.Match[0].Category != CategoryData {
+		t.Errorf("Scan(ODbL-1.0) = %+v, want match with Category data", cov.Match)
+	}
+}
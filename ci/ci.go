@@ -0,0 +1,280 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ci checks a source tree against a license policy
+// in a single call, for use in pre-merge hooks and CI jobs.
+//
+// CheckTree scans the tree's license files, applies the policy,
+// and returns a pass/fail Report listing each violation
+// with the file and line it concerns:
+//
+//	r, err := ci.CheckTree(os.DirFS("."), ci.Policy{
+//		Allow:          []string{"MIT", "BSD-3-Clause", "Apache-2.0"},
+//		MinPercent:     75,
+//		RequireLicense: true,
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	r.WriteAnnotations(os.Stdout) // or fmt.Print(r)
+//	if !r.Pass {
+//		os.Exit(1)
+//	}
+package ci
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/internal/spdxid"
+	"github.com/google/licensecheck/manifest"
+	"github.com/google/licensecheck/modlicense"
+)
+
+// A Policy says which licenses a tree may contain.
+// The zero Policy allows everything and only reports
+// license files that cannot be scanned.
+type Policy struct {
+	// Allow lists the license IDs allowed in the tree.
+	// If Allow is empty, all licenses not in Deny are allowed.
+	// Deny lists license IDs that must not appear in the tree.
+	// IDs are compared without regard to case or to -only, -or-later,
+	// and + suffixes, so that "GPL-2.0" names GPL-2.0-only and GPL-2.0-or-later too.
+	Allow []string
+	Deny  []string
+
+	// MinPercent is the smallest percentage of each license file
+	// that known licenses must cover. Less coverage usually means
+	// the file has been modified or holds an unknown license.
+	// If zero, coverage is not checked.
+	MinPercent float64
+
+	// RequireLicense requires a license file in the root of the tree.
+	RequireLicense bool
+
+	// CheckManifests compares the licenses declared by package manifests,
+	// such as package.json and Cargo.toml, with the licenses found
+	// in the license files of the manifests' directories.
+	// See package manifest for the supported manifests.
+	CheckManifests bool
+
+	// IsLicenseFile reports whether the file with the given
+	// slash-separated path is a license file to scan.
+	// If nil, files whose base names satisfy modlicense.IsLicenseFile are scanned.
+	IsLicenseFile func(file string) bool
+
+	// Scanner is the scanner to use.
+	// If nil, the built-in license set is used.
+	Scanner *licensecheck.Scanner
+
+	// Options are the options for scanning each file.
+	Options licensecheck.Options
+}
+
+// Violation rules.
+const (
+	RuleDenied     = "denied"      // license is in Policy.Deny
+	RuleNotAllowed = "not-allowed" // license is not in Policy.Allow
+	RuleCoverage   = "coverage"    // license file is covered less than Policy.MinPercent
+	RuleMissing    = "missing"     // tree has no license file (Policy.RequireLicense)
+	RuleManifest   = "manifest"    // manifest does not declare a license found (Policy.CheckManifests)
+	RuleError      = "error"       // license file could not be scanned
+)
+
+// A Violation is a single breach of a policy.
+type Violation struct {
+	Rule    string // rule broken, such as RuleDenied
+	File    string // slash-separated path of the file concerned, or "" for the whole tree
+	Line    int    // 1-based line of the file where the license starts, or 0
+	License string // ID of the license concerned, if any
+	Message string // description of the violation
+}
+
+// String returns the violation as "file:line: message [rule]".
+func (v Violation) String() string {
+	pos := v.File
+	if pos == "" {
+		pos = "."
+	}
+	if v.Line > 0 {
+		pos += fmt.Sprintf(":%d", v.Line)
+	}
+	return fmt.Sprintf("%s: %s [%s]", pos, v.Message, v.Rule)
+}
+
+// A Report is the result of checking a tree.
+type Report struct {
+	Pass       bool                        // whether the tree satisfies the policy
+	Files      []licensecheck.FileCoverage // license files scanned, in path order
+	Violations []Violation                 // violations in license files, in path order, then others
+}
+
+// String returns a summary of the report: a line for each violation
+// followed by a PASS or FAIL line.
+func (r Report) String() string {
+	var b strings.Builder
+	for _, v := range r.Violations {
+		fmt.Fprintf(&b, "%v\n", v)
+	}
+	if r.Pass {
+		fmt.Fprintf(&b, "PASS: %d license files checked\n", len(r.Files))
+	} else {
+		fmt.Fprintf(&b, "FAIL: %d violations in %d license files\n", len(r.Violations), len(r.Files))
+	}
+	return b.String()
+}
+
+// WriteAnnotations writes each violation to w as a workflow command
+// that GitHub Actions shows as an error annotation on the file and line:
+//
+//	::error file=LICENSE,line=1,title=licensecheck denied::license GPL-3.0 is denied
+//
+// Other CI systems show the lines as ordinary log output.
+func (r Report) WriteAnnotations(w io.Writer) error {
+	for _, v := range r.Violations {
+		var props []string
+		if v.File != "" {
+			props = append(props, "file="+escapeProperty(v.File))
+			if v.Line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", v.Line))
+			}
+		}
+		props = append(props, "title="+escapeProperty("licensecheck "+v.Rule))
+		if _, err := fmt.Fprintf(w, "::error %s::%s\n", strings.Join(props, ","), escapeData(v.Message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Escaping for workflow command data and properties.
+var (
+	escapeData     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace
+	escapeProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace
+)
+
+// CheckTree scans the license files in the tree in fsys,
+// skipping .git and node_modules directories,
+// and checks them against the policy.
+// It returns an error only if the tree cannot be read.
+func CheckTree(fsys fs.FS, policy Policy) (Report, error) {
+	isLicense := policy.IsLicenseFile
+	if isLicense == nil {
+		isLicense = func(file string) bool { return modlicense.IsLicenseFile(path.Base(file)) }
+	}
+	var r Report
+	dirs := make(map[string][]licensecheck.Coverage) // directory -> coverage of its license files
+	err := fs.WalkDir(fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", "node_modules":
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !isLicense(file) {
+			return nil
+		}
+		text, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		fc := licensecheck.FileCoverage{File: file}
+		if policy.Scanner != nil {
+			fc.Coverage, fc.Err = policy.Scanner.ScanWithOptions(text, policy.Options)
+		} else {
+			fc.Coverage, fc.Err = licensecheck.ScanWithOptions(text, policy.Options)
+		}
+		r.Files = append(r.Files, fc)
+		r.Violations = append(r.Violations, policy.check(text, fc)...)
+		dirs[path.Dir(file)] = append(dirs[path.Dir(file)], fc.Coverage)
+		return nil
+	})
+	if err != nil {
+		return Report{}, fmt.Errorf("ci: %v", err)
+	}
+
+	if policy.RequireLicense && len(dirs["."]) == 0 {
+		r.Violations = append(r.Violations, Violation{Rule: RuleMissing, Message: "no license file in the root directory"})
+	}
+	if policy.CheckManifests {
+		decls, err := manifest.ReadAll(fsys)
+		if err != nil {
+			return Report{}, fmt.Errorf("ci: %v", err)
+		}
+		for _, decl := range decls {
+			seen := make(map[string]bool)
+			for _, cov := range dirs[path.Dir(decl.File)] {
+				for _, id := range decl.Undeclared(cov) {
+					if !seen[id] {
+						seen[id] = true
+						r.Violations = append(r.Violations, Violation{
+							Rule:    RuleManifest,
+							File:    decl.File,
+							License: id,
+							Message: fmt.Sprintf("declares %q, but license files have %s", decl.License, id),
+						})
+					}
+				}
+			}
+		}
+	}
+	r.Pass = len(r.Violations) == 0
+	return r, nil
+}
+
+// check returns the violations of the policy by the scanned file.
+func (p *Policy) check(text []byte, fc licensecheck.FileCoverage) []Violation {
+	var list []Violation
+	if fc.Err != nil && !errors.Is(fc.Err, licensecheck.ErrNoMatch) && !errors.Is(fc.Err, licensecheck.ErrEmptyInput) {
+		return append(list, Violation{Rule: RuleError, File: fc.File, Message: fc.Err.Error()})
+	}
+	seen := make(map[string]bool)
+	for _, m := range fc.Coverage.Match {
+		if seen[m.ID] {
+			continue
+		}
+		seen[m.ID] = true
+		v := Violation{File: fc.File, Line: 1 + bytes.Count(text[:m.Start], []byte("\n")), License: m.ID}
+		switch {
+		case contains(p.Deny, m.ID):
+			v.Rule = RuleDenied
+			v.Message = fmt.Sprintf("license %s is denied", m.ID)
+		case len(p.Allow) > 0 && !contains(p.Allow, m.ID):
+			v.Rule = RuleNotAllowed
+			v.Message = fmt.Sprintf("license %s is not allowed", m.ID)
+		default:
+			continue
+		}
+		list = append(list, v)
+	}
+	if p.MinPercent > 0 && fc.Coverage.Percent < p.MinPercent {
+		list = append(list, Violation{
+			Rule:    RuleCoverage,
+			File:    fc.File,
+			Message: fmt.Sprintf("known licenses cover %.1f%% of the file, want at least %.1f%%", fc.Coverage.Percent, p.MinPercent),
+		})
+	}
+	return list
+}
+
+// contains reports whether ids contains id,
+// ignoring case and -only, -or-later, and + suffixes.
+func contains(ids []string, id string) bool {
+	for _, x := range ids {
+		if spdxid.Same(x, id) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+)
+
+func TestCheckTree(t *testing.T) {
+	mit, _ := licensecheck.Text("MIT")
+	gpl, _ := licensecheck.Text("GPL-3.0")
+	fsys := fstest.MapFS{
+		"LICENSE":                   {Data: []byte(mit)},
+		"package.json":              {Data: []byte(`{"license": "Apache-2.0"}`)},
+		"third_party/x/COPYING":     {Data: []byte("Vendored code.\n\n" + gpl)},
+		"third_party/y/LICENSE.txt": {Data: []byte("All rights reserved.\n")},
+		"node_modules/z/LICENSE":    {Data: []byte(gpl)},
+		"main.go":                   {Data: []byte("package main\n")},
+	}
+
+	r, err := CheckTree(fsys, Policy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Pass || len(r.Files) != 3 {
+		t.Fatalf("zero Policy: Pass=%v, %d files, want true, 3\n%v", r.Pass, len(r.Files), r)
+	}
+
+	r, err = CheckTree(fsys, Policy{
+		Allow:          []string{"MIT", "Apache-2.0"},
+		Deny:           []string{"GPL-3.0"},
+		MinPercent:     50,
+		RequireLicense: true,
+		CheckManifests: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Violation{
+		{Rule: RuleDenied, File: "third_party/x/COPYING", Line: 3},
+		{Rule: RuleCoverage, File: "third_party/y/LICENSE.txt"},
+		{Rule: RuleManifest, File: "package.json", License: "MIT"},
+	}
+	if r.Pass || len(r.Violations) != len(want) {
+		t.Fatalf("Pass=%v, violations:\n%v", r.Pass, r)
+	}
+	for i, v := range r.Violations {
+		w := want[i]
+		if v.Rule != w.Rule || v.File != w.File || v.Line != w.Line || w.License != "" && v.License != w.License {
+			t.Errorf("violation %d = %+v, want %+v", i, v, w)
+		}
+	}
+	if v := r.Violations[0]; !strings.HasPrefix(v.License, "GPL-3.0") {
+		t.Errorf("denied license = %s, want GPL-3.0", v.License)
+	}
+	if s := r.String(); !strings.HasSuffix(s, "FAIL: 3 violations in 3 license files\n") {
+		t.Errorf("String() = %q", s)
+	}
+
+	var b strings.Builder
+	r.WriteAnnotations(&b)
+	if line := strings.SplitN(b.String(), "\n", 2)[0]; !strings.HasPrefix(line, "::error file=third_party/x/COPYING,line=3,title=licensecheck denied::license GPL-3.0") {
+		t.Errorf("annotation = %q", line)
+	}
+
+	r, err = CheckTree(fstest.MapFS{"main.go": {Data: []byte("package main\n")}}, Policy{RequireLicense: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Pass || len(r.Violations) != 1 || r.Violations[0].Rule != RuleMissing {
+		t.Errorf("no license: Pass=%v, violations %v", r.Pass, r.Violations)
+	}
+}
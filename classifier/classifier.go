@@ -0,0 +1,152 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package classifier converts the license data set of
+// github.com/google/licenseclassifier into licensecheck.License entries.
+//
+// The licenseclassifier data set is distributed as licenses/licenses.db,
+// a gzip-compressed tar archive of plain-text files.
+// Each file ID.txt holds the text of the license ID,
+// and each file ID.header.txt holds the text of the
+// short header recommended for source files under that license.
+// Both kinds of files become licenses with the same ID,
+// matched by their literal text (see licensecheck.QuoteLRE).
+//
+// Scanning with the converted licenses reports the same
+// license IDs that licenseclassifier does,
+// which helps when comparing the two or migrating between them:
+//
+//	f, err := os.Open("licenseclassifier/licenses/licenses.db")
+//	if err != nil {
+//		...
+//	}
+//	list, err := classifier.ReadArchive(f)
+//	if err != nil {
+//		...
+//	}
+//	s, err := licensecheck.NewScanner(list)
+//
+// Compare runs licensecheck and another classifier, such as licenseclassifier,
+// over the same files and reports the licenses that only one of them found,
+// with excerpts of the text matched, to measure how the two differ
+// and to triage gaps in either.
+package classifier
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/google/licensecheck"
+)
+
+// ReadArchive reads a licenseclassifier licenses.db archive from r
+// and returns the licenses it contains, sorted by ID.
+func ReadArchive(r io.Reader) ([]licensecheck.License, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("classifier: %v", err)
+	}
+	defer zr.Close()
+
+	var list []licensecheck.License
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("classifier: %v", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		id, ok := licenseID(hdr.Name)
+		if !ok {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("classifier: %s: %v", hdr.Name, err)
+		}
+		list = append(list, newLicense(hdr.Name, id, data))
+	}
+	sortLicenses(list)
+	return list, nil
+}
+
+// ReadFS reads the licenses from the *.txt files in the root directory of fsys,
+// such as an extracted licenses.db archive,
+// and returns them sorted by ID.
+func ReadFS(fsys fs.FS) ([]licensecheck.License, error) {
+	files, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("classifier: %v", err)
+	}
+	var list []licensecheck.License
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		id, ok := licenseID(f.Name())
+		if !ok {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, f.Name())
+		if err != nil {
+			return nil, fmt.Errorf("classifier: %v", err)
+		}
+		list = append(list, newLicense(f.Name(), id, data))
+	}
+	sortLicenses(list)
+	return list, nil
+}
+
+// licenseID returns the license ID for the data file with the given name.
+// It reports false for files that do not hold license text.
+func licenseID(name string) (string, bool) {
+	name = path.Base(name)
+	if !strings.HasSuffix(name, ".txt") || strings.HasPrefix(name, ".") {
+		return "", false
+	}
+	name = strings.TrimSuffix(name, ".txt")
+	name = strings.TrimSuffix(name, ".header")
+	return name, name != ""
+}
+
+// newLicense returns a License matching the text data
+// read from the named file.
+// A file named ID.header.txt holds a header (see License.IsHeader);
+// the text of a full license is also the license Text.
+func newLicense(name, id string, data []byte) licensecheck.License {
+	l := licensecheck.License{
+		ID:  id,
+		LRE: licensecheck.QuoteLRE(string(data)),
+	}
+	if strings.HasSuffix(name, ".header.txt") {
+		l.IsHeader = true
+	} else {
+		l.Text = string(data)
+	}
+	return l
+}
+
+// sortLicenses sorts list by ID and then by decreasing text length,
+// so that the result does not depend on the archive order
+// and each license text comes before its shorter header.
+func sortLicenses(list []licensecheck.License) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].ID != list[j].ID {
+			return list[i].ID < list[j].ID
+		}
+		return len(list[i].LRE) > len(list[j].LRE)
+	})
+}
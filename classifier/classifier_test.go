@@ -0,0 +1,103 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package classifier
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+)
+
+var testFiles = map[string]string{
+	"Test-1.0.txt": `Permission to use, copy, modify, and distribute this software
+for any purpose with or without fee is hereby granted, provided that
+the above notice appears in all copies. ((This)) text has no warranty.
+`,
+	"Test-1.0.header.txt": `This file is licensed under the Test License, version 1.0.
+See the LICENSE file for details.
+`,
+	"README": "not a license",
+}
+
+func testArchive(t *testing.T) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for name, data := range testFiles {
+		hdr := &tar.Header{Name: "licenses/" + name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadArchive(t *testing.T) {
+	list, err := ReadArchive(bytes.NewReader(testArchive(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLicenses(t, "ReadArchive", list)
+}
+
+func TestReadFS(t *testing.T) {
+	fsys := make(fstest.MapFS)
+	for name, data := range testFiles {
+		fsys[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+	list, err := ReadFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLicenses(t, "ReadFS", list)
+}
+
+func checkLicenses(t *testing.T, name string, list []licensecheck.License) {
+	t.Helper()
+	if len(list) != 2 || list[0].ID != "Test-1.0" || list[1].ID != "Test-1.0" {
+		t.Fatalf("%s = %+v, want two Test-1.0 licenses", name, list)
+	}
+	if list[0].Text != testFiles["Test-1.0.txt"] || list[1].Text != "" {
+		t.Errorf("%s: Text = %q, %q, want license text and no header text", name, list[0].Text, list[1].Text)
+	}
+	if list[0].IsHeader || !list[1].IsHeader {
+		t.Errorf("%s: IsHeader = %v, %v, want false, true", name, list[0].IsHeader, list[1].IsHeader)
+	}
+	s, err := licensecheck.NewScanner(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		text     string
+		isHeader bool
+	}{
+		{testFiles["Test-1.0.txt"], false},
+		{testFiles["Test-1.0.header.txt"], true},
+	} {
+		cov := s.Scan([]byte(tt.text))
+		if len(cov.Match) != 1 || cov.Match[0].ID != "Test-1.0" || cov.Match[0].IsHeader != tt.isHeader || cov.Percent < 100 {
+			t.Errorf("Scan(%q) = %+v, want Test-1.0 with IsHeader=%v", tt.text, cov, tt.isHeader)
+		}
+	}
+}
+
+func TestReadArchiveError(t *testing.T) {
+	if _, err := ReadArchive(bytes.NewReader([]byte("not gzip"))); err == nil {
+		t.Errorf("ReadArchive(not gzip) succeeded, want error")
+	}
+}
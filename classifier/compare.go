@@ -0,0 +1,170 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package classifier
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/internal/strs"
+)
+
+// maxExcerpt is the most bytes of text quoted in a Disagreement.
+const maxExcerpt = 200
+
+// A Result is a license that a classifier found in a text.
+type Result struct {
+	ID    string
+	Start int // byte offset of start of text matched
+	End   int // byte offset of end of text matched; zero if not known
+}
+
+// A Func classifies a text, returning the licenses it finds in it.
+// To compare licenseclassifier with licensecheck,
+// wrap a licenseclassifier.License in a Func:
+//
+//	lc, err := licenseclassifier.New(licenseclassifier.DefaultConfidenceThreshold)
+//	if err != nil {
+//		...
+//	}
+//	classify := func(text []byte) ([]classifier.Result, error) {
+//		var list []classifier.Result
+//		for _, m := range lc.MultipleMatch(string(text), true) {
+//			list = append(list, classifier.Result{ID: m.Name, Start: m.Offset, End: m.Offset + m.Extent})
+//		}
+//		return list, nil
+//	}
+type Func func(text []byte) ([]Result, error)
+
+// A Comparison reports how licensecheck and another classifier
+// differ on a set of files (see Compare).
+type Comparison struct {
+	Files         int            // number of files compared
+	Agree         int            // number of files in which both found the same licenses
+	Disagreements []Disagreement // in file order, then sorted by ID
+}
+
+// A Disagreement is a license found in a file by only one of the two classifiers.
+type Disagreement struct {
+	File         string
+	ID           string
+	Licensecheck bool   // whether licensecheck found the license; if not, the other classifier did
+	Start, End   int    // byte offsets of the text matched, as reported by the classifier that found it
+	Excerpt      string // text matched, shortened to at most 200 bytes
+}
+
+// Compare scans each of the named files in fsys using s.ScanWithOptions,
+// or licensecheck.ScanWithOptions if s is nil, classifies it using classify,
+// and reports the licenses found by only one of the two.
+// URL matches are ignored, since licenseclassifier does not report them.
+// License IDs must agree exactly, so when comparing with licenseclassifier,
+// s should usually be created from its licenses (see ReadArchive),
+// so that differences come from the matching, not the license set.
+//
+// As with licensecheck.ScanFS, the text of each file is extracted
+// using licensecheck.ExtractText, and both classifiers are given that text.
+// Compare returns an error if a file cannot be read or extracted
+// or if either classifier fails, other than by finding no license.
+func Compare(fsys fs.FS, files []string, s *licensecheck.Scanner, opts licensecheck.Options, classify Func) (*Comparison, error) {
+	scan := licensecheck.ScanWithOptions
+	if s != nil {
+		scan = s.ScanWithOptions
+	}
+	c := new(Comparison)
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("classifier: %v", err)
+		}
+		text, err := licensecheck.ExtractText(file, data)
+		if err != nil {
+			return nil, fmt.Errorf("classifier: %s: %v", file, err)
+		}
+		cov, err := scan(text, opts)
+		if err != nil && !errors.Is(err, licensecheck.ErrNoMatch) && !errors.Is(err, licensecheck.ErrEmptyInput) {
+			return nil, fmt.Errorf("classifier: %s: %v", file, err)
+		}
+		other, err := classify(text)
+		if err != nil {
+			return nil, fmt.Errorf("classifier: %s: %v", file, err)
+		}
+
+		// Record the first place each classifier found each license.
+		mine := make(map[string]Result)
+		for _, m := range cov.Match {
+			if _, ok := mine[m.ID]; !ok && !m.IsURL {
+				mine[m.ID] = Result{m.ID, m.Start, m.End}
+			}
+		}
+		theirs := make(map[string]Result)
+		for _, r := range other {
+			if _, ok := theirs[r.ID]; !ok {
+				theirs[r.ID] = r
+			}
+		}
+
+		var list []Disagreement
+		for id, r := range mine {
+			if _, ok := theirs[id]; !ok {
+				list = append(list, newDisagreement(file, text, r, true))
+			}
+		}
+		for id, r := range theirs {
+			if _, ok := mine[id]; !ok {
+				list = append(list, newDisagreement(file, text, r, false))
+			}
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+		c.Files++
+		if len(list) == 0 {
+			c.Agree++
+		}
+		c.Disagreements = append(c.Disagreements, list...)
+	}
+	return c, nil
+}
+
+// newDisagreement returns the Disagreement for the result r,
+// found in text, the text of the named file, by only one classifier.
+func newDisagreement(file string, text []byte, r Result, byLicensecheck bool) Disagreement {
+	d := Disagreement{File: file, ID: r.ID, Licensecheck: byLicensecheck, Start: r.Start, End: r.End}
+	if 0 <= r.Start && r.Start < r.End && r.End <= len(text) {
+		d.Excerpt = strs.Excerpt(text[r.Start:r.End], maxExcerpt)
+	}
+	return d
+}
+
+// WriteCSV writes the disagreements in c to w as CSV, with a header row,
+// for triage in a spreadsheet. The found_by column says which classifier
+// found the license: "licensecheck" or "other".
+// Cells that a spreadsheet would take for a formula,
+// because they begin with =, +, -, or @, are prefixed with a quote,
+// since excerpts come from the compared files.
+func (c *Comparison) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"file", "id", "found_by", "start", "end", "excerpt"})
+	for _, d := range c.Disagreements {
+		by := "other"
+		if d.Licensecheck {
+			by = "licensecheck"
+		}
+		row := []string{d.File, d.ID, by, strconv.Itoa(d.Start), strconv.Itoa(d.End), d.Excerpt}
+		for i, cell := range row {
+			if cell != "" && strings.ContainsRune("=+-@", rune(cell[0])) {
+				row[i] = "'" + cell
+			}
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package classifier
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+)
+
+func TestCompare(t *testing.T) {
+	mit, _ := licensecheck.Text("MIT")
+	fsys := fstest.MapFS{
+		"a/LICENSE": {Data: []byte(mit)},
+		"b/LICENSE": {Data: []byte("Copyright 2020 The Gopher\n\n" + mit)},
+		"c/README":  {Data: []byte("See https://opensource.org/licenses/MIT for details.\n")},
+		"d/NOTICE":  {Data: []byte("-- Nothing here.\n")},
+	}
+	files := []string{"a/LICENSE", "b/LICENSE", "c/README", "d/NOTICE"}
+
+	// The other classifier agrees about a/LICENSE and c/README,
+	// where licensecheck finds only a URL, takes b/LICENSE for Apache-2.0,
+	// and finds an unknown license, without offsets, in d/NOTICE.
+	classify := func(text []byte) ([]Result, error) {
+		switch {
+		case bytes.Equal(text, fsys["a/LICENSE"].Data):
+			return []Result{{ID: "MIT", Start: 0, End: len(text)}}, nil
+		case strings.HasPrefix(string(text), "--"):
+			return []Result{{ID: "Unknown-1.0"}}, nil
+		case strings.HasPrefix(string(text), "Copyright"):
+			return []Result{{ID: "Apache-2.0", Start: 0, End: len("Copyright")}}, nil
+		}
+		return nil, nil
+	}
+	c, err := Compare(fsys, files, nil, licensecheck.Options{}, classify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := string(fsys["b/LICENSE"].Data)
+	want := &Comparison{
+		Files: 4,
+		Agree: 2,
+		Disagreements: []Disagreement{
+			{File: "b/LICENSE", ID: "Apache-2.0", End: 9, Excerpt: "Copyright"},
+			{File: "b/LICENSE", ID: "MIT", Licensecheck: true, End: len(b), Excerpt: b[:maxExcerpt] + "..."},
+			{File: "d/NOTICE", ID: "Unknown-1.0"},
+		},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Compare:\nhave %+v\nwant %+v", c, want)
+	}
+	var buf bytes.Buffer
+	if err := c.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "file,id,found_by,start,end,excerpt\nb/LICENSE,Apache-2.0,other,0,9,Copyright\n") ||
+		!strings.HasSuffix(out, "\nd/NOTICE,Unknown-1.0,other,0,0,\n") {
+		t.Errorf("WriteCSV:\n%s", out)
+	}
+}
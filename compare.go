@@ -0,0 +1,157 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import "github.com/google/licensecheck/internal/match"
+
+// A Similarity describes how much of two texts is the same.
+type Similarity struct {
+	// APercent is the percentage of the words of the first text
+	// that also appear, in the same order, in the second text.
+	// BPercent is the same for the words of the second text.
+	// Texts without words are 100% covered by any other text.
+	APercent float64
+	BPercent float64
+
+	// AWords and BWords are the number of words in each text.
+	AWords int
+	BWords int
+}
+
+// Compare compares the texts a and b directly, without reference to any license.
+// It splits both texts into words the same way that Scan does,
+// ignoring case, punctuation, and markup,
+// and finds the longest sequence of words common to both.
+// The result reports how much of each text that sequence covers.
+//
+// Compare is useful for deciding whether two license files,
+// such as a LICENSE file and a copy in a vendored directory,
+// hold effectively the same text, whether or not it is a known license.
+// Differences in copyright lines reduce the percentages slightly,
+// as any other differing words do.
+// Unrelated texts still share many common words, like "the" and "of",
+// so only percentages near 100 indicate the same text.
+//
+// The cost of Compare grows with the product of the lengths of the texts
+// after removing any common prefix and suffix,
+// so it is fast for texts that are nearly the same
+// but can be slow for long texts that are very different.
+func Compare(a, b []byte) Similarity {
+	d := new(match.Dict)
+	return compare(wordIDs(d, a), wordIDs(d, b))
+}
+
+// compare returns the Similarity of the word sequences a and b.
+func compare(a, b []match.WordID) Similarity {
+	n := lcs(a, b)
+	return Similarity{
+		APercent: percent(n, len(a)),
+		BPercent: percent(n, len(b)),
+		AWords:   len(a),
+		BWords:   len(b),
+	}
+}
+
+// Cluster groups the texts that are nearly the same,
+// so that, for example, the hundreds of copies of LICENSE files
+// in a large source tree can be reviewed one group at a time.
+// Two texts are nearly the same if, according to Compare,
+// each covers at least minPercent of the other.
+//
+// Cluster returns the groups as lists of indexes into texts.
+// Every text is in exactly one group.
+// The first text in each group is its representative:
+// every other text in the group is nearly the same as it,
+// although not necessarily as each other.
+// The groups are ordered by their first index,
+// and the indexes in each group are increasing.
+func Cluster(texts [][]byte, minPercent float64) [][]int {
+	d := new(match.Dict)
+	words := make([][]match.WordID, len(texts))
+	for i, text := range texts {
+		words[i] = wordIDs(d, text)
+	}
+
+	var groups [][]int
+Texts:
+	for i, w := range words {
+		for g, group := range groups {
+			rep := words[group[0]]
+			// The common words cannot outnumber the shorter text,
+			// so texts of very different lengths cannot be nearly the same.
+			short, long := len(w), len(rep)
+			if short > long {
+				short, long = long, short
+			}
+			if percent(short, long) < minPercent {
+				continue
+			}
+			if s := compare(rep, w); s.APercent >= minPercent && s.BPercent >= minPercent {
+				groups[g] = append(group, i)
+				continue Texts
+			}
+		}
+		groups = append(groups, []int{i})
+	}
+	return groups
+}
+
+// wordIDs returns the IDs of the words in text, adding them to d.
+func wordIDs(d *match.Dict, text []byte) []match.WordID {
+	return wordIDsOf(d.InsertSplit(string(text)))
+}
+
+// percent returns n as a percentage of total.
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return 100 * float64(n) / float64(total)
+}
+
+// lcs returns the length of the longest common subsequence of a and b.
+func lcs(a, b []match.WordID) int {
+	a, b, pre, suf := trimCommon(a, b)
+	n := pre + suf
+	if len(a) == 0 || len(b) == 0 {
+		return n
+	}
+	return n + int(lcsRow(a, b)[len(b)])
+}
+
+// trimCommon returns a and b without their longest common prefix and suffix,
+// along with the lengths of the prefix and suffix.
+func trimCommon(a, b []match.WordID) (a1, b1 []match.WordID, pre, suf int) {
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b, pre = a[1:], b[1:], pre+1
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b, suf = a[:len(a)-1], b[:len(b)-1], suf+1
+	}
+	return a, b, pre, suf
+}
+
+// lcsRow returns the lengths of the longest common subsequences
+// of a with each prefix of b: row[j] is the length for a and b[:j].
+func lcsRow(a, b []match.WordID) []int32 {
+	// prev[j] and cur[j] are the LCS lengths of
+	// a[:i-1] and a[:i] with b[:j].
+	prev := make([]int32, len(b)+1)
+	cur := make([]int32, len(b)+1)
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] >= cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
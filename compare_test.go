@@ -0,0 +1,78 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	apache, err := os.ReadFile("testdata/Apache-2.0.t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		name       string
+		a, b       string
+		aMin, aMax float64
+		bMin, bMax float64
+	}{
+		{"same", mitText, mitText, 100, 100, 100, 100},
+		{"case and punctuation", mitText, strings.ToUpper(strings.ReplaceAll(mitText, ",", "")), 100, 100, 100, 100},
+		{"markdown", mitText, "# License\n\n" + strings.ReplaceAll(mitText, "\n\n", "\n\n---\n\n"), 100, 100, 99, 100},
+		{"copyright", "Copyright 2020 Alice\n\n" + mitText, "Copyright 2019 Bob\n\n" + mitText, 98, 99.9, 98, 99.9},
+		{"extra", mitText, mitText + "\nExcept for the files in the extra directory.\n", 100, 100, 90, 99},
+		{"different", mitText, string(apache), 0, 60, 0, 20},
+		{"empty", "", mitText, 100, 100, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Compare([]byte(tt.a), []byte(tt.b))
+			if s.APercent < tt.aMin || s.APercent > tt.aMax || s.BPercent < tt.bMin || s.BPercent > tt.bMax {
+				t.Errorf("Compare = %.2f%%, %.2f%%, want [%.0f, %.0f], [%.0f, %.0f]", s.APercent, s.BPercent, tt.aMin, tt.aMax, tt.bMin, tt.bMax)
+			}
+
+			r := Compare([]byte(tt.b), []byte(tt.a))
+			if r.APercent != s.BPercent || r.BPercent != s.APercent || r.AWords != s.BWords || r.BWords != s.AWords {
+				t.Errorf("Compare(b, a) = %+v, want reverse of %+v", r, s)
+			}
+		})
+	}
+}
+
+func TestCluster(t *testing.T) {
+	apache, err := os.ReadFile("testdata/Apache-2.0.t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	texts := []string{
+		"Copyright 2020 Alice\n\n" + mitText,
+		string(apache),
+		"Copyright 2019 Bob\n\n" + mitText,
+		"Short note.",
+		strings.ReplaceAll(string(apache), ",", ""),
+		"# License\n\nCopyright 2018 Carol\n\n" + mitText,
+		"",
+		"",
+	}
+	var data [][]byte
+	for _, text := range texts {
+		data = append(data, []byte(text))
+	}
+	got := Cluster(data, 95)
+	want := [][]int{{0, 2, 5}, {1, 4}, {3}, {6, 7}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Cluster = %v, want %v", got, want)
+	}
+
+	got = Cluster(data, 100)
+	want = [][]int{{0}, {1, 4}, {2}, {3}, {5}, {6, 7}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Cluster(100) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A ComponentKind is the kind of a Component.
+type ComponentKind int
+
+const (
+	// ComponentOther is text that is none of the other kinds.
+	ComponentOther ComponentKind = iota
+
+	// ComponentLicense is a license.
+	ComponentLicense
+
+	// ComponentPatents is a patent grant, such as Go's PATENTS file.
+	ComponentPatents
+
+	// ComponentNotice is an attribution notice, such as an Apache NOTICE file.
+	ComponentNotice
+
+	// ComponentAuthors is a list of authors or contributors,
+	// such as Go's AUTHORS file.
+	ComponentAuthors
+)
+
+var componentKindNames = []string{
+	ComponentOther:   "other",
+	ComponentLicense: "license",
+	ComponentPatents: "patents",
+	ComponentNotice:  "notice",
+	ComponentAuthors: "authors",
+}
+
+// String returns the lower-case name of k, such as "patents".
+func (k ComponentKind) String() string {
+	if 0 <= k && int(k) < len(componentKindNames) {
+		return componentKindNames[k]
+	}
+	return fmt.Sprintf("ComponentKind(%d)", int(k))
+}
+
+// A Component is one part of a file that combines several,
+// such as a LICENSE file followed by the text of a PATENTS file
+// and an AUTHORS list.
+type Component struct {
+	Kind       ComponentKind
+	Start, End int // byte offsets of the component; it is text[Start:End]
+	Match      int // index in Coverage.Match of the matched license, or -1
+}
+
+// patentGrants lists the IDs of the licenses that are patent grants
+// to go with another license, rather than licenses on their own.
+var patentGrants = map[string]bool{
+	"GooglePatentClause": true,
+	"GooglePatentsFile":  true,
+}
+
+// Components splits text, which must be the text scanned to produce c,
+// into its components, so that a file concatenating a license,
+// a patent grant, a notice, and an authors list, as some projects ship,
+// can report each on its own instead of as one partial match.
+//
+// Each match in c is a component: ComponentPatents for a patent grant,
+// such as GooglePatentsFile, and ComponentLicense otherwise.
+// Each stretch of unmatched text is also a component,
+// classified by its wording: ComponentAuthors for a list of names
+// or text headed as a list of authors or contributors,
+// ComponentNotice for an attribution notice, and ComponentOther otherwise.
+// Blank text between components is not part of any.
+func Components(text []byte, c Coverage) []Component {
+	var list []Component
+	gap := func(start, end int) {
+		t := bytes.TrimSpace(text[start:end])
+		if len(t) > 0 {
+			start += bytes.Index(text[start:end], t)
+			end = start + len(t)
+			list = append(list, Component{Kind: gapKind(text[start:end]), Start: start, End: end, Match: -1})
+		}
+	}
+	last := 0
+	for i, m := range c.Match {
+		if m.Start < last {
+			// Overlaps the previous match, as a URL inside a license can.
+			continue
+		}
+		gap(last, m.Start)
+		kind := ComponentLicense
+		if patentGrants[m.ID] {
+			kind = ComponentPatents
+		}
+		list = append(list, Component{Kind: kind, Start: m.Start, End: m.End, Match: i})
+		last = m.End
+	}
+	gap(last, len(text))
+	return list
+}
+
+// noticePhrases are lower-case phrases that mark an attribution notice.
+var noticePhrases = []string{
+	"includes software developed",
+	"contains software developed",
+}
+
+// gapKind returns the kind of the unmatched text gap.
+func gapKind(gap []byte) ComponentKind {
+	var lines []string
+	for _, line := range strings.Split(string(gap), "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "#/* \t"))
+		if line != "" {
+			lines = append(lines, strings.ToLower(line))
+		}
+	}
+	if len(lines) == 0 {
+		return ComponentOther
+	}
+
+	first := lines[0]
+	if strings.HasPrefix(first, "notice") {
+		return ComponentNotice
+	}
+	for _, line := range lines {
+		for _, p := range noticePhrases {
+			if strings.Contains(line, p) {
+				return ComponentNotice
+			}
+		}
+	}
+
+	// A heading, not a copyright line like "Copyright 2009 The Go Authors".
+	if (strings.Contains(first, "authors") || strings.Contains(first, "contributors")) && !strings.HasPrefix(first, "copyright") {
+		return ComponentAuthors
+	}
+	// A list of names with email addresses.
+	emails := 0
+	for _, line := range lines {
+		if i := strings.Index(line, "<"); i >= 0 && strings.Contains(line[i:], "@") && strings.HasSuffix(line, ">") {
+			emails++
+		}
+	}
+	if emails >= 2 && 2*emails >= len(lines) {
+		return ComponentAuthors
+	}
+	return ComponentOther
+}
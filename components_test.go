@@ -0,0 +1,66 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComponents(t *testing.T) {
+	skipProfile(t)
+	bsd, _ := Text("BSD-3-Clause")
+	patents, _ := Text("GooglePatentsFile")
+	apache, _ := Text("Apache-2.0")
+	const (
+		authors = "# This is the official list of Gopher authors for copyright purposes.\n" +
+			"# Names should be added to this file as\n#\tName or Organization <email address>\n\n" +
+			"Gopher LLC\nJane Doe <jane@example.com>\n"
+		notice = "Apache Widgets\nCopyright 2020 The Apache Software Foundation\n\n" +
+			"This product includes software developed at\nThe Apache Software Foundation (http://www.apache.org/).\n"
+		other = "The widgets are licensed as follows.\n"
+	)
+
+	for _, tt := range []struct {
+		parts []string
+		want  []ComponentKind
+	}{
+		{
+			[]string{bsd, patents, authors},
+			[]ComponentKind{ComponentLicense, ComponentPatents, ComponentAuthors},
+		},
+		{
+			[]string{other, apache, notice},
+			[]ComponentKind{ComponentOther, ComponentLicense, ComponentNotice},
+		},
+	} {
+		text := strings.Join(tt.parts, "\n\n")
+		list := Components([]byte(text), Scan([]byte(text)))
+		if len(list) != len(tt.want) {
+			t.Errorf("Components = %+v, want kinds %v", list, tt.want)
+			continue
+		}
+		for i, c := range list {
+			part := strings.TrimSpace(tt.parts[i])
+			if c.Kind != tt.want[i] || !strings.Contains(text[c.Start:c.End], part) {
+				t.Errorf("Components[%d] = %v %q..., want %v %q...", i, c.Kind, clip(text[c.Start:c.End]), tt.want[i], clip(part))
+			}
+			if (c.Match >= 0) != (c.Kind == ComponentLicense || c.Kind == ComponentPatents) {
+				t.Errorf("Components[%d] = %v with Match %d", i, c.Kind, c.Match)
+			}
+		}
+	}
+
+	if s := ComponentPatents.String(); s != "patents" {
+		t.Errorf("ComponentPatents.String() = %q, want %q", s, "patents")
+	}
+}
+
+func clip(s string) string {
+	if len(s) > 40 {
+		return s[:40]
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentScan checks that a Scanner can be shared by goroutines,
+// including while it builds its lazy indexes.
+// It is most useful when run with the race detector (go test -race).
+func TestConcurrentScan(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) > 10 {
+		files = files[:10]
+	}
+	var texts [][]byte
+	var want []Coverage
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		texts = append(texts, data)
+		want = append(want, Scan(data))
+	}
+
+	// Use a new Scanner, so that its lazy indexes
+	// are built by the concurrent calls.
+	s, err := NewScanner(BuiltinLicenses())
+	if err != nil {
+		t.Fatal(err)
+	}
+	const goroutines = 4
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			s.Nearest(texts[g%len(texts)], 3)
+			for k := range texts {
+				i := (k + g*len(texts)/goroutines) % len(texts)
+				if have := s.Scan(texts[i]); !reflect.DeepEqual(have, want[i]) {
+					t.Errorf("%s: concurrent Scan = %+v, want %+v", files[i], have, want[i])
+				}
+				if _, err := s.ScanWithOptions(texts[i], Options{MergeGapWords: 10}); err != nil && !errors.Is(err, ErrNoMatch) {
+					t.Errorf("%s: concurrent ScanWithOptions: %v", files[i], err)
+				}
+				s.ScanHeader(texts[i])
+				for _, m := range want[i].Match {
+					s.Text(m.ID)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
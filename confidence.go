@@ -0,0 +1,67 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+// Parameters of the confidence model (see Match.Confidence).
+const (
+	confidenceURL      = 90 // confidence in a URL naming a license
+	confidenceApprox   = 15 // points lost by an approximate match
+	confidenceCoverage = 20 // most points lost by covering little of the license
+	confidenceMinWords = 20 // matches shorter than this lose confidence
+	confidenceContest  = 10 // percent lost by a match that others contested
+	confidenceDeviated = 10 // percent lost by a match with deviations
+)
+
+// setConfidence sets the Confidence of each match in c.
+func (c *Coverage) setConfidence() {
+	contested := make([]bool, len(c.Match))
+	for _, a := range c.Alternatives {
+		contested[a.Match] = true
+	}
+	deviated := make([]bool, len(c.Match))
+	for _, d := range c.Deviations {
+		deviated[d.Match] = true
+	}
+	for i := range c.Match {
+		c.Match[i].Confidence = confidence(&c.Match[i], contested[i], deviated[i])
+	}
+}
+
+// confidence returns the confidence in the match m,
+// which other licenses contested if contested is set
+// and which has deviations if deviated is set.
+func confidence(m *Match, contested, deviated bool) float64 {
+	switch {
+	case m.IsTag, m.IsExact:
+		return 100
+	case m.IsURL:
+		return confidenceURL
+	}
+
+	c := 100.0
+	if m.IsApprox {
+		c -= confidenceApprox
+	}
+	// A match covering only part of a license text is less certain,
+	// but a header is meant to be a small part.
+	if !m.IsHeader && m.LicenseWords > 0 {
+		cover := float64(m.MatchedWords) / float64(m.LicenseWords)
+		if cover > 1 {
+			cover = 1
+		}
+		c -= confidenceCoverage * (1 - cover)
+	}
+	// A short match is more likely to be a coincidence.
+	if m.MatchedWords < confidenceMinWords {
+		c *= 0.5 + 0.5*float64(m.MatchedWords)/confidenceMinWords
+	}
+	if contested {
+		c *= 1 - confidenceContest/100.0
+	}
+	if deviated {
+		c *= 1 - confidenceDeviated/100.0
+	}
+	return c
+}
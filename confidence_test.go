@@ -0,0 +1,52 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"math"
+	"testing"
+)
+
+var confidenceTests = []struct {
+	m         Match
+	contested bool
+	deviated  bool
+	want      float64
+}{
+	{Match{IsTag: true}, false, false, 100},
+	{Match{IsExact: true, MatchedWords: 100, LicenseWords: 100}, true, false, 100},
+	{Match{IsURL: true, MatchedWords: 5}, false, false, 90},
+	{Match{MatchedWords: 100, LicenseWords: 100}, false, false, 100},
+	{Match{MatchedWords: 50, LicenseWords: 100}, false, false, 90},
+	{Match{MatchedWords: 50, LicenseWords: 100, IsHeader: true}, false, false, 100},
+	{Match{MatchedWords: 100, LicenseWords: 0}, false, false, 100},
+	{Match{MatchedWords: 100, LicenseWords: 100, IsApprox: true}, false, false, 85},
+	{Match{MatchedWords: 10, LicenseWords: 0}, false, false, 75},
+	{Match{MatchedWords: 100, LicenseWords: 100}, true, false, 90},
+	{Match{MatchedWords: 100, LicenseWords: 100}, true, true, 81},
+}
+
+func TestConfidence(t *testing.T) {
+	for _, tt := range confidenceTests {
+		if c := confidence(&tt.m, tt.contested, tt.deviated); math.Abs(c-tt.want) > 1e-9 {
+			t.Errorf("confidence(%+v, %v, %v) = %v, want %v", tt.m, tt.contested, tt.deviated, c, tt.want)
+		}
+	}
+
+	// Scan sets Confidence.
+	mit, _ := Text("MIT")
+	cov := Scan([]byte(mit))
+	if len(cov.Match) != 1 || cov.Match[0].Confidence != 100 {
+		t.Errorf("Scan(MIT) = %+v, want Confidence 100", cov.Match)
+	}
+	cov = ScanHeader([]byte("// SPDX-License-Identifier: MIT\npackage p\n"))
+	if len(cov.Match) != 1 || cov.Match[0].Confidence != 100 {
+		t.Errorf("ScanHeader(tag) = %+v, want Confidence 100", cov.Match)
+	}
+	cov, err := ScanWithOptions([]byte(urlTests[0].text), Options{})
+	if err != nil || len(cov.Match) != 1 || cov.Match[0].Confidence != confidenceURL {
+		t.Errorf("ScanWithOptions(URL) = %+v, %v, want Confidence %d", cov.Match, err, confidenceURL)
+	}
+}
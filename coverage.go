@@ -0,0 +1,112 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import "sort"
+
+// A LicenseShare is the part of a Coverage accounted for
+// by all the matches of a single license.
+type LicenseShare struct {
+	Matches int     `json:"matches"` // number of matches of the license
+	Words   int     `json:"words"`   // total MatchedWords of those matches
+	Percent float64 `json:"percent"` // percentage of the text they cover
+}
+
+// ByLicense returns the share of c accounted for by each matched license,
+// keyed by license ID, so that a license matched several times
+// (as in a concatenation of license texts or a repeated header)
+// is counted once.
+// The Percent of each share is the license's portion of c.Percent,
+// in proportion to its matched words, so the shares sum to c.Percent.
+// If c has no matches, ByLicense returns an empty map.
+func (c Coverage) ByLicense() map[string]LicenseShare {
+	shares := make(map[string]LicenseShare)
+	total := 0
+	for _, m := range c.Match {
+		sh := shares[m.ID]
+		sh.Matches++
+		sh.Words += m.MatchedWords
+		shares[m.ID] = sh
+		total += m.MatchedWords
+	}
+	for id, sh := range shares {
+		if total > 0 {
+			sh.Percent = c.Percent * float64(sh.Words) / float64(total)
+		}
+		shares[id] = sh
+	}
+	return shares
+}
+
+// Primary returns the license accounting for the most matched words
+// in c, counting all its matches, along with the percentage
+// of the text those matches cover, in the same terms as c.Percent.
+// Ties go to the license matched first in the text.
+// If c has no matches, Primary returns "", 0.
+func (c Coverage) Primary() (id string, percent float64) {
+	shares := c.ByLicense()
+	best := -1
+	for _, m := range c.Match {
+		if sh := shares[m.ID]; sh.Words > best {
+			id, best, percent = m.ID, sh.Words, sh.Percent
+		}
+	}
+	return id, percent
+}
+
+// A Range is a range of byte offsets in a text: text[Start:End].
+type Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Unmatched returns the ranges of input, which must be the text scanned
+// to produce c, not covered by any match, in increasing order.
+// Each range is trimmed of leading and trailing white space,
+// and ranges holding only white space are omitted,
+// so that the blank lines separating matches are not reported.
+// Matches may overlap and need not be sorted.
+func (c Coverage) Unmatched(input []byte) []Range {
+	covered := make([]Range, 0, len(c.Match))
+	for _, m := range c.Match {
+		if m.Start < m.End {
+			covered = append(covered, Range{m.Start, m.End})
+		}
+	}
+	sort.Slice(covered, func(i, j int) bool { return covered[i].Start < covered[j].Start })
+
+	var list []Range
+	add := func(start, end int) {
+		for start < end && isSpace(input[start]) {
+			start++
+		}
+		for end > start && isSpace(input[end-1]) {
+			end--
+		}
+		if start < end {
+			list = append(list, Range{start, end})
+		}
+	}
+	pos := 0
+	for _, r := range covered {
+		if r.Start > len(input) {
+			break
+		}
+		if r.Start > pos {
+			add(pos, r.Start)
+		}
+		if r.End > pos {
+			pos = r.End
+		}
+	}
+	if pos < len(input) {
+		add(pos, len(input))
+	}
+	return list
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f' || b == '\v'
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestByLicense(t *testing.T) {
+	c := Coverage{
+		Percent: 90,
+		Match: []Match{
+			{ID: "A", MatchedWords: 20},
+			{ID: "B", MatchedWords: 40},
+			{ID: "A", MatchedWords: 30},
+		},
+	}
+	want := map[string]LicenseShare{
+		"A": {Matches: 2, Words: 50, Percent: 50},
+		"B": {Matches: 1, Words: 40, Percent: 40},
+	}
+	if have := c.ByLicense(); !reflect.DeepEqual(have, want) {
+		t.Errorf("ByLicense() = %v, want %v", have, want)
+	}
+	if have := (Coverage{}).ByLicense(); len(have) != 0 {
+		t.Errorf("Coverage{}.ByLicense() = %v, want empty", have)
+	}
+
+	// A repeated header rolls up into one share.
+	text := headerTexts[0].text + "\n\n" + headerTexts[0].text
+	cov := Scan([]byte(text))
+	shares := cov.ByLicense()
+	if len(cov.Match) != 2 || len(shares) != 1 || shares["Apache-2.0"].Matches != 2 ||
+		!matchPercent(shares["Apache-2.0"].Percent, cov.Percent) {
+		t.Errorf("Scan(header twice).ByLicense() = %v from %+v, want one Apache-2.0 share of 2 matches", shares, cov.Match)
+	}
+}
+
+func TestPrimary(t *testing.T) {
+	c := Coverage{
+		Percent: 80,
+		Match: []Match{
+			{ID: "A", MatchedWords: 30},
+			{ID: "B", MatchedWords: 40},
+			{ID: "A", MatchedWords: 30},
+		},
+	}
+	if id, pct := c.Primary(); id != "A" || !matchPercent(pct, 48) {
+		t.Errorf("Primary() = %q, %.1f, want A, 48.0", id, pct)
+	}
+	c.Match[2].ID = "C"
+	if id, pct := c.Primary(); id != "B" || !matchPercent(pct, 32) {
+		t.Errorf("Primary() = %q, %.1f, want B, 32.0", id, pct)
+	}
+	c.Match[1].MatchedWords = 30
+	if id, _ := c.Primary(); id != "A" {
+		t.Errorf("Primary() with tie = %q, want A", id)
+	}
+	if id, pct := (Coverage{}).Primary(); id != "" || pct != 0 {
+		t.Errorf("Coverage{}.Primary() = %q, %v, want \"\", 0", id, pct)
+	}
+
+	mit, _ := Text("MIT")
+	bsd, _ := Text("BSD-3-Clause")
+	cov := Scan([]byte("Parts are MIT:\n\n" + mit + "\n\n" + bsd))
+	if id, pct := cov.Primary(); id != "BSD-3-Clause" || pct <= 0 || pct >= cov.Percent {
+		t.Errorf("Primary() = %q, %.1f, want BSD-3-Clause, under %.1f", id, pct, cov.Percent)
+	}
+	cov = ScanHeader([]byte("// SPDX-License-Identifier: Apache-2.0\npackage p\n"))
+	if id, pct := cov.Primary(); id != "Apache-2.0" || pct != cov.Percent {
+		t.Errorf("ScanHeader(tag).Primary() = %q, %.1f, want Apache-2.0, %.1f", id, pct, cov.Percent)
+	}
+}
+
+func TestUnmatched(t *testing.T) {
+	input := []byte("intro\n\nAAAA\n\nmiddle BBBB  \n")
+	c := Coverage{Match: []Match{
+		{ID: "B", Start: 20, End: 24},
+		{ID: "A", Start: 7, End: 11},
+		{ID: "A2", Start: 8, End: 10},
+	}}
+	want := []Range{{0, 5}, {13, 19}}
+	if have := c.Unmatched(input); !reflect.DeepEqual(have, want) {
+		t.Errorf("Unmatched() = %v, want %v", have, want)
+	}
+	if have := (Coverage{}).Unmatched([]byte(" \n")); have != nil {
+		t.Errorf("Coverage{}.Unmatched(space) = %v, want nil", have)
+	}
+
+	mit, _ := Text("MIT")
+	text := "The MIT License\n\n" + mit + "\nThanks for reading!\n"
+	cov := Scan([]byte(text))
+	var have []string
+	for _, r := range cov.Unmatched([]byte(text)) {
+		have = append(have, text[r.Start:r.End])
+	}
+	if !reflect.DeepEqual(have, []string{"The MIT License", "Thanks for reading!"}) {
+		t.Errorf("Scan(MIT).Unmatched() = %q, want title and trailer", have)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// The built-in licenses are the *.lre files in the licenses directory,
+// embedded as-is. Each file is text/template input that generates an LRE
+// (see licenses/README.md). The templates are executed on first use
+// and again by each call to BuiltinLicenses.
+//
+// The file licenses/SHA256SUMS records the checksum of every *.lre file.
+// It is checked when the licenses are loaded, to catch partial or
+// accidental edits. After editing the licenses, run "go generate"
+// to update it.
+//
+// The canonical license texts, returned by Text, are the
+// *.txt files in licenses/text. They are not used for matching.
+//
+// Which files are embedded depends on the build profile,
+// selected by build tag. The default profile, in data_full.go,
+// embeds every license. The licensecheck_top50 profile, in data_top50.go,
+// embeds only the most commonly used ones, making programs that
+// import the package, such as ones compiled to WebAssembly, much smaller.
+// The licenses a profile includes are listed in builtinProfile;
+// any others defined by its files are dropped when the licenses are loaded.
+
+//go:generate go run gen_sums.go
+
+// builtinLREs returns the LRE licenses built into the package.
+// Each call loads them anew and returns a new list.
+// The list is not cached: the LREs take more memory than the
+// compiled Scanner that is built from them, and a Scanner
+// keeps only the compiled form.
+func builtinLREs() []License {
+	list, err := loadLREs(licenseFS, "licenses", builtinProfile != nil)
+	if err != nil {
+		panic("licensecheck: loading built-in licenses: " + err.Error())
+	}
+	if builtinProfile != nil {
+		in := make(map[string]bool)
+		for _, id := range builtinProfile {
+			in[id] = true
+		}
+		keep := list[:0]
+		for _, l := range list {
+			if in[l.ID] {
+				keep = append(keep, l)
+			}
+		}
+		list = keep
+	}
+	return list
+}
+
+// builtinText returns the canonical text of the built-in license with the given ID.
+func builtinText(id string) (string, bool) {
+	if id == "" || strings.ContainsAny(id, "/*?[\\") {
+		return "", false
+	}
+	data, err := licenseFS.ReadFile("licenses/text/" + id + ".txt")
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// loadLREs loads the licenses from the *.lre files in dir,
+// after verifying them against dir/SHA256SUMS.
+// If partial is set, as for a build profile, not every listed file need exist.
+func loadLREs(fsys fs.FS, dir string, partial bool) ([]License, error) {
+	if err := checkSums(fsys, dir, partial); err != nil {
+		return nil, err
+	}
+
+	var typ Type
+	setType := func(s string) (string, error) {
+		t, err := ParseType(s)
+		if err != nil {
+			return "", err
+		}
+		typ = t
+		return "", nil
+	}
+	var cat Category
+	setCategory := func(s string) (string, error) {
+		c, err := ParseCategory(s)
+		if err != nil {
+			return "", err
+		}
+		cat = c
+		return "", nil
+	}
+	var lang string
+	setLanguage := func(s string) string {
+		lang = s
+		return ""
+	}
+	var header bool
+	setHeader := func() string {
+		header = true
+		return ""
+	}
+	var rider bool
+	setRider := func() string {
+		rider = true
+		return ""
+	}
+	var wraps string
+	setWraps := func(id string) string {
+		wraps = id
+		return ""
+	}
+	var spdxID string
+	setSPDX := func(s string) string {
+		spdxID = s
+		return ""
+	}
+	var unlisted bool
+	setUnlisted := func() string {
+		unlisted = true
+		return ""
+	}
+	t := template.New("").Funcs(template.FuncMap{
+		"list":     templateList,
+		"Type":     setType,
+		"Category": setCategory,
+		"Language": setLanguage,
+		"Header":   setHeader,
+		"SPDX":     setSPDX,
+		"Rider":    setRider,
+		"Wraps":    setWraps,
+		"Unlisted": setUnlisted,
+	})
+	t, err := t.ParseFS(fsys, path.Join(dir, "*.lre"))
+	if err != nil {
+		return nil, err
+	}
+
+	var list []License
+	for _, t := range t.Templates() {
+		if !strings.HasSuffix(t.Name(), ".lre") {
+			continue
+		}
+		var buf bytes.Buffer
+		typ = Unknown
+		cat = CategoryGeneral
+		lang = ""
+		header = false
+		rider = false
+		wraps = ""
+		spdxID = ""
+		unlisted = false
+		if err := t.Execute(&buf, nil); err != nil {
+			return nil, fmt.Errorf("executing %s: %v", t.Name(), err)
+		}
+		if len(bytes.TrimSpace(buf.Bytes())) == 0 {
+			// Only contained useful definitions.
+			continue
+		}
+		id := strings.TrimSuffix(t.Name(), ".lre")
+		if lang != "" {
+			// A translation ID.LANG.lre is another text for license ID.
+			if !strings.HasSuffix(id, "."+lang) {
+				return nil, fmt.Errorf("%s: translation to %q must be named ID.%s.lre", t.Name(), lang, lang)
+			}
+			id = strings.TrimSuffix(id, "."+lang)
+		}
+		if strings.HasSuffix(id, ".header") {
+			// A header ID.header.lre is a per-file notice for license ID.
+			if !header {
+				return nil, fmt.Errorf("%s: header must call Header", t.Name())
+			}
+			id = strings.TrimSuffix(id, ".header")
+		}
+		listVersion := SPDXVersion
+		if unlisted {
+			listVersion = ""
+		}
+		list = append(list, License{
+			ID:          id,
+			Type:        typ,
+			Category:    cat,
+			LRE:         buf.String(),
+			SPDXID:      spdxID,
+			ListVersion: listVersion,
+			Language:    lang,
+			IsHeader:    header,
+			IsRider:     rider,
+			Wraps:       wraps,
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return licenseLess(&list[i], &list[j]) })
+
+	return list, nil
+}
+
+// checkSums verifies the *.lre files in dir against dir/SHA256SUMS.
+// Every file must be listed, and every listed file must exist
+// unless partial is set, as for a build profile
+// that embeds only some of the files.
+func checkSums(fsys fs.FS, dir string, partial bool) error {
+	data, err := fs.ReadFile(fsys, path.Join(dir, "SHA256SUMS"))
+	if err != nil {
+		return err
+	}
+	want := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for lineno := 1; sc.Scan(); lineno++ {
+		f := strings.Fields(sc.Text())
+		if len(f) != 2 {
+			return fmt.Errorf("SHA256SUMS:%d: malformed line", lineno)
+		}
+		want[f[1]] = f[0]
+	}
+
+	files, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		name := f.Name()
+		if !strings.HasSuffix(name, ".lre") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		sum, ok := want[name]
+		if !ok {
+			return fmt.Errorf("%s: missing from SHA256SUMS", name)
+		}
+		delete(want, name)
+		if have := sha256.Sum256(data); hex.EncodeToString(have[:]) != sum {
+			return fmt.Errorf("%s: checksum mismatch", name)
+		}
+	}
+	if partial {
+		return nil
+	}
+	for name := range want {
+		return fmt.Errorf("%s: listed in SHA256SUMS but missing", name)
+	}
+	return nil
+}
+
+// templateList returns xs, but it flattens any nested []interface{} into the main list.
+// Called from templates as "list", to pass multiple arguments to templates.
+func templateList(xs ...interface{}) []interface{} {
+	var list []interface{}
+	for _, x := range xs {
+		switch x := x.(type) {
+		case []interface{}:
+			list = append(list, x...)
+		default:
+			list = append(list, x)
+		}
+	}
+	return list
+}
@@ -0,0 +1,17 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !licensecheck_top50
+// +build !licensecheck_top50
+
+package licensecheck
+
+import "embed"
+
+//go:embed licenses/*.lre licenses/SHA256SUMS licenses/text/*.txt
+var licenseFS embed.FS
+
+// builtinProfile lists the IDs of the built-in licenses in this profile.
+// In the full profile, the default, it is nil: all licenses are built in.
+var builtinProfile []string
@@ -0,0 +1,220 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// skipProfile skips a test that needs the full set of built-in licenses
+// in a build profile that embeds only some of them.
+func skipProfile(t *testing.T) {
+	t.Helper()
+	if builtinProfile != nil {
+		t.Skip("skipping: build profile embeds only some licenses")
+	}
+}
+
+func TestBuiltinLREs(t *testing.T) {
+	list := builtinLREs()
+	if len(list) == 0 {
+		t.Fatal("no built-in licenses")
+	}
+	for _, l := range list {
+		if strings.Contains(l.LRE, "{{") {
+			t.Errorf("%s: unexpanded template in LRE", l.ID)
+		}
+	}
+}
+
+func TestCheckSums(t *testing.T) {
+	const mit = "Permission is hereby granted, free of charge.\n"
+	sums := func(files ...string) *fstest.MapFile {
+		var b strings.Builder
+		for _, f := range files {
+			fmt.Fprintf(&b, "%x  %s\n", sha256.Sum256([]byte(mit)), f)
+		}
+		return &fstest.MapFile{Data: []byte(b.String())}
+	}
+	lre := &fstest.MapFile{Data: []byte(mit)}
+
+	fsys := fstest.MapFS{"d/MIT.lre": lre, "d/SHA256SUMS": sums("MIT.lre")}
+	list, err := loadLREs(fsys, "d", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != "MIT" || list[0].LRE != mit {
+		t.Errorf("loadLREs = %+v, want MIT", list)
+	}
+
+	for _, fsys := range []fstest.MapFS{
+		{"d/MIT.lre": &fstest.MapFile{Data: []byte("changed")}, "d/SHA256SUMS": sums("MIT.lre")},
+		{"d/MIT.lre": lre, "d/X.lre": lre, "d/SHA256SUMS": sums("MIT.lre")},
+		{"d/MIT.lre": lre, "d/SHA256SUMS": sums("MIT.lre", "X.lre")},
+		{"d/MIT.lre": lre},
+	} {
+		if err := checkSums(fsys, "d", false); err == nil {
+			t.Errorf("checkSums succeeded on bad manifest")
+		}
+	}
+
+	// A partial set of files, as in a build profile, may omit listed files.
+	fsys = fstest.MapFS{"d/MIT.lre": lre, "d/SHA256SUMS": sums("MIT.lre", "X.lre")}
+	if err := checkSums(fsys, "d", true); err != nil {
+		t.Errorf("checkSums with partial set: %v", err)
+	}
+	fsys["d/Y.lre"] = lre
+	if err := checkSums(fsys, "d", true); err == nil {
+		t.Errorf("checkSums with partial set succeeded on unlisted file")
+	}
+}
+
+func TestLoadTranslations(t *testing.T) {
+	files := map[string]string{
+		"Corp-1.0.lre":    "This software is licensed under the Corporate License, version 1.0.\n",
+		"Corp-1.0.de.lre": "{{Language \"de\"}}Diese Software ist lizenziert unter der Firmenlizenz, Version 1.0.\n",
+	}
+	fsys := make(fstest.MapFS)
+	var sums strings.Builder
+	for name, data := range files {
+		fsys["d/"+name] = &fstest.MapFile{Data: []byte(data)}
+		fmt.Fprintf(&sums, "%x  %s\n", sha256.Sum256([]byte(data)), name)
+	}
+	fsys["d/SHA256SUMS"] = &fstest.MapFile{Data: []byte(sums.String())}
+
+	list, err := loadLREs(fsys, "d", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ID != "Corp-1.0" || list[0].Language != "" || list[1].ID != "Corp-1.0" || list[1].Language != "de" {
+		t.Fatalf("loadLREs = %+v, want Corp-1.0 and its de translation", list)
+	}
+	s, err := NewScanner(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := "Diese Software ist lizenziert unter der Firmenlizenz, Version 1.0."
+	cov := s.Scan([]byte(text))
+	if len(cov.Match) != 1 || cov.Match[0].ID != "Corp-1.0" || cov.Match[0].Language != "de" {
+		t.Errorf("Scan(%q) = %+v, want Corp-1.0 in de", text, cov)
+	}
+
+	// A translation must be named for its language.
+	data := "{{Language \"fr\"}}Ce logiciel est sous licence.\n"
+	fsys = fstest.MapFS{
+		"d/Corp-1.0.de.lre": &fstest.MapFile{Data: []byte(data)},
+		"d/SHA256SUMS":      &fstest.MapFile{Data: []byte(fmt.Sprintf("%x  Corp-1.0.de.lre\n", sha256.Sum256([]byte(data))))},
+	}
+	if list, err := loadLREs(fsys, "d", false); err == nil {
+		t.Errorf("loadLREs with misnamed translation = %+v, want error", list)
+	}
+}
+
+var headerTexts = []struct {
+	id   string
+	text string
+}{
+	{"Apache-2.0", `Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+`},
+	{"GPL-2.0-or-later", `This program is free software; you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation; either version 2 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+`},
+	{"MPL-2.0", `This Source Code Form is subject to the terms of the Mozilla Public
+License, v. 2.0. If a copy of the MPL was not distributed with this
+file, You can obtain one at http://mozilla.org/MPL/2.0/.
+`},
+	{"Perl", `This library is free software; you can redistribute it and/or modify
+it under the same terms as Perl itself, either Perl version 5.8.8 or,
+at your option, any later version of Perl 5 you may have available.
+`},
+}
+
+func TestBuiltinHeaders(t *testing.T) {
+	skipProfile(t)
+	for _, tt := range headerTexts {
+		cov := Scan([]byte(tt.text))
+		if len(cov.Match) != 1 || cov.Match[0].ID != tt.id || !cov.Match[0].IsHeader || cov.Percent != 100 {
+			t.Errorf("Scan(%s header) = %+v, want IsHeader match", tt.id, cov)
+		}
+		if text, ok := Text(tt.id); ok {
+			cov := Scan([]byte(text))
+			if len(cov.Match) == 0 || cov.Match[0].ID != tt.id || cov.Match[0].IsHeader {
+				t.Errorf("Scan(%s text) = %+v, want non-header match", tt.id, cov)
+			}
+		}
+	}
+
+	// The Perl notice offers a choice of licenses, so its SPDX ID is an expression.
+	cov := Scan([]byte(headerTexts[3].text))
+	if len(cov.Match) != 1 || cov.Match[0].SPDXID != "Artistic-1.0-Perl OR GPL-1.0-or-later" {
+		t.Errorf("Scan(Perl header) = %+v, want SPDXID Artistic-1.0-Perl OR GPL-1.0-or-later", cov)
+	}
+
+	// A header must call Header.
+	data := "This software is licensed under the Corporate License, version 1.0.\n"
+	fsys := fstest.MapFS{
+		"d/Corp-1.0.header.lre": &fstest.MapFile{Data: []byte(data)},
+		"d/SHA256SUMS":          &fstest.MapFile{Data: []byte(fmt.Sprintf("%x  Corp-1.0.header.lre\n", sha256.Sum256([]byte(data))))},
+	}
+	if list, err := loadLREs(fsys, "d", false); err == nil {
+		t.Errorf("loadLREs with unmarked header = %+v, want error", list)
+	}
+	fsys["d/Corp-1.0.header.lre"].Data = []byte("{{Header}}" + data)
+	fsys["d/SHA256SUMS"].Data = []byte(fmt.Sprintf("%x  Corp-1.0.header.lre\n", sha256.Sum256(fsys["d/Corp-1.0.header.lre"].Data)))
+	if list, err := loadLREs(fsys, "d", false); err != nil || len(list) != 1 || list[0].ID != "Corp-1.0" || !list[0].IsHeader {
+		t.Errorf("loadLREs with header = %+v, %v, want Corp-1.0 header", list, err)
+	}
+}
+
+func TestBuiltinText(t *testing.T) {
+	ids := make(map[string]bool)
+	for _, l := range builtinLREs() {
+		ids[l.ID] = true
+	}
+	files, err := licenseFS.ReadDir("licenses/text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no built-in license texts")
+	}
+	for _, f := range files {
+		id := strings.TrimSuffix(f.Name(), ".txt")
+		if !ids[id] {
+			t.Errorf("licenses/text/%s: not a built-in license", f.Name())
+			continue
+		}
+		text, ok := builtinText(id)
+		if !ok {
+			t.Errorf("builtinText(%q) failed", id)
+			continue
+		}
+		cov := Scan([]byte(text))
+		if len(cov.Match) != 1 || cov.Match[0].ID != id || cov.Percent < 100 {
+			t.Errorf("Scan(licenses/text/%s) = %+v, want 100%% %s", f.Name(), cov, id)
+		}
+	}
+}
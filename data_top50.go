@@ -0,0 +1,66 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build licensecheck_top50
+// +build licensecheck_top50
+
+package licensecheck
+
+import "embed"
+
+// The top50 profile embeds only the 50 most commonly used licenses,
+// along with the -only and -or-later forms of the GNU licenses,
+// for programs where size matters, such as ones compiled to WebAssembly.
+// The files listed here must include any *.lre files defining templates
+// that the licenses use, such as BSD.lre and GPL.lre.
+
+//go:embed licenses/SHA256SUMS
+//go:embed licenses/0BSD.lre licenses/AGPL-3.0.lre licenses/Apache-1.1.lre licenses/Apache-2.0.lre
+//go:embed licenses/Artistic-1.0.lre licenses/Artistic-2.0.lre licenses/BSD.lre licenses/BSL-1.0.lre
+//go:embed licenses/BlueOak-1.0.0.lre licenses/CC-BY-3.0.lre licenses/CC-BY-4.0.lre
+//go:embed licenses/CC-BY-SA-3.0.lre licenses/CC-BY-SA-4.0.lre licenses/CC0-1.0.lre
+//go:embed licenses/CDDL-1.0.lre licenses/CDDL-1.1.lre licenses/EPL-1.0.lre licenses/EPL-2.0.lre
+//go:embed licenses/EUPL-1.1.lre licenses/EUPL-1.2.lre licenses/GPL-2.0.lre licenses/GPL-3.0.lre
+//go:embed licenses/GPL.lre licenses/ISC.lre licenses/LGPL-2.0.lre licenses/LGPL-2.1.lre
+//go:embed licenses/LGPL-3.0.lre licenses/MIT.lre licenses/MPL-1.1.lre licenses/MPL-2.0.lre
+//go:embed licenses/MS-PL.lre licenses/MS-RL.lre licenses/NCSA.lre licenses/OFL-1.1.lre
+//go:embed licenses/OpenSSL.lre licenses/PHP-3.01.lre licenses/PostgreSQL.lre
+//go:embed licenses/Python-2.0.lre licenses/Ruby.lre licenses/UPL-1.0.lre
+//go:embed licenses/Unicode-DFS-2016.lre licenses/Unlicense.lre licenses/WTFPL.lre licenses/X11.lre
+//go:embed licenses/Zlib.lre
+//go:embed licenses/text/0BSD.txt licenses/text/AGPL-3.0.txt licenses/text/Apache-1.1.txt
+//go:embed licenses/text/Apache-2.0.txt licenses/text/Artistic-1.0.txt
+//go:embed licenses/text/Artistic-2.0.txt licenses/text/BSD-1-Clause.txt
+//go:embed licenses/text/BSD-2-Clause.txt licenses/text/BSD-2-Clause-Patent.txt
+//go:embed licenses/text/BSD-3-Clause.txt licenses/text/BSD-3-Clause-Clear.txt
+//go:embed licenses/text/BSD-4-Clause.txt licenses/text/BSL-1.0.txt licenses/text/BlueOak-1.0.0.txt
+//go:embed licenses/text/CC-BY-3.0.txt licenses/text/CC-BY-4.0.txt licenses/text/CC-BY-SA-3.0.txt
+//go:embed licenses/text/CC-BY-SA-4.0.txt licenses/text/CC0-1.0.txt licenses/text/CDDL-1.0.txt
+//go:embed licenses/text/CDDL-1.1.txt licenses/text/EPL-1.0.txt licenses/text/EPL-2.0.txt
+//go:embed licenses/text/EUPL-1.1.txt licenses/text/EUPL-1.2.txt licenses/text/GPL-2.0.txt
+//go:embed licenses/text/GPL-3.0.txt licenses/text/ISC.txt licenses/text/LGPL-2.0.txt
+//go:embed licenses/text/LGPL-2.1.txt licenses/text/LGPL-3.0.txt licenses/text/MIT.txt
+//go:embed licenses/text/MPL-1.1.txt licenses/text/MPL-2.0.txt licenses/text/MS-PL.txt
+//go:embed licenses/text/MS-RL.txt licenses/text/NCSA.txt licenses/text/OFL-1.1.txt
+//go:embed licenses/text/PHP-3.01.txt licenses/text/PostgreSQL.txt licenses/text/Python-2.0.txt
+//go:embed licenses/text/Ruby.txt licenses/text/UPL-1.0.txt licenses/text/Unicode-DFS-2016.txt
+//go:embed licenses/text/Unlicense.txt licenses/text/WTFPL.txt licenses/text/X11.txt
+//go:embed licenses/text/Zlib.txt
+var licenseFS embed.FS
+
+// builtinProfile lists the IDs of the built-in licenses in this profile.
+var builtinProfile = []string{
+	"0BSD", "AGPL-3.0", "AGPL-3.0-only", "AGPL-3.0-or-later", "Apache-1.1",
+	"Apache-2.0", "Artistic-1.0", "Artistic-2.0", "BSD-1-Clause",
+	"BSD-2-Clause", "BSD-2-Clause-Patent", "BSD-3-Clause", "BSD-3-Clause-Clear",
+	"BSD-4-Clause", "BSL-1.0", "BlueOak-1.0.0", "CC-BY-3.0", "CC-BY-4.0",
+	"CC-BY-SA-3.0", "CC-BY-SA-4.0", "CC0-1.0", "CDDL-1.0", "CDDL-1.1",
+	"EPL-1.0", "EPL-2.0", "EUPL-1.1", "EUPL-1.2", "GPL-2.0", "GPL-2.0-only",
+	"GPL-2.0-or-later", "GPL-3.0", "GPL-3.0-only", "GPL-3.0-or-later", "ISC",
+	"LGPL-2.0", "LGPL-2.0-only", "LGPL-2.0-or-later", "LGPL-2.1",
+	"LGPL-2.1-only", "LGPL-2.1-or-later", "LGPL-3.0", "LGPL-3.0-only",
+	"LGPL-3.0-or-later", "MIT", "MIT-0", "MPL-1.1", "MPL-2.0", "MS-PL", "MS-RL",
+	"NCSA", "OFL-1.1", "OpenSSL", "PHP-3.01", "PostgreSQL", "Python-2.0",
+	"Ruby", "UPL-1.0", "Unicode-DFS-2016", "Unlicense", "WTFPL", "X11", "Zlib",
+}
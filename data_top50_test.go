@@ -0,0 +1,40 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build licensecheck_top50
+// +build licensecheck_top50
+
+package licensecheck
+
+import "testing"
+
+func TestTop50(t *testing.T) {
+	want := make(map[string]bool)
+	for _, id := range builtinProfile {
+		want[id] = true
+	}
+	have := make(map[string]bool)
+	for _, l := range BuiltinLicenses() {
+		if !want[l.ID] {
+			t.Errorf("built-in license %s not in profile", l.ID)
+		}
+		have[l.ID] = true
+	}
+	for id := range want {
+		if !have[id] {
+			t.Errorf("profile license %s not built in", id)
+		}
+	}
+
+	if _, ok := Text("AAL"); ok {
+		t.Errorf("Text(AAL) succeeded, want no text outside profile")
+	}
+	mit, ok := Text("MIT")
+	if !ok {
+		t.Fatal("no text for MIT")
+	}
+	if cov := Scan([]byte(mit)); len(cov.Match) != 1 || cov.Match[0].ID != "MIT" {
+		t.Errorf("Scan(MIT) = %+v, want MIT", cov)
+	}
+}
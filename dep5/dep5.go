@@ -0,0 +1,341 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dep5 parses machine-readable debian/copyright files
+// (https://dep-team.pages.debian.net/deps/dep5/)
+// and checks them against the licenses licensecheck finds in a source tree.
+//
+// A debian/copyright file is a sequence of stanzas.
+// Files stanzas declare the copyright and license of the files
+// matching a list of patterns, the last matching stanza taking precedence.
+// Standalone License stanzas hold the text of licenses named elsewhere.
+//
+//	c, err := dep5.Parse(data)
+//	if err != nil {
+//		...
+//	}
+//	findings, err := c.Check(os.DirFS(dir))
+//	for _, f := range findings {
+//		fmt.Printf("%s: declared %s, found %v\n", f.Path, f.Declared, f.Detected)
+//	}
+//
+// Debian license short names, such as Expat and GPL-2+,
+// are compared with licensecheck's SPDX identifiers, such as MIT and GPL-2.0-or-later,
+// by the rules in Same.
+package dep5
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/google/licensecheck"
+)
+
+// A Copyright is a parsed debian/copyright file.
+type Copyright struct {
+	Format       string     // Format field of the header stanza
+	UpstreamName string     // Upstream-Name field of the header stanza
+	Source       string     // Source field of the header stanza
+	Files        []*Files   // Files stanzas, in file order
+	Licenses     []*License // standalone License stanzas, in file order
+}
+
+// A Files stanza declares the copyright and license of a set of files.
+type Files struct {
+	Line      int      // line number of the stanza in the file
+	Patterns  []string // patterns from the Files field, such as * or src/*.c
+	Copyright string   // Copyright field
+	License   string   // license name or expression from the first line of the License field
+	Text      string   // license text from the rest of the License field, if any
+
+	re []*regexp.Regexp // compiled Patterns, set by Parse
+}
+
+// A License stanza gives the text of a license named in Files stanzas.
+type License struct {
+	Line int    // line number of the stanza in the file
+	Name string // license name from the first line of the License field
+	Text string // license text from the rest of the License field
+}
+
+// Parse parses the content of a machine-readable debian/copyright file.
+func Parse(data []byte) (*Copyright, error) {
+	stanzas, err := parseStanzas(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(stanzas) == 0 || stanzas[0].fields["format"] == "" {
+		return nil, fmt.Errorf("dep5: missing Format field: not a machine-readable copyright file")
+	}
+	c := &Copyright{
+		Format:       stanzas[0].fields["format"],
+		UpstreamName: stanzas[0].fields["upstream-name"],
+		Source:       stanzas[0].fields["source"],
+	}
+	for _, st := range stanzas[1:] {
+		name, text := splitLicense(st.fields["license"])
+		if files, ok := st.fields["files"]; ok {
+			if name == "" {
+				return nil, fmt.Errorf("dep5: line %d: Files stanza without License", st.line)
+			}
+			f := &Files{
+				Line:      st.line,
+				Patterns:  strings.Fields(files),
+				Copyright: st.fields["copyright"],
+				License:   name,
+				Text:      text,
+			}
+			for _, pattern := range f.Patterns {
+				f.re = append(f.re, compilePattern(pattern))
+			}
+			c.Files = append(c.Files, f)
+			continue
+		}
+		if name == "" {
+			return nil, fmt.Errorf("dep5: line %d: stanza has neither Files nor License", st.line)
+		}
+		c.Licenses = append(c.Licenses, &License{Line: st.line, Name: name, Text: text})
+	}
+	return c, nil
+}
+
+// A stanza is a paragraph of fields.
+// Field names are lower-cased.
+// Multi-line values keep their line breaks, with the leading space
+// of continuation lines removed and " ." lines turned into blank lines.
+type stanza struct {
+	line   int
+	fields map[string]string
+}
+
+func parseStanzas(data []byte) ([]*stanza, error) {
+	var list []*stanza
+	var st *stanza
+	var field string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for lineno := 1; sc.Scan(); lineno++ {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		switch {
+		case line == "":
+			st = nil
+			continue
+		case line[0] == '#':
+			continue
+		case line[0] == ' ' || line[0] == '\t':
+			if st == nil || field == "" {
+				return nil, fmt.Errorf("dep5: line %d: unexpected continuation line", lineno)
+			}
+			line = strings.TrimSpace(line)
+			if line == "." {
+				line = ""
+			}
+			st.fields[field] += "\n" + line
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("dep5: line %d: malformed field", lineno)
+		}
+		if st == nil {
+			st = &stanza{line: lineno, fields: make(map[string]string)}
+			list = append(list, st)
+		}
+		field = strings.ToLower(line[:i])
+		if _, ok := st.fields[field]; ok {
+			return nil, fmt.Errorf("dep5: line %d: duplicate field %s", lineno, line[:i])
+		}
+		st.fields[field] = strings.TrimSpace(line[i+1:])
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("dep5: %v", err)
+	}
+	return list, nil
+}
+
+// splitLicense splits a License field value into
+// the license name on its first line and the license text after it.
+func splitLicense(value string) (name, text string) {
+	if i := strings.Index(value, "\n"); i >= 0 {
+		return strings.TrimSpace(value[:i]), strings.TrimPrefix(value[i+1:], "\n")
+	}
+	return strings.TrimSpace(value), ""
+}
+
+// FilesFor returns the Files stanza governing the file with the given
+// slash-separated path, which is the last stanza with a matching pattern,
+// or nil if no stanza matches.
+func (c *Copyright) FilesFor(file string) *Files {
+	file = path.Clean(file)
+	for i := len(c.Files) - 1; i >= 0; i-- {
+		if c.Files[i].match(file) {
+			return c.Files[i]
+		}
+	}
+	return nil
+}
+
+// match reports whether the cleaned file path matches any of f's patterns.
+func (f *Files) match(file string) bool {
+	if len(f.re) != len(f.Patterns) {
+		// Not from Parse, or Patterns changed since: compile them now.
+		for _, pattern := range f.Patterns {
+			if compilePattern(pattern).MatchString(file) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, re := range f.re {
+		if re.MatchString(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether the file path matches the Files pattern,
+// in which * matches any sequence of characters, including slashes,
+// ? matches any single character, and \ escapes the next character.
+// As in the DEP-5 specification, a pattern naming a directory
+// does not match the files in it; use dir/* for that.
+//
+// Match compiles the pattern on each call.
+// FilesFor uses the patterns compiled once by Parse.
+func Match(pattern, file string) bool {
+	return compilePattern(pattern).MatchString(path.Clean(file))
+}
+
+// compilePattern returns a regexp matching the file paths
+// that the Files pattern matches.
+func compilePattern(pattern string) *regexp.Regexp {
+	pattern = strings.TrimPrefix(pattern, "./")
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	// Every character is quoted or translated, so the regexp is always valid.
+	return regexp.MustCompile(b.String())
+}
+
+// A Finding reports a file whose detected licenses disagree
+// with the license declared for it in debian/copyright.
+type Finding struct {
+	Path     string   // slash-separated path of the file
+	Files    *Files   // governing Files stanza, or nil if none
+	Declared string   // declared license name or expression; empty if no stanza matches
+	Detected []string // IDs of licenses licensecheck found in the file
+}
+
+// Check scans each file in fsys, outside the debian directory,
+// and reports the files in which licensecheck finds a license
+// that the governing Files stanza does not declare.
+// Source files are scanned with licensecheck.ScanSource,
+// so only their comments are considered.
+// Files in which no license is found are not reported,
+// since most files carry no license text of their own.
+func (c *Copyright) Check(fsys fs.FS) ([]Finding, error) {
+	var findings []Finding
+	err := fs.WalkDir(fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if file == "debian" || d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		var detected []string
+		for _, m := range licensecheck.ScanSource(file, data).Match {
+			detected = append(detected, m.ID)
+		}
+		if len(detected) == 0 {
+			return nil
+		}
+		f := Finding{Path: file, Files: c.FilesFor(file), Detected: detected}
+		if f.Files != nil {
+			f.Declared = f.Files.License
+			if declares(f.Declared, detected) {
+				return nil
+			}
+		}
+		findings = append(findings, f)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dep5: %v", err)
+	}
+	return findings, nil
+}
+
+// declares reports whether the license expression declared
+// names every one of the detected licenses.
+func declares(declared string, detected []string) bool {
+	names := strings.FieldsFunc(declared, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '(' || r == ')'
+	})
+Detected:
+	for _, id := range detected {
+		for _, name := range names {
+			if Same(name, id) {
+				continue Detected
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// debianNames maps lower-case Debian license names
+// to the corresponding lower-case SPDX names, where they differ.
+var debianNames = map[string]string{
+	"expat": "mit",
+}
+
+// Same reports whether the Debian license name
+// and the SPDX license ID refer to the same license.
+// The comparison ignores case and treats a trailing ".0" in versions as optional,
+// so that GPL-2 is the same as GPL-2.0.
+// Whether later versions are allowed (GPL-2 versus GPL-2+) is ignored,
+// since a license text alone does not say.
+func Same(name, id string) bool {
+	return canonical(name) == canonical(id)
+}
+
+func canonical(name string) string {
+	name = strings.ToLower(name)
+	name = strings.TrimSuffix(name, "+")
+	name = strings.TrimSuffix(name, "-only")
+	name = strings.TrimSuffix(name, "-or-later")
+	if n, ok := debianNames[name]; ok {
+		name = n
+	}
+	for strings.HasSuffix(name, ".0") {
+		name = strings.TrimSuffix(name, ".0")
+	}
+	return name
+}
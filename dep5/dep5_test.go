@@ -0,0 +1,156 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dep5
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+)
+
+const testCopyright = `Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/
+Upstream-Name: example
+Source: https://example.com/example
+
+# Everything is MIT unless noted.
+Files: *
+Copyright: 2020 Example Authors
+License: Expat
+
+Files: vendor/*
+ third_party/gpl.c
+Copyright: 2019 Someone Else
+License: GPL-2+
+
+Files: debian/*
+Copyright: 2020 Packager
+License: GPL-2+
+
+License: GPL-2+
+ This program is free software.
+ .
+ See /usr/share/common-licenses/GPL-2.
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(testCopyright))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.UpstreamName != "example" || c.Source != "https://example.com/example" || len(c.Files) != 3 || len(c.Licenses) != 1 {
+		t.Fatalf("Parse = %+v, want 3 Files and 1 License stanza", c)
+	}
+	if f := c.Files[1]; f.Line != 10 || !reflect.DeepEqual(f.Patterns, []string{"vendor/*", "third_party/gpl.c"}) || f.License != "GPL-2+" || f.Copyright != "2019 Someone Else" {
+		t.Errorf("Files[1] = %+v", f)
+	}
+	if l := c.Licenses[0]; l.Name != "GPL-2+" || l.Text != "This program is free software.\n\nSee /usr/share/common-licenses/GPL-2." {
+		t.Errorf("Licenses[0] = %+v", l)
+	}
+
+	for _, bad := range []string{
+		"Files: *\nLicense: MIT\n",
+		"Format: x\n\nFiles: *\nCopyright: me\n",
+		"Format: x\n\nCopyright: me\n",
+		"Format: x\n\n continuation\n",
+		"Format: x\nFormat: y\n",
+	} {
+		if c, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("Parse(%q) = %+v, want error", bad, c)
+		}
+	}
+}
+
+func TestFilesFor(t *testing.T) {
+	c, err := Parse([]byte(testCopyright))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for file, want := range map[string]string{
+		"main.go":             "Expat",
+		"vendor/x/y.go":       "GPL-2+",
+		"third_party/gpl.c":   "GPL-2+",
+		"third_party/other.c": "Expat",
+	} {
+		if f := c.FilesFor(file); f == nil || f.License != want {
+			t.Errorf("FilesFor(%q) = %+v, want %s", file, f, want)
+		}
+	}
+	if !Match("doc/*", "doc/a/b.txt") || Match("a?c", "abbc") || !Match(`a\*`, "a*") || Match(`a\*`, "ab") || !Match("./a.c", "a.c") {
+		t.Errorf("Match mismatch")
+	}
+
+	// A bare directory name does not match the files in it.
+	if Match("doc", "doc/a.txt") || !Match("doc", "doc") {
+		t.Errorf("Match(doc) mismatch")
+	}
+
+	// Files stanzas built by hand, without Parse, work too.
+	c = &Copyright{Files: []*Files{{Patterns: []string{"*"}, License: "Expat"}, {Patterns: []string{"lib/*"}, License: "ISC"}}}
+	if f := c.FilesFor("lib/x.c"); f == nil || f.License != "ISC" {
+		t.Errorf("FilesFor(lib/x.c) = %+v, want ISC", f)
+	}
+}
+
+func TestSame(t *testing.T) {
+	for _, tt := range []struct {
+		name, id string
+		same     bool
+	}{
+		{"Expat", "MIT", true},
+		{"GPL-2+", "GPL-2.0-or-later", true},
+		{"GPL-2", "GPL-2.0", true},
+		{"BSD-3-clause", "BSD-3-Clause", true},
+		{"Apache-2.0", "Apache-2.0", true},
+		{"GPL-2", "GPL-3.0", false},
+		{"MIT", "ISC", false},
+	} {
+		if same := Same(tt.name, tt.id); same != tt.same {
+			t.Errorf("Same(%q, %q) = %v, want %v", tt.name, tt.id, same, tt.same)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	c, err := Parse([]byte(testCopyright))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mit, _ := licensecheck.Text("MIT")
+	gpl := `This program is free software; you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation; either version 2 of the License, or
+(at your option) any later version.
+`
+	comment := func(s string) string {
+		return "// " + strings.ReplaceAll(strings.TrimSuffix(s, "\n"), "\n", "\n// ") + "\n\npackage p\n"
+	}
+	fsys := fstest.MapFS{
+		"LICENSE":           {Data: []byte(mit)},
+		"main.go":           {Data: []byte("package main\n")},
+		"vendor/x/y.go":     {Data: []byte(comment(gpl))},
+		"internal/z.go":     {Data: []byte(comment(gpl))},
+		"debian/copyright":  {Data: []byte(testCopyright)},
+		"debian/rules":      {Data: []byte(gpl)},
+		"third_party/gpl.c": {Data: []byte("/*\n" + mit + "*/\nint x;\n")},
+	}
+	findings, err := c.Check(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Path+": "+f.Declared+" "+strings.Join(f.Detected, ","))
+	}
+	want := []string{
+		"internal/z.go: Expat GPL-2.0-or-later",
+		"third_party/gpl.c: GPL-2+ MIT",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check:\nhave %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/licensecheck/internal/match"
+)
+
+// DiffAgainst returns a word diff between the canonical text
+// of the license with the given ID (see Text) and the input text,
+// whether or not Scan recognizes the input as that license.
+// It returns an error if the license's canonical text is not available.
+//
+// The diff is in the style of git diff --word-diff=plain:
+// after a two-line header naming the license and the input,
+// it reproduces the input text, marking the words found only in the input
+// as {+added+} and the words found only in the license as [-removed-].
+// Words are compared after the normalization used by Scan,
+// so differences in case, punctuation, and spacing are not reported.
+func DiffAgainst(input []byte, id string) (string, error) {
+	return builtinScanner.DiffAgainst(input, id)
+}
+
+// DiffAgainst is like the top-level function DiffAgainst,
+// but it uses the set of licenses in the Scanner instead of the built-in license set.
+func (s *Scanner) DiffAgainst(input []byte, id string) (string, error) {
+	text, ok := s.Text(id)
+	if !ok {
+		return "", fmt.Errorf("licensecheck: no text for license %q", id)
+	}
+
+	d := new(match.Dict)
+	lw := d.InsertSplit(text)
+	iw := d.InsertSplit(string(input))
+	pairs := diffWords(wordIDsOf(lw), wordIDsOf(iw), 0, 0, nil)
+	pairs = append(pairs, [2]int{len(lw), len(iw)}) // sentinel
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ input\n", id)
+	pos := 0 // offset in input of text not yet written
+	copyTo := func(end int) {
+		if end > pos {
+			b.Write(input[pos:end])
+			pos = end
+		}
+	}
+	li, ii := 0, 0
+	for _, p := range pairs {
+		if ii < len(iw) {
+			copyTo(int(iw[ii].Lo))
+		}
+		if li < p[0] {
+			b.WriteString("[-")
+			b.WriteString(strings.Join(strings.Fields(text[lw[li].Lo:lw[p[0]-1].Hi]), " "))
+			b.WriteString("-]")
+			if ii == p[1] && p[1] < len(iw) {
+				// Separate the removed words from the next input word.
+				b.WriteString(" ")
+			}
+		}
+		if ii < p[1] {
+			b.WriteString("{+")
+			copyTo(int(iw[p[1]-1].Hi))
+			b.WriteString("+}")
+		}
+		if p[1] < len(iw) {
+			copyTo(int(iw[p[1]].Hi))
+		}
+		li, ii = p[0]+1, p[1]+1
+	}
+	copyTo(len(input))
+	if !strings.HasSuffix(b.String(), "\n") {
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// wordIDsOf returns the IDs of words.
+func wordIDsOf(words []match.Word) []match.WordID {
+	list := make([]match.WordID, len(words))
+	for i, w := range words {
+		list[i] = w.ID
+	}
+	return list
+}
+
+// diffWords appends to pairs the index pairs {i, j} of a longest common
+// subsequence of a and b, in increasing order, and returns the result.
+// The indexes are offset by ai and bi, the positions of a and b
+// in the original sequences.
+// It uses Hirschberg's algorithm, which needs space only linear
+// in the lengths of a and b.
+func diffWords(a, b []match.WordID, ai, bi int, pairs [][2]int) [][2]int {
+	a, b, pre, suf := trimCommon(a, b)
+	for k := 0; k < pre; k++ {
+		pairs = append(pairs, [2]int{ai + k, bi + k})
+	}
+	ai, bi = ai+pre, bi+pre
+
+	switch {
+	case len(a) == 0 || len(b) == 0:
+		// Nothing in common.
+	case len(a) == 1:
+		for j, w := range b {
+			if w == a[0] {
+				pairs = append(pairs, [2]int{ai, bi + j})
+				break
+			}
+		}
+	default:
+		// Split a in half and find the best place to split b,
+		// using the LCS lengths of the first half of a with prefixes of b
+		// and of the second half of a with suffixes of b.
+		mid := len(a) / 2
+		fwd := lcsRow(a[:mid], b)
+		rev := lcsRow(reverseWords(a[mid:]), reverseWords(b))
+		best, k := int32(-1), 0
+		for j := 0; j <= len(b); j++ {
+			if n := fwd[j] + rev[len(b)-j]; n > best {
+				best, k = n, j
+			}
+		}
+		pairs = diffWords(a[:mid], b[:k], ai, bi, pairs)
+		pairs = diffWords(a[mid:], b[k:], ai+mid, bi+k, pairs)
+	}
+
+	for k := 0; k < suf; k++ {
+		pairs = append(pairs, [2]int{ai + len(a) + k, bi + len(b) + k})
+	}
+	return pairs
+}
+
+// reverseWords returns a reversed copy of words.
+func reverseWords(words []match.WordID) []match.WordID {
+	r := make([]match.WordID, len(words))
+	for i, w := range words {
+		r[len(words)-1-i] = w
+	}
+	return r
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffAgainst(t *testing.T) {
+	var diffTests = []struct {
+		lic  string
+		in   string
+		want string
+	}{
+		{"a b c d", "a b c d", "a b c d\n"},
+		{"a b c d", "A, B. c d!\n", "A, B. c d!\n"},
+		{"a b c d", "a x c d", "a [-b-]{+x+} c d\n"},
+		{"a b c d", "a b x y c d", "a b {+x y+} c d\n"},
+		{"a b c d", "a d", "a [-b c-] d\n"},
+		{"a b c d", "x a b c d y", "{+x+} a b c d {+y+}\n"},
+		{"a b\nc d", "", "[-a b c d-]\n"},
+	}
+	for _, tt := range diffTests {
+		s, err := NewScanner([]License{{ID: "L", LRE: "zzz yyy", Text: tt.lic}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.DiffAgainst([]byte(tt.in), "L")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "--- L\n+++ input\n" + tt.want
+		if got != want {
+			t.Errorf("DiffAgainst(%q, %q):\n%s\nwant:\n%s", tt.in, tt.lic, got, want)
+		}
+	}
+
+	// A modified built-in license.
+	in := strings.Replace(mitText, "free of charge", "for a small fee", 1)
+	got, err := DiffAgainst([]byte(in), "MIT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "[-free of charge-]{+for a small fee+}") {
+		t.Errorf("DiffAgainst(modified MIT) = %s\nwant [-free of charge-]{+for a small fee+}", got)
+	}
+
+	if _, err := DiffAgainst([]byte(in), "No-Such-License"); err == nil {
+		t.Errorf("DiffAgainst(unknown license) succeeded")
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxEULAPercent is the largest Coverage.Percent
+// of a text that can be reported as an EULA.
+// A text mostly covered by known licenses is one of them,
+// whatever phrases it has in common with an EULA.
+const maxEULAPercent = 50
+
+// eulaPhrases lists, for each feature of an end-user license agreement
+// or terms of service, phrases showing the feature, in normalized form:
+// lower case, with words separated by single spaces.
+// The first two features, an act of use and the user's consent to the terms,
+// together make the agreement binding on whoever installs or uses the software,
+// which is what distinguishes a clickwrap agreement from an open-source license;
+// both are required.
+var eulaPhrases = [][]string{
+	// Act of use.
+	{"by installing", "by downloading", "by accessing", "by using", "by clicking", "by copying", "by opening"},
+	// Consent.
+	{"you agree", "you accept", "i agree", "i accept", "agree to be bound", "accept the terms"},
+	// Title.
+	{"end user license agreement", "end user licence agreement", "eula",
+		"software license agreement", "terms of service", "terms of use"},
+	// Termination.
+	{"termination", "terminate", "terminated", "terminates"},
+	// Proprietary restrictions.
+	{"reverse engineer", "decompile", "disassemble", "licensed not sold", "non transferable", "nontransferable"},
+}
+
+// Numbers of features in eulaPhrases: the required ones, at the start,
+// and the least that a text must show to be reported as an EULA.
+const (
+	requiredEULAFeatures = 2
+	minEULAFeatures      = 4
+)
+
+// setEULA sets c.EULA according to whether text,
+// which must be the text scanned, looks like an EULA.
+func (c *Coverage) setEULA(text []byte) {
+	c.EULA = c.Percent <= maxEULAPercent && !c.Empty && isEULA(text)
+}
+
+// isEULA reports whether text shows the required features of an EULA
+// and enough others (see eulaPhrases).
+func isEULA(text []byte) bool {
+	words := strings.FieldsFunc(strings.ToLower(string(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	norm := " " + strings.Join(words, " ") + " "
+	n := 0
+	for i, phrases := range eulaPhrases {
+		found := false
+		for _, p := range phrases {
+			if strings.Contains(norm, " "+p+" ") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			if i < requiredEULAFeatures {
+				return false
+			}
+			continue
+		}
+		n++
+	}
+	return n >= minEULAFeatures
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"errors"
+	"testing"
+)
+
+const testEULA = `GOPHER SDK END USER LICENSE AGREEMENT
+
+IMPORTANT: READ CAREFULLY. By installing, copying, or otherwise using the
+Gopher SDK, you agree to be bound by the terms of this Agreement.
+If you do not agree, do not install or use the SDK.
+
+1. License Grant. Gopher Inc. grants you a non-exclusive, non-transferable
+license to use the SDK solely to develop applications for Gopher devices.
+The SDK is licensed, not sold.
+
+2. Restrictions. You may not reverse engineer, decompile, or disassemble the SDK.
+
+3. Termination. This Agreement terminates automatically if you fail to comply
+with any of its terms.
+`
+
+const testToS = `Terms of Service
+
+By accessing the Gopher Cloud API you accept these Terms of Use.
+We may suspend or terminate your access at any time, for any reason.
+`
+
+func TestEULA(t *testing.T) {
+	apache, _ := Text("Apache-2.0")
+	tests := []struct {
+		name string
+		text string
+		eula bool
+	}{
+		{"EULA", testEULA, true},
+		{"ToS", testToS, true},
+		{"ConsentOnly", "By using this library you agree that it is provided as is.", false},
+		{"Apache", apache, false},
+		{"Empty", "", false},
+	}
+	for _, tt := range tests {
+		if cov, _ := ScanWithOptions([]byte(tt.text), Options{EULA: true}); cov.EULA != tt.eula {
+			t.Errorf("ScanWithOptions(%s, EULA).EULA = %v, want %v", tt.name, cov.EULA, tt.eula)
+		}
+	}
+
+	if cov := Scan([]byte(testEULA)); cov.EULA {
+		t.Errorf("Scan(EULA).EULA = true, want false without Options.EULA")
+	}
+	cov, err := ScanWithOptions([]byte(testEULA), Options{EULA: true})
+	if !errors.Is(err, ErrNoMatch) || !cov.EULA {
+		t.Errorf("ScanWithOptions(EULA) = EULA %v, err %v, want EULA true, ErrNoMatch", cov.EULA, err)
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package evidence collects the results of a scan into the tables
+// a legal review asks for and writes them as a bundle of CSV files,
+// which any spreadsheet program opens directly.
+//
+// A Report has three tables:
+// one row per file giving the license determined for it,
+// one row per copyright notice found in a file, with its holder,
+// and one row per low-confidence match, with an excerpt of the text matched,
+// so that a reviewer can check each doubtful finding by eye:
+//
+//	r := new(evidence.Report)
+//	if err := r.AddFS(fsys, files, licensecheck.Options{}); err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := r.WriteZip(out); err != nil { // files.csv, copyrights.csv, findings.csv
+//		log.Fatal(err)
+//	}
+package evidence
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"io"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/internal/strs"
+)
+
+// Defaults for the Report settings.
+const (
+	DefaultMinConfidence = 80  // see Report.MinConfidence
+	DefaultMaxExcerpt    = 500 // see Report.MaxExcerpt
+)
+
+// A Report is the evidence gathered from scanning a set of files.
+// The settings must not be changed after the first call to Add.
+type Report struct {
+	// MinConfidence is the Match.Confidence below which
+	// a match is listed as a low-confidence Finding.
+	// If zero, DefaultMinConfidence is used.
+	MinConfidence float64
+
+	// MaxExcerpt is the most bytes of text to quote in a Finding.
+	// Longer excerpts are cut at a UTF-8 boundary and end in "...".
+	// If zero, DefaultMaxExcerpt is used.
+	MaxExcerpt int
+
+	Files      []File      // one per file, in the order added
+	Copyrights []Copyright // in the order found
+	Findings   []Finding   // in the order found
+
+	byPath map[string]int // index in Files of each file
+}
+
+// A File is the determination for a single file.
+type File struct {
+	Path        string
+	License     string   // license covering most of the file (see Coverage.Primary), or ""
+	Licenses    []string // IDs of all the licenses matched, sorted
+	Percent     float64  // percentage of the file covered by matches
+	DuplicateOf string   // copied from licensecheck.FileCoverage
+	Error       string   // error from scanning, other than finding no license
+}
+
+// A Copyright is a copyright notice found in a file.
+type Copyright struct {
+	Path   string
+	Notice string // the notice, such as "Copyright (c) 2020 The Go Authors. All rights reserved."
+	Holder string // the holder named by the notice, such as "The Go Authors"
+}
+
+// A Finding is a match that a reviewer should check by hand,
+// because its confidence is low.
+type Finding struct {
+	Path       string
+	License    string
+	Start, End int // byte offsets of the match in the file
+	Confidence float64
+	Excerpt    string // text matched, shortened to at most MaxExcerpt bytes
+}
+
+// Add adds the result of scanning one file to the report.
+// The text is the content of the file, used for copyright notices
+// and for excerpts; it may be nil if neither is wanted.
+// A file whose fc.DuplicateOf names a file already added
+// gets the same determination as that file.
+func (r *Report) Add(fc licensecheck.FileCoverage, text []byte) {
+	if r.byPath == nil {
+		r.byPath = make(map[string]int)
+	}
+	f := File{Path: fc.File, DuplicateOf: fc.DuplicateOf}
+	if fc.Err != nil && !errors.Is(fc.Err, licensecheck.ErrNoMatch) && !errors.Is(fc.Err, licensecheck.ErrEmptyInput) {
+		f.Error = fc.Err.Error()
+	}
+	if i, ok := r.byPath[fc.DuplicateOf]; ok && fc.DuplicateOf != "" {
+		orig := r.Files[i]
+		f.License, f.Licenses, f.Percent = orig.License, orig.Licenses, orig.Percent
+	} else {
+		cov := fc.Coverage
+		f.License, _ = cov.Primary()
+		f.Percent = cov.Percent
+		for id := range cov.ByLicense() {
+			f.Licenses = append(f.Licenses, id)
+		}
+		sort.Strings(f.Licenses)
+		for _, m := range cov.Match {
+			if m.Confidence < r.minConfidence() {
+				r.Findings = append(r.Findings, Finding{
+					Path:       fc.File,
+					License:    m.ID,
+					Start:      m.Start,
+					End:        m.End,
+					Confidence: m.Confidence,
+					Excerpt:    r.excerpt(m.Excerpt(text)),
+				})
+			}
+		}
+	}
+	r.byPath[fc.File] = len(r.Files)
+	r.Files = append(r.Files, f)
+
+	seen := make(map[string]bool)
+	for _, c := range copyrights(string(text)) {
+		if !seen[c.Notice] {
+			seen[c.Notice] = true
+			c.Path = fc.File
+			r.Copyrights = append(r.Copyrights, c)
+		}
+	}
+}
+
+// AddFS scans the named files in fsys using licensecheck.ScanFSFunc
+// and adds each result to the report.
+// Excerpts and copyright notices are taken from the text scanned,
+// as extracted by licensecheck.ExtractText.
+func (r *Report) AddFS(fsys fs.FS, files []string, opts licensecheck.Options) error {
+	return licensecheck.ScanFSFunc(fsys, files, opts, func(fc licensecheck.FileCoverage) error {
+		data, err := fs.ReadFile(fsys, fc.File)
+		if err != nil {
+			return err
+		}
+		text, err := licensecheck.ExtractText(fc.File, data)
+		if err != nil {
+			text = nil // reported in fc.Err
+		}
+		r.Add(fc, text)
+		return nil
+	})
+}
+
+func (r *Report) minConfidence() float64 {
+	if r.MinConfidence == 0 {
+		return DefaultMinConfidence
+	}
+	return r.MinConfidence
+}
+
+// excerpt returns text shortened to at most r.MaxExcerpt bytes.
+func (r *Report) excerpt(text []byte) string {
+	max := r.MaxExcerpt
+	if max == 0 {
+		max = DefaultMaxExcerpt
+	}
+	return strs.Excerpt(text, max)
+}
+
+var (
+	copyrightRE  = regexp.MustCompile(`(?m)^[^\pL\pN\r\n]*((?:(?:Copyright|COPYRIGHT)(?:[ \t]*(?:\([cC]\)|©))?|©)[ \t]+[^\r\n]*)`)
+	copyMarkRE   = regexp.MustCompile(`^(?:Copyright|COPYRIGHT)?[ \t]*(?:\([cC]\)|©)?[ \t]*`)
+	yearsRE      = regexp.MustCompile(`^(?:(?:19|20)\d\d[ \t]*(?:[-–,][ \t]*)?)+`)
+	reservedRE   = regexp.MustCompile(`(?i)[ \t.,;]*all rights reserved\.?$`)
+	commentEndRE = regexp.MustCompile(`[ \t]*(?:\*/|-->|-\}|\*\))$`)
+)
+
+// copyrights returns the copyright notices in text.
+// A line counts as a notice only if it begins with "Copyright" or ©
+// and has a year or a copyright sign, so that lines
+// like "Copyright and license terms" in prose are skipped.
+func copyrights(text string) []Copyright {
+	var list []Copyright
+	for _, m := range copyrightRE.FindAllStringSubmatch(text, -1) {
+		notice := strings.TrimSpace(commentEndRE.ReplaceAllString(m[1], ""))
+		mark := copyMarkRE.FindString(notice)
+		rest := notice[len(mark):]
+		years := yearsRE.FindString(rest)
+		if years == "" && !strings.ContainsAny(mark, "(©") {
+			continue
+		}
+		holder := rest[len(years):]
+		holder = strings.TrimSpace(reservedRE.ReplaceAllString(holder, ""))
+		holder = strings.TrimLeft(holder, ", ")
+		holder = strings.TrimPrefix(holder, "by ")
+		list = append(list, Copyright{Notice: notice, Holder: holder})
+	}
+	return list
+}
+
+// WriteFiles writes the table of files to w as CSV, with a header row.
+func (r *Report) WriteFiles(w io.Writer) error {
+	rows := [][]string{{"file", "license", "licenses", "percent", "duplicate_of", "error"}}
+	for _, f := range r.Files {
+		rows = append(rows, []string{f.Path, f.License, strings.Join(f.Licenses, " "), formatFloat(f.Percent), f.DuplicateOf, f.Error})
+	}
+	return writeCSV(w, rows)
+}
+
+// WriteCopyrights writes the table of copyright notices to w as CSV, with a header row.
+func (r *Report) WriteCopyrights(w io.Writer) error {
+	rows := [][]string{{"file", "holder", "notice"}}
+	for _, c := range r.Copyrights {
+		rows = append(rows, []string{c.Path, c.Holder, c.Notice})
+	}
+	return writeCSV(w, rows)
+}
+
+// WriteFindings writes the table of low-confidence findings to w as CSV, with a header row.
+func (r *Report) WriteFindings(w io.Writer) error {
+	rows := [][]string{{"file", "license", "start", "end", "confidence", "excerpt"}}
+	for _, f := range r.Findings {
+		rows = append(rows, []string{f.Path, f.License, strconv.Itoa(f.Start), strconv.Itoa(f.End), formatFloat(f.Confidence), f.Excerpt})
+	}
+	return writeCSV(w, rows)
+}
+
+// WriteZip writes the three tables to w as a zip archive
+// holding files.csv, copyrights.csv, and findings.csv.
+func (r *Report) WriteZip(w io.Writer) error {
+	z := zip.NewWriter(w)
+	for _, t := range []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"files.csv", r.WriteFiles},
+		{"copyrights.csv", r.WriteCopyrights},
+		{"findings.csv", r.WriteFindings},
+	} {
+		fw, err := z.Create(t.name)
+		if err != nil {
+			return err
+		}
+		if err := t.write(fw); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 1, 64)
+}
+
+// writeCSV writes rows to w as CSV.
+// Cells that a spreadsheet would take for a formula,
+// because they begin with =, +, -, or @, are prefixed with a quote,
+// since excerpts and notices come from the scanned files.
+func writeCSV(w io.Writer, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	for _, row := range rows {
+		for i, cell := range row {
+			if cell != "" && strings.ContainsRune("=+-@", rune(cell[0])) {
+				row[i] = "'" + cell
+			}
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
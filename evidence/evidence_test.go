@@ -0,0 +1,92 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package evidence
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+)
+
+func TestReport(t *testing.T) {
+	mit, _ := licensecheck.Text("MIT")
+	mit = strings.Replace(mit, "Copyright <YEAR> <HOLDER>", "Copyright (c) 2019-2020, Jane Doe", 1)
+	fsys := fstest.MapFS{
+		"LICENSE":   {Data: []byte(mit)},
+		"a/LICENSE": {Data: []byte(mit)},
+		"main.go": {Data: []byte("// Copyright 2020 The Go Authors. All rights reserved.\n" +
+			"// This code is licensed by https://creativecommons.org/licenses/BY/4.0/ so have fun\n\npackage main\n")},
+		"README": {Data: []byte("=cmd|' /C calc'!A0\nCopyright and licensing are described below.\n")},
+	}
+	r := &Report{MinConfidence: 95, MaxExcerpt: 20}
+	if err := r.AddFS(fsys, []string{"LICENSE", "a/LICENSE", "main.go", "README"}, licensecheck.Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var have []string
+	for _, f := range r.Files {
+		have = append(have, f.Path+":"+f.License+":"+strings.Join(f.Licenses, ",")+":"+f.DuplicateOf)
+	}
+	want := []string{"LICENSE:MIT:MIT:", "a/LICENSE:MIT:MIT:LICENSE", "main.go:CC-BY-4.0:CC-BY-4.0:", "README:::"}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("Files = %q, want %q", have, want)
+	}
+
+	wantCopyrights := []Copyright{
+		{"LICENSE", "Copyright (c) 2019-2020, Jane Doe", "Jane Doe"},
+		{"a/LICENSE", "Copyright (c) 2019-2020, Jane Doe", "Jane Doe"},
+		{"main.go", "Copyright 2020 The Go Authors. All rights reserved.", "The Go Authors"},
+	}
+	if !reflect.DeepEqual(r.Copyrights, wantCopyrights) {
+		t.Errorf("Copyrights = %+v, want %+v", r.Copyrights, wantCopyrights)
+	}
+
+	if len(r.Findings) != 1 || r.Findings[0].Path != "main.go" || r.Findings[0].License != "CC-BY-4.0" ||
+		r.Findings[0].Excerpt != "https://creativecomm..." {
+		t.Errorf("Findings = %+v, want one CC-BY-4.0 URL finding in main.go", r.Findings)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteZip(&buf); err != nil {
+		t.Fatal(err)
+	}
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := make(map[string]int)
+	for _, f := range z.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs, err := csv.NewReader(rc).ReadAll()
+		rc.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", f.Name, err)
+		}
+		rows[f.Name] = len(recs)
+	}
+	wantRows := map[string]int{"files.csv": 5, "copyrights.csv": 4, "findings.csv": 2}
+	if !reflect.DeepEqual(rows, wantRows) {
+		t.Errorf("zip tables have %v rows, want %v", rows, wantRows)
+	}
+}
+
+func TestWriteCSVFormula(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeCSV(&buf, [][]string{{"=1+2", "-x", "ok"}}); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := buf.String(), "'=1+2,'-x,ok\n"; have != want {
+		t.Errorf("writeCSV = %q, want %q", have, want)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// An Extractor extracts the text to be scanned from the content of a file
+// in some document format, such as PDF or DOCX,
+// so that licenses delivered as documents can be scanned like plain text.
+// ScanFile and ScanFS choose the Extractor for a file by its extension
+// (see RegisterExtractor) and report match offsets in the extracted text.
+//
+// An Extractor must be safe to call from multiple goroutines at once.
+// It must not retain data after the scan,
+// since ScanFile may pass it the contents of a memory-mapped file.
+type Extractor interface {
+	Extract(data []byte) ([]byte, error)
+}
+
+// An ExtractorFunc is a function implementing Extractor.
+type ExtractorFunc func(data []byte) ([]byte, error)
+
+// Extract returns f(data).
+func (f ExtractorFunc) Extract(data []byte) ([]byte, error) {
+	return f(data)
+}
+
+// The built-in extractors.
+var (
+	// PlainTextExtractor returns the data unchanged.
+	// It is used for files with no registered Extractor.
+	PlainTextExtractor Extractor = ExtractorFunc(extractPlainText)
+
+	// HTMLExtractor returns the text of an HTML document,
+	// with tags, processing instructions, and script and style elements
+	// replaced by spaces, except for line endings,
+	// so that offsets in the text are offsets in the document.
+	// Comments are kept, since license notices are often in them,
+	// and so that Options.Source can still select them.
+	// Character entities are left in place; scanning treats them as punctuation.
+	HTMLExtractor Extractor = ExtractorFunc(extractHTML)
+)
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   = map[string]Extractor{
+		".txt":   PlainTextExtractor,
+		".html":  HTMLExtractor,
+		".htm":   HTMLExtractor,
+		".xhtml": HTMLExtractor,
+	}
+)
+
+// RegisterExtractor registers x as the Extractor for files
+// with the given extension, such as ".pdf", replacing any earlier one.
+// Extensions are compared without regard to case.
+// If x is nil, RegisterExtractor removes the extension's Extractor,
+// so that such files are scanned as plain text.
+//
+// RegisterExtractor is typically called from an init function,
+// so that a package providing a PDF or DOCX extractor
+// can be enabled by importing it.
+func RegisterExtractor(ext string, x Extractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	ext = strings.ToLower(ext)
+	if x == nil {
+		delete(extractors, ext)
+		return
+	}
+	extractors[ext] = x
+}
+
+// extractor returns the Extractor for the file with the given name.
+func extractor(name string) Extractor {
+	extractorsMu.RLock()
+	x := extractors[strings.ToLower(filepath.Ext(name))]
+	extractorsMu.RUnlock()
+	if x == nil {
+		return PlainTextExtractor
+	}
+	return x
+}
+
+// ExtractText returns the text that ScanFile and ScanFS scan
+// for the file with the given name and content,
+// using the Extractor registered for the file's extension.
+// Match offsets in their results are offsets in this text,
+// which programs quoting the matched text, for example, should use.
+func ExtractText(name string, data []byte) ([]byte, error) {
+	return extractor(name).Extract(data)
+}
+
+func extractPlainText(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+// extractHTML implements HTMLExtractor.
+func extractHTML(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	copy(out, data)
+	for i := 0; i < len(data); {
+		if data[i] != '<' {
+			i++
+			continue
+		}
+		rest := data[i:]
+		var end int // end of markup starting at i, relative to i
+		switch {
+		case bytes.HasPrefix(rest, []byte("<!--")):
+			// Keep the comment.
+			j := bytes.Index(rest[len("<!--"):], []byte("-->"))
+			if j < 0 {
+				return out, nil
+			}
+			i += len("<!--") + j + len("-->")
+			continue
+		case htmlRawElement(rest) != "":
+			// Blank the whole element, through its end tag.
+			endTag := []byte("</" + htmlRawElement(rest))
+			end = len(rest)
+			if j := indexFold(rest, endTag); j >= 0 {
+				end = j + len(endTag)
+				if k := bytes.IndexByte(rest[end:], '>'); k >= 0 {
+					end += k + 1
+				}
+			}
+		case len(rest) > 1 && (isASCIILetter(rest[1]) || rest[1] == '/' || rest[1] == '!' || rest[1] == '?'):
+			end = len(rest)
+			if j := bytes.IndexByte(rest, '>'); j >= 0 {
+				end = j + 1
+			}
+		default:
+			// A literal <, as in "a < b".
+			i++
+			continue
+		}
+		blank(out[i : i+end])
+		i += end
+	}
+	return out, nil
+}
+
+// htmlRawElement returns the name of the script or style element
+// whose start tag begins text, or the empty string if there is none.
+func htmlRawElement(text []byte) string {
+	for _, name := range []string{"script", "style"} {
+		n := 1 + len(name)
+		if len(text) > n && bytes.EqualFold(text[1:n], []byte(name)) && strings.IndexByte(" \t\r\n/>", text[n]) >= 0 {
+			return name
+		}
+	}
+	return ""
+}
+
+// indexFold is like bytes.Index but ignores ASCII case.
+func indexFold(s, sep []byte) int {
+	for i := 0; i+len(sep) <= len(s); i++ {
+		if bytes.EqualFold(s[i:i+len(sep)], sep) {
+			return i
+		}
+	}
+	return -1
+}
+
+// blank replaces every byte of b other than the line endings \r and \n by a space.
+func blank(b []byte) {
+	for i, c := range b {
+		if c != '\n' && c != '\r' {
+			b[i] = ' '
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExtractHTML(t *testing.T) {
+	for _, tt := range []struct{ in, out string }{
+		{"<p>Hello, <b>world</b>.</p>", "   Hello,    world    .    "},
+		{"<!DOCTYPE html>\n<html lang=en>\nx", "               \n              \nx"},
+		{"a < b and b <= c", "a < b and b <= c"},
+		{"<script>var x = '<p>';\n</script>y", "        " + strings.Repeat(" ", 14) + "\n" + "         y"},
+		{"<STYLE type=text/css>p {}</Style >z", strings.Repeat(" ", 34) + "z"},
+		{"<!-- Licensed under <MIT> -->\n<br/>", "<!-- Licensed under <MIT> -->\n     "},
+		{"<scripts>ok</scripts>", "         ok          "},
+		{"unterminated <a href=", "unterminated         "},
+	} {
+		out, err := HTMLExtractor.Extract([]byte(tt.in))
+		if err != nil || string(out) != tt.out {
+			t.Errorf("HTMLExtractor.Extract(%q) = %q, %v, want %q, nil", tt.in, out, err, tt.out)
+		}
+	}
+}
+
+func TestExtractor(t *testing.T) {
+	errBad := errors.New("bad document")
+	RegisterExtractor(".TestDoc", ExtractorFunc(func(data []byte) ([]byte, error) {
+		if !bytes.HasPrefix(data, []byte("%DOC\n")) {
+			return nil, errBad
+		}
+		return bytes.ToLower(data[len("%DOC\n"):]), nil
+	}))
+	defer RegisterExtractor(".testdoc", nil)
+
+	html := "<html><head><style>body { color: red }</style></head>\n<body><p>" +
+		strings.ReplaceAll(mitText, "\n\n", "</p>\n\n<p>") + "</p></body></html>\n"
+	fsys := fstest.MapFS{
+		"LICENSE.testdoc": {Data: []byte("%DOC\n" + strings.ToUpper(mitText))},
+		"bad.testdoc":     {Data: []byte("PK\x03\x04")},
+		"LICENSE.html":    {Data: []byte(html)},
+		"LICENSE.txt":     {Data: []byte(mitText)},
+	}
+	files := []string{"LICENSE.testdoc", "bad.testdoc", "LICENSE.html", "LICENSE.txt"}
+	list, err := ScanFS(fsys, files, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fc := list[0]; fc.Err != nil || len(fc.Coverage.Match) != 1 || fc.Coverage.Match[0].ID != "MIT" {
+		t.Errorf("%s: Coverage=%+v Err=%v, want MIT", fc.File, fc.Coverage, fc.Err)
+	}
+	if fc := list[1]; fc.Err != errBad {
+		t.Errorf("%s: Err=%v, want %v", fc.File, fc.Err, errBad)
+	}
+	// With the markup and style sheet removed,
+	// the HTML and plain text files have the same words as the first.
+	for _, fc := range list[2:] {
+		if fc.DuplicateOf != files[0] {
+			t.Errorf("%s: DuplicateOf=%q, want %q", fc.File, fc.DuplicateOf, files[0])
+		}
+	}
+
+	if text, err := ExtractText("a.HTM", []byte("<b>x</b>")); err != nil || string(text) != "   x    " {
+		t.Errorf("ExtractText(a.HTM) = %q, %v, want %q", text, err, "   x    ")
+	}
+	if text, err := ExtractText("LICENSE", []byte(mitText)); err != nil || string(text) != mitText {
+		t.Errorf("ExtractText(LICENSE) = %q, %v, want text unchanged", text, err)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"io/ioutil"
+	"os"
+)
+
+// ScanFile is like ScanWithOptions but scans the content of the named file.
+// Where the operating system allows, ScanFile maps the file into memory
+// instead of reading it, so that scanning a very large file,
+// such as a bundle of concatenated license files,
+// does not copy the whole file onto the heap.
+// The file must not be modified while it is being scanned.
+// The text scanned is extracted from the file by the Extractor
+// registered for its extension (see RegisterExtractor),
+// and match offsets are offsets in that text.
+// To scan only the comments in a source file, set opts.Source to file.
+func ScanFile(file string, opts Options) (Coverage, error) {
+	return builtinScanner.ScanFile(file, opts)
+}
+
+// ScanFile is like the top-level function ScanFile,
+// but it uses the set of licenses in the Scanner instead of the built-in license set.
+func (s *Scanner) ScanFile(file string, opts Options) (Coverage, error) {
+	s.initBuiltin()
+
+	f, err := os.Open(file)
+	if err != nil {
+		return Coverage{}, err
+	}
+	defer f.Close()
+
+	data, unmap, err := mmapFile(f)
+	if err != nil {
+		// Fall back to reading the file.
+		data, err = ioutil.ReadAll(f)
+		if err != nil {
+			return Coverage{}, err
+		}
+		unmap = func() {}
+	}
+	defer unmap()
+
+	text, err := ExtractText(file, data)
+	if err != nil {
+		return Coverage{}, err
+	}
+
+	// The Coverage returned by ScanWithOptions
+	// does not refer to data, so it can be unmapped.
+	return s.ScanWithOptions(text, opts)
+}
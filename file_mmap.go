@@ -0,0 +1,36 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package licensecheck
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// mmapFile maps the content of f into memory for reading.
+// It returns the mapped data and a function to unmap it.
+// If f cannot be mapped, mmapFile returns an error,
+// and the caller should read f instead.
+func mmapFile(f *os.File) (data []byte, unmap func(), err error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	size := info.Size()
+	if !info.Mode().IsRegular() || size <= 0 || int64(int(size)) != size {
+		// Empty files cannot be mapped, and special files
+		// do not necessarily report their size.
+		return nil, nil, errors.New("cannot mmap")
+	}
+	data, err = syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() { syscall.Munmap(data) }, nil
+}
@@ -0,0 +1,19 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package licensecheck
+
+import (
+	"errors"
+	"os"
+)
+
+// mmapFile always fails on systems without mmap,
+// so that the caller reads the file instead.
+func mmapFile(f *os.File) (data []byte, unmap func(), err error) {
+	return nil, nil, errors.New("mmap not supported")
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"math/bits"
+
+	"github.com/google/licensecheck/internal/match"
+)
+
+// shingleWords is the number of words in a shingle,
+// the unit of text hashed by SimHash and MinHash.
+const shingleWords = 3
+
+// SimHash returns the 64-bit SimHash of text.
+// Similar texts have SimHashes that differ in few bits,
+// as counted by SimHashDistance.
+// Copies of the same license with different copyright lines
+// typically differ in no more than a few bits,
+// while unrelated texts differ in about half of them.
+func SimHash(text []byte) uint64 {
+	var counts [64]int
+	shingles(text, func(h uint64) {
+		for i := range counts {
+			if h&(1<<i) != 0 {
+				counts[i]++
+			} else {
+				counts[i]--
+			}
+		}
+	})
+	var h uint64
+	for i, n := range counts {
+		if n > 0 {
+			h |= 1 << i
+		}
+	}
+	return h
+}
+
+// SimHashDistance returns the number of bits in which
+// the SimHashes a and b differ, from 0 to 64.
+func SimHashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// MinHash returns the MinHash signature of text, using n hash functions.
+// The fraction of positions in which the signatures of two texts agree,
+// as computed by MinHashSimilarity, estimates the Jaccard similarity
+// of their sets of shingles: the number of shingles in both texts
+// divided by the number in either.
+// Larger values of n give better estimates; 128 is typical.
+// Signatures of different lengths cannot be compared.
+// A text with fewer than shingleWords words has no shingles,
+// and its signature is all ones (the maximum uint64).
+func MinHash(text []byte, n int) []uint64 {
+	sig := make([]uint64, n)
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	shingles(text, func(h uint64) {
+		for i := range sig {
+			if x := minHashPerm(h, i); x < sig[i] {
+				sig[i] = x
+			}
+		}
+	})
+	return sig
+}
+
+// MinHashSimilarity returns the fraction of positions
+// in which the MinHash signatures a and b agree.
+// It returns 0 if the signatures are empty or have different lengths.
+func MinHashSimilarity(a, b []uint64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
+
+// shingles calls f with the hash of each shingle in text:
+// each run of shingleWords consecutive words, after normalization.
+func shingles(text []byte, f func(uint64)) {
+	d := new(match.Dict)
+	words := d.InsertSplit(string(text))
+	dict := d.Words()
+	for i := 0; i+shingleWords <= len(words); i++ {
+		// FNV-1a, with a zero byte after each word.
+		h := uint64(14695981039346656037)
+		for _, w := range words[i : i+shingleWords] {
+			word := dict[w.ID]
+			for j := 0; j <= len(word); j++ {
+				c := byte(0)
+				if j < len(word) {
+					c = word[j]
+				}
+				h ^= uint64(c)
+				h *= 1099511628211
+			}
+		}
+		f(mix64(h))
+	}
+}
+
+// minHashPerm returns the i'th of the MinHash hash functions applied to h.
+// The functions are h XOR a seed, mixed, for a fixed sequence of seeds.
+func minHashPerm(h uint64, i int) uint64 {
+	return mix64(h ^ (uint64(i+1) * 0x9e3779b97f4a7c15))
+}
+
+// minHashShingle returns the shingle hash h for which minHashPerm(h, i) == x.
+func minHashShingle(x uint64, i int) uint64 {
+	return unmix64(x) ^ (uint64(i+1) * 0x9e3779b97f4a7c15)
+}
+
+// mix64 is the SplitMix64 finalizer,
+// which spreads every input bit across the output.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// unmix64 is the inverse of mix64.
+func unmix64(x uint64) uint64 {
+	x ^= x>>31 ^ x>>62
+	x *= 0x319642b2d24d8ec3 // inverse of 0x94d049bb133111eb
+	x ^= x>>27 ^ x>>54
+	x *= 0x96de1b173f119089 // inverse of 0xbf58476d1ce4e5b9
+	x ^= x>>30 ^ x>>60
+	return x
+}
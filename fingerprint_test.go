@@ -0,0 +1,73 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFingerprints(t *testing.T) {
+	apache, err := os.ReadFile("testdata/Apache-2.0.t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mit := []byte(mitText)
+	mit2 := []byte("Copyright 2019 Someone Else\n\n# " + strings.ToUpper(mitText))
+
+	// Normalization: case, punctuation, and markup do not matter.
+	if a, b := SimHash(mit), SimHash([]byte(strings.ToUpper(strings.ReplaceAll(mitText, ",", "")))); a != b {
+		t.Errorf("SimHash differs after normalization: %#x, %#x", a, b)
+	}
+
+	if d := SimHashDistance(SimHash(mit), SimHash(mit2)); d > 8 {
+		t.Errorf("SimHashDistance(MIT, MIT copy) = %d, want ≤ 8", d)
+	}
+	if d := SimHashDistance(SimHash(mit), SimHash(apache)); d < 16 {
+		t.Errorf("SimHashDistance(MIT, Apache) = %d, want ≥ 16", d)
+	}
+
+	const n = 128
+	m1, m2, a := MinHash(mit, n), MinHash(mit2, n), MinHash(apache, n)
+	if s := MinHashSimilarity(m1, MinHash(mit, n)); s != 1 {
+		t.Errorf("MinHashSimilarity(MIT, MIT) = %.2f, want 1", s)
+	}
+	if s := MinHashSimilarity(m1, m2); s < 0.8 {
+		t.Errorf("MinHashSimilarity(MIT, MIT copy) = %.2f, want ≥ 0.8", s)
+	}
+	if s := MinHashSimilarity(m1, a); s > 0.1 {
+		t.Errorf("MinHashSimilarity(MIT, Apache) = %.2f, want ≤ 0.1", s)
+	}
+	if s := MinHashSimilarity(m1, MinHash(mit, n/2)); s != 0 {
+		t.Errorf("MinHashSimilarity of different lengths = %.2f, want 0", s)
+	}
+
+	// Short texts have no shingles.
+	for _, x := range MinHash([]byte("two words"), 4) {
+		if x != ^uint64(0) {
+			t.Errorf("MinHash(short text) = %#x, want all ones", x)
+		}
+	}
+
+	// Fingerprints are stable across systems and runs.
+	if h := SimHash([]byte("Permission is hereby granted")); h != simHashGolden {
+		t.Errorf("SimHash(golden) = %#x, want %#x", h, simHashGolden)
+	}
+}
+
+func TestMinHashShingle(t *testing.T) {
+	for _, h := range []uint64{0, 1, 0x123456789abcdef, ^uint64(0)} {
+		for i := 0; i < 4; i++ {
+			if x := minHashShingle(minHashPerm(h, i), i); x != h {
+				t.Errorf("minHashShingle(minHashPerm(%#x, %d), %d) = %#x, want %#x", h, i, i, x, h)
+			}
+		}
+	}
+}
+
+// simHashGolden is the SimHash of a fixed text.
+// It must not change unless normalization changes.
+const simHashGolden = 0xa95e00914311865
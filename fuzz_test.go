@@ -0,0 +1,74 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package licensecheck
+
+import (
+	"errors"
+	"testing"
+)
+
+// checkCoverage reports any inconsistency in the coverage c of text.
+func checkCoverage(t *testing.T, text []byte, c Coverage) {
+	t.Helper()
+	if c.Percent < 0 || c.Percent > 100 {
+		t.Fatalf("Percent = %v, want 0 to 100", c.Percent)
+	}
+	end := 0
+	for _, m := range c.Match {
+		if m.Start < end || m.End < m.Start || m.End > len(text) {
+			t.Fatalf("Match %s at [%d:%d] after end %d in text of length %d", m.ID, m.Start, m.End, end, len(text))
+		}
+		end = m.End
+	}
+}
+
+func FuzzScan(f *testing.F) {
+	f.Add([]byte(""))
+	f.Add([]byte(mitText))
+	f.Add([]byte("See https://opensource.org/licenses/MIT for details."))
+	f.Add([]byte("Copyright © 2020 <year> &copy; (c) notice(s) ]( http://"))
+	f.Add([]byte("\uFEFFCopyright\r\n\r\nPermission is hereby granted\xff"))
+	f.Fuzz(func(t *testing.T, text []byte) {
+		checkCoverage(t, text, Scan(text))
+		for _, opts := range []Options{
+			{InvalidUTF8: UTF8Skip},
+			{MaxInputBytes: len(text)/2 + 1, Truncate: true},
+		} {
+			c, err := ScanWithOptions(text, opts)
+			if err != nil && !errors.Is(err, ErrNoMatch) && !errors.Is(err, ErrEmptyInput) {
+				t.Fatalf("ScanWithOptions(%+v): %v", opts, err)
+			}
+			checkCoverage(t, text, c)
+		}
+	})
+}
+
+func FuzzLicenseURL(f *testing.F) {
+	f.Add("https://opensource.org/licenses/MIT")
+	f.Add("http://creativecommons.org/licenses/by/4.0/legalcode")
+	f.Add("https://")
+	f.Fuzz(func(t *testing.T, url string) {
+		builtinScanner.initBuiltin()
+		builtinScanner.licenseURL(url)
+	})
+}
+
+func FuzzSPDXTemplateLRE(f *testing.F) {
+	f.Add(templateMIT)
+	f.Add(`<<var;name="x";original="a \"quoted\" >> value";match=".{0,20}">> text here`)
+	f.Add("text <<beginOptional>> <<beginOptional>> x <<endOptional>> <<endOptional>> more text")
+	f.Fuzz(func(t *testing.T, tmpl string) {
+		lre, err := SPDXTemplateLRE(tmpl)
+		if err != nil {
+			return
+		}
+		// The result need not be a valid LRE, for example if the
+		// template has only wildcards, but it must not crash NewScanner.
+		NewScanner([]License{{ID: "X", LRE: lre}})
+	})
+}
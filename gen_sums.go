@@ -0,0 +1,49 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build ignore
+
+// This file generates licenses/SHA256SUMS,
+// the checksum manifest for the built-in license files.
+// Run by a "go:generate" comment in data.go.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+)
+
+var outFile = flag.String("o", filepath.Join("licenses", "SHA256SUMS"), "`file` to write")
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("gen: ")
+	flag.Parse()
+
+	files, err := filepath.Glob(filepath.Join("licenses", "*.lre"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(files) == 0 {
+		log.Fatal("no license files")
+	}
+
+	var out bytes.Buffer
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(&out, "%x  %s\n", sha256.Sum256(data), filepath.Base(file))
+	}
+	if err := ioutil.WriteFile(*outFile, out.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package github cross-checks the license GitHub reports for a repository
+// against the license licensecheck finds in the repository's license file.
+//
+// GitHub identifies repository licenses with its own classifier,
+// which reports a single SPDX ID or NOASSERTION.
+// Audits of dependencies hosted on GitHub often take that ID on trust;
+// Check confirms it, or explains the disagreement:
+//
+//	c := &github.Client{Token: os.Getenv("GITHUB_TOKEN")}
+//	r, err := c.Check(ctx, "google/licensecheck")
+//	if err != nil {
+//		...
+//	}
+//	if !r.Agree {
+//		fmt.Printf("%s: %s\n", r.Repo, r.Details)
+//	}
+//
+// This package makes network requests.
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/internal/spdxid"
+)
+
+// DefaultBaseURL is the location of the GitHub REST API.
+const DefaultBaseURL = "https://api.github.com"
+
+// A Client checks the licenses of GitHub repositories.
+// The zero Client is ready to use, without authentication.
+type Client struct {
+	// Client is the HTTP client to use.
+	// If nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// BaseURL is the URL of the GitHub REST API,
+	// such as the API of a GitHub Enterprise server.
+	// If empty, it defaults to DefaultBaseURL.
+	BaseURL string
+
+	// Token, if set, is sent to authenticate API requests,
+	// which raises the rate limit and allows access to private repositories.
+	Token string
+
+	// Scanner is the scanner to use.
+	// If nil, the built-in license set is used.
+	Scanner *licensecheck.Scanner
+}
+
+// A Result is the result of cross-checking a repository's license.
+type Result struct {
+	Repo string // repository, as "owner/name"
+	File string // path of the license file GitHub found in the repository
+
+	// GitHubID is the SPDX ID of the license GitHub reports,
+	// or NOASSERTION if GitHub found a license file
+	// but did not recognize the license.
+	GitHubID string
+
+	// Coverage is the result of scanning the license file.
+	Coverage licensecheck.Coverage
+
+	// IDs lists the IDs of the licenses found in the license file,
+	// not counting URLs, without duplicates, in order of appearance.
+	IDs []string
+
+	// Agree reports whether GitHubID is among IDs.
+	// Details describes the agreement or disagreement.
+	Agree   bool
+	Details string
+}
+
+// Check fetches the license GitHub reports for the repository,
+// given as "owner/name", along with the license file it is based on,
+// and compares it with the result of scanning that file.
+// It returns an error if the repository does not exist
+// or has no license file GitHub recognizes as one.
+func (c *Client) Check(ctx context.Context, repo string) (*Result, error) {
+	if f := strings.Split(repo, "/"); len(f) != 2 || f[0] == "" || f[1] == "" || strings.HasPrefix(f[0], ".") || strings.HasPrefix(f[1], ".") {
+		return nil, fmt.Errorf("github: invalid repository %q, want owner/name", repo)
+	}
+
+	var info struct {
+		Path     string
+		Content  string
+		Encoding string
+		License  struct {
+			SPDXID string `json:"spdx_id"`
+		}
+	}
+	if err := c.get(ctx, "/repos/"+repo+"/license", &info); err != nil {
+		return nil, err
+	}
+	if info.Encoding != "base64" {
+		return nil, fmt.Errorf("github: %s: unexpected license file encoding %q", repo, info.Encoding)
+	}
+	// GitHub breaks the encoded content into lines.
+	text, err := base64.StdEncoding.DecodeString(strings.NewReplacer("\n", "", "\r", "").Replace(info.Content))
+	if err != nil {
+		return nil, fmt.Errorf("github: %s: decoding license file: %v", repo, err)
+	}
+
+	r := &Result{Repo: repo, File: info.Path, GitHubID: info.License.SPDXID}
+	if c.Scanner != nil {
+		r.Coverage = c.Scanner.Scan(text)
+	} else {
+		r.Coverage = licensecheck.Scan(text)
+	}
+	r.IDs, r.Agree, r.Details = Compare(r.GitHubID, r.Coverage)
+	return r, nil
+}
+
+// Compare compares the license ID reported by GitHub with the coverage
+// found by scanning the license file. It returns the distinct IDs
+// of the licenses in cov, not counting URLs, in order of appearance;
+// whether githubID is one of them; and a description of the comparison.
+// IDs are compared without regard to case or to -only and -or-later suffixes,
+// which GitHub and licensecheck do not always use the same way.
+func Compare(githubID string, cov licensecheck.Coverage) (ids []string, agree bool, details string) {
+	seen := make(map[string]bool)
+	for _, m := range cov.Match {
+		if !m.IsURL && !seen[m.ID] {
+			seen[m.ID] = true
+			ids = append(ids, m.ID)
+		}
+	}
+
+	switch {
+	case githubID == "" || githubID == "NOASSERTION":
+		if len(ids) == 0 {
+			return ids, false, "neither GitHub nor licensecheck recognizes the license"
+		}
+		return ids, false, fmt.Sprintf("GitHub does not recognize the license; licensecheck finds %s", list(ids))
+	case len(ids) == 0:
+		return ids, false, fmt.Sprintf("GitHub reports %s; licensecheck finds no license", githubID)
+	}
+
+	var others []string
+	for _, id := range ids {
+		if spdxid.Same(id, githubID) {
+			agree = true
+		} else {
+			others = append(others, id)
+		}
+	}
+	switch {
+	case !agree:
+		details = fmt.Sprintf("GitHub reports %s; licensecheck finds %s", githubID, list(ids))
+	case len(others) > 0:
+		details = fmt.Sprintf("GitHub reports %s; licensecheck finds it along with %s", githubID, list(others))
+	default:
+		details = fmt.Sprintf("GitHub and licensecheck agree on %s", githubID)
+	}
+	if cov.Percent < 90 {
+		details += fmt.Sprintf(" (licenses cover only %.0f%% of the file)", cov.Percent)
+	}
+	return ids, agree, details
+}
+
+// list returns the IDs as a sorted, comma-separated list.
+func list(ids []string) string {
+	ids = append([]string(nil), ids...)
+	sort.Strings(ids)
+	return strings.Join(ids, ", ")
+}
+
+// get fetches the API path and unmarshals the JSON response into v.
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	base := strings.TrimSuffix(c.BaseURL, "/")
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	req, err := http.NewRequest("GET", base+path, nil)
+	if err != nil {
+		return fmt.Errorf("github: %v", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("github: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("github: fetching %s: %s", req.URL, resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("github: fetching %s: %v", req.URL, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("github: parsing %s: %v", req.URL, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/licensecheck"
+)
+
+func TestCheck(t *testing.T) {
+	mit, ok := licensecheck.Text("MIT")
+	if !ok {
+		t.Fatal("no text for MIT")
+	}
+	repos := map[string]string{ // repo -> reported SPDX ID
+		"me/agree":    "MIT",
+		"me/disagree": "Apache-2.0",
+		"me/unknown":  "NOASSERTION",
+	}
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		repo := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/repos/"), "/license")
+		id, ok := repos[repo]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		// Break the encoding into lines as GitHub does.
+		enc := base64.StdEncoding.EncodeToString([]byte(mit))
+		var lines []string
+		for len(enc) > 60 {
+			lines, enc = append(lines, enc[:60]), enc[60:]
+		}
+		lines = append(lines, enc)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"path":     "LICENSE.txt",
+			"content":  strings.Join(lines, "\n"),
+			"encoding": "base64",
+			"license":  map[string]string{"key": strings.ToLower(id), "spdx_id": id},
+		})
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, Token: "secret"}
+	for _, tt := range []struct {
+		repo    string
+		agree   bool
+		details string
+	}{
+		{"me/agree", true, "GitHub and licensecheck agree on MIT"},
+		{"me/disagree", false, "GitHub reports Apache-2.0; licensecheck finds MIT"},
+		{"me/unknown", false, "GitHub does not recognize the license; licensecheck finds MIT"},
+	} {
+		r, err := c.Check(context.Background(), tt.repo)
+		if err != nil {
+			t.Errorf("Check(%s): %v", tt.repo, err)
+			continue
+		}
+		if r.Repo != tt.repo || r.File != "LICENSE.txt" || len(r.IDs) != 1 || r.IDs[0] != "MIT" || r.Agree != tt.agree || r.Details != tt.details {
+			t.Errorf("Check(%s) = %+v, want Agree=%v, Details=%q", tt.repo, r, tt.agree, tt.details)
+		}
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want Bearer secret", auth)
+	}
+
+	for _, repo := range []string{"me/missing", "me", "me/x/y", "../x"} {
+		if r, err := c.Check(context.Background(), repo); err == nil {
+			t.Errorf("Check(%s) = %+v, want error", repo, r)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cov := func(pct float64, ids ...string) licensecheck.Coverage {
+		c := licensecheck.Coverage{Percent: pct}
+		for _, id := range ids {
+			c.Match = append(c.Match, licensecheck.Match{ID: id})
+		}
+		return c
+	}
+	for _, tt := range []struct {
+		githubID string
+		cov      licensecheck.Coverage
+		agree    bool
+		details  string
+	}{
+		{"GPL-2.0", cov(100, "GPL-2.0-or-later"), true, "GitHub and licensecheck agree on GPL-2.0"},
+		{"MIT", cov(100, "Apache-2.0", "MIT", "MIT"), true, "GitHub reports MIT; licensecheck finds it along with Apache-2.0"},
+		{"MIT", cov(60, "MIT"), true, "GitHub and licensecheck agree on MIT (licenses cover only 60% of the file)"},
+		{"MIT", cov(0), false, "GitHub reports MIT; licensecheck finds no license"},
+		{"", cov(0), false, "neither GitHub nor licensecheck recognizes the license"},
+	} {
+		_, agree, details := Compare(tt.githubID, tt.cov)
+		if agree != tt.agree || details != tt.details {
+			t.Errorf("Compare(%q, %v) = %v, %q, want %v, %q", tt.githubID, tt.cov.Match, agree, details, tt.agree, tt.details)
+		}
+	}
+}
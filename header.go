@@ -0,0 +1,359 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/google/licensecheck/internal/strs"
+)
+
+// Limits on the header examined by ScanHeader.
+const (
+	maxHeaderLines = 200
+	maxHeaderBytes = 16 << 10
+)
+
+// ScanHeader is like Scan but scans only the license header of a source file:
+// the comments at the top of the file, before the first line of code,
+// limited to the first 200 lines and 16 kB.
+// Comment markers are ignored, so the header is matched as plain text.
+//
+// ScanHeader also reports each license named in an
+// SPDX-License-Identifier tag in the header as a Match with IsTag set,
+// located at the license identifier in the tag.
+// Identifiers are compared without regard to case,
+// and a deprecated ID+ names the license ID-or-later.
+// Identifiers naming licenses the Scanner does not know
+// are reported in Coverage.UnknownTags instead.
+// Coverage.Mismatches reports any license text in the header
+// that disagrees with the tags.
+//
+// Percent describes only the header, not the whole file.
+func ScanHeader(text []byte) Coverage {
+	return builtinScanner.ScanHeader(text)
+}
+
+// ScanHeader is like the top-level function ScanHeader,
+// but it uses the set of licenses in the Scanner instead of the built-in license set.
+func (s *Scanner) ScanHeader(text []byte) Coverage {
+	s.initBuiltin()
+
+	header := anyComments.header(text)
+	tags, unknown, tagWords, tagMatched := s.spdxTags(header)
+	c, st := s.scanWindow(header, len(header), Options{})
+	if len(tags) > 0 {
+		c.Match = append(c.Match, tags...)
+		c.Sort()
+	}
+	c.UnknownTags = unknown
+	matched, words := st.matched+tagMatched, st.words+tagWords
+	if words == 0 {
+		c.Empty = true
+	} else {
+		c.Percent = 100.0 * float64(matched) / float64(words)
+	}
+	c.applyRiders()
+	c.setConfidence()
+	return c
+}
+
+// spdxTagRE matches an SPDX-License-Identifier tag,
+// with the license expression in the first submatch.
+var spdxTagRE = regexp.MustCompile(`(?i)SPDX-License-Identifier:[ \t]*([^\r\n]*)`)
+
+// spdxIDRE matches a license identifier in an SPDX license expression.
+var spdxIDRE = regexp.MustCompile(`[A-Za-z0-9.+:-]+`)
+
+// spdxTags returns matches for the licenses named in the SPDX-License-Identifier
+// tags in text, separating those the Scanner does not know,
+// along with the number of words in the tags
+// and the number of those words not naming unknown licenses.
+// It blanks out the tags in text, so that scanning text
+// does not count their words again.
+func (s *Scanner) spdxTags(text []byte) (tags, unknown []Match, words, matched int) {
+	for _, tag := range spdxTagRE.FindAllSubmatchIndex(text, -1) {
+		n := len(s.re.Dict().Split(string(text[tag[0]:tag[1]])))
+		words += n
+		matched += n
+		expr := text[tag[2]:tag[3]]
+		afterWith := false
+		for _, id := range spdxIDRE.FindAllIndex(expr, -1) {
+			name := string(expr[id[0]:id[1]])
+			switch strings.ToUpper(name) {
+			case "AND", "OR":
+				continue
+			case "WITH":
+				afterWith = true
+				continue
+			}
+			if afterWith {
+				// Exception, not a license.
+				afterWith = false
+				continue
+			}
+			l, ok := s.tagLicense(name)
+			m := Match{ID: name}
+			if ok {
+				m = l.match()
+			}
+			m.Start, m.End, m.IsTag = tag[2]+id[0], tag[2]+id[1], true
+			m.MatchedWords = len(s.re.Dict().Split(name))
+			if !ok {
+				matched -= m.MatchedWords
+				m.MatchedWords = 0
+				unknown = append(unknown, m)
+				continue
+			}
+			tags = append(tags, m)
+		}
+		for i := tag[0]; i < tag[1]; i++ {
+			text[i] = ' '
+		}
+	}
+	return tags, unknown, words, matched
+}
+
+// tagLicense returns the license in s named by the identifier id
+// in an SPDX-License-Identifier tag: the license with that ID,
+// ignoring case, or, for a deprecated ID+, the license ID-or-later.
+func (s *Scanner) tagLicense(id string) (License, bool) {
+	if l, ok := s.license(id); ok {
+		return l, true
+	}
+	id = spdxCanonical(id)
+	for _, l := range s.licenses {
+		if strings.EqualFold(l.ID, id) {
+			return l, true
+		}
+	}
+	return License{}, false
+}
+
+// license returns the license in s with the given ID.
+// If there is more than one, it returns the first.
+func (s *Scanner) license(id string) (License, bool) {
+	for _, l := range s.licenses {
+		if l.ID == id {
+			return l, true
+		}
+	}
+	for _, l := range s.urls {
+		if l.ID == id {
+			return l, true
+		}
+	}
+	return License{}, false
+}
+
+// A commentSyntax describes the comments in a programming language.
+type commentSyntax struct {
+	line  []string    // line comment markers, such as //
+	block [][2]string // block comment start and end markers, such as /* and */
+	quote []string    // string literal quotes, such as "
+}
+
+// anyComments is a commentSyntax recognizing the comments
+// of most common programming languages.
+var anyComments = &commentSyntax{
+	line: []string{"//", "#", "--", ";", "%", "'", "!", "REM ", "rem ", "dnl ", "::"},
+	block: [][2]string{
+		{"/*", "*/"},
+		{"<!--", "-->"},
+		{"{-", "-}"},
+		{"(*", "*)"},
+		{`"""`, `"""`},
+		{"'''", "'''"},
+		{"=begin", "=end"},
+	},
+}
+
+// header returns a copy of the license header at the start of text:
+// the comments before the first line of code, limited to
+// maxHeaderLines lines and maxHeaderBytes bytes.
+// Everything in the copy other than the comment text and line endings,
+// including the comment markers, is replaced by spaces,
+// so that offsets in the copy are offsets in text.
+func (cs *commentSyntax) header(text []byte) []byte {
+	if len(text) > maxHeaderBytes {
+		text = strs.Truncate(text, maxHeaderBytes)
+	}
+	out := blankCopy(text)
+	end := "" // end marker of current block comment
+	headerEnd := 0
+	for n, off := 0, 0; n < maxHeaderLines && off < len(text); n++ {
+		line := text[off:]
+		if i := bytes.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i+1]
+		}
+		lineOff := off
+		off += len(line)
+
+		// Copy comment text from line to out.
+		code := false
+		for i := 0; i < len(line); {
+			if end != "" {
+				j := bytes.Index(line[i:], []byte(end))
+				if j < 0 {
+					j = len(line) - i
+				}
+				copy(out[lineOff+i:], line[i:i+j])
+				if i+j < len(line) {
+					j += len(end)
+					end = ""
+				}
+				i += j
+				continue
+			}
+			rest := line[i:]
+			trimmed := bytes.TrimLeft(rest, " \t\r\n\uFEFF")
+			if len(trimmed) == 0 {
+				break
+			}
+			i += len(rest) - len(trimmed)
+			if (n == 0 && bytes.HasPrefix(trimmed, []byte("#!"))) || bytes.HasPrefix(trimmed, []byte("<?")) {
+				// Skip #! interpreter line or <?xml ...?> or <?php preamble.
+				break
+			}
+			if m, ok := cs.blockStart(trimmed); ok {
+				end = m[1]
+				i += len(m[0])
+				continue
+			}
+			if m := cs.lineStart(trimmed); m != "" {
+				copy(out[lineOff+i+len(m):], line[i+len(m):])
+				break
+			}
+			code = true
+			break
+		}
+		if code {
+			// First line of code ends the header.
+			break
+		}
+		headerEnd = off
+	}
+	return out[:headerEnd]
+}
+
+// lineStart returns the line comment marker at the start of text, if any.
+func (cs *commentSyntax) lineStart(text []byte) string {
+	for _, m := range cs.line {
+		if bytes.HasPrefix(text, []byte(m)) {
+			if m == "#" && len(text) > 1 && isASCIILetter(text[1]) {
+				// C preprocessor directive like #include, not a comment.
+				continue
+			}
+			return m
+		}
+	}
+	return ""
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// blockStart returns the block comment markers starting text, if any.
+func (cs *commentSyntax) blockStart(text []byte) ([2]string, bool) {
+	for _, m := range cs.block {
+		if bytes.HasPrefix(text, []byte(m[0])) {
+			return m, true
+		}
+	}
+	return [2]string{}, false
+}
+
+// A Mismatch reports that a license found in the text of a file
+// is not one of the licenses named by the file's SPDX-License-Identifier tags,
+// as when a file tagged MIT has an Apache-2.0 header.
+// Such a file is mislabeled, or its licensing is more complex than its tags say.
+type Mismatch struct {
+	Match Match   // license text or URL found in the file
+	Tags  []Match // all the tags in the file, none of which names Match's license
+}
+
+// Mismatches returns the license texts and URLs in c
+// that disagree with the SPDX-License-Identifier tags in c (see ScanHeader),
+// including the tags in c.UnknownTags.
+// If c has no tags, or no matches other than tags, there are no mismatches.
+func (c Coverage) Mismatches() []Mismatch {
+	var tags []Match
+	for _, m := range c.Match {
+		if m.IsTag {
+			tags = append(tags, m)
+		}
+	}
+	tags = append(tags, c.UnknownTags...)
+	if len(tags) == 0 {
+		return nil
+	}
+	var list []Mismatch
+Matches:
+	for _, m := range c.Match {
+		if m.IsTag {
+			continue
+		}
+		for _, tag := range tags {
+			if tagNames(tag, m) {
+				continue Matches
+			}
+		}
+		list = append(list, Mismatch{Match: m, Tags: tags})
+	}
+	return list
+}
+
+// tagNames reports whether the SPDX tag names the license of m.
+// SPDX IDs are compared without regard to case,
+// and a deprecated ID+ is the same as ID-or-later.
+// Because a full license text or URL does not say whether
+// later versions may be used, the text of GPL-2.0 is named
+// by both GPL-2.0-only and GPL-2.0-or-later.
+// Only a header says which one applies.
+// If the SPDXID of m is an expression, as for a notice
+// offering a choice of licenses, the tag may name any license in it.
+// The tag may also name the license that m's license wraps (see Match.Wraps),
+// since the licensee may choose to use the work under that license instead.
+func tagNames(tag, m Match) bool {
+	id := spdxCanonical(tag.ID)
+	mids := []string{m.ID}
+	if m.Wraps != "" {
+		mids = append(mids, m.Wraps)
+	}
+	for _, mid := range spdxIDRE.FindAllString(m.SPDXID, -1) {
+		switch strings.ToUpper(mid) {
+		case "AND", "OR", "WITH":
+			continue
+		}
+		mids = append(mids, mid)
+	}
+	for _, mid := range mids {
+		mid = spdxCanonical(mid)
+		if strings.EqualFold(id, mid) {
+			return true
+		}
+		if !m.IsHeader && strings.EqualFold(spdxBase(id), spdxBase(mid)) {
+			return true
+		}
+	}
+	return false
+}
+
+// spdxCanonical returns id with a trailing + rewritten as -or-later.
+func spdxCanonical(id string) string {
+	if strings.HasSuffix(id, "+") {
+		return strings.TrimSuffix(id, "+") + "-or-later"
+	}
+	return id
+}
+
+// spdxBase returns id without any -only or -or-later suffix.
+func spdxBase(id string) string {
+	id = strings.TrimSuffix(id, "-only")
+	return strings.TrimSuffix(id, "-or-later")
+}
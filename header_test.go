@@ -0,0 +1,177 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"strings"
+	"testing"
+)
+
+// commented returns text with each line prefixed by the comment marker.
+func commented(marker, text string) string {
+	lines := strings.SplitAfter(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(marker+" "+line, " ")
+	}
+	return strings.Join(lines, "") + "\n"
+}
+
+var headerTests = []struct {
+	name string
+	text string
+	ids  []string
+}{
+	{
+		name: "Go",
+		text: commented("//", mitText) + "\npackage p\n\n// " + gpl2Blurb + "\nfunc f() {}\n",
+		ids:  []string{"MIT"},
+	},
+	{
+		name: "C",
+		text: "/*\n" + commented(" *", mitText) + " */\n\n#include <stdio.h>\n\nint x;\n",
+		ids:  []string{"MIT"},
+	},
+	{
+		name: "Python",
+		text: "#!/usr/bin/env python3\n" + commented("#", mitText) + "\nimport os\n",
+		ids:  []string{"MIT"},
+	},
+	{
+		name: "NoHeader",
+		text: "package p\n\n// " + mitText,
+	},
+}
+
+// gpl2Blurb is a license notice that must not be found after code begins.
+const gpl2Blurb = "Licensed under the GNU General Public License, version 2."
+
+func TestScanHeader(t *testing.T) {
+	for _, tt := range headerTests {
+		t.Run(tt.name, func(t *testing.T) {
+			cov := ScanHeader([]byte(tt.text))
+			var ids []string
+			for _, m := range cov.Match {
+				ids = append(ids, m.ID)
+				if m.IsTag {
+					t.Errorf("match %s has IsTag set", m.ID)
+				}
+				if m.End > len(tt.text) || strings.Contains(tt.text[m.Start:m.End], "package") {
+					t.Errorf("match %s at [%d:%d] extends past header", m.ID, m.Start, m.End)
+				}
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.ids, ",") {
+				t.Errorf("ScanHeader: IDs = %v, want %v", ids, tt.ids)
+			}
+			if len(tt.ids) > 0 && cov.Percent < 90 {
+				t.Errorf("ScanHeader: Percent = %.1f, want header mostly covered", cov.Percent)
+			}
+		})
+	}
+}
+
+func TestScanHeaderTag(t *testing.T) {
+	text := "// Copyright 2020 Someone\n" +
+		"// SPDX-License-Identifier: MIT OR (Apache-2.0 WITH LLVM-exception)\n" +
+		"\n" +
+		"package p\n\n" +
+		"// SPDX-License-Identifier: GPL-2.0\n"
+	cov := ScanHeader([]byte(text))
+	var ids []string
+	for _, m := range cov.Match {
+		if !m.IsTag {
+			t.Errorf("match %s has IsTag unset", m.ID)
+			continue
+		}
+		ids = append(ids, m.ID)
+		if got := text[m.Start:m.End]; got != m.ID {
+			t.Errorf("match %s at [%d:%d] = %q, want ID", m.ID, m.Start, m.End, got)
+		}
+	}
+	if got, want := strings.Join(ids, ","), "MIT,Apache-2.0"; got != want {
+		t.Errorf("ScanHeader: tag IDs = %v, want %v", got, want)
+	}
+	if want := Scan([]byte(mitText)).Match[0]; cov.Match[0].Type != want.Type || cov.Match[0].Name != want.Name {
+		t.Errorf("ScanHeader: MIT tag = %+v, want metadata from %+v", cov.Match[0], want)
+	}
+	if cov.Percent <= 0 || cov.Percent >= 100 {
+		t.Errorf("ScanHeader: Percent = %.1f, want partial coverage (copyright line is unmatched)", cov.Percent)
+	}
+}
+
+func TestScanHeaderUnknownTag(t *testing.T) {
+	text := "// SPDX-License-Identifier: MTI OR gpl-2.0+ OR LicenseRef-Corp\n\npackage p\n"
+	cov := ScanHeader([]byte(text))
+	if len(cov.Match) != 1 || cov.Match[0].ID != "GPL-2.0-or-later" || !cov.Match[0].IsTag || text[cov.Match[0].Start:cov.Match[0].End] != "gpl-2.0+" {
+		t.Errorf("ScanHeader: Match = %+v, want GPL-2.0-or-later tag at gpl-2.0+", cov.Match)
+	}
+	var ids []string
+	for _, m := range cov.UnknownTags {
+		ids = append(ids, m.ID)
+		if !m.IsTag || m.Type != Unknown || text[m.Start:m.End] != m.ID {
+			t.Errorf("ScanHeader: unknown tag %+v, want IsTag set at ID", m)
+		}
+	}
+	if got, want := strings.Join(ids, ","), "MTI,LicenseRef-Corp"; got != want {
+		t.Errorf("ScanHeader: UnknownTags = %v, want %v", got, want)
+	}
+	if cov.Percent >= 100 {
+		t.Errorf("ScanHeader: Percent = %.1f, want unknown IDs not counted", cov.Percent)
+	}
+}
+
+func TestMismatches(t *testing.T) {
+	skipProfile(t)
+	apache := headerTexts[0].text
+	shl := "SOLDERPAD HARDWARE LICENSE version 2.1\n\n" +
+		"This license operates as a wraparound license to the Apache License Version 2.0\n" +
+		"(the “Apache License”) and incorporates the terms and conditions of the Apache License\n" +
+		"(which can be found here: http://apache.org/licenses/LICENSE-2.0), with the following\n" +
+		"additions and modifications. It must be read in conjunction with the Apache License.\n" +
+		"Section 1 below modifies definitions and terminology in the Apache License and\n" +
+		"Section 2 below replaces Section 2 of the Apache License. The Appendix replaces the\n" +
+		"Appendix in the Apache License. You may, at your option, choose to treat any Work\n" +
+		"released under this license as released under the Apache License (thus ignoring all\n" +
+		"sections written below entirely).\n"
+	tests := []struct {
+		tag  string
+		text string
+		want []string // IDs of mismatched matches
+	}{
+		{"MIT", apache, []string{"Apache-2.0"}},
+		{"Apache-2.0", apache, nil},
+		{"apache-2.0", apache, nil},
+		{"MIT OR Apache-2.0", apache, nil},
+		{"GPL-2.0+", headerTexts[1].text, nil},
+		{"GPL-2.0-only", headerTexts[1].text, []string{"GPL-2.0-or-later"}},
+		{"MIT", "", nil},
+		{"GPL-1.0-or-later", headerTexts[3].text, nil},
+		{"Artistic-1.0-Perl OR GPL-1.0-or-later", headerTexts[3].text, nil},
+		{"MIT", headerTexts[3].text, []string{"Perl"}},
+		{"SHL-2.1", shl, nil},
+		{"Apache-2.0", shl, nil},
+		{"MIT", shl, []string{"SHL-2.1"}},
+		{"MTI", apache, []string{"Apache-2.0"}},
+		{"LicenseRef-Corp", apache, []string{"Apache-2.0"}},
+	}
+	for _, tt := range tests {
+		text := "// SPDX-License-Identifier: " + tt.tag + "\n//\n" + commented("//", tt.text) + "\npackage p\n"
+		cov := ScanHeader([]byte(text))
+		var ids []string
+		for _, m := range cov.Mismatches() {
+			ids = append(ids, m.Match.ID)
+			if len(m.Tags) == 0 || !m.Tags[0].IsTag {
+				t.Errorf("%s: Mismatch.Tags = %+v, want tags", tt.tag, m.Tags)
+			}
+		}
+		if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: Mismatches = %v, want %v", tt.tag, ids, tt.want)
+		}
+	}
+
+	// Without tags, there is nothing to disagree with.
+	if list := Scan([]byte(apache)).Mismatches(); list != nil {
+		t.Errorf("Scan(apache).Mismatches() = %+v, want nil", list)
+	}
+}
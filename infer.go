@@ -0,0 +1,88 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/licensecheck/internal/match"
+)
+
+// InferLRE infers a license regular expression (LRE) from several copies
+// of the same license that differ in filled-in fields,
+// such as an organization's license naming a different licensee in each copy.
+// The words common to all the copies, in order, become the words of the LRE,
+// and each place where the copies differ becomes a __N__ wildcard,
+// where N is the most words any copy has there.
+// Varying text before the first common word and after the last is omitted.
+// The LRE's words are in the normalized form used for matching,
+// in lower case and with a few words canonicalized, such as "this" to "the".
+// The LRE breaks lines where the first copy does.
+//
+// The result can be used as the LRE of a License passed to NewScanner,
+// so that future copies are recognized, whatever their fields hold.
+// At least two copies are needed, and more copies give a better template:
+// with only two, words that happen to agree in a field,
+// like a shared surname, become part of the template.
+func InferLRE(texts [][]byte) (string, error) {
+	if len(texts) < 2 {
+		return "", errors.New("licensecheck: InferLRE needs at least two texts")
+	}
+
+	d := new(match.Dict)
+	words := make([][]match.Word, len(texts))
+	for i, text := range texts {
+		words[i] = d.InsertSplit(string(text))
+	}
+
+	// anchors[m][k] is the index in words[k] of the m'th common word.
+	// Start with all the words of the first text,
+	// and keep only those that align with each other text in turn.
+	var anchors [][]int
+	for i := range words[0] {
+		anchors = append(anchors, []int{i})
+	}
+	for k := 1; k < len(words); k++ {
+		common := make([]match.WordID, len(anchors))
+		for m, a := range anchors {
+			common[m] = words[0][a[0]].ID
+		}
+		var next [][]int
+		for _, p := range diffWords(common, wordIDsOf(words[k]), 0, 0, nil) {
+			next = append(next, append(anchors[p[0]], p[1]))
+		}
+		anchors = next
+	}
+	if len(anchors) == 0 {
+		return "", errors.New("licensecheck: InferLRE: texts have no words in common")
+	}
+
+	var b strings.Builder
+	dict := d.Words()
+	first := words[0]
+	for m, a := range anchors {
+		if m > 0 {
+			gap := 0
+			for k, i := range a {
+				if n := i - anchors[m-1][k] - 1; n > gap {
+					gap = n
+				}
+			}
+			sep := " "
+			if hi, lo := first[anchors[m-1][0]].Hi, first[a[0]].Lo; hi < lo && strings.Contains(string(texts[0][hi:lo]), "\n") {
+				sep = "\n"
+			}
+			b.WriteString(sep)
+			if gap > 0 {
+				fmt.Fprintf(&b, "__%d__%s", gap, sep)
+			}
+		}
+		b.WriteString(dict[first[a[0]].ID])
+	}
+	b.WriteString("\n")
+	return b.String(), nil
+}
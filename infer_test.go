@@ -0,0 +1,64 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const inferTemplate = `Acme Internal Software License
+
+Copyright %s Acme Corporation.
+
+This software is licensed to %s for use within %s only.
+The licensee may not redistribute this software outside of
+the organization named above without written permission from Acme.
+This license expires on %s.
+`
+
+func TestInferLRE(t *testing.T) {
+	fill := func(year, who, where, when string) []byte {
+		return []byte(fmt.Sprintf(inferTemplate, year, who, where, when))
+	}
+	texts := [][]byte{
+		fill("2019", "Example Widgets, Inc.", "the Widgets division", "December 31, 2021"),
+		fill("2020", "Jane Q. Public", "her household", "June 1, 2022"),
+		fill("2020-2021", "The Gopher Foundation", "its offices in Oslo and Berlin", "1 March 2025"),
+	}
+	lre, err := InferLRE(texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `acme internal software license
+copyright __2__ acme corporation
+the software is licensed to __3__ for use within __6__ only
+the licensee may not redistribute the software outside of
+the organization named above without written permission from acme
+the license expires on
+`
+	if lre != want {
+		t.Errorf("InferLRE:\n%s\nwant:\n%s", lre, want)
+	}
+
+	// The inferred LRE recognizes a new copy.
+	s, err := NewScanner([]License{{ID: "LicenseRef-Acme", LRE: lre}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := fill("2022", "Bob", "the lab", "never")
+	cov := s.Scan(text)
+	if len(cov.Match) != 1 || cov.Match[0].ID != "LicenseRef-Acme" || cov.Percent < 90 {
+		t.Errorf("Scan(new copy) = %+v, want LicenseRef-Acme", cov)
+	}
+
+	if _, err := InferLRE(texts[:1]); err == nil {
+		t.Errorf("InferLRE(one text) succeeded")
+	}
+	if _, err := InferLRE([][]byte{[]byte("a b c"), []byte("x y z")}); err == nil || !strings.Contains(err.Error(), "no words in common") {
+		t.Errorf("InferLRE(unrelated) error = %v, want no words in common", err)
+	}
+}
@@ -19,8 +19,9 @@ import (
 // Insert is a write operation; it must not run concurrently with
 // any other call, whether to Insert, Lookup, or Words.
 type Dict struct {
-	dict map[string]WordID // dict maps word to index in list
-	list []string          // list of known words
+	dict     map[string]WordID         // dict maps word to index in list
+	list     []string                  // list of known words
+	tokenize func(text string) []Token // replacement for built-in splitting; see SetTokenizer
 }
 
 // A WordID is the index of a word in a dictionary.
@@ -77,22 +78,50 @@ type Word struct {
 // InsertSplit splits text into a sequence of lowercase words,
 // inserting any new words in the dictionary.
 func (d *Dict) InsertSplit(text string) []Word {
-	return d.split(text, true)
+	return d.split(nil, text, true)
 }
 
 // Split splits text into a sequence of lowercase words.
 // It does not add any new words to the dictionary.
 // Unrecognized words are reported as having ID = BadWord.
 func (d *Dict) Split(text string) []Word {
-	return d.split(text, false)
+	return d.split(nil, text, false)
+}
+
+// A Token is a single word of a text, as returned by a tokenizer set with SetTokenizer.
+type Token struct {
+	Text string // word, already normalized
+	Lo   int    // Word appears at text[Lo:Hi].
+	Hi   int
+}
+
+// SetTokenizer sets the function that InsertSplit and Split use
+// to break a text into words, replacing the built-in splitting
+// and its normalizations. The words are used exactly as returned,
+// so f should fold case and punctuation itself if needed.
+// Tokens with empty text or with offsets out of range
+// or out of order are dropped.
+// A Dict with a tokenizer cannot be encoded.
+func (d *Dict) SetTokenizer(f func(text string) []Token) {
+	d.tokenize = f
+}
+
+// Tokenized reports whether d has a tokenizer set by SetTokenizer.
+func (d *Dict) Tokenized() bool {
+	return d.tokenize != nil
 }
 
 // © is rewritten to this text.
 var copyright = []byte("copyright")
 
-func (d *Dict) split(text string, insert bool) []Word {
-	var wbuf []byte
-	var words []Word
+// split appends the words of text to words and returns the result.
+func (d *Dict) split(words []Word, text string, insert bool) []Word {
+	if d.tokenize != nil {
+		return d.splitTokens(words, text, insert)
+	}
+	var buf [64]byte // initial word buffer, to avoid allocation for most texts
+	wbuf := buf[:0]
+	prevFirst := "" // previous word, if it begins a typoJoins key
 	t := text
 	for t != "" {
 		var w []byte
@@ -183,9 +212,31 @@ func (d *Dict) split(text string, insert bool) []Word {
 					w = append(w[:0], m.x...)
 				}
 			}
+
+			// Common typos.
+			// The length checks keep most words from needing a map lookup.
+			if len(w) >= minTypoLen {
+				if fix, ok := typoWords[string(w)]; ok {
+					// A run-together word like "publicdomain" becomes
+					// several words, each spanning the original word.
+					for _, f := range fix[:len(fix)-1] {
+						words = d.appendWord(words, f, lo, hi, insert)
+					}
+					w = append(w[:0], fix[len(fix)-1]...)
+				}
+			}
+			if n := len(words); prevFirst != "" && n > 0 && len(w) <= maxTypoJoinLen && isJoinSpace(text[words[n-1].Hi:lo]) {
+				// A split word like "sub license" becomes one word.
+				if fix, ok := typoJoins[prevFirst+" "+string(w)]; ok {
+					lo = words[n-1].Lo
+					words = words[:n-1]
+					w = append(w[:0], fix...)
+				}
+			}
 		}
 
 	Emit:
+		prevFirst = typoFirstWord(w)
 		id, ok := d.dict[string(w)]
 		if ok {
 			if len(words) > 0 && words[len(words)-1].ID == id && string(w) == "copyright" {
@@ -208,6 +259,28 @@ func (d *Dict) split(text string, insert bool) []Word {
 	return words
 }
 
+// splitTokens is like split but uses d.tokenize to find the words.
+func (d *Dict) splitTokens(words []Word, text string, insert bool) []Word {
+	end := 0
+	for _, t := range d.tokenize(text) {
+		if t.Text == "" || t.Lo < end || t.Hi < t.Lo || t.Hi > len(text) {
+			continue
+		}
+		words = d.appendWord(words, t.Text, int32(t.Lo), int32(t.Hi), insert)
+		end = t.Hi
+	}
+	return words
+}
+
+// appendWord appends the word w, found at text[lo:hi], to words.
+func (d *Dict) appendWord(words []Word, w string, lo, hi int32, insert bool) []Word {
+	id := d.Lookup(w)
+	if id == BadWord && insert {
+		id = d.Insert(w)
+	}
+	return append(words, Word{id, lo, hi})
+}
+
 // foldRune returns the folded rune r.
 // It returns -1 if the rune r should be omitted entirely.
 //
@@ -259,6 +332,18 @@ func foldRune(r rune) rune {
 
 // toFold converts s to folded form.
 func toFold(s string) string {
+	// Most words are already folded; return them without allocating.
+	folded := true
+	for _, r := range s {
+		if foldRune(r) != r {
+			folded = false
+			break
+		}
+	}
+	if folded {
+		return s
+	}
+
 	var buf []byte
 	for _, r := range s {
 		buf = appendFoldRune(buf, r)
@@ -387,7 +472,8 @@ func markdownAnchorSize(t string) int {
 		switch t[i] {
 		case '}':
 			return i + 1
-		case ' ', '\r', '\n':
+		case ' ', '\r', '\n', '{':
+			// Stopping at { avoids quadratic behavior on {#{#{#...
 			return 0
 		}
 	}
@@ -421,7 +507,8 @@ func markdownLinkSize(t string) int {
 
 	for i := 2; i < len(t); i++ {
 		c := t[i]
-		if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == ']' {
+			// Stopping at ] avoids quadratic behavior on ](http://](http://...
 			return 0
 		}
 		if c == ')' {
@@ -446,3 +533,111 @@ var canonicalRewrites = []struct {
 	{"the", "those"},
 	{"copy", "copies"}, // most plurals are handled as 1-letter typos
 }
+
+// typos is a list of misspellings commonly found in copies of licenses,
+// which are canonicalized during word splitting like canonicalRewrites:
+// the words on the right are parsed as if they were the words on the left.
+// Most single-letter misspellings are accepted by canMisspell anyway,
+// but only once a match has started and only in longer words.
+//
+// Either side may be two words, for words that are often run together
+// ("publicdomain") or split apart ("sub license", or "sub-license",
+// since hyphens separate words).
+var typos = []struct {
+	x, y string
+}{
+	{"copyright", "copywrite"},
+	{"copyright", "copywright"},
+	{"copyright", "copyrigth"},
+	{"infringement", "infringment"},
+	{"noninfringement", "noninfringment"},
+	{"license", "lisence"},
+	{"license", "lisense"},
+	{"licensed", "lisenced"},
+	{"liability", "liablity"},
+	{"merchantability", "merchantibility"},
+	{"merchantability", "merchantablity"},
+	{"merchantability", "merchantabilty"},
+	{"permission", "permision"},
+	{"royalty", "royality"},
+	{"warranties", "warrenties"},
+	{"warranties", "waranties"},
+	{"warranty", "warrenty"},
+	{"warranty", "waranty"},
+	{"public domain", "publicdomain"},
+	{"hereby", "here by"},
+	{"redistribute", "re distribute"},
+	{"redistribution", "re distribution"},
+	{"sublicense", "sub license"},
+	{"sublicenses", "sub licenses"},
+	{"without", "with out"},
+}
+
+// Lookup tables built from typos.
+// Splitting consults them for every word of every text,
+// so the lengths and first bytes let it skip the maps for most words.
+var (
+	typoWords      = make(map[string][]string) // misspelled word -> correct words
+	typoJoins      = make(map[string]string)   // misspelled "w1 w2" -> correct word
+	typoFirst      []string                    // first words of typoJoins keys
+	typoFirstByte  [256]bool                   // first bytes of typoFirst words
+	minTypoLen     = -1                        // length of shortest typoWords key
+	maxTypoFirst   int                         // length of longest typoFirst word
+	maxTypoJoinLen int                         // length of longest second word of typoJoins key
+)
+
+func init() {
+	for _, t := range typos {
+		x, y := strings.Fields(t.x), strings.Fields(t.y)
+		switch {
+		case len(y) == 1:
+			typoWords[t.y] = x
+			if minTypoLen < 0 || len(t.y) < minTypoLen {
+				minTypoLen = len(t.y)
+			}
+		case len(y) == 2 && len(x) == 1:
+			typoJoins[t.y] = t.x
+			if typoFirstWord([]byte(y[0])) == "" {
+				typoFirst = append(typoFirst, y[0])
+				typoFirstByte[y[0][0]] = true
+			}
+			if len(y[0]) > maxTypoFirst {
+				maxTypoFirst = len(y[0])
+			}
+			if len(y[1]) > maxTypoJoinLen {
+				maxTypoJoinLen = len(y[1])
+			}
+		default:
+			panic("bad typo: " + t.y)
+		}
+	}
+}
+
+// typoFirstWord returns w if it is the first word of a typoJoins key,
+// or else the empty string. It does not allocate.
+func typoFirstWord(w []byte) string {
+	if len(w) == 0 || len(w) > maxTypoFirst || !typoFirstByte[w[0]] {
+		return ""
+	}
+	for _, f := range typoFirst {
+		if string(w) == f {
+			return f
+		}
+	}
+	return ""
+}
+
+// isJoinSpace reports whether the text between two words
+// allows them to be joined by typoJoins: it must be only
+// spaces, line breaks, and hyphens, not other punctuation,
+// so that "comply with. Out of" does not become "without".
+func isJoinSpace(sep string) bool {
+	for i := 0; i < len(sep); i++ {
+		switch sep[i] {
+		case ' ', '\t', '\r', '\n', '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
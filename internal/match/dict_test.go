@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -100,6 +101,7 @@ var markdownAnchorSizeTests = []struct {
 	{"{#abc def}", 0},
 	{"{#abc\ndef}", 0},
 	{"{#abc\rdef}", 0},
+	{"{#abc{#def}", 0},
 }
 
 func TestMarkdownAnchorSize(t *testing.T) {
@@ -142,6 +144,7 @@ var insertSplitTests = []struct {
 	{"[text](https://link\r)MORE", "text http link more"},
 	{"[text](https://link\n)MORE", "text http link more"},
 	{"[text](#anchor) more", "text more"},
+	{"[a](http://b[c](http://d) more", "a http b c more"},
 	{"Copyright 2020 Gopher®", "copyright 2020 gopher"},
 	{"Copyright © 2020 Gopher®", "copyright 2020 gopher"},
 	{"(c) 2020 Gopher®", "copyright 2020 gopher"},
@@ -155,6 +158,17 @@ var insertSplitTests = []struct {
 	{"http://golang.org", "http golang org"},
 	{"https://golang.org", "http golang org"},
 	{"the notice(s) must", "the notices must"},
+
+	// Typos.
+	{"NO WARRENTY", "no warranty"},
+	{"Copywrite 2020", "copyright 2020"},
+	{"into the publicdomain", "into the public domain"},
+	{"sub license, sub-license, or sub-\nlicenses", "sublicense sublicense or sublicenses"},
+	{"with out warranty", "without warranty"},
+	{"with outstanding sub committees", "with outstanding sub committees"},
+	{"comply with. Out of", "comply with out of"},
+	{"here, by the way", "here by the way"},
+	{"sub (license)", "sub license"},
 }
 
 func TestDictInsertSplit(t *testing.T) {
@@ -183,6 +197,7 @@ var splitTests = []struct {
 	out string
 }{
 	{"distribute,\n<p>sublicense, extort,  and/or\rsell ", "distribute sublicense ? and or sell"},
+	{"distribute, sub-license, and/or sell", "distribute sublicense and or sell"},
 }
 
 func TestDictSplit(t *testing.T) {
@@ -255,7 +270,7 @@ func benchSetup(b *testing.B) {
 	if bench.data != nil {
 		return
 	}
-	files, err := filepath.Glob("../testdata/*")
+	files, err := filepath.Glob("../../testdata/*")
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -283,7 +298,61 @@ func BenchmarkInsertSplit(b *testing.B) {
 func BenchmarkSplit(b *testing.B) {
 	benchSetup(b)
 	b.SetBytes(int64(len(bench.str)))
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		bench.dict.Split(bench.str)
 	}
 }
+
+func TestDictSplitTypoOffsets(t *testing.T) {
+	var d Dict
+	text := "the publicdomain or sub-license"
+	var got []string
+	for _, w := range d.InsertSplit(text) {
+		got = append(got, d.Words()[w.ID]+"="+text[w.Lo:w.Hi])
+	}
+	want := []string{"the=the", "public=publicdomain", "domain=publicdomain", "or=or", "sublicense=sub-license"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("InsertSplit(%q) = %q, want %q", text, got, want)
+	}
+}
+
+func TestDictSetTokenizer(t *testing.T) {
+	var d Dict
+	d.SetTokenizer(func(text string) []Token {
+		return []Token{
+			{"A", 0, 1},
+			{"", 1, 2}, // empty: dropped
+			{"b", 2, 3},
+			{"x", 1, 3}, // overlaps previous: dropped
+			{"c", 4, 9}, // out of range: dropped
+			{"d", 4, 5},
+		}
+	})
+	text := "a-b d"
+	var got []string
+	for _, w := range d.InsertSplit(text) {
+		got = append(got, d.Words()[w.ID]+"="+text[w.Lo:w.Hi])
+	}
+	want := []string{"A=a", "b=b", "d=d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("InsertSplit(%q) = %q, want %q", text, got, want)
+	}
+	if ws := d.Split(text); len(ws) != 3 || ws[2].ID != d.Lookup("d") {
+		t.Errorf("Split(%q) = %v, want tokenizer words", text, ws)
+	}
+}
+
+func BenchmarkSplitTypoJoins(b *testing.B) {
+	// Words that begin typo joins, like "with" and "here",
+	// are common, so splitting must check them cheaply.
+	var dict Dict
+	text := strings.Repeat("here is a text with words that sub for the real ones, re read with care. ", 1000)
+	dict.InsertSplit(text)
+	b.SetBytes(int64(len(text)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dict.Split(text)
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package match
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// multiLREMagic identifies the binary encoding of a MultiLRE.
+// It must change whenever the encoding or the DFA format changes.
+const multiLREMagic = "MultiLRE 1\n"
+
+// MarshalBinary returns a compact binary encoding of re,
+// which can be decoded using UnmarshalBinary.
+//
+// The encoding holds the dictionary, the start phrases, and the DFA.
+// DFA transitions are stored relative to the state containing them,
+// since most lead to nearby states.
+// A MultiLRE whose dictionary has a tokenizer cannot be encoded,
+// since the tokenizer is code, not data.
+func (re *MultiLRE) MarshalBinary() ([]byte, error) {
+	if re.dict != nil && re.dict.Tokenized() {
+		return nil, errors.New("MultiLRE: cannot encode with custom tokenizer")
+	}
+	var e encoder
+	e.buf = append(e.buf, multiLREMagic...)
+	e.uvarint(uint64(re.n))
+
+	var words []string
+	if re.dict != nil {
+		words = re.dict.Words()
+	}
+	e.uvarint(uint64(len(words)))
+	for _, w := range words {
+		e.uvarint(uint64(len(w)))
+		e.buf = append(e.buf, w...)
+	}
+
+	// Sort start phrases for a deterministic encoding.
+	var start []phrase
+	for p := range re.start {
+		start = append(start, p)
+	}
+	sort.Slice(start, func(i, j int) bool {
+		if start[i][0] != start[j][0] {
+			return start[i][0] < start[j][0]
+		}
+		return start[i][1] < start[j][1]
+	})
+	e.uvarint(uint64(len(start)))
+	for _, p := range start {
+		e.varint(int64(p[0]))
+		e.varint(int64(p[1]))
+	}
+
+	e.uvarint(uint64(len(re.dfa)))
+	for i := 0; i < len(re.dfa); {
+		pos := int32(i)
+		hdr := re.dfa[i]
+		e.varint(int64(hdr))
+		i++
+		if hdr < 0 {
+			continue // chain state
+		}
+		if hdr&1 != 0 {
+			e.varint(int64(re.dfa[i])) // match value
+			i++
+		}
+		for n := hdr >> 1; n > 0; n-- {
+			e.varint(int64(re.dfa[i]))
+			e.varint(int64(re.dfa[i+1] - pos))
+			i += 2
+		}
+	}
+	return e.buf, nil
+}
+
+// UnmarshalBinary sets re to the MultiLRE encoded in data,
+// which must have been returned by MarshalBinary.
+func (re *MultiLRE) UnmarshalBinary(data []byte) error {
+	if len(data) < len(multiLREMagic) || string(data[:len(multiLREMagic)]) != multiLREMagic {
+		return errors.New("MultiLRE: invalid encoding or unsupported version")
+	}
+	d := decoder{buf: data[len(multiLREMagic):]}
+	nlre := d.count()
+
+	dict := new(Dict)
+	n := d.count()
+	for i := 0; i < n && d.err == nil; i++ {
+		w := d.bytes(d.count())
+		if dict.Insert(string(w)) != WordID(i) {
+			d.fail()
+		}
+	}
+	nword := WordID(len(dict.Words()))
+	word := func(w WordID) WordID {
+		if w < AnyWord || w >= nword {
+			d.fail()
+		}
+		return w
+	}
+
+	start := make(map[phrase]struct{})
+	startWord := make([]bool, nword)
+	n = d.count()
+	for i := 0; i < n && d.err == nil; i++ {
+		p := phrase{word(WordID(d.varint())), word(WordID(d.varint()))}
+		if d.err != nil || p[0] < 0 {
+			d.fail()
+			break
+		}
+		start[p] = struct{}{}
+		startWord[p[0]] = true
+	}
+
+	dfa := make(reDFA, 0, d.count())
+	for len(dfa) < cap(dfa) && d.err == nil {
+		pos := int32(len(dfa))
+		hdr := int32(d.varint())
+		dfa = append(dfa, hdr)
+		if hdr < 0 {
+			word(^WordID(hdr))
+			continue
+		}
+		if hdr&1 != 0 {
+			dfa = append(dfa, int32(d.varint()))
+		}
+		for n := hdr >> 1; n > 0 && d.err == nil; n-- {
+			dfa = append(dfa, int32(word(WordID(d.varint()))), pos+int32(d.varint()))
+		}
+	}
+	if d.err == nil && (len(d.buf) != 0 || len(dfa) != cap(dfa)) {
+		d.fail()
+	}
+	// Check that every transition leads to the start of a state,
+	// so that a corrupt encoding cannot make matching crash.
+	if d.err == nil && !dfa.valid(nlre) {
+		d.fail()
+	}
+	if d.err != nil {
+		return d.err
+	}
+
+	*re = MultiLRE{nlre, dict, dfa, start, dfa.minWords(), startWord}
+	return nil
+}
+
+// valid reports whether dfa is well-formed:
+// each state fits in the DFA, each match value is less than nlre,
+// each transition is on a word or else is the first one, on AnyWord,
+// and each transition leads to the start of a state.
+func (dfa reDFA) valid(nlre int) bool {
+	start := make([]bool, len(dfa))
+	for i := 0; i < len(dfa); {
+		start[i] = true
+		hdr := dfa[i]
+		i++
+		if hdr < 0 {
+			continue
+		}
+		if hdr&1 != 0 {
+			if i >= len(dfa) || dfa[i] < 0 || int(dfa[i]) >= nlre {
+				return false
+			}
+			i++
+		}
+		if n := int(hdr >> 1); n > (len(dfa)-i)/2 {
+			return false
+		}
+		i += 2 * int(hdr>>1)
+	}
+	for i := 0; i < len(dfa); {
+		hdr := dfa[i]
+		i++
+		if hdr < 0 {
+			if i >= len(dfa) {
+				return false // chain state must be followed by its next state
+			}
+			continue
+		}
+		i += int(hdr & 1)
+		for j := int32(0); j < hdr>>1; j++ {
+			w, next := WordID(dfa[i]), dfa[i+1]
+			if w < 0 && (w != AnyWord || j > 0) {
+				return false
+			}
+			if next < 0 || int(next) >= len(dfa) || !start[next] {
+				return false
+			}
+			i += 2
+		}
+	}
+	return true
+}
+
+// An encoder appends variable-length integers to buf.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) uvarint(x uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf, tmp[:binary.PutUvarint(tmp[:], x)]...)
+}
+
+func (e *encoder) varint(x int64) {
+	var tmp [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf, tmp[:binary.PutVarint(tmp[:], x)]...)
+}
+
+// A decoder reads values written by an encoder from buf.
+// After the first error, it returns only zero values.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = errors.New("MultiLRE: corrupt encoding")
+		d.buf = nil
+	}
+}
+
+func (d *decoder) uvarint() uint64 {
+	x, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return x
+}
+
+func (d *decoder) varint() int64 {
+	x, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return x
+}
+
+// count decodes a length or count, which cannot exceed
+// the number of remaining bytes in the encoding.
+func (d *decoder) count() int {
+	x := d.uvarint()
+	if x > uint64(len(d.buf)) {
+		d.fail()
+		return 0
+	}
+	return int(x)
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if n > len(d.buf) {
+		d.fail()
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
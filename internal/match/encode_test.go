@@ -0,0 +1,83 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package match
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testMultiLRE(t *testing.T, exprs ...string) *MultiLRE {
+	t.Helper()
+	var d Dict
+	var list []*LRE
+	for _, expr := range exprs {
+		re, err := ParseLRE(&d, "x", expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", expr, err)
+		}
+		list = append(list, re)
+	}
+	re, err := NewMultiLRE(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return re
+}
+
+func TestMarshalBinary(t *testing.T) {
+	for _, tt := range multiMatchTests {
+		re := testMultiLRE(t, strings.Split(tt.re, "/")...)
+		data, err := re.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		re2 := new(MultiLRE)
+		if err := re2.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary(MarshalBinary(%q)): %v", tt.re, err)
+		}
+		if re2.Len() != re.Len() || !reflect.DeepEqual(re2.dfa, re.dfa) || !reflect.DeepEqual(re2.start, re.start) {
+			t.Errorf("UnmarshalBinary(MarshalBinary(%q)) differs from original", tt.re)
+		}
+		m := re2.Match(tt.in)
+		if len(m.List) != len(tt.list) || len(tt.list) > 0 && !reflect.DeepEqual(m.List, tt.list) {
+			t.Errorf("decoded %q: Match(%q) = %+v, want %+v", tt.re, tt.in, m.List, tt.list)
+		}
+		data2, _ := re2.MarshalBinary()
+		if !bytes.Equal(data, data2) {
+			t.Errorf("re-encoding %q changed the encoding", tt.re)
+		}
+	}
+}
+
+func TestUnmarshalBinaryCorrupt(t *testing.T) {
+	re := testMultiLRE(t, "a b c", "a b __3__ d", "x\n((y || z))??\nb")
+	data, err := re.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every truncation must be rejected.
+	for i := 0; i < len(data); i++ {
+		if err := new(MultiLRE).UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("UnmarshalBinary(data[:%d]) succeeded", i)
+		}
+	}
+
+	// Changed bytes may decode successfully,
+	// but the result must never make Match crash.
+	for i := len(multiLREMagic); i < len(data); i++ {
+		for _, b := range []byte{0, 1, 0x7f, 0x80, 0xff} {
+			bad := append([]byte(nil), data...)
+			bad[i] = b
+			re := new(MultiLRE)
+			if err := re.UnmarshalBinary(bad); err == nil {
+				re.Match("a b c a b x y d x y b x z b")
+			}
+		}
+	}
+}
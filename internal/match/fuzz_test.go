@@ -0,0 +1,46 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package match
+
+import (
+	"testing"
+)
+
+func FuzzSplit(f *testing.F) {
+	f.Add("Hello, world!")
+	f.Add("<b>bold</b> &copy; &amp; {#anchor} [link](http://x) notice(s) (c) ©1996")
+	f.Add("<a\n\n\nb> &#12345678901234567890; \xff\xfe ﬁ")
+	f.Fuzz(func(t *testing.T, text string) {
+		var d Dict
+		end := int32(0)
+		for _, w := range d.InsertSplit(text) {
+			if w.Lo < end || w.Hi <= w.Lo || int(w.Hi) > len(text) {
+				t.Fatalf("word at [%d:%d] after end %d in text of length %d", w.Lo, w.Hi, end, len(text))
+			}
+			end = w.Hi
+		}
+	})
+}
+
+func FuzzParseLRE(f *testing.F) {
+	f.Add("a b c")
+	f.Add("a b\n((c || d))??\n__3__ e")
+	f.Add("a b\n((\n((c))\n))\n//** x **// f")
+	f.Fuzz(func(t *testing.T, lre string) {
+		var d Dict
+		re, err := ParseLRE(&d, "fuzz", lre)
+		if err != nil {
+			return
+		}
+		m, err := NewMultiLRE([]*LRE{re})
+		if err != nil {
+			return
+		}
+		m.Match(lre)
+	})
+}
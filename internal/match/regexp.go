@@ -9,6 +9,7 @@ package match
 import (
 	"fmt"
 	"sync"
+	"unsafe"
 )
 
 // An LRE is a compiled license regular expression.
@@ -84,7 +85,7 @@ func (re *LRE) File() string {
 // Match reports whether text matches the license regexp.
 func (re *LRE) match(text string) bool {
 	re.onceDFA.Do(re.compile)
-	match, _ := re.dfa.match(re.dict, text, re.dict.Split(text))
+	match, _ := re.dfa.match(re.dict, text, re.dict.Split(text), false)
 	return match >= 0
 }
 
@@ -98,6 +99,7 @@ func (re *LRE) compile() {
 // A MultiLRE matches multiple LREs simultaneously against a text.
 // It is more efficient than matching each LRE in sequence against the text.
 type MultiLRE struct {
+	n    int   // number of LREs
 	dict *Dict // dict shared by all LREs
 	dfa  reDFA // compiled DFA for all LREs
 
@@ -105,6 +107,17 @@ type MultiLRE struct {
 	// where a match can validly start,
 	// to allow for faster scans over non-license text.
 	start map[phrase]struct{}
+
+	// minWords is the minimum number of words in any text
+	// that can match, so that Match need not run the DFA
+	// starting at the last few words of a text.
+	minWords int
+
+	// startWord[w] reports whether any phrase in start begins with w.
+	// Most words in a text do not begin any start phrase,
+	// and checking startWord is much cheaper than looking up
+	// the word's phrase in start.
+	startWord []bool
 }
 
 // A phrase is a phrase of up to two words.
@@ -161,10 +174,21 @@ func NewMultiLRE(list []*LRE) (_ *MultiLRE, err error) {
 		}
 	}
 
+	startWord := make([]bool, len(dict.Words()))
+	for p := range start {
+		startWord[p[0]] = true
+	}
+
 	prog := reCompileMulti(progs)
 	dfa := reCompileDFA(prog)
 
-	return &MultiLRE{dict, dfa, start}, nil
+	return &MultiLRE{len(list), dict, dfa, start, dfa.minWords(), startWord}, nil
+}
+
+// Len returns the number of LREs in the MultiLRE.
+// Match reports match IDs from 0 to Len()-1.
+func (re *MultiLRE) Len() int {
+	return re.n
 }
 
 // Dict returns the Dict used by the MultiLRE.
@@ -177,6 +201,27 @@ type Matches struct {
 	Text  string  // the entire text
 	Words []Word  // the text, split into Words
 	List  []Match // the matches
+
+	cands []Candidate // scratch space for MatchChoose
+}
+
+// matchesPool holds Matches released by Free, for reuse by Match.
+var matchesPool sync.Pool
+
+// maxPoolWords is the maximum capacity of a Words slice
+// that Free will keep for reuse, so that one very large text
+// does not pin its memory for the life of the program.
+const maxPoolWords = 1 << 16
+
+// Free releases m for reuse by a later call to Match,
+// avoiding new allocations when matching many texts.
+// After calling Free, the caller must not use m, m.Words, or m.List.
+func (m *Matches) Free() {
+	if cap(m.Words) > maxPoolWords {
+		return
+	}
+	m.Text = ""
+	matchesPool.Put(m)
 }
 
 // A Match records the position of a single match in a text.
@@ -190,15 +235,79 @@ type Match struct {
 // It always returns a non-nil *Matches, in order to return the split text.
 // Check len(matches.List) to see whether any matches were found.
 func (re *MultiLRE) Match(text string) *Matches {
-	m := &Matches{
-		Text:  text,
-		Words: re.dict.Split(text),
+	return re.match(text, false, nil)
+}
+
+// MatchOCR is like Match but tolerates the errors typical of text
+// produced by optical character recognition, such as "rn" for "m"
+// or "1" for "l", within a match.
+func (re *MultiLRE) MatchOCR(text string) *Matches {
+	return re.match(text, true, nil)
+}
+
+// A Candidate is one of the matches beginning at a given word,
+// among which MatchChoose chooses the one to report.
+type Candidate struct {
+	ID    int // index of LRE in list passed to NewMultiLRE
+	End   int // word index of end of match
+	Fixed int // number of words matched only by correcting their spelling
+}
+
+// MatchChoose is like Match, or MatchOCR if ocr is true,
+// but it calls choose to decide which of the matches beginning at a word
+// to report, instead of always reporting the longest.
+// The words passed to choose are the text split into words,
+// and the candidates all begin at the word index start.
+// They are in order of increasing End, so the last is the longest,
+// and choose returns the index of the one to report.
+// The search for the next match begins at the end of the chosen one.
+// The candidates slice is reused after choose returns.
+// If choose is nil, MatchChoose reports the longest, like Match.
+func (re *MultiLRE) MatchChoose(text string, ocr bool, choose func(words []Word, start int, cands []Candidate) int) *Matches {
+	return re.match(text, ocr, choose)
+}
+
+// MatchBytesChoose is like MatchChoose but matches the bytes of text,
+// with the same restriction as MatchBytes.
+func (re *MultiLRE) MatchBytesChoose(text []byte, ocr bool, choose func(words []Word, start int, cands []Candidate) int) *Matches {
+	return re.MatchChoose(*(*string)(unsafe.Pointer(&text)), ocr, choose)
+}
+
+func (re *MultiLRE) match(text string, ocr bool, choose func([]Word, int, []Candidate) int) *Matches {
+	m, _ := matchesPool.Get().(*Matches)
+	if m == nil {
+		m = new(Matches)
 	}
+	m.Text = text
+	m.Words = re.dict.split(m.Words[:0], text, false)
+	m.List = m.List[:0]
 	p := phrase{BadWord, BadWord}
 	for i := 0; i < len(m.Words); i++ {
+		if len(m.Words)-(i-1) < re.minWords {
+			// Too few words left for a match starting at i-1.
+			break
+		}
 		p[0], p[1] = p[1], m.Words[i].ID
+		if p[0] < 0 || int(p[0]) >= len(re.startWord) || !re.startWord[p[0]] {
+			continue
+		}
 		if _, ok := re.start[p]; ok {
-			match, end := re.dfa.match(re.dict, text, m.Words[i-1:])
+			var match int32
+			var end int
+			if choose == nil {
+				match, end = re.dfa.match(re.dict, text, m.Words[i-1:], ocr)
+			} else {
+				m.cands = m.cands[:0]
+				re.dfa.matchAll(re.dict, text, m.Words[i-1:], ocr, &m.cands)
+				match, end = -1, 0
+				if len(m.cands) > 0 {
+					for j := range m.cands {
+						m.cands[j].End += i - 1
+					}
+					c := m.cands[choose(m.Words, i-1, m.cands)]
+					match, end = int32(c.ID), c.End-(i-1)
+				}
+			}
 			if match >= 0 && end > 0 {
 				end += i - 1 // translate from index in m.Words[i-1:] to index in m.Words
 				m.List = append(m.List, Match{ID: int(match), Start: i - 1, End: end})
@@ -212,3 +321,18 @@ func (re *MultiLRE) Match(text string) *Matches {
 	}
 	return m
 }
+
+// MatchBytes is like Match but matches the bytes of text.
+// The returned Matches refer to text directly instead of to a copy,
+// so the caller must not modify text while using the result.
+func (re *MultiLRE) MatchBytes(text []byte) *Matches {
+	// Converting text to a string without a copy is safe
+	// as long as text is not modified, as required above.
+	return re.Match(*(*string)(unsafe.Pointer(&text)))
+}
+
+// MatchBytesOCR is like MatchOCR but matches the bytes of text,
+// with the same restriction as MatchBytes.
+func (re *MultiLRE) MatchBytesOCR(text []byte) *Matches {
+	return re.MatchOCR(*(*string)(unsafe.Pointer(&text)))
+}
@@ -56,3 +56,95 @@ func TestMultiLREMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchesFree(t *testing.T) {
+	var d Dict
+	lre, err := ParseLRE(&d, "x", "a b c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	re, err := NewMultiLRE([]*LRE{lre})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Results must not depend on a Matches reused from a previous call.
+	re.Match("x a b c y a b c z").Free()
+	m := re.Match("a b c")
+	want := []Match{{ID: 0, Start: 0, End: 3}}
+	if m.Text != "a b c" || len(m.Words) != 3 || !reflect.DeepEqual(m.List, want) {
+		t.Errorf("Match after Free = %+v, want %v", m, want)
+	}
+	m.Free()
+
+	if testing.Short() || raceEnabled {
+		// The race detector drops sync.Pool items.
+		return
+	}
+	text := strings.Repeat("x a b c y ", 100)
+	allocs := testing.AllocsPerRun(100, func() {
+		re.Match(text).Free()
+	})
+	if allocs > 2 {
+		t.Errorf("Match+Free allocates %v times, want at most 2", allocs)
+	}
+}
+
+func TestMultiLREDictGrowth(t *testing.T) {
+	// Words added to the Dict after NewMultiLRE must not confuse Match.
+	var d Dict
+	lre, err := ParseLRE(&d, "x", "a b c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	re, err := NewMultiLRE([]*LRE{lre})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.InsertSplit("new words here")
+	m := re.Match("new words a b c here")
+	want := []Match{{ID: 0, Start: 2, End: 5}}
+	if !reflect.DeepEqual(m.List, want) {
+		t.Errorf("Match = %+v, want %+v", m.List, want)
+	}
+}
+
+func TestMultiLREMatchChoose(t *testing.T) {
+	var d Dict
+	var list []*LRE
+	for _, expr := range []string{"a b c", "a b c d e", "a b __2__ fifth"} {
+		re, err := ParseLRE(&d, "x", expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", expr, err)
+		}
+		list = append(list, re)
+	}
+	re, err := NewMultiLRE(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := "x a b c d e a b c d fiffth"
+	var got [][]Candidate
+	m := re.MatchChoose(text, false, func(words []Word, start int, cands []Candidate) int {
+		got = append(got, append([]Candidate(nil), cands...))
+		return 0 // shortest
+	})
+	want := [][]Candidate{
+		{{ID: 0, End: 4}, {ID: 1, End: 6}},
+		{{ID: 0, End: 9}, {ID: 2, End: 11, Fixed: 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates:\nhave %+v\nwant %+v", got, want)
+	}
+	wantList := []Match{{ID: 0, Start: 1, End: 4}, {ID: 0, Start: 6, End: 9}}
+	if !reflect.DeepEqual(m.List, wantList) {
+		t.Errorf("MatchChoose = %+v, want %+v", m.List, wantList)
+	}
+
+	// Choosing the longest matches like Match.
+	m = re.MatchChoose(text, false, func(words []Word, start int, cands []Candidate) int { return len(cands) - 1 })
+	if want := re.Match(text).List; !reflect.DeepEqual(m.List, want) {
+		t.Errorf("MatchChoose(longest) = %+v, want %+v", m.List, want)
+	}
+}
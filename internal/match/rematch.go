@@ -504,29 +504,47 @@ func nfaStart(prog reProg) nfaState {
 // add adds pc and other states reachable from it
 // to the set of possible instruction locations in *s.
 func (s *nfaState) add(prog reProg, pc int32) {
+	s.addSparse(prog, pc, nil)
+}
+
+// addSparse is like add but uses sparse, if non-nil, to avoid adding
+// the same state twice in constant time.
+// If sparse is non-nil, it must have length len(prog),
+// and *s must have been built entirely by addSparse calls using sparse.
+// The contents of sparse need not be initialized;
+// see https://research.swtch.com/sparse.
+//
+// Without sparse, checking for a duplicate is a scan linear in the size of *s,
+// which makes building a state technically quadratic in its size.
+// Licenses are long texts of literal words, so the NFA states
+// end up being very small - there's not much ambiguity about
+// where we are in the list - except when there are many licenses
+// beginning with the same words or wildcards, as in large sets of
+// short license notices. The DFA builder uses sparse for that case.
+func (s *nfaState) addSparse(prog reProg, pc int32, sparse []int32) {
 	// Avoid adding same state twice.
-	// This scan is linear in the size of *s, which makes the overall
-	// nfaStart / s.next operation technically quadratic in the size of *s,
-	// but licenses are long texts of literal words, so the NFA states
-	// end up being very small - there's not much ambiguity about
-	// where we are in the list. If this ever showed up as expensive
-	// on a profile, we could switch to a sparse set instead;
-	// see https://research.swtch.com/sparse.
-	for _, old := range *s {
-		if old == pc {
+	if sparse != nil {
+		if i := sparse[pc]; 0 <= i && int(i) < len(*s) && (*s)[i] == pc {
 			return
 		}
+		sparse[pc] = int32(len(*s))
+	} else {
+		for _, old := range *s {
+			if old == pc {
+				return
+			}
+		}
 	}
 
 	*s = append(*s, pc)
 	switch prog[pc].op {
 	case instAlt:
-		s.add(prog, pc+1)
-		s.add(prog, pc+1+prog[pc].arg)
+		s.addSparse(prog, pc+1, sparse)
+		s.addSparse(prog, pc+1+prog[pc].arg, sparse)
 	case instJump:
-		s.add(prog, pc+1+prog[pc].arg)
+		s.addSparse(prog, pc+1+prog[pc].arg, sparse)
 	case instCut:
-		s.add(prog, pc+1)
+		s.addSparse(prog, pc+1, sparse)
 	}
 }
 
@@ -672,24 +690,34 @@ func (s nfaState) appendEncoding(enc []byte) []byte {
 //	  is treated as matching any input word; an exact match later in the list takes priority.
 //	  The list is sorted by W, so AnyWord is always first if present.
 //
+// Most states in a DFA for license texts are links in a long chain:
+// they do not match, and they have a single transition, on a word W,
+// to the state that immediately follows them in the encoding.
+// Such a state is encoded as the single negative word ^W, in place of the
+// usual three words M | N<<1, W, NEXT. This shrinks a typical DFA by more than a third.
+//
 type reDFA []int32
 
 // A dfaBuilder holds state for building a DFA from a reProg.
 type dfaBuilder struct {
-	prog reProg         // program being processed
-	dfa  reDFA          // DFA so far
-	have map[string]int // map from encoded NFA state to dfa array offset
-	enc  []byte         // encoding buffer
+	prog   reProg         // program being processed
+	dfa    reDFA          // DFA so far
+	have   map[string]int // map from encoded NFA state to dfa array offset
+	enc    []byte         // encoding buffer
+	sparse []int32        // sparse set for nfaState.addSparse
 }
 
 // reCompileDFA compiles prog into a DFA.
 func reCompileDFA(prog reProg) reDFA {
 	b := &dfaBuilder{
-		prog: prog,
-		have: map[string]int{"": -1}, // dead (empty) NFA state encoding maps to DFA offset -1
+		prog:   prog,
+		have:   map[string]int{"": -1}, // dead (empty) NFA state encoding maps to DFA offset -1
+		sparse: make([]int32, len(prog)),
 	}
 	b.add(nfaStart(prog))
-	return b.dfa
+
+	// Drop any unused capacity left over from appending.
+	return append(reDFA(nil), b.dfa...)
 }
 
 // add returns the offset of the NFA state s in the DFA b.dfa,
@@ -708,11 +736,52 @@ func (b *dfaBuilder) add(s nfaState) int32 {
 	pos = len(b.dfa)
 	b.have[string(b.enc)] = pos
 
+	words := s.words(b.prog)
+	match := s.match(b.prog)
+
+	// Group the instructions in s by the word they advance on,
+	// so that computing each next state does not rescan all of s.
+	var anyPCs []int32
+	wordPCs := make(map[WordID][]int32)
+	for _, pc := range s {
+		inst := &b.prog[pc]
+		switch inst.op {
+		case instAny:
+			anyPCs = append(anyPCs, pc)
+		case instWord:
+			wordPCs[WordID(inst.arg)] = append(wordPCs[WordID(inst.arg)], pc)
+		}
+	}
+	next := func(w WordID) nfaState {
+		var next nfaState
+		for _, pc := range anyPCs {
+			next.addSparse(b.prog, pc+1, b.sparse)
+		}
+		for _, pc := range wordPCs[w] {
+			next.addSparse(b.prog, pc+1, b.sparse)
+		}
+		next.trim(b.prog)
+		return next
+	}
+
+	// If s is a link in a chain and its next state is new,
+	// the next state will be added immediately after s,
+	// so s can use the one-word chain encoding.
+	if match < 0 && len(words) == 1 && words[0] != AnyWord {
+		n := next(words[0])
+		b.enc = n.appendEncoding(b.enc[:0])
+		if _, ok := b.have[string(b.enc)]; !ok {
+			b.dfa = append(b.dfa, ^int32(words[0]))
+			if b.add(n) != int32(pos+1) {
+				panic("dfaBuilder: chain state not followed by its next state")
+			}
+			return int32(pos)
+		}
+	}
+
 	// Reserve room for this DFA state, so that new DFA states
 	// can be appended to it as we fill this one in.
 	// The total size of the state is 1+haveMatch+2*#words.
-	words := s.words(b.prog)
-	match := s.match(b.prog)
 	size := 1 + 2*len(words)
 	if match >= 0 {
 		size++
@@ -731,8 +800,7 @@ func (b *dfaBuilder) add(s nfaState) int32 {
 		off++
 	}
 	for _, w := range words {
-		next := s.next(b.prog, w)
-		nextPos := b.add(next)
+		nextPos := b.add(next(w))
 		b.dfa[off] = int32(w)
 		b.dfa[off+1] = nextPos
 		off += 2
@@ -749,6 +817,11 @@ func (dfa reDFA) string(d *Dict) string {
 		fmt.Fprintf(&b, "%d", i)
 		hdr := dfa[i]
 		i++
+		if hdr < 0 {
+			// Chain state.
+			fmt.Fprintf(&b, " %s:%d\n", d.Words()[^hdr], i)
+			continue
+		}
 		if hdr&1 != 0 {
 			fmt.Fprintf(&b, " m%d", dfa[i])
 			i++
@@ -776,7 +849,9 @@ func (dfa reDFA) string(d *Dict) string {
 // If the state is a matching state, stateAt returns match >= 0 specifies the match ID.
 // If the state is not a matching state, stateAt returns match == -1.
 // Either way, stateAt also returns the outgoing transition list
-// interlaced in the delta slice. The caller can iterate over delta using:
+// interlaced in the delta slice. For a chain state, stateAt decodes
+// the transition into *chain and returns chain[:] as delta.
+// The caller can iterate over delta using:
 //
 //	for i := 0; i < len(delta); i += 2 {
 //		dw, dnext := WordID(delta[i]), delta[i+1]
@@ -785,9 +860,13 @@ func (dfa reDFA) string(d *Dict) string {
 //		}
 //	}
 //
-func (dfa reDFA) stateAt(off int32) (match int32, delta []int32) {
+func (dfa reDFA) stateAt(off int32, chain *[2]int32) (match int32, delta []int32) {
 	hdr := dfa[off]
 	off++
+	if hdr < 0 {
+		chain[0], chain[1] = ^hdr, off
+		return -1, chain[:]
+	}
 	match = -1
 	if hdr&1 != 0 {
 		match = dfa[off]
@@ -797,6 +876,39 @@ func (dfa reDFA) stateAt(off int32) (match int32, delta []int32) {
 	return match, dfa[off : off+2*n]
 }
 
+// minWords returns a lower bound on the number of words
+// in any text that the DFA matches.
+func (dfa reDFA) minWords() int {
+	if len(dfa) == 0 {
+		return 0
+	}
+
+	// Breadth-first search for the shortest path to a matching state.
+	var chain [2]int32
+	seen := make(map[int32]bool)
+	queue := []int32{0}
+	seen[0] = true
+	for steps := 0; len(queue) > 0; steps++ {
+		var next []int32
+		for _, off := range queue {
+			m, delta := dfa.stateAt(off, &chain)
+			if m >= 0 {
+				// Spelling correction in match can split one word
+				// into two, moving the DFA forward two steps per word.
+				return (steps + 1) / 2
+			}
+			for i := 0; i < len(delta); i += 2 {
+				if d := delta[i+1]; d >= 0 && !seen[d] {
+					seen[d] = true
+					next = append(next, d)
+				}
+			}
+		}
+		queue = next
+	}
+	return 0
+}
+
 // TraceDFA controls whether DFA execution prints debug tracing when stuck.
 // If TraceDFA > 0 and the DFA has followed a path of at least TraceDFA symbols
 // since the last matching state but hits a dead end, it prints out information
@@ -808,10 +920,21 @@ var TraceDFA int
 // match returns the match ID of the longest match, as well as
 // the index in words immediately following the last matched word.
 // If there is no match, match returns -1, 0.
-func (dfa reDFA) match(dict *Dict, text string, words []Word) (match int32, end int) {
+// If ocr is true, the spell check also allows the character confusions
+// typical of optical character recognition (see canMisspellOCR).
+func (dfa reDFA) match(dict *Dict, text string, words []Word, ocr bool) (match int32, end int) {
+	return dfa.matchAll(dict, text, words, ocr, nil)
+}
+
+// matchAll is like match, but if cands is not nil,
+// it also appends to *cands every match it passes on the way
+// to the longest, in order of increasing End.
+func (dfa reDFA) matchAll(dict *Dict, text string, words []Word, ocr bool, cands *[]Candidate) (match int32, end int) {
 	match, end = -1, 0
 	off := int32(0) // offset of current state in DFA
+	fixed := 0      // number of words matched by correcting their spelling
 	dictWords := dict.Words()
+	var chain, chain2 [2]int32 // decoded chain states
 
 	// No range loop here: misspellings can adjust i.
 Words:
@@ -820,10 +943,13 @@ Words:
 		w := word.ID
 
 		// Find next state in DFA for w.
-		m, delta := dfa.stateAt(off)
+		m, delta := dfa.stateAt(off, &chain)
 		if m >= 0 {
 			match = m
 			end = i
+			if cands != nil {
+				*cands = append(*cands, Candidate{ID: int(m), End: i, Fixed: fixed})
+			}
 		}
 
 		// Handle and remove AnyWord if present.
@@ -860,8 +986,9 @@ Words:
 
 			// Can we spell want by joining have and have2?
 			// This can happen with hyphenated line breaks.
-			if canMisspellJoin(want, have, have2) {
+			if canMisspellJoin(want, have, have2) || ocr && have2 != "" && canMisspellOCR(want, have+have2) {
 				off = dnext
+				fixed += 2
 				i++ // for have; loop will i++ again for have2
 				continue Words
 			}
@@ -873,7 +1000,7 @@ Words:
 				// have[:len(want)] matches want.
 				// Look to see if have[len(want):] can be the word after want.
 				rest := have[len(want):]
-				m2, delta2 := dfa.stateAt(dnext)
+				m2, delta2 := dfa.stateAt(dnext, &chain2)
 				next2 := int32(-1)
 				for j2 := 0; j2 < len(delta2); j2 += 2 {
 					dw2, dnext2 := WordID(delta2[j2]), delta2[j2+1]
@@ -884,9 +1011,13 @@ Words:
 				if next2 >= 0 {
 					// Successfully split have into two words
 					// to drive the DFA forward two steps.
+					fixed++
 					if m2 >= 0 {
 						match = m2
 						end = i
+						if cands != nil {
+							*cands = append(*cands, Candidate{ID: int(m2), End: i, Fixed: fixed})
+						}
 					}
 					off = next2
 					continue Words
@@ -894,8 +1025,9 @@ Words:
 			}
 
 			// Can we misspell want as have?
-			if canMisspell(want, have) {
+			if canMisspell(want, have) || ocr && canMisspellOCR(want, have) {
 				off = dnext
+				fixed++
 				continue Words
 			}
 		}
@@ -927,9 +1059,12 @@ Words:
 		off = nextAny
 	}
 
-	if m, _ := dfa.stateAt(off); m >= 0 {
+	if m, _ := dfa.stateAt(off, &chain); m >= 0 {
 		match = m
 		end = len(words)
+		if cands != nil {
+			*cands = append(*cands, Candidate{ID: int(m), End: end, Fixed: fixed})
+		}
 	}
 	if i := len(words); TraceDFA > 0 && i-end >= TraceDFA {
 		start := i - 10
@@ -994,6 +1129,31 @@ func canMisspell(want, have string) bool {
 	return false
 }
 
+// ocrFolder folds the characters and character sequences
+// that optical character recognition commonly confuses.
+var ocrFolder = strings.NewReplacer(
+	"rn", "m",
+	"cl", "d",
+	"vv", "w",
+	"1", "l",
+	"i", "l",
+	"0", "o",
+	"5", "s",
+	"e", "c",
+)
+
+// canMisspellOCR reports whether want can be misspelled as have
+// by optical character recognition of a scanned document,
+// which confuses rn with m, 1 and i with l, e with c, and so on.
+// After folding those confusions, the words must be the same
+// or differ by a single letter, as for canMisspell.
+// Unlike canMisspell, canMisspellOCR allows confusions in short words
+// such as "tbe" for "the", provided the folded words are the same.
+func canMisspellOCR(want, have string) bool {
+	want, have = ocrFolder.Replace(want), ocrFolder.Replace(have)
+	return want == have || canMisspell(want, have)
+}
+
 // canMisspellJoin reports whether want can be misspelled as the word pair have1, have2.
 // All three words have been converted to lowercase already
 // (want by the Dict, have1, have2 by the caller).
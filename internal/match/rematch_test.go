@@ -387,182 +387,182 @@ func testProg(t *testing.T, dict *Dict, expr string) reProg {
 
 var compileDFATests = `
 a b c
-0 a:3
-3 b:6
-6 c:9
-9 m0
+0 a:1
+1 b:2
+2 c:3
+3 m0
 
 a ((b || c)) d
-0 a:3
-3 b:8 c:8
-8 d:11
-11 m0
+0 a:1
+1 b:6 c:6
+6 d:7
+7 m0
 
 a b c / a ((c | d)) e
-0 a:3
-3 b:8 c:13
-8 c:11
-11 m0
-13 d:16
-16 e:19
-19 m1
+0 a:1
+1 b:6 c:9
+6 c:7
+7 m0
+9 d:10
+10 e:11
+11 m1
 
 ((c __2__))?? d e f
-0 c:5 d:18
-5 *:10 d:31
-10 *:15 d:26
-15 d:18
-18 e:21
-21 f:24
-24 m0
-26 d:18 e:21
-31 *:15 d:26 e:38
-38 d:18 f:24
+0 c:5 d:16
+5 *:10 d:25
+10 *:15 d:20
+15 d:16
+16 e:17
+17 f:18
+18 m0
+20 d:16 e:17
+25 *:15 d:20 e:32
+32 d:16 f:18
 
 a b c / a ((c || d)) e
-0 a:3
-3 b:10 c:15 d:15
-10 c:13
-13 m0
-15 e:18
-18 m1
+0 a:1
+1 b:8 c:11 d:11
+8 c:9
+9 m0
+11 e:12
+12 m1
 
 a __1__ b c d e
-0 a:3
-3 *:8 b:22
-8 b:11
-11 c:14
-14 d:17
-17 e:20
-20 m0
-22 b:11 c:14
+0 a:1
+1 *:6 b:12
+6 b:7
+7 c:8
+8 d:9
+9 e:10
+10 m0
+12 b:7 c:8
 
 a __2__ b c d e
-0 a:3
-3 *:8 b:32
-8 *:13 b:27
-13 b:16
-16 c:19
-19 d:22
-22 e:25
-25 m0
-27 b:16 c:19
-32 *:13 b:27 c:39
-39 b:16 d:22
+0 a:1
+1 *:6 b:22
+6 *:11 b:17
+11 b:12
+12 c:13
+13 d:14
+14 e:15
+15 m0
+17 b:12 c:13
+22 *:11 b:17 c:29
+29 b:12 d:14
 
 a __3__ b c d e
-0 a:3
-3 *:8 b:49
-8 *:13 b:37
-13 *:18 b:32
-18 b:21
-21 c:24
-24 d:27
-27 e:30
-30 m0
-32 b:21 c:24
-37 *:18 b:32 c:44
-44 b:21 d:27
-49 *:13 b:37 c:56
-56 *:18 b:32 d:63
-63 b:21 e:30
+0 a:1
+1 *:6 b:39
+6 *:11 b:27
+11 *:16 b:22
+16 b:17
+17 c:18
+18 d:19
+19 e:20
+20 m0
+22 b:17 c:18
+27 *:16 b:22 c:34
+34 b:17 d:19
+39 *:11 b:27 c:46
+46 *:16 b:22 d:53
+53 b:17 e:20
 
 a __4__ b c d e
-0 a:3
-3 *:8 b:68
-8 *:13 b:54
-13 *:18 b:42
-18 *:23 b:37
-23 b:26
-26 c:29
-29 d:32
-32 e:35
-35 m0
-37 b:26 c:29
-42 *:23 b:37 c:49
-49 b:26 d:32
-54 *:18 b:42 c:61
-61 *:23 b:37 d:32
-68 *:13 b:54 c:75
-75 *:18 b:42 d:32
+0 a:1
+1 *:6 b:58
+6 *:11 b:44
+11 *:16 b:32
+16 *:21 b:27
+21 b:22
+22 c:23
+23 d:24
+24 e:25
+25 m0
+27 b:22 c:23
+32 *:21 b:27 c:39
+39 b:22 d:24
+44 *:16 b:32 c:51
+51 *:21 b:27 d:24
+58 *:11 b:44 c:65
+65 *:16 b:32 d:24
 
 a __5__ b c d e f g
-0 a:3
-3 *:8 b:93
-8 *:13 b:79
-13 *:18 b:65
-18 *:23 b:53
-23 *:28 b:48
-28 b:31
-31 c:34
-34 d:37
-37 e:40
-40 f:43
-43 g:46
-46 m0
-48 b:31 c:34
-53 *:28 b:48 c:60
-60 b:31 d:37
-65 *:23 b:53 c:72
-72 *:28 b:48 d:37
-79 *:18 b:65 c:86
-86 *:23 b:53 d:37
-93 *:13 b:79 c:100
-100 *:18 b:65 d:37
+0 a:1
+1 *:6 b:79
+6 *:11 b:65
+11 *:16 b:51
+16 *:21 b:39
+21 *:26 b:34
+26 b:27
+27 c:28
+28 d:29
+29 e:30
+30 f:31
+31 g:32
+32 m0
+34 b:27 c:28
+39 *:26 b:34 c:46
+46 b:27 d:29
+51 *:21 b:39 c:58
+58 *:26 b:34 d:29
+65 *:16 b:51 c:72
+72 *:21 b:39 d:29
+79 *:11 b:65 c:86
+86 *:16 b:51 d:29
 
 a __5__ b __5__ c
-0 a:3
-3 *:8 b:31
-8 *:13 b:31
-13 *:18 b:31
-18 *:23 b:31
-23 *:28 b:31
-28 b:31
-31 *:36 c:59
-36 *:41 c:59
-41 *:46 c:59
-46 *:51 c:59
-51 *:56 c:59
-56 c:59
-59 m0
+0 a:1
+1 *:6 b:27
+6 *:11 b:27
+11 *:16 b:27
+16 *:21 b:27
+21 *:26 b:27
+26 b:27
+27 *:32 c:53
+32 *:37 c:53
+37 *:42 c:53
+42 *:47 c:53
+47 *:52 c:53
+52 c:53
+53 m0
 
 The name __10__ may not be used
-0 the:3
-3 name:6
-6 *:11 may:185
-11 *:16 may:171
-16 *:21 may:157
-21 *:26 may:143
-26 *:31 may:129
-31 *:36 may:115
-36 *:41 may:101
-41 *:46 may:87
-46 *:51 may:75
-51 *:56 may:70
-56 may:59
-59 not:62
-62 be:65
-65 used:68
-68 m0
-70 may:59 not:62
-75 *:56 may:70 not:82
-82 may:59 be:65
-87 *:51 may:75 not:94
-94 *:56 may:70 be:65
-101 *:46 may:87 not:108
-108 *:51 may:75 be:65
-115 *:41 may:101 not:122
-122 *:46 may:87 be:65
-129 *:36 may:115 not:136
-136 *:41 may:101 be:65
-143 *:31 may:129 not:150
-150 *:36 may:115 be:65
-157 *:26 may:143 not:164
-164 *:31 may:129 be:65
-171 *:21 may:157 not:178
-178 *:26 may:143 be:65
-185 *:16 may:171 not:192
-192 *:21 may:157 be:65
+0 the:1
+1 name:2
+2 *:7 may:173
+7 *:12 may:159
+12 *:17 may:145
+17 *:22 may:131
+22 *:27 may:117
+27 *:32 may:103
+32 *:37 may:89
+37 *:42 may:75
+42 *:47 may:63
+47 *:52 may:58
+52 may:53
+53 not:54
+54 be:55
+55 used:56
+56 m0
+58 may:53 not:54
+63 *:52 may:58 not:70
+70 may:53 be:55
+75 *:47 may:63 not:82
+82 *:52 may:58 be:55
+89 *:42 may:75 not:96
+96 *:47 may:63 be:55
+103 *:37 may:89 not:110
+110 *:42 may:75 be:55
+117 *:32 may:103 not:124
+124 *:37 may:89 be:55
+131 *:27 may:117 not:138
+138 *:32 may:103 be:55
+145 *:22 may:131 not:152
+152 *:27 may:117 be:55
+159 *:17 may:145 not:166
+166 *:22 may:131 be:55
+173 *:12 may:159 not:180
+180 *:17 may:145 be:55
 `
 
 func TestCompileDFA(t *testing.T) {
@@ -656,9 +656,71 @@ func TestReDFAMatch(t *testing.T) {
 			continue
 		}
 		dfa := reCompileDFA(prog)
-		match, end := dfa.match(&d, tt.in, d.Split(tt.in))
+		match, end := dfa.match(&d, tt.in, d.Split(tt.in), false)
 		if match != tt.match || end != tt.end {
 			t.Errorf("reDFA(%q).match(%v) = %v, %v, want %v, %v", tt.re, tt.in, match, end, tt.match, tt.end)
 		}
 	}
 }
+
+var matchOCRTests = []struct {
+	re    string
+	in    string
+	match int32
+	end   int
+}{
+	{`a b the form`, `a b tbe forrn`, -1, 0}, // b is not a confusion
+	{`a b the form`, `a b thc forrn`, 0, 4},
+	{`a b all permission`, `a b a1l perrnissi0n`, 0, 4},
+	{`a b clause window`, `a b dause wmdow`, -1, 0},
+	{`a b clause window`, `a b clause vvindovv`, 0, 4},
+	{`a b permission`, `a b per rnission`, 0, 4},
+	{`a b permission`, `a b perm1 ssion`, 0, 4},
+	{`a b permission`, `a b pertnission`, -1, 0},
+	{`a b permission`, `a b license`, -1, 0},
+}
+
+func TestReDFAMatchOCR(t *testing.T) {
+	var d Dict
+	for _, tt := range matchOCRTests {
+		prog := testProg(t, &d, tt.re)
+		if prog == nil {
+			continue
+		}
+		dfa := reCompileDFA(prog)
+		match, end := dfa.match(&d, tt.in, d.Split(tt.in), true)
+		if match != tt.match || end != tt.end {
+			t.Errorf("reDFA(%q).match(%v, ocr) = %v, %v, want %v, %v", tt.re, tt.in, match, end, tt.match, tt.end)
+		}
+		if match >= 0 {
+			// Without OCR, the damaged text must not match.
+			if match, _ := dfa.match(&d, tt.in, d.Split(tt.in), false); match >= 0 {
+				t.Errorf("reDFA(%q).match(%v) matched without OCR", tt.re, tt.in)
+			}
+		}
+	}
+}
+
+var minWordsTests = []struct {
+	re  string
+	min int
+}{
+	{`a b c d`, 2},
+	{`a b c d e`, 3},
+	{`a b ((c d e))?? f`, 2},
+	{`a __5__ b`, 1},
+	{`a b c d e f / a b`, 1},
+}
+
+func TestMinWords(t *testing.T) {
+	var d Dict
+	for _, tt := range minWordsTests {
+		prog := testProg(t, &d, tt.re)
+		if prog == nil {
+			continue
+		}
+		if min := reCompileDFA(prog).minWords(); min != tt.min {
+			t.Errorf("RE(%q).minWords() = %d, want %d", tt.re, min, tt.min)
+		}
+	}
+}
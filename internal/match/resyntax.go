@@ -218,7 +218,7 @@ func reParse(d *Dict, s string, strict bool) (*reSyntax, error) {
 			// )) must be followed by ?? or end line
 			if strict {
 				j := i + 2
-				for j < len(s) && (s[j] == ' ' || s[j] == '\t') {
+				for j < len(s) && (s[j] == ' ' || s[j] == '\t' || s[j] == '\r') {
 					j++
 				}
 				if j < len(s) && s[j] != '\n' && (j+1 >= len(s) || s[j] != '?' || s[j+1] != '?') {
@@ -24,6 +24,7 @@ var reParseTests = []struct {
 	{in: "(a b ((c) ))??", out: "a b\n((c))??"},
 	{in: "(( a b c )) ??", out: "((a b c))??"},
 	{in: "z \n(( w ))\n(( a b c )) ??\n", out: "z w\n((a b c))??"},
+	{in: "z \r\n(( w ))\r\n(( a b c )) ??\r\n", out: "z w\n((a b c))??"},
 	{in: "(( a __123__ c )) ??", out: "((a __123__ c))??"},
 	{in: "a b ((c ||| d e)) f", out: "a b\n((c || d e))\nf"},
 }
@@ -0,0 +1,25 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package spdxid compares SPDX license IDs
+// for the packages that check declared licenses against found ones.
+package spdxid
+
+import "strings"
+
+// Base returns id without any +, -only, or -or-later suffix,
+// so that GPL-2.0+, GPL-2.0-only, and GPL-2.0-or-later all become GPL-2.0.
+// The suffixes say whether later versions of a license may be used,
+// which the text of the license does not.
+func Base(id string) string {
+	id = strings.TrimSuffix(id, "+")
+	id = strings.TrimSuffix(id, "-only")
+	return strings.TrimSuffix(id, "-or-later")
+}
+
+// Same reports whether the IDs x and y name the same license text,
+// ignoring case, as SPDX does, and the suffixes removed by Base.
+func Same(x, y string) bool {
+	return strings.EqualFold(Base(x), Base(y))
+}
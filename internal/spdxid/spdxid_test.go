@@ -0,0 +1,26 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spdxid
+
+import "testing"
+
+func TestSame(t *testing.T) {
+	for _, tt := range []struct {
+		x, y string
+		same bool
+	}{
+		{"MIT", "MIT", true},
+		{"mit", "MIT", true},
+		{"GPL-2.0", "GPL-2.0+", true},
+		{"GPL-2.0-only", "GPL-2.0-or-later", true},
+		{"gpl-3.0-or-later", "GPL-3.0", true},
+		{"GPL-2.0", "GPL-3.0", false},
+		{"LGPL-2.1", "GPL-2.1", false},
+	} {
+		if same := Same(tt.x, tt.y); same != tt.same {
+			t.Errorf("Same(%q, %q) = %v, want %v", tt.x, tt.y, same, tt.same)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package strs holds string helpers shared by the licensecheck packages,
+// including ones that are not in the standard library of the oldest supported Go.
+package strs
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Cut slices s around the first instance of sep,
+// returning the text before and after sep.
+// If sep does not appear in s, Cut returns s, "", false.
+// It is strings.Cut, which is new in Go 1.18.
+func Cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// Truncate returns the longest prefix of text
+// that is at most max bytes and does not split a UTF-8 sequence.
+func Truncate(text []byte, max int) []byte {
+	if len(text) <= max {
+		return text
+	}
+	n := max
+	for i := 0; i < utf8.UTFMax && n > 0 && !utf8.RuneStart(text[n]); i++ {
+		n--
+	}
+	if !utf8.RuneStart(text[n]) {
+		// Not valid UTF-8 anyway.
+		n = max
+	}
+	return text[:n]
+}
+
+// Excerpt returns text for display, shortened by Truncate
+// to at most max bytes and then marked with "..." if it was shortened.
+func Excerpt(text []byte, max int) string {
+	if len(text) <= max {
+		return string(text)
+	}
+	return string(Truncate(text, max)) + "..."
+}
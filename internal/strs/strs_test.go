@@ -0,0 +1,42 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package strs
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		max int
+		out string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"h€llo", 2, "h"},
+		{"h€llo", 3, "h"},
+		{"h€llo", 4, "h€"},
+		{"\xbf\xbf\xbf\xbf\xbf", 4, "\xbf\xbf\xbf\xbf"},
+	} {
+		if out := string(Truncate([]byte(tt.in), tt.max)); out != tt.out {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.in, tt.max, out, tt.out)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		max int
+		out string
+	}{
+		{"hello", 5, "hello"},
+		{"hello", 3, "hel..."},
+		{"h€llo", 3, "h..."},
+	} {
+		if out := Excerpt([]byte(tt.in), tt.max); out != tt.out {
+			t.Errorf("Excerpt(%q, %d) = %q, want %q", tt.in, tt.max, out, tt.out)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lcgrpc implements a minimal gRPC-compatible endpoint
+// for the LicenseCheck service defined in licensecheck.proto,
+// so that programs in any language can scan for licenses
+// with licensecheck without reimplementing it.
+//
+// The service has three methods:
+// ScanText scans a single text,
+// ScanArchive scans each file in a zip or tar archive,
+// and Verify checks that a text holds only the licenses
+// named by an SPDX expression.
+//
+// A Server is an http.Handler speaking the gRPC protocol over HTTP/2,
+// with the protobuf messages encoded by hand,
+// so it needs no gRPC library, only an HTTP/2 server.
+// The net/http server uses HTTP/2 for TLS connections:
+//
+//	srv := &http.Server{Addr: ":8443", Handler: new(lcgrpc.Server)}
+//	log.Fatal(srv.ListenAndServeTLS(certFile, keyFile))
+//
+// It is not a full gRPC server. It supports only unary calls
+// with uncompressed messages, which is all the service needs,
+// and it does not offer server reflection,
+// so clients must generate their stubs from licensecheck.proto.
+// The tests check it against requests encoded as generated clients encode them.
+package lcgrpc
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/manifest"
+)
+
+// A Server serves the LicenseCheck service.
+// The zero Server scans with the built-in licenses and default limits.
+type Server struct {
+	// Scanner is the scanner to use.
+	// If nil, the server uses the built-in license set.
+	Scanner *licensecheck.Scanner
+
+	// Options are the options for scanning each text.
+	// Options.MaxInputBytes limits the size of each text or archived file.
+	Options licensecheck.Options
+
+	// SourceNames says to set Options.Source to the name of each text,
+	// so that only the comments in recognized source files are scanned.
+	SourceNames bool
+
+	// MaxMessageBytes limits the size of a request message.
+	// If zero, the limit is DefaultMaxMessageBytes.
+	MaxMessageBytes int
+
+	// MaxArchiveBytes limits the total uncompressed size
+	// of the files in an archive.
+	// If zero, the limit is DefaultMaxArchiveBytes.
+	MaxArchiveBytes int64
+
+	// MaxFiles limits the number of files in an archive.
+	// If zero, the limit is DefaultMaxFiles.
+	MaxFiles int
+}
+
+// Default limits for a Server.
+const (
+	DefaultMaxMessageBytes = 32 << 20
+	DefaultMaxArchiveBytes = 256 << 20
+	DefaultMaxFiles        = 1000
+)
+
+// gRPC status codes.
+const (
+	codeOK                = 0
+	codeInvalidArgument   = 3
+	codeResourceExhausted = 8
+	codeUnimplemented     = 12
+	codeInternal          = 13
+)
+
+// A statusError is an error reported to the client as a gRPC status.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string { return e.msg }
+
+func errorf(code int, format string, args ...interface{}) error {
+	return &statusError{code, fmt.Sprintf(format, args...)}
+}
+
+// methods maps the gRPC method paths to their implementations,
+// which decode a request message and return the encoded response.
+var methods = map[string]func(s *Server, req []byte) ([]byte, error){
+	"/licensecheck.LicenseCheck/ScanText":    (*Server).scanText,
+	"/licensecheck.LicenseCheck/ScanArchive": (*Server).scanArchive,
+	"/licensecheck.LicenseCheck/Verify":      (*Server).verify,
+}
+
+// ServeHTTP implements http.Handler.
+// It replies with HTTP status 405 to methods other than POST
+// and 415 to requests that are not gRPC requests.
+// Otherwise it replies with HTTP status 200,
+// reporting any failure in the grpc-status trailer.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/grpc" && !strings.HasPrefix(mt, "application/grpc+") {
+		http.Error(w, "not a gRPC request", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "application/grpc")
+	h.Set("Grpc-Accept-Encoding", "identity")
+	h.Set("Trailer", "Grpc-Status, Grpc-Message")
+	w.WriteHeader(http.StatusOK)
+
+	var resp []byte
+	method := methods[r.URL.Path]
+	req, err := s.readMessage(r.Body)
+	if err == nil && method == nil {
+		err = errorf(codeUnimplemented, "unknown method %s", r.URL.Path)
+	}
+	if err == nil {
+		resp, err = method(s, req)
+	}
+	if err == nil {
+		var prefix [5]byte
+		binary.BigEndian.PutUint32(prefix[1:], uint32(len(resp)))
+		w.Write(prefix[:])
+		w.Write(resp)
+	}
+
+	code, msg := codeOK, ""
+	if err != nil {
+		code, msg = codeInternal, err.Error()
+		if se, ok := err.(*statusError); ok {
+			code = se.code
+		}
+	}
+	h.Set("Grpc-Status", strconv.Itoa(code))
+	if msg != "" {
+		h.Set("Grpc-Message", percentEncode(msg))
+	}
+}
+
+// readMessage reads the single length-prefixed message of a unary call.
+func (s *Server) readMessage(r io.Reader) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, errorf(codeInvalidArgument, "reading request: %v", err)
+	}
+	if prefix[0] != 0 {
+		return nil, errorf(codeUnimplemented, "compressed messages not supported")
+	}
+	max := s.MaxMessageBytes
+	if max <= 0 {
+		max = DefaultMaxMessageBytes
+	}
+	n := binary.BigEndian.Uint32(prefix[1:])
+	if uint64(n) > uint64(max) {
+		return nil, errorf(codeResourceExhausted, "request message larger than %d bytes", max)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, errorf(codeInvalidArgument, "reading request: %v", err)
+	}
+	return msg, nil
+}
+
+// percentEncode encodes msg for the grpc-message trailer,
+// which allows only printable ASCII other than %.
+func percentEncode(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func (s *Server) scanText(msg []byte) ([]byte, error) {
+	var req scanTextRequest
+	if err := req.unmarshal(msg); err != nil {
+		return nil, errorf(codeInvalidArgument, "ScanTextRequest: %v", err)
+	}
+	cov, err := s.scan(req.name, req.text)
+	if err != nil {
+		return nil, err
+	}
+	var e encoder
+	e.coverage(1, cov)
+	return e.buf, nil
+}
+
+func (s *Server) scanArchive(msg []byte) ([]byte, error) {
+	var req scanArchiveRequest
+	if err := req.unmarshal(msg); err != nil {
+		return nil, errorf(codeInvalidArgument, "ScanArchiveRequest: %v", err)
+	}
+	var e encoder
+	err := s.walkArchive(req.archive, func(name string, data []byte) {
+		cov, err := s.scan(name, data)
+		e.message(1, func(e *encoder) {
+			e.string(1, name)
+			e.coverage(2, cov)
+			if err != nil {
+				e.string(3, err.Error())
+			}
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+func (s *Server) verify(msg []byte) ([]byte, error) {
+	var req verifyRequest
+	if err := req.unmarshal(msg); err != nil {
+		return nil, errorf(codeInvalidArgument, "VerifyRequest: %v", err)
+	}
+	if strings.TrimSpace(req.expected) == "" {
+		return nil, errorf(codeInvalidArgument, "VerifyRequest: missing expected license")
+	}
+	cov, err := s.scan("", req.text)
+	if err != nil {
+		return nil, err
+	}
+	decl := &manifest.Declaration{License: req.expected}
+	unexpected := decl.Undeclared(cov)
+
+	var e encoder
+	e.bool(1, len(unexpected) == 0 && cov.Percent >= req.minPercent)
+	e.coverage(2, cov)
+	for _, id := range unexpected {
+		e.string(3, id)
+	}
+	return e.buf, nil
+}
+
+// scan scans a single text.
+// Finding no license is not an error.
+func (s *Server) scan(name string, data []byte) (licensecheck.Coverage, error) {
+	opts := s.Options
+	if s.SourceNames {
+		opts.Source = name
+	}
+	var cov licensecheck.Coverage
+	var err error
+	if s.Scanner != nil {
+		cov, err = s.Scanner.ScanWithOptions(data, opts)
+	} else {
+		cov, err = licensecheck.ScanWithOptions(data, opts)
+	}
+	switch {
+	case err == nil, errors.Is(err, licensecheck.ErrNoMatch), errors.Is(err, licensecheck.ErrEmptyInput):
+		return cov, nil
+	case errors.Is(err, licensecheck.ErrInputTooLarge):
+		return cov, errorf(codeResourceExhausted, "%v", err)
+	case errors.Is(err, licensecheck.ErrInvalidUTF8):
+		return cov, errorf(codeInvalidArgument, "%v", err)
+	}
+	return cov, err
+}
+
+// walkArchive calls f for each regular file in the zip, tar, or gzipped tar archive.
+func (s *Server) walkArchive(archive []byte, f func(name string, data []byte)) error {
+	maxFiles := s.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = DefaultMaxFiles
+	}
+	left := s.maxArchiveBytes()
+	files := 0
+	// file reads one file from r and calls f,
+	// enforcing the limits on the number and size of files.
+	file := func(name string, r io.Reader) error {
+		if files++; files > maxFiles {
+			return errorf(codeResourceExhausted, "archive has more than %d files", maxFiles)
+		}
+		data, err := ioutil.ReadAll(io.LimitReader(r, left+1))
+		if err != nil {
+			return errorf(codeInvalidArgument, "reading archive: %s: %v", name, err)
+		}
+		if left -= int64(len(data)); left < 0 {
+			return errorf(codeResourceExhausted, "archive larger than %d bytes uncompressed", s.maxArchiveBytes())
+		}
+		f(name, data)
+		return nil
+	}
+
+	switch {
+	case bytes.HasPrefix(archive, []byte("PK\x03\x04")), bytes.HasPrefix(archive, []byte("PK\x05\x06")):
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return errorf(codeInvalidArgument, "reading archive: %v", err)
+		}
+		for _, zf := range zr.File {
+			if !zf.Mode().IsRegular() {
+				continue
+			}
+			rc, err := zf.Open()
+			if err != nil {
+				return errorf(codeInvalidArgument, "reading archive: %s: %v", zf.Name, err)
+			}
+			err = file(zf.Name, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case bytes.HasPrefix(archive, []byte("\x1f\x8b")):
+		zr, err := gzip.NewReader(bytes.NewReader(archive))
+		if err != nil {
+			return errorf(codeInvalidArgument, "reading archive: %v", err)
+		}
+		return walkTar(zr, file)
+	}
+	return walkTar(bytes.NewReader(archive), file)
+}
+
+// walkTar calls file for each regular file in the tar archive r.
+func walkTar(r io.Reader, file func(name string, r io.Reader) error) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errorf(codeInvalidArgument, "reading archive: %v", err)
+		}
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		if err := file(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// maxArchiveBytes returns the limit on the uncompressed size of an archive.
+func (s *Server) maxArchiveBytes() int64 {
+	if s.MaxArchiveBytes <= 0 {
+		return DefaultMaxArchiveBytes
+	}
+	return s.MaxArchiveBytes
+}
@@ -0,0 +1,287 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lcgrpc
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/google/licensecheck"
+)
+
+// call makes a unary gRPC call to srv over HTTP/2
+// and returns the response message and the grpc-status trailer.
+func call(t *testing.T, srv *httptest.Server, method string, req []byte) (resp []byte, status string) {
+	t.Helper()
+	body := make([]byte, 5, 5+len(req))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(req)))
+	body = append(body, req...)
+	hresp, err := srv.Client().Post(srv.URL+"/licensecheck.LicenseCheck/"+method, "application/grpc", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hresp.Body.Close()
+	if hresp.ProtoMajor != 2 || hresp.StatusCode != http.StatusOK {
+		t.Fatalf("%s: %s %s", method, hresp.Proto, hresp.Status)
+	}
+	data, err := ioutil.ReadAll(hresp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status = hresp.Trailer.Get("Grpc-Status")
+	if len(data) > 0 {
+		if len(data) < 5 || int(binary.BigEndian.Uint32(data[1:])) != len(data)-5 {
+			t.Fatalf("%s: malformed response %q", method, data)
+		}
+		resp = data[5:]
+	}
+	return resp, status
+}
+
+func newServer(s *Server) *httptest.Server {
+	srv := httptest.NewUnstartedServer(s)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	return srv
+}
+
+// fields decodes msg into a map from field number to the field values.
+func fields(t *testing.T, msg []byte) map[int][]field {
+	t.Helper()
+	m := make(map[int][]field)
+	err := decode(msg, func(fd field) error {
+		m[fd.num] = append(m[fd.num], fd)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// matchIDs returns the license IDs in the encoded Coverage msg.
+func matchIDs(t *testing.T, msg []byte) []string {
+	t.Helper()
+	var ids []string
+	for _, m := range fields(t, msg)[2] {
+		id, _ := fields(t, m.data)[1][0].string()
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestServer(t *testing.T) {
+	mit, ok := licensecheck.Text("MIT")
+	if !ok {
+		t.Fatal("no text for MIT")
+	}
+	srv := newServer(new(Server))
+	defer srv.Close()
+
+	var e encoder
+	e.bytes(1, []byte(mit))
+	e.string(2, "LICENSE")
+	resp, status := call(t, srv, "ScanText", e.buf)
+	if status != "0" {
+		t.Fatalf("ScanText: grpc-status %s", status)
+	}
+	cov := fields(t, resp)[1][0].data
+	if pct, _ := fields(t, cov)[1][0].double(); pct < 99 {
+		t.Errorf("ScanText: percent %.1f, want 100", pct)
+	}
+	if ids := matchIDs(t, cov); !reflect.DeepEqual(ids, []string{"MIT"}) {
+		t.Errorf("ScanText: matches %v, want [MIT]", ids)
+	}
+
+	for _, tt := range []struct {
+		expected string
+		ok       bool
+	}{
+		{"MIT", true},
+		{"Apache-2.0 OR MIT", true},
+		{"Apache-2.0", false},
+	} {
+		e = encoder{}
+		e.bytes(1, []byte(mit))
+		e.string(2, tt.expected)
+		e.double(3, 90)
+		resp, status = call(t, srv, "Verify", e.buf)
+		if status != "0" {
+			t.Fatalf("Verify %s: grpc-status %s", tt.expected, status)
+		}
+		f := fields(t, resp)
+		if ok := len(f[1]) > 0 && f[1][0].val == 1; ok != tt.ok {
+			t.Errorf("Verify %s: ok=%v, want %v", tt.expected, ok, tt.ok)
+		}
+		if !tt.ok {
+			if id, _ := f[3][0].string(); len(f[3]) != 1 || id != "MIT" {
+				t.Errorf("Verify %s: unexpected %q, want [MIT]", tt.expected, id)
+			}
+		}
+	}
+
+	resp, status = call(t, srv, "Verify", nil)
+	if status != "3" || resp != nil {
+		t.Errorf("Verify without expected: grpc-status %s, want 3", status)
+	}
+	if _, status = call(t, srv, "Frob", nil); status != "12" {
+		t.Errorf("Frob: grpc-status %s, want 12", status)
+	}
+	e = encoder{}
+	e.bytes(1, []byte(mit))
+	small := newServer(&Server{MaxMessageBytes: 100})
+	defer small.Close()
+	if _, status = call(t, small, "ScanText", e.buf); status != "8" {
+		t.Errorf("ScanText too large: grpc-status %s, want 8", status)
+	}
+
+	hresp, err := srv.Client().Get(srv.URL + "/licensecheck.LicenseCheck/ScanText")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hresp.Body.Close()
+	if hresp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET: %s, want 405", hresp.Status)
+	}
+}
+
+func TestScanArchive(t *testing.T) {
+	mit, _ := licensecheck.Text("MIT")
+	files := []struct{ name, text string }{
+		{"LICENSE", mit},
+		{"src/main.go", "package main\n"},
+	}
+
+	var zipData bytes.Buffer
+	zw := zip.NewWriter(&zipData)
+	zw.Create("src/")
+	for _, f := range files {
+		w, _ := zw.Create(f.name)
+		w.Write([]byte(f.text))
+	}
+	zw.Close()
+
+	var tgzData bytes.Buffer
+	gw := gzip.NewWriter(&tgzData)
+	tw := tar.NewWriter(gw)
+	tw.WriteHeader(&tar.Header{Name: "src/", Typeflag: tar.TypeDir, Mode: 0755})
+	for _, f := range files {
+		tw.WriteHeader(&tar.Header{Name: f.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(f.text))})
+		tw.Write([]byte(f.text))
+	}
+	tw.Close()
+	gw.Close()
+
+	srv := newServer(new(Server))
+	defer srv.Close()
+	for _, archive := range []struct {
+		name string
+		data []byte
+	}{
+		{"zip", zipData.Bytes()},
+		{"tgz", tgzData.Bytes()},
+	} {
+		var e encoder
+		e.bytes(1, archive.data)
+		resp, status := call(t, srv, "ScanArchive", e.buf)
+		if status != "0" {
+			t.Fatalf("%s: grpc-status %s", archive.name, status)
+		}
+		var names []string
+		var ids [][]string
+		for _, fd := range fields(t, resp)[1] {
+			f := fields(t, fd.data)
+			name, _ := f[1][0].string()
+			names = append(names, name)
+			ids = append(ids, matchIDs(t, f[2][0].data))
+		}
+		if want := []string{"LICENSE", "src/main.go"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("%s: files %q, want %q", archive.name, names, want)
+		}
+		if want := [][]string{{"MIT"}, nil}; !reflect.DeepEqual(ids, want) {
+			t.Errorf("%s: matches %q, want %q", archive.name, ids, want)
+		}
+
+		small := newServer(&Server{MaxFiles: 1})
+		if _, status := call(t, small, "ScanArchive", e.buf); status != "8" {
+			t.Errorf("%s with MaxFiles=1: grpc-status %s, want 8", archive.name, status)
+		}
+		small.Close()
+	}
+}
+
+func TestInterop(t *testing.T) {
+	// Send the requests a client generated from licensecheck.proto would send,
+	// with the headers gRPC libraries set and the message encoded
+	// as the protobuf runtime encodes it, not by this package's encoder.
+	mit, ok := licensecheck.Text("MIT")
+	if !ok {
+		t.Fatal("no text for MIT")
+	}
+	srv := newServer(new(Server))
+	defer srv.Close()
+
+	// ScanTextRequest{text: mit, name: "LICENSE"}
+	var n [binary.MaxVarintLen64]byte
+	msg := append([]byte{0x0a}, n[:binary.PutUvarint(n[:], uint64(len(mit)))]...)
+	msg = append(msg, mit...)
+	msg = append(msg, 0x12, 7)
+	msg = append(msg, "LICENSE"...)
+
+	post := func(compressed byte, msg []byte) *http.Response {
+		body := append([]byte{compressed, 0, 0, 0, 0}, msg...)
+		binary.BigEndian.PutUint32(body[1:], uint32(len(msg)))
+		req, err := http.NewRequest("POST", srv.URL+"/licensecheck.LicenseCheck/ScanText", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/grpc+proto")
+		req.Header.Set("Te", "trailers")
+		req.Header.Set("Grpc-Timeout", "10S")
+		req.Header.Set("Grpc-Accept-Encoding", "identity,gzip")
+		req.Header.Set("User-Agent", "grpc-go/1.0")
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := post(0, msg)
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); resp.ProtoMajor != 2 || resp.StatusCode != http.StatusOK || ct != "application/grpc" {
+		t.Fatalf("ScanText: %s %s, Content-Type %q, want HTTP/2 200, application/grpc", resp.Proto, resp.Status, ct)
+	}
+	if status := resp.Trailer.Get("Grpc-Status"); status != "0" {
+		t.Fatalf("ScanText: grpc-status %q, want 0", status)
+	}
+	if len(data) < 5 || data[0] != 0 || int(binary.BigEndian.Uint32(data[1:])) != len(data)-5 {
+		t.Fatalf("ScanText: malformed response %q", data)
+	}
+	// ScanTextResponse{coverage: {..., match: [{id: "MIT", ...}]}}
+	if out := data[5:]; len(out) == 0 || out[0] != 0x0a || !bytes.Contains(out, []byte("\x0a\x03MIT")) {
+		t.Errorf("ScanText: response %q, want coverage with MIT match", out)
+	}
+
+	// gRPC libraries report an unsupported compressed message as UNIMPLEMENTED.
+	resp = post(1, msg)
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if status := resp.Trailer.Get("Grpc-Status"); status != "12" || resp.Trailer.Get("Grpc-Message") == "" {
+		t.Errorf("ScanText compressed: grpc-status %q, message %q, want 12 and a message", status, resp.Trailer.Get("Grpc-Message"))
+	}
+}
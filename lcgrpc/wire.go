@@ -0,0 +1,235 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lcgrpc
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/google/licensecheck"
+)
+
+// This file encodes and decodes the messages in licensecheck.proto
+// in the protocol buffer wire format.
+// Fields with default values are omitted, as proto3 requires,
+// and unknown fields are ignored when decoding.
+
+// Protocol buffer wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errMalformed = errors.New("malformed message")
+
+// An encoder appends fields to a message.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf, b[:binary.PutUvarint(b[:], v)]...)
+}
+
+func (e *encoder) tag(field, wire int) {
+	e.varint(uint64(field)<<3 | uint64(wire))
+}
+
+func (e *encoder) bytes(field int, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.varint(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) string(field int, s string) {
+	e.bytes(field, []byte(s))
+}
+
+func (e *encoder) int64(field int, v int64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.varint(uint64(v))
+}
+
+func (e *encoder) bool(field int, v bool) {
+	if v {
+		e.tag(field, wireVarint)
+		e.varint(1)
+	}
+}
+
+func (e *encoder) double(field int, v float64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireFixed64)
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+	e.buf = append(e.buf, b[:]...)
+}
+
+// message appends the message encoded by f as field.
+// Unlike the scalar fields, it is written even if empty,
+// so that repeated messages keep their positions.
+func (e *encoder) message(field int, f func(*encoder)) {
+	var m encoder
+	f(&m)
+	e.tag(field, wireBytes)
+	e.varint(uint64(len(m.buf)))
+	e.buf = append(e.buf, m.buf...)
+}
+
+func (e *encoder) coverage(field int, cov licensecheck.Coverage) {
+	e.message(field, func(e *encoder) {
+		e.double(1, cov.Percent)
+		for _, m := range cov.Match {
+			e.message(2, func(e *encoder) {
+				e.string(1, m.ID)
+				e.int64(2, int64(m.Start))
+				e.int64(3, int64(m.End))
+				e.bool(4, m.IsURL)
+				e.bool(5, m.IsTag)
+				e.string(6, m.Name)
+			})
+		}
+		e.bool(3, cov.Truncated)
+		e.bool(4, cov.Empty)
+	})
+}
+
+// A field is a single decoded field of a message.
+// For varint and fixed-size fields, val holds the value;
+// for length-delimited fields, data does.
+type field struct {
+	num  int
+	wire int
+	val  uint64
+	data []byte
+}
+
+// decode calls f for each field in the message msg.
+func decode(msg []byte, f func(field) error) error {
+	for len(msg) > 0 {
+		tag, n := binary.Uvarint(msg)
+		if n <= 0 || tag>>3 == 0 || tag>>3 > math.MaxInt32 {
+			return errMalformed
+		}
+		msg = msg[n:]
+		fd := field{num: int(tag >> 3), wire: int(tag & 7)}
+		switch fd.wire {
+		case wireVarint:
+			fd.val, n = binary.Uvarint(msg)
+			if n <= 0 {
+				return errMalformed
+			}
+			msg = msg[n:]
+		case wireFixed64:
+			if len(msg) < 8 {
+				return errMalformed
+			}
+			fd.val, msg = binary.LittleEndian.Uint64(msg), msg[8:]
+		case wireFixed32:
+			if len(msg) < 4 {
+				return errMalformed
+			}
+			fd.val, msg = uint64(binary.LittleEndian.Uint32(msg)), msg[4:]
+		case wireBytes:
+			size, n := binary.Uvarint(msg)
+			if n <= 0 || size > uint64(len(msg)-n) {
+				return errMalformed
+			}
+			msg = msg[n:]
+			fd.data, msg = msg[:size], msg[size:]
+		default:
+			return errMalformed
+		}
+		if err := f(fd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check reports an error if fd does not have the wire type a field of its kind needs.
+func (fd field) check(wire int) error {
+	if fd.wire != wire {
+		return errMalformed
+	}
+	return nil
+}
+
+// bytes, string, and double return the value of fd,
+// which must have the matching wire type.
+
+func (fd field) bytes() ([]byte, error) {
+	return fd.data, fd.check(wireBytes)
+}
+
+func (fd field) string() (string, error) {
+	return string(fd.data), fd.check(wireBytes)
+}
+
+func (fd field) double() (float64, error) {
+	return math.Float64frombits(fd.val), fd.check(wireFixed64)
+}
+
+type scanTextRequest struct {
+	text []byte
+	name string
+}
+
+func (r *scanTextRequest) unmarshal(msg []byte) error {
+	return decode(msg, func(fd field) (err error) {
+		switch fd.num {
+		case 1:
+			r.text, err = fd.bytes()
+		case 2:
+			r.name, err = fd.string()
+		}
+		return err
+	})
+}
+
+type scanArchiveRequest struct {
+	archive []byte
+}
+
+func (r *scanArchiveRequest) unmarshal(msg []byte) error {
+	return decode(msg, func(fd field) (err error) {
+		if fd.num == 1 {
+			r.archive, err = fd.bytes()
+		}
+		return err
+	})
+}
+
+type verifyRequest struct {
+	text       []byte
+	expected   string
+	minPercent float64
+}
+
+func (r *verifyRequest) unmarshal(msg []byte) error {
+	return decode(msg, func(fd field) (err error) {
+		switch fd.num {
+		case 1:
+			r.text, err = fd.bytes()
+		case 2:
+			r.expected, err = fd.string()
+		case 3:
+			r.minPercent, err = fd.double()
+		}
+		return err
+	})
+}
@@ -0,0 +1,173 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lchttp provides an HTTP handler that scans uploaded files
+// for licenses, so that a license-checking service needs no plumbing:
+//
+//	http.Handle("/scan", &lchttp.Handler{MaxRequestBytes: 8 << 20})
+//
+// A client POSTs either a single file as the request body,
+// optionally naming it with a name query parameter,
+// or several files as a multipart/form-data form:
+//
+//	curl --data-binary @LICENSE 'http://localhost:8080/scan?name=LICENSE'
+//	curl -F f=@LICENSE -F f=@main.go http://localhost:8080/scan
+//
+// The response is a JSON Response listing the Coverage of each file.
+package lchttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+
+	"github.com/google/licensecheck"
+)
+
+// A Handler is an http.Handler that scans the files in POST requests.
+// The zero Handler scans with the built-in licenses and default limits.
+type Handler struct {
+	// Scanner is the scanner to use.
+	// If nil, the handler uses the built-in license set.
+	Scanner *licensecheck.Scanner
+
+	// Options are the options for scanning each file.
+	// Options.MaxInputBytes limits the size of each file.
+	Options licensecheck.Options
+
+	// SourceNames says to set Options.Source to each file's name,
+	// so that only the comments in recognized source files are scanned.
+	SourceNames bool
+
+	// MaxRequestBytes limits the size of a request body.
+	// If zero, the limit is DefaultMaxRequestBytes.
+	MaxRequestBytes int64
+
+	// MaxFiles limits the number of files in a multipart request.
+	// If zero, the limit is DefaultMaxFiles.
+	MaxFiles int
+}
+
+// Default limits for a Handler.
+const (
+	DefaultMaxRequestBytes = 32 << 20
+	DefaultMaxFiles        = 100
+)
+
+// A Response is the JSON body of a successful response.
+type Response struct {
+	Files []File
+}
+
+// A File is the result of scanning one file.
+type File struct {
+	Name     string
+	Coverage licensecheck.Coverage
+
+	// Error is the error from scanning the file, if any,
+	// such as the file being larger than Options.MaxInputBytes.
+	// Finding no license is not an error.
+	Error string `json:",omitempty"`
+}
+
+// ServeHTTP implements http.Handler.
+// It replies with status 405 to methods other than POST,
+// 413 to requests larger than MaxRequestBytes,
+// and 400 to other malformed requests.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	max := h.MaxRequestBytes
+	if max <= 0 {
+		max = DefaultMaxRequestBytes
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, max)
+
+	var resp Response
+	var err error
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "multipart/form-data" {
+		resp.Files, err = h.scanMultipart(r)
+	} else {
+		var data []byte
+		data, err = ioutil.ReadAll(r.Body)
+		if err == nil {
+			resp.Files = []File{h.scan(r.URL.Query().Get("name"), data)}
+		}
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		if err.Error() == "http: request body too large" {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&resp)
+}
+
+// scanMultipart scans each file in the multipart form in r.
+func (h *Handler) scanMultipart(r *http.Request) ([]File, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	maxFiles := h.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = DefaultMaxFiles
+	}
+	var files []File
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FileName() == "" {
+			continue // not a file
+		}
+		if len(files) == maxFiles {
+			return nil, fmt.Errorf("too many files (limit %d)", maxFiles)
+		}
+		data, err := ioutil.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, h.scan(part.FileName(), data))
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no files in form")
+	}
+	return files, nil
+}
+
+// scan scans a single file.
+func (h *Handler) scan(name string, data []byte) File {
+	opts := h.Options
+	if h.SourceNames {
+		opts.Source = name
+	}
+	var cov licensecheck.Coverage
+	var err error
+	if h.Scanner != nil {
+		cov, err = h.Scanner.ScanWithOptions(data, opts)
+	} else {
+		cov, err = licensecheck.ScanWithOptions(data, opts)
+	}
+	f := File{Name: name, Coverage: cov}
+	if err != nil && !errors.Is(err, licensecheck.ErrNoMatch) && !errors.Is(err, licensecheck.ErrEmptyInput) {
+		f.Error = err.Error()
+	}
+	return f
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lchttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/licensecheck"
+)
+
+func post(t *testing.T, h http.Handler, url, contentType string, body []byte) (*httptest.ResponseRecorder, Response) {
+	t.Helper()
+	req := httptest.NewRequest("POST", url, bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	var resp Response
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshaling response: %v\n%s", err, rec.Body.Bytes())
+		}
+	}
+	return rec, resp
+}
+
+func TestHandler(t *testing.T) {
+	mit, ok := licensecheck.Text("MIT")
+	if !ok {
+		t.Fatal("no text for MIT")
+	}
+	h := &Handler{}
+
+	// A single file.
+	rec, resp := post(t, h, "/scan?name=LICENSE", "text/plain", []byte(mit))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("POST file: %d %s", rec.Code, rec.Body.Bytes())
+	}
+	if len(resp.Files) != 1 || resp.Files[0].Name != "LICENSE" || len(resp.Files[0].Coverage.Match) != 1 || resp.Files[0].Coverage.Match[0].ID != "MIT" {
+		t.Errorf("POST file: response %+v, want MIT", resp)
+	}
+
+	// Several files.
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, f := range []struct{ name, text string }{
+		{"LICENSE", mit},
+		{"main.go", "package main\n"},
+	} {
+		fw, _ := mw.CreateFormFile("file", f.name)
+		fw.Write([]byte(f.text))
+	}
+	mw.WriteField("comment", "not a file")
+	mw.Close()
+	rec, resp = post(t, h, "/scan", mw.FormDataContentType(), body.Bytes())
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST multipart: %d %s", rec.Code, rec.Body.Bytes())
+	}
+	if len(resp.Files) != 2 || resp.Files[0].Coverage.Match[0].ID != "MIT" || resp.Files[1].Name != "main.go" || len(resp.Files[1].Coverage.Match) != 0 || resp.Files[1].Error != "" {
+		t.Errorf("POST multipart: response %+v, want MIT and nothing", resp)
+	}
+
+	// Limits.
+	if rec, _ := post(t, &Handler{MaxRequestBytes: 100}, "/scan", "", []byte(mit)); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("POST too large: %d, want 413", rec.Code)
+	}
+	if rec, _ := post(t, &Handler{MaxFiles: 1}, "/scan", mw.FormDataContentType(), body.Bytes()); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST too many files: %d, want 400", rec.Code)
+	}
+	_, resp = post(t, &Handler{Options: licensecheck.Options{MaxInputBytes: 100}}, "/scan", "", []byte(mit))
+	if len(resp.Files) != 1 || !strings.Contains(resp.Files[0].Error, "too large") {
+		t.Errorf("POST with MaxInputBytes: response %+v, want input too large error", resp)
+	}
+
+	// Other methods.
+	req := httptest.NewRequest("GET", "/scan", nil)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "POST" {
+		t.Errorf("GET: %d, want 405", rec.Code)
+	}
+}
+
+func TestHandlerScanner(t *testing.T) {
+	s, err := licensecheck.NewScanner([]licensecheck.License{{ID: "Corp-1.0", LRE: "This software is licensed under the Corporate License, version 1.0."}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Handler{Scanner: s, SourceNames: true}
+	_, resp := post(t, h, "/scan?name=x.go", "", []byte("// This software is licensed under the Corporate License, version 1.0.\npackage x\n"))
+	if len(resp.Files) != 1 || len(resp.Files[0].Coverage.Match) != 1 || resp.Files[0].Coverage.Match[0].ID != "Corp-1.0" || resp.Files[0].Coverage.Percent != 100 {
+		t.Errorf("response %+v, want Corp-1.0 at 100%% of comments", resp)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lcprom exports licensecheck scan metrics to Prometheus,
+// so that fleets of scanning services can be monitored.
+//
+// An Exporter is a licensecheck.Metrics that records each scan
+// and an http.Handler that serves the records
+// in the Prometheus text exposition format:
+//
+//	exp := new(lcprom.Exporter)
+//	http.Handle("/metrics", exp)
+//	...
+//	cov, err := licensecheck.ScanWithOptions(text, licensecheck.Options{Metrics: exp})
+//
+// The exported metrics, each prefixed by the Exporter's Namespace, are:
+//
+//	scans_total{result}             scans, by result: match, nomatch, empty, or error
+//	scan_cache_hits_total           scans whose result was reused (see licensecheck.ScanEvent)
+//	scanned_bytes_total             bytes of text scanned
+//	scan_duration_seconds           histogram of the time taken by each computed scan
+//	scan_coverage_percent           histogram of Coverage.Percent for scans finding a license
+//	license_matches_total{license}  matches, by license ID
+//
+// The cache hit rate is scan_cache_hits_total / sum(scans_total).
+//
+// The package implements the exposition format itself,
+// so it adds no dependencies.
+package lcprom
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/licensecheck"
+)
+
+// An Exporter records scans and serves them as Prometheus metrics.
+// The zero Exporter is ready to use. An Exporter must not be copied after first use.
+type Exporter struct {
+	// Namespace is the prefix of the metric names, followed by an underscore.
+	// If empty, the namespace is "licensecheck".
+	Namespace string
+
+	mu        sync.Mutex
+	scans     map[string]int64 // result -> count
+	cacheHits int64
+	bytes     int64
+	duration  histogram
+	coverage  histogram
+	matches   map[string]int64 // license ID -> count
+}
+
+// Histogram bucket upper bounds.
+var (
+	durationBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	coverageBuckets = []float64{10, 20, 30, 40, 50, 60, 70, 80, 90, 95, 99, 100}
+)
+
+// A histogram counts observations in buckets.
+// counts[i] is the number of observations at most bounds[i],
+// not including those in earlier buckets.
+type histogram struct {
+	counts []int64
+	sum    float64
+	count  int64
+}
+
+func (h *histogram) observe(bounds []float64, v float64) {
+	if h.counts == nil {
+		h.counts = make([]int64, len(bounds))
+	}
+	if i := sort.SearchFloat64s(bounds, v); i < len(bounds) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+}
+
+// Observe implements licensecheck.Metrics.
+func (e *Exporter) Observe(ev licensecheck.ScanEvent) {
+	result := "match"
+	switch {
+	case errors.Is(ev.Err, licensecheck.ErrNoMatch):
+		result = "nomatch"
+	case errors.Is(ev.Err, licensecheck.ErrEmptyInput):
+		result = "empty"
+	case ev.Err != nil:
+		result = "error"
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.scans == nil {
+		e.scans = make(map[string]int64)
+		e.matches = make(map[string]int64)
+	}
+	e.scans[result]++
+	e.bytes += int64(ev.Bytes)
+	if ev.Cached {
+		e.cacheHits++
+	} else {
+		e.duration.observe(durationBuckets, ev.Duration.Seconds())
+	}
+	if result == "match" {
+		e.coverage.observe(coverageBuckets, ev.Coverage.Percent)
+	}
+	for _, m := range ev.Coverage.Match {
+		e.matches[m.ID]++
+	}
+}
+
+// ServeHTTP implements http.Handler, serving the metrics
+// in the Prometheus text exposition format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	e.write(bw)
+	bw.Flush()
+}
+
+// write writes the metrics to w.
+func (e *Exporter) write(w *bufio.Writer) {
+	ns := e.Namespace
+	if ns == "" {
+		ns = "licensecheck"
+	}
+	header := func(name, typ, help string) string {
+		name = ns + "_" + name
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+		return name
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	name := header("scans_total", "counter", "Number of texts scanned, by result.")
+	for _, result := range []string{"match", "nomatch", "empty", "error"} {
+		fmt.Fprintf(w, "%s{result=%q} %d\n", name, result, e.scans[result])
+	}
+	name = header("scan_cache_hits_total", "counter", "Number of scans whose result was reused from an earlier scan.")
+	fmt.Fprintf(w, "%s %d\n", name, e.cacheHits)
+	name = header("scanned_bytes_total", "counter", "Number of bytes of text scanned.")
+	fmt.Fprintf(w, "%s %d\n", name, e.bytes)
+	name = header("scan_duration_seconds", "histogram", "Time taken by each scan, not counting reused results.")
+	e.duration.write(w, name, durationBuckets)
+	name = header("scan_coverage_percent", "histogram", "Percentage of each text covered by licenses, for texts with a license.")
+	e.coverage.write(w, name, coverageBuckets)
+
+	name = header("license_matches_total", "counter", "Number of matches, by license.")
+	ids := make([]string, 0, len(e.matches))
+	for id := range e.matches {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintf(w, "%s{license=\"%s\"} %d\n", name, escape(id), e.matches[id])
+	}
+}
+
+// write writes the samples of h, named name, to w.
+func (h *histogram) write(w *bufio.Writer, name string, bounds []float64) {
+	n := int64(0)
+	for i, b := range bounds {
+		if h.counts != nil {
+			n += h.counts[i]
+		}
+		fmt.Fprintf(w, "%s_bucket{le=\"%g\"} %d\n", name, b, n)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.count)
+	fmt.Fprintf(w, "%s_sum %g\n", name, h.sum)
+	fmt.Fprintf(w, "%s_count %d\n", name, h.count)
+}
+
+// escape escapes a label value for the text exposition format.
+var escape = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace
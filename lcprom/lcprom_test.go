@@ -0,0 +1,60 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lcprom
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+)
+
+func TestExporter(t *testing.T) {
+	mit, ok := licensecheck.Text("MIT")
+	if !ok {
+		t.Fatal("no text for MIT")
+	}
+	exp := &Exporter{Namespace: "lc"}
+	opts := licensecheck.Options{Metrics: exp}
+	fsys := fstest.MapFS{
+		"LICENSE":        {Data: []byte(mit)},
+		"vendor/LICENSE": {Data: []byte(mit)},
+		"README":         {Data: []byte("Nothing to see here.\n")},
+		"empty":          {Data: []byte("")},
+	}
+	if _, err := licensecheck.ScanFS(fsys, []string{"LICENSE", "vendor/LICENSE", "README", "empty"}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := licensecheck.ScanWithOptions([]byte(mit), licensecheck.Options{Metrics: exp, MaxInputBytes: 10}); err == nil {
+		t.Fatal("ScanWithOptions succeeded, want too large")
+	}
+
+	rec := httptest.NewRecorder()
+	exp.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	out := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE lc_scans_total counter\n",
+		`lc_scans_total{result="match"} 2` + "\n",
+		`lc_scans_total{result="nomatch"} 1` + "\n",
+		`lc_scans_total{result="empty"} 1` + "\n",
+		`lc_scans_total{result="error"} 1` + "\n",
+		"lc_scan_cache_hits_total 1\n",
+		"# TYPE lc_scan_duration_seconds histogram\n",
+		`lc_scan_duration_seconds_bucket{le="+Inf"} 4` + "\n",
+		"lc_scan_duration_seconds_count 4\n",
+		`lc_scan_coverage_percent_bucket{le="90"} 0` + "\n",
+		`lc_scan_coverage_percent_bucket{le="100"} 2` + "\n",
+		`lc_license_matches_total{license="MIT"} 2` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q:\n%s", want, out)
+		}
+	}
+}
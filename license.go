@@ -25,8 +25,46 @@
 //
 // A custom scanner can be created using NewScanner, passing in a set of license
 // patterns to scan for. The license patterns are written as license regular
-// expressions (LREs).
+// expressions (LREs). NewScannerFS creates a scanner from a directory of
+// license files, such as one embedded in the program using go:embed.
 // BuiltinLicenses returns the set of license patterns used by Scan.
+// InferLRE writes a pattern for a license from several filled-in copies of it.
+// Compiling a large set of patterns takes time, so a program can
+// save a compiled Scanner with Save and restore it with LoadScanner.
+//
+// ScanWithOptions is like Scan but accepts Options controlling the scan,
+// such as how to treat input that is not valid UTF-8
+// and how much input to accept.
+// By default, invalid UTF-8 bytes are treated like punctuation,
+// and there is no limit on the input size.
+// ScanFile scans the content of a file, mapping it into memory when possible.
+// ScanFS scans many files, scanning only once any files with the same normalized text.
+// ScanHeader scans only the license header comments at the top of a source file,
+// including any SPDX-License-Identifier tags.
+// ScanSource scans all the comments in a source file, ignoring the code.
+// Options.Matcher replaces the Scanner's matching of license texts
+// with another engine (see Matcher), for experiments and benchmarks.
+// Compare compares two texts directly, such as two copies of a license file,
+// and Cluster groups texts that are nearly the same.
+//
+// SimHash and MinHash compute fingerprints of texts, normalized as for Scan,
+// so that systems holding many texts can cheaply find those
+// nearly the same as a given text before scanning or comparing them.
+// For a given version of this package, a text always has the same fingerprints.
+// Nearest suggests the known licenses most similar to a text that Scan
+// does not recognize, as a starting point for classifying it,
+// and DiffAgainst shows how a text differs from a chosen license.
+//
+// The built-in license set adds several megabytes to a program.
+// Building with the tag licensecheck_top50 includes only
+// the 50 or so most commonly used licenses instead,
+// for programs where size matters, such as a license checker
+// compiled to WebAssembly (GOOS=js GOARCH=wasm) to run in a browser.
+// Scan then reports texts of other licenses as unrecognized.
+//
+// Nothing in this package uses the network.
+// The subpackages that do, such as spdx, which downloads the SPDX license list,
+// say so in their documentation, and are used only by programs importing them.
 //
 // License Regular Expressions
 //
@@ -63,54 +101,323 @@
 // An older, less precise matcher using the names Cover, New, and Checker
 // was removed from this package.
 // Use v0.1.0 for the final version of that API.
+// Its Options struct, with fields MinLength, Threshold, and Slop,
+// was a set of tuning knobs marked for deletion, not a stable API.
+// Programs using it should move to the Scan API, which replaces it as follows:
+//
+//   - Cover(text, opts) becomes Scan(text), or ScanWithOptions(text, opts)
+//     with the Options of this package.
+//   - New(licenses) and Checker become NewScanner(licenses) and Scanner,
+//     and Checker.Cover becomes Scanner.Scan.
+//   - Options.Threshold becomes a check of Coverage.Percent,
+//     or of Match.Confidence for each match, by the caller.
+//   - Options.Slop becomes Options.MergeGapWords,
+//     which reports the inserted text in Coverage.Deviations.
+//   - Options.MinLength has no replacement: LREs match whole licenses,
+//     not runs of words.
+//
+// Unlike the old Options, the Options of this package are part of the stable API.
+// New fields may be added, but the zero value of each field
+// keeps the results of earlier versions.
 //
 package licensecheck
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
-// The order matters here.
-// gen_data.go imports licensecheck for Type, so we copy over
-// a trivial data.gen.go in order to build gen_data.go during "go run".
-//go:generate cp data.gen.go.triv data.gen.go
-//go:generate go run gen_data.go
-
 // A License describes a single license that can be recognized.
 // At least one of LRE or URL should be set.
 type License struct {
-	ID   string // reported license ID
-	Type Type   // reported license type
-	LRE  string // license regular expression (see licenses/README.md)
-	URL  string // identifying URL
+	ID       string   // reported license ID
+	Type     Type     // reported license type
+	Category Category // kind of work the license is for
+	LRE      string   // license regular expression (see licenses/README.md)
+	URL      string   // identifying URL
+
+	// Optional metadata, copied into any Match of the license.
+	SPDXID      string // canonical SPDX identifier, if ID is not one
+	Name        string // full human-readable name
+	ListVersion string // version of the SPDX license list the definition is based on, if any
+	Language    string // language of a translated license text, such as "de"; empty for English
+	IsHeader    bool   // whether the LRE matches a short per-file notice, not the license text itself
+	IsRider     bool   // whether the license is a rider restricting the licenses it accompanies
+	Wraps       string // ID of a license the licensee may choose to use instead, such as Apache-2.0 for SHL-2.1
+
+	// Text is the canonical text of the license, if known.
+	// It is not used for matching (see Scanner.Text).
+	Text string
+}
+
+// SPDXVersion is the version of the SPDX license list
+// on which the built-in license set is based.
+// It is the ListVersion of every built-in license
+// except those added beyond that version of the list,
+// either because SPDX added them later, such as BUSL-1.1,
+// or because SPDX does not list them, such as Llama-2;
+// their ListVersion is empty.
+const SPDXVersion = "3.10"
+
+// QuoteLRE returns an LRE that matches the literal text.
+// It breaks up any LRE operators, such as (( or __N__, appearing in text
+// by inserting spaces, which does not change the words of the text.
+func QuoteLRE(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for i := 0; i < len(text); i++ {
+		b.WriteByte(text[i])
+		if i+1 < len(text) {
+			switch text[i : i+2] {
+			case "((", "))", "||", "??", "__", "/*":
+				b.WriteByte(' ')
+			}
+		}
+	}
+	return b.String()
 }
 
 // Coverage describes how the text matches various licenses.
+//
+// Coverage and the types it contains have JSON struct tags
+// naming their fields in lower camel case, such as "isURL",
+// with license types encoded as strings (see Type.MarshalText).
+// The names will not change, so that stored results remain readable.
 type Coverage struct {
 	// Percent is the fraction of the total text, in normalized words, that
 	// matches any valid license, expressed as a percentage across all of the
 	// licenses matched.
-	Percent float64
+	// A URL match counts only the words of the URL itself,
+	// not the text around it, so that Percent, like the match's
+	// Start and End, reflects only what the URL evidence spans.
+	Percent float64 `json:"percent"`
 
 	// Match describes, in sequential order, the matches of the input text
 	// across the various licenses. Typically it will be only one match long,
 	// but if the input text is a concatenation of licenses it will contain
 	// a match value for each element of the concatenation.
-	Match []Match
+	Match []Match `json:"match"`
+
+	// UnknownTags lists the licenses named in SPDX-License-Identifier tags
+	// (see ScanHeader) that the Scanner does not know,
+	// such as LicenseRef- identifiers or misspellings like “MTI”.
+	// They are not in Match, and their words do not count toward Percent,
+	// since nothing is known about them. Each has IsTag set
+	// and is located at the identifier in the tag.
+	UnknownTags []Match `json:"unknownTags,omitempty"`
+
+	// Truncated reports whether only a prefix of the text was scanned,
+	// because it was longer than Options.MaxInputBytes.
+	Truncated bool `json:"truncated,omitempty"`
+
+	// Empty reports whether the input had no words at all:
+	// it was empty or contained only spaces, punctuation, and markup.
+	// An empty input has no matches and a Percent of 0,
+	// but that says nothing about licensing,
+	// unlike a Percent of 0 for text with words in it.
+	Empty bool `json:"empty,omitempty"`
+
+	// EULA reports whether the text, though not mostly covered
+	// by known licenses, reads like an end-user license agreement
+	// or terms of service: a proprietary agreement that binds anyone
+	// who installs or uses the software, as in many vendored SDKs.
+	// Such a text is not an open-source license.
+	// The determination is heuristic, based on key phrases such as
+	// “by installing”, “you agree”, and “termination”,
+	// and is made only when Options.EULA is set.
+	EULA bool `json:"eula,omitempty"`
+
+	// Restrictions lists the clauses restricting the use of the work,
+	// such as “non-commercial use only” or “may not be used for military purposes”,
+	// found in the text not covered by Match (see Restriction and Restricted),
+	// only when Options.Restrictions is set.
+	Restrictions []Restriction `json:"restrictions,omitempty"`
+
+	// Deviations lists the text inserted into matched licenses,
+	// found only when Options.MergeGapWords is set.
+	Deviations []Deviation `json:"deviations,omitempty"`
+
+	// Alternatives lists the licenses that lost to the matches in Match
+	// when more than one matched text beginning at the same place,
+	// the longest match of each license only.
+	Alternatives []Alternative `json:"alternatives,omitempty"`
+
+	// Parameters lists the values that the matched license texts
+	// give to the licenses' parameters, such as the Change Date
+	// and Change License of the Business Source License (see Parameter).
+	Parameters []Parameter `json:"parameters,omitempty"`
+
+	// Submatches lists the runs of words that each match shares
+	// with the canonical text of its license, found only when
+	// Options.Submatches is set (see Submatch).
+	Submatches []Submatch `json:"submatches,omitempty"`
+
+	// Words lists the words of the text, normalized as for matching:
+	// in lower case, without punctuation or markup, and with a few
+	// words canonicalized, such as "this" to "the".
+	// It is set only when Options.Words is set,
+	// along with each match's WordStart and WordEnd.
+	Words []string `json:"words,omitempty"`
+}
+
+// Sort sorts c.Match by Start, keeping matches with the same Start
+// in their original order, and updates the indexes of the matches
+// in c.Deviations, c.Alternatives, c.Parameters, and c.Submatches to match.
+// It is for programs that add matches to a Coverage.
+func (c *Coverage) Sort() {
+	index := make([]int, len(c.Match)) // index[new] = old
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool { return c.Match[index[i]].Start < c.Match[index[j]].Start })
+	moved := make([]int, len(index)) // moved[old] = new
+	list := make([]Match, len(c.Match))
+	for i, old := range index {
+		moved[old] = i
+		list[i] = c.Match[old]
+	}
+	copy(c.Match, list)
+	for i := range c.Deviations {
+		c.Deviations[i].Match = moved[c.Deviations[i].Match]
+	}
+	for i := range c.Alternatives {
+		c.Alternatives[i].Match = moved[c.Alternatives[i].Match]
+	}
+	for i := range c.Parameters {
+		c.Parameters[i].Match = moved[c.Parameters[i].Match]
+	}
+	for i := range c.Submatches {
+		c.Submatches[i].Match = moved[c.Submatches[i].Match]
+	}
+	sort.SliceStable(c.Deviations, func(i, j int) bool { return c.Deviations[i].Match < c.Deviations[j].Match })
+	sort.SliceStable(c.Alternatives, func(i, j int) bool { return c.Alternatives[i].Match < c.Alternatives[j].Match })
+	sort.SliceStable(c.Parameters, func(i, j int) bool { return c.Parameters[i].Match < c.Parameters[j].Match })
+	sort.SliceStable(c.Submatches, func(i, j int) bool { return c.Submatches[i].Match < c.Submatches[j].Match })
 }
 
 // Match describes how a section of the input matches a license.
 // The ID field identifies the specific license. Its value is either an SPDX
 // identifier, or a locally created name for licenses that SPDX does not classify.
 // See licenses/README.md for more information.
+//
+// Start and End are byte offsets in the original input text,
+// always at UTF-8 character boundaries, no matter how the text
+// was normalized for matching.
 type Match struct {
-	ID    string // License identifier.
-	Type  Type   // The type of the license: BSD, MIT, etc.
-	Start int    // Start offset of match in text; match is at text[Start:End].
-	End   int    // End offset of match in text.
-	IsURL bool   // Whether match is a URL.
+	ID   string `json:"id"`   // License identifier.
+	Type Type   `json:"type"` // The type of the license: BSD, MIT, etc.
+
+	// Category is the kind of work the license is for (see License.Category).
+	Category Category `json:"category,omitempty"`
+
+	Start int  `json:"start"`           // Start byte offset of match in text; match is at text[Start:End].
+	End   int  `json:"end"`             // End byte offset of match in text.
+	IsURL bool `json:"isURL,omitempty"` // Whether match is a URL; Start and End span only the URL.
+	IsTag bool `json:"isTag,omitempty"` // Whether match is an SPDX-License-Identifier tag (see ScanHeader).
+
+	// IsApprox reports whether the match was found only approximately,
+	// despite scattered differences from the license (see Options.ApproxPercent).
+	IsApprox bool `json:"isApprox,omitempty"`
+
+	// IsExact reports whether the match is a verbatim copy,
+	// after normalization, of the license's entire canonical text (see Text),
+	// apart from any copyright lines at the start.
+	IsExact bool `json:"isExact,omitempty"`
+
+	// LicenseStart and LicenseEnd are the byte offsets of the part
+	// of the license's canonical text (see Text) that the match covers,
+	// from the first word the two share to the last:
+	// the match corresponds to text[LicenseStart:LicenseEnd] of the license.
+	// Both are zero if the license has no canonical text
+	// or the match shares too little of it to locate it, as for a URL.
+	LicenseStart int `json:"licenseStart,omitempty"`
+	LicenseEnd   int `json:"licenseEnd,omitempty"`
+
+	// WordStart and WordEnd are the offsets of the match in Coverage.Words,
+	// set only when Options.Words is set:
+	// the match covers the words Coverage.Words[WordStart:WordEnd].
+	WordStart int `json:"wordStart,omitempty"`
+	WordEnd   int `json:"wordEnd,omitempty"`
+
+	// MatchedWords is the number of words of the text
+	// that the match accounts for, not counting any deviations
+	// allowed by Options.MergeGapWords or Options.ApproxPercent.
+	// LicenseWords is the number of words in the license's
+	// canonical text, or zero if it has none or the match is a URL or tag.
+	// Together they show how much evidence the match rests on:
+	// a match of a 12-word notice is weaker than one of a 1,600-word license.
+	MatchedWords int `json:"matchedWords"`
+	LicenseWords int `json:"licenseWords,omitempty"`
+
+	// Confidence is how sure the scan is that the text is the license,
+	// from 0 to 100, as distinct from how much of the text
+	// the match covers (see Coverage.Percent).
+	// An SPDX tag or an exact copy (see IsExact) has confidence 100,
+	// and a URL has 90. Any other match starts at 100 and then:
+	// an approximate match (see IsApprox) loses 15 points;
+	// a match that is not a header loses up to 20 points,
+	// in proportion to the part of the license text it does not cover;
+	// a match of fewer than 20 words is scaled down to between
+	// 50% and 100% of its confidence, in proportion to its length;
+	// and a match that other licenses contested (see Coverage.Alternatives)
+	// or that has deviations (see Coverage.Deviations) loses 10% for each.
+	// Programs may rely on these rules to set their own thresholds:
+	// they will not change except in a new major version of this package.
+	Confidence float64 `json:"confidence"`
+
+	SPDXID      string `json:"spdxID,omitempty"`      // License.SPDXID of the matched license.
+	Name        string `json:"name,omitempty"`        // License.Name of the matched license.
+	ListVersion string `json:"listVersion,omitempty"` // License.ListVersion of the matched license.
+	Language    string `json:"language,omitempty"`    // License.Language of the matched license.
+	IsHeader    bool   `json:"isHeader,omitempty"`    // License.IsHeader of the matched license.
+	IsRider     bool   `json:"isRider,omitempty"`     // License.IsRider of the matched license.
+
+	// Rider is the ID of a rider, such as CommonsClause,
+	// found in the same text and restricting the matched license.
+	// A rider changes the terms of the license it is added to
+	// without changing its text, which still matches in full,
+	// so the rider's NonCommercial, Discouraged, SourceAvailable,
+	// and UseRestrictions type bits are added to Type. Rider is empty for the riders themselves.
+	Rider string `json:"rider,omitempty"`
+
+	// Wraps is License.Wraps of the matched license:
+	// the ID of a license that the matched one wraps or is based on,
+	// under which the licensee may choose to use the work instead,
+	// as the Solderpad Hardware License 2.1 (SHL-2.1) wraps Apache-2.0.
+	Wraps string `json:"wraps,omitempty"`
+}
+
+// RuneOffsets returns the start and end offsets of m in text
+// counted in runes (Unicode code points) instead of bytes,
+// for clients, such as editors, that index text by rune.
+// The text must be the one that was scanned to produce m.
+func (m *Match) RuneOffsets(text []byte) (start, end int) {
+	start = utf8.RuneCount(text[:m.Start])
+	end = start + utf8.RuneCount(text[m.Start:m.End])
+	return start, end
+}
+
+// Excerpt returns the text of m in input, input[m.Start:m.End],
+// which must be the text that was scanned to produce m.
+// If the offsets lie outside input, as when m came from scanning
+// a different text, Excerpt returns only the part inside input,
+// possibly none of it, instead of panicking.
+// The result shares storage with input.
+func (m *Match) Excerpt(input []byte) []byte {
+	start, end := m.Start, m.End
+	if start < 0 {
+		start = 0
+	}
+	if end > len(input) {
+		end = len(input)
+	}
+	if start >= end {
+		return nil
+	}
+	return input[start:end]
 }
 
 // Type is a bit set describing the requirements imposed by a license or group of
@@ -155,14 +462,33 @@ const (
 	// making it difficult to comply with or vague about what it permits.
 	// Examples: Beerware, SISSL, WTFPL.
 	Discouraged
+
+	// SourceAvailable indicates that the source code is published
+	// under a license that is not open source, because it forbids
+	// uses such as offering the software as a service or in production,
+	// though it may look like an open-source license at first glance.
+	// Examples: BUSL-1.1, Elastic-2.0, SSPL-1.0.
+	SourceAvailable
+
+	// UseRestrictions indicates that the license forbids specific uses
+	// of the software, such as the harmful uses listed by the
+	// Responsible AI Licenses that accompany machine-learning models,
+	// so that complying with it means checking how the software is used.
+	// Examples: Hippocratic-2.1, Llama-2, OpenRAIL.
+	UseRestrictions
 )
 
+// restrictions are the Type bits that restrict a license
+// rather than say which of the ordered requirements it carries.
+const restrictions = NonCommercial | Discouraged | SourceAvailable | UseRestrictions
+
 // Merge returns the result of merging the requirements of license types t and u.
 //
 // If either is Unknown, the result is Unknown.
 // Among the bits Unrestricted, Notice, ShareChanges, ShareProgram, ShareServer,
 // the result will use the one that appears latest in the list and is present in either t or u.
-// The NonCommercial and Discouraged bits are set in the result if they are set in either t or u.
+// The NonCommercial, Discouraged, SourceAvailable, and UseRestrictions bits
+// are set in the result if they are set in either t or u.
 func (t Type) Merge(u Type) Type {
 	if t == Unknown || u == Unknown {
 		return Unknown
@@ -175,11 +501,11 @@ func (t Type) Merge(u Type) Type {
 			break
 		}
 	}
-	m |= (t | u) & (NonCommercial | Discouraged)
+	m |= (t | u) & restrictions
 
-	// Special case: NonCommercial is a restriction,
+	// Special case: NonCommercial, SourceAvailable, and UseRestrictions are restrictions,
 	// so drop the unrestricted bit if still set.
-	if m&Unrestricted != 0 && m&NonCommercial != 0 {
+	if m&Unrestricted != 0 && m&(NonCommercial|SourceAvailable|UseRestrictions) != 0 {
 		m &^= Unrestricted
 	}
 
@@ -198,6 +524,8 @@ var typeBits = []struct {
 	{ShareServer, "ShareServer"},
 	{NonCommercial, "NonCommercial"},
 	{Discouraged, "Discouraged"},
+	{SourceAvailable, "SourceAvailable"},
+	{UseRestrictions, "UseRestrictions"},
 }
 
 // String returns the type t in string form.
@@ -247,3 +575,20 @@ Fields:
 	}
 	return t, nil
 }
+
+// MarshalText implements encoding.TextMarshaler,
+// encoding t in the form returned by String.
+func (t Type) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler,
+// decoding text in the form accepted by ParseType.
+func (t *Type) UnmarshalText(text []byte) error {
+	typ, err := ParseType(string(text))
+	if err != nil {
+		return err
+	}
+	*t = typ
+	return nil
+}
@@ -6,12 +6,14 @@ package licensecheck
 
 import (
 	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -24,6 +26,7 @@ func init() {
 }
 
 func TestTestdata(t *testing.T) {
+	skipProfile(t)
 	files, err := filepath.Glob("testdata/*")
 	if err != nil {
 		t.Fatal(err)
@@ -114,6 +117,9 @@ func TestTestdata(t *testing.T) {
 			cov := Scan(data)
 			for _, m := range cov.Match {
 				typ := licenseType(m.ID)
+				if m.Rider != "" {
+					typ |= licenseType(m.Rider) & restrictions
+				}
 				if m.Type != typ {
 					t.Errorf("%s: match %s has Type=%s, want %s", file, m.ID, m.Type, typ)
 				}
@@ -239,11 +245,100 @@ func BenchmarkScanTestdata(b *testing.B) {
 	}
 
 	b.SetBytes(int64(len(benchdata)))
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		Scan(benchdata)
 	}
 }
 
+// benchInputs returns representative inputs for BenchmarkScan.
+func benchInputs(b *testing.B) []struct{ name, text string } {
+	text := func(id string) string {
+		t, ok := Text(id)
+		if !ok {
+			b.Fatalf("missing text for %s", id)
+		}
+		return t
+	}
+
+	// A near miss is a license with every 15th word changed,
+	// so that the matcher does a lot of work without finding a match.
+	words := strings.Fields(text("GPL-3.0"))
+	for i := 0; i < len(words); i += 15 {
+		words[i] = "xyzzy"
+	}
+	nearMiss := strings.Join(words, " ")
+
+	// Source code with a license header but otherwise no license text.
+	var code strings.Builder
+	code.WriteString("// Copyright 2020 The Go Authors. All rights reserved.\n")
+	code.WriteString("// Use of this source code is governed by a BSD-style\n")
+	code.WriteString("// license that can be found in the LICENSE file.\n\n")
+	for i := 0; code.Len() < 100000; i++ {
+		fmt.Fprintf(&code, "// f%d returns the %dth value from the table of the results.\n", i, i)
+		fmt.Fprintf(&code, "func f%d(x int) int {\n\treturn table[x+%d] * %d\n}\n\n", i, i, i)
+	}
+
+	// A README-like text with many links, some to licenses.
+	var urls strings.Builder
+	for i := 0; urls.Len() < 20000; i++ {
+		fmt.Fprintf(&urls, "See https://example.com/docs/page%d.html and https://github.com/example/repo%d for more.\n", i, i)
+		if i%10 == 0 {
+			urls.WriteString("Licensed under https://www.apache.org/licenses/LICENSE-2.0.\n")
+		}
+	}
+
+	return []struct{ name, text string }{
+		{"MIT", text("MIT")},
+		{"GPL-3.0", text("GPL-3.0")},
+		{"EUPL-1.2", text("EUPL-1.2")},
+		{"Concatenated", text("MIT") + "\n\n" + text("Apache-2.0") + "\n\n" + text("BSD-3-Clause") + "\n\n" + text("GPL-3.0")},
+		{"NearMiss", nearMiss},
+		{"SourceCode", code.String()},
+		{"URLs", urls.String()},
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	Scan(nil) // initialize built-in scanner
+	for _, in := range benchInputs(b) {
+		text := []byte(in.text)
+		b.Run(in.name, func(b *testing.B) {
+			b.SetBytes(int64(len(text)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Scan(text)
+			}
+		})
+	}
+}
+
+func BenchmarkLoadScanner(b *testing.B) {
+	var buf bytes.Buffer
+	if err := builtinScanner.Save(&buf); err != nil {
+		b.Fatal(err)
+	}
+	data := buf.Bytes()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := LoadScanner(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewScanner(b *testing.B) {
+	list := BuiltinLicenses()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewScanner(list); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 var trace = flag.String("tr", "", "trace DFA execution on `file` in TestTrace")
 
 func TestTrace(t *testing.T) {
@@ -265,3 +360,89 @@ func TestTrace(t *testing.T) {
 	}
 	t.Logf("coverage:\n%v", buf.String())
 }
+
+func TestCoverageSort(t *testing.T) {
+	c := Coverage{
+		Match:        []Match{{ID: "A", Start: 10}, {ID: "B", Start: 30}, {ID: "C", Start: 0}, {ID: "D", Start: 20}},
+		Deviations:   []Deviation{{Match: 3, Start: 22}},
+		Alternatives: []Alternative{{Match: 0, ID: "A2"}, {Match: 2, ID: "C2"}},
+		Parameters:   []Parameter{{Match: 1, Name: "B1"}, {Match: 2, Name: "C1"}},
+		Submatches:   []Submatch{{Match: 3, Start: 20}, {Match: 0, Start: 10}},
+	}
+	c.Sort()
+	want := Coverage{
+		Match:        []Match{{ID: "C", Start: 0}, {ID: "A", Start: 10}, {ID: "D", Start: 20}, {ID: "B", Start: 30}},
+		Deviations:   []Deviation{{Match: 2, Start: 22}},
+		Alternatives: []Alternative{{Match: 0, ID: "C2"}, {Match: 1, ID: "A2"}},
+		Parameters:   []Parameter{{Match: 0, Name: "C1"}, {Match: 3, Name: "B1"}},
+		Submatches:   []Submatch{{Match: 1, Start: 10}, {Match: 2, Start: 20}},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Sort:\nhave %+v\nwant %+v", c, want)
+	}
+}
+
+func TestCoverageJSON(t *testing.T) {
+	// The JSON field names are part of the API: do not change them.
+	c := Coverage{
+		Percent: 97.5,
+		Match: []Match{
+			{ID: "MIT", Type: Notice, Start: 0, End: 1000, IsExact: true, LicenseStart: 10, LicenseEnd: 990, MatchedWords: 160, LicenseWords: 161, Confidence: 100, ListVersion: "3.10"},
+			{ID: "Apache-2.0", Type: Unknown, Start: 1010, End: 1050, IsURL: true, MatchedWords: 7, Confidence: 90},
+		},
+		Truncated:    true,
+		Deviations:   []Deviation{{Match: 0, Start: 20, End: 40, Words: 3}},
+		Alternatives: []Alternative{{Match: 0, ID: "JSON", End: 900, Words: 150}},
+		Parameters:   []Parameter{{Match: 0, Name: "Change Date", Value: "2027-01-01"}},
+		Submatches:   []Submatch{{Match: 0, Start: 0, End: 1000, LicenseStart: 10, LicenseEnd: 990, Words: 160}},
+	}
+	want := `{"percent":97.5,"match":[` +
+		`{"id":"MIT","type":"Notice","start":0,"end":1000,"isExact":true,"licenseStart":10,"licenseEnd":990,"matchedWords":160,"licenseWords":161,"confidence":100,"listVersion":"3.10"},` +
+		`{"id":"Apache-2.0","type":"Unknown","start":1010,"end":1050,"isURL":true,"matchedWords":7,"confidence":90}],` +
+		`"truncated":true,` +
+		`"deviations":[{"match":0,"start":20,"end":40,"words":3}],` +
+		`"alternatives":[{"match":0,"id":"JSON","end":900,"words":150}],` +
+		`"parameters":[{"match":0,"name":"Change Date","value":"2027-01-01"}],` +
+		`"submatches":[{"match":0,"start":0,"end":1000,"licenseStart":10,"licenseEnd":990,"words":160}]}`
+	js, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(js) != want {
+		t.Errorf("json.Marshal(Coverage):\nhave %s\nwant %s", js, want)
+	}
+	var back Coverage
+	if err := json.Unmarshal(js, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, c) {
+		t.Errorf("json round trip:\nhave %+v\nwant %+v", back, c)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	input := []byte("hello, world")
+	for _, tt := range []struct {
+		start, end int
+		want       string
+	}{
+		{0, 5, "hello"},
+		{7, 12, "world"},
+		{7, 100, "world"},
+		{-3, 5, "hello"},
+		{20, 30, ""},
+		{5, 2, ""},
+	} {
+		m := Match{Start: tt.start, End: tt.end}
+		if got := m.Excerpt(input); string(got) != tt.want {
+			t.Errorf("Match{Start: %d, End: %d}.Excerpt(%q) = %q, want %q", tt.start, tt.end, input, got, tt.want)
+		}
+	}
+
+	mit, _ := Text("MIT")
+	text := "The license:\n\n" + mit
+	cov := Scan([]byte(text))
+	if len(cov.Match) != 1 || string(cov.Match[0].Excerpt([]byte(text))) != text[cov.Match[0].Start:cov.Match[0].End] {
+		t.Errorf("Scan(MIT).Excerpt does not match offsets: %+v", cov.Match)
+	}
+}
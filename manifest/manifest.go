@@ -0,0 +1,361 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package manifest reads the licenses declared in package manifests,
+// so that they can be compared with the licenses licensecheck finds.
+//
+// The supported manifests are:
+//
+//  - package.json (npm): the license field, or the older licenses list
+//  - composer.json (PHP Composer): the license field, a string or a list
+//  - Cargo.toml (Rust): the license and license-file fields of [package]
+//  - pyproject.toml (Python): the license field of [project] or [tool.poetry]
+//  - setup.cfg (Python setuptools): the license and license_files fields of [metadata]
+//  - pom.xml (Maven): the name and url of each license in <licenses>
+//
+// Maven identifies licenses by name and URL rather than SPDX ID.
+// LicenseID maps those to SPDX IDs.
+//
+// Only the fields naming licenses are read; the rest of each file is ignored.
+// The TOML and INI readers understand just enough of those formats
+// to find simple key = value lines.
+//
+//	decls, err := manifest.ReadAll(os.DirFS(dir))
+//	if err != nil {
+//		...
+//	}
+//	cov := licensecheck.Scan(licenseText)
+//	for _, d := range decls {
+//		if ids := d.Undeclared(cov); len(ids) > 0 {
+//			fmt.Printf("%s declares %s, but found %v\n", d.File, d.License, ids)
+//		}
+//	}
+package manifest
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/internal/spdxid"
+	"github.com/google/licensecheck/internal/strs"
+)
+
+// A Declaration is the license declared by a single manifest.
+type Declaration struct {
+	File    string   // path of the manifest
+	License string   // declared license, usually an SPDX expression like "MIT OR Apache-2.0"
+	Files   []string // license files named by the manifest, such as "LICENSE"
+	Unknown []string // licenses named by the manifest that have no known SPDX ID
+}
+
+// readers maps a manifest base name to the function that reads it.
+var readers = map[string]func(data []byte) (*Declaration, error){
+	"package.json":   readPackageJSON,
+	"composer.json":  readComposerJSON,
+	"Cargo.toml":     readCargoTOML,
+	"pyproject.toml": readPyprojectTOML,
+	"setup.cfg":      readSetupCfg,
+	"pom.xml":        readPomXML,
+}
+
+// Read reads the license declared by the manifest file in fsys.
+// The base name of file must be one of the supported manifest names.
+// If the manifest declares no license, Read returns a Declaration
+// with an empty License and Files.
+func Read(fsys fs.FS, file string) (*Declaration, error) {
+	read := readers[path.Base(file)]
+	if read == nil {
+		return nil, fmt.Errorf("manifest: %s: unknown manifest type", file)
+	}
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, fmt.Errorf("manifest: %v", err)
+	}
+	d, err := read(data)
+	if err != nil {
+		return nil, fmt.Errorf("manifest: %s: %v", file, err)
+	}
+	d.File = file
+	return d, nil
+}
+
+// ReadAll reads every supported manifest in the tree in fsys,
+// skipping .git, node_modules, and vendor directories,
+// and returns the declarations that name a license or license file.
+func ReadAll(fsys fs.FS) ([]*Declaration, error) {
+	var list []*Declaration
+	err := fs.WalkDir(fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("manifest: %v", err)
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", "node_modules", "vendor":
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if readers[d.Name()] == nil {
+			return nil
+		}
+		decl, err := Read(fsys, file)
+		if err != nil {
+			return err
+		}
+		if decl.License != "" || len(decl.Files) > 0 || len(decl.Unknown) > 0 {
+			list = append(list, decl)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Undeclared returns the IDs of the licenses in cov that d.License does not name.
+// IDs are compared without regard to case or to -only and -or-later suffixes.
+// URL matches are ignored, as are all matches if d.License is empty.
+func (d *Declaration) Undeclared(cov licensecheck.Coverage) []string {
+	if d.License == "" {
+		return nil
+	}
+	var names []string
+	for _, f := range strings.FieldsFunc(d.License, func(r rune) bool { return r == ' ' || r == '(' || r == ')' || r == '/' }) {
+		names = append(names, f)
+	}
+	var ids []string
+Matches:
+	for _, m := range cov.Match {
+		if m.IsURL {
+			continue
+		}
+		for _, name := range names {
+			if spdxid.Same(name, m.ID) {
+				continue Matches
+			}
+		}
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func readPackageJSON(data []byte) (*Declaration, error) {
+	var pkg struct {
+		License  json.RawMessage
+		Licenses []struct{ Type string }
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	d := new(Declaration)
+	if len(pkg.License) > 0 {
+		// Either "MIT" or, in old packages, {"type": "MIT", "url": "..."}.
+		var s string
+		var obj struct{ Type string }
+		if json.Unmarshal(pkg.License, &s) == nil {
+			d.License = s
+		} else if json.Unmarshal(pkg.License, &obj) == nil {
+			d.License = obj.Type
+		}
+	}
+	if d.License == "" {
+		var types []string
+		for _, l := range pkg.Licenses {
+			types = append(types, l.Type)
+		}
+		d.License = orList(types)
+	}
+	// "SEE LICENSE IN <file>" names a license file instead.
+	if f := strings.TrimPrefix(d.License, "SEE LICENSE IN "); f != d.License {
+		d.License = ""
+		d.Files = []string{f}
+	}
+	return d, nil
+}
+
+func readComposerJSON(data []byte) (*Declaration, error) {
+	var pkg struct {
+		License json.RawMessage
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	d := new(Declaration)
+	if len(pkg.License) > 0 {
+		// Either "MIT" or a list of alternatives, ["MIT", "GPL-3.0-or-later"].
+		var s string
+		var list []string
+		if json.Unmarshal(pkg.License, &s) == nil {
+			d.License = s
+		} else if json.Unmarshal(pkg.License, &list) == nil {
+			d.License = orList(list)
+		}
+	}
+	return d, nil
+}
+
+func readPomXML(data []byte) (*Declaration, error) {
+	var pom struct {
+		Licenses []struct {
+			Name string `xml:"name"`
+			URL  string `xml:"url"`
+		} `xml:"licenses>license"`
+	}
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil, err
+	}
+	d := new(Declaration)
+	var ids []string
+	for _, l := range pom.Licenses {
+		name, url := strings.TrimSpace(l.Name), strings.TrimSpace(l.URL)
+		if id, ok := LicenseID(name, url); ok {
+			ids = append(ids, id)
+			continue
+		}
+		if name == "" {
+			name = url
+		}
+		if name != "" {
+			d.Unknown = append(d.Unknown, name)
+		}
+	}
+	// Maven does not say how multiple licenses combine,
+	// but they are almost always alternatives.
+	d.License = orList(ids)
+	return d, nil
+}
+
+// orList returns an SPDX expression choosing among the licenses in list.
+func orList(list []string) string {
+	if len(list) > 1 {
+		for i, l := range list {
+			if strings.Contains(l, " ") {
+				list[i] = "(" + l + ")"
+			}
+		}
+	}
+	return strings.Join(list, " OR ")
+}
+
+func readCargoTOML(data []byte) (*Declaration, error) {
+	d := new(Declaration)
+	err := readKeys(data, func(section, key, value string) {
+		if section != "package" {
+			return
+		}
+		switch key {
+		case "license":
+			d.License = tomlString(value)
+		case "license-file":
+			d.Files = []string{tomlString(value)}
+		}
+	})
+	return d, err
+}
+
+func readPyprojectTOML(data []byte) (*Declaration, error) {
+	d := new(Declaration)
+	err := readKeys(data, func(section, key, value string) {
+		if key != "license" || section != "project" && section != "tool.poetry" {
+			return
+		}
+		if !strings.HasPrefix(value, "{") {
+			d.License = tomlString(value)
+			return
+		}
+		// PEP 621: license = {text = "MIT"} or license = {file = "LICENSE"}.
+		for _, kv := range strings.Split(strings.Trim(value, "{}"), ",") {
+			k, v, ok := strs.Cut(kv, "=")
+			if !ok {
+				continue
+			}
+			switch strings.TrimSpace(k) {
+			case "text":
+				d.License = tomlString(v)
+			case "file":
+				d.Files = []string{tomlString(v)}
+			}
+		}
+	})
+	return d, err
+}
+
+func readSetupCfg(data []byte) (*Declaration, error) {
+	d := new(Declaration)
+	err := readKeys(data, func(section, key, value string) {
+		if section != "metadata" {
+			return
+		}
+		switch key {
+		case "license":
+			d.License = value
+		case "license_file", "license_files", "license-file", "license-files":
+			d.Files = append(d.Files, strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' })...)
+		}
+	})
+	return d, err
+}
+
+// readKeys calls f for each key = value line in the TOML or INI data,
+// with the name of the enclosing [section].
+// A value continued on indented lines, as INI allows, is joined with newlines.
+// Comment lines, and the lines of TOML multi-line arrays and strings, are skipped.
+func readKeys(data []byte, f func(section, key, value string)) error {
+	sc := bufio.NewScanner(strings.NewReader(string(data)))
+	section := ""
+	var key, value string
+	flush := func() {
+		if key != "" {
+			f(section, key, value)
+		}
+		key, value = "", ""
+	}
+	for sc.Scan() {
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || trimmed[0] == '#' || trimmed[0] == ';':
+			continue
+		case line[0] == ' ' || line[0] == '\t':
+			if key != "" {
+				value = strings.TrimLeft(value+"\n"+trimmed, "\n")
+			}
+			continue
+		case trimmed[0] == '[':
+			flush()
+			section = strings.TrimSpace(strings.Trim(trimmed, "[]"))
+			continue
+		}
+		flush()
+		k, v, ok := strs.Cut(trimmed, "=")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(k), strings.TrimSpace(v)
+	}
+	flush()
+	return sc.Err()
+}
+
+// tomlString returns the value of the TOML string s,
+// which may be quoted with " or ' and followed by a comment.
+func tomlString(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > 0 && (s[0] == '"' || s[0] == '\'') {
+		if i := strings.IndexByte(s[1:], s[0]); i >= 0 {
+			return s[1 : 1+i]
+		}
+	}
+	if i := strings.Index(s, "#"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
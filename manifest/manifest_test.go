@@ -0,0 +1,169 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package manifest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+)
+
+var readTests = []struct {
+	file    string
+	data    string
+	license string
+	files   []string
+}{
+	{"package.json", `{"name": "x", "license": "MIT"}`, "MIT", nil},
+	{"package.json", `{"license": {"type": "ISC", "url": "https://example.com"}}`, "ISC", nil},
+	{"package.json", `{"licenses": [{"type": "MIT"}, {"type": "Apache-2.0"}]}`, "MIT OR Apache-2.0", nil},
+	{"package.json", `{"license": "SEE LICENSE IN EULA.txt"}`, "", []string{"EULA.txt"}},
+	{"composer.json", `{"license": "GPL-3.0-or-later"}`, "GPL-3.0-or-later", nil},
+	{"composer.json", `{"license": ["LGPL-2.1-only", "GPL-3.0-or-later"]}`, "LGPL-2.1-only OR GPL-3.0-or-later", nil},
+	{"Cargo.toml", `[package]
+name = "x"
+license = "MIT OR Apache-2.0" # dual
+license-file = 'LICENSE'
+
+[dependencies]
+license = "not this"
+`, "MIT OR Apache-2.0", []string{"LICENSE"}},
+	{"pyproject.toml", "[project]\nname = \"x\"\nlicense = {text = \"BSD-3-Clause\"}\n", "BSD-3-Clause", nil},
+	{"pyproject.toml", "[project]\nlicense = { file = \"LICENSE.txt\" }\n", "", []string{"LICENSE.txt"}},
+	{"pyproject.toml", "[tool.poetry]\nlicense = \"MIT\"\n", "MIT", nil},
+	{"setup.cfg", "[metadata]\nname = x\nlicense = Apache-2.0\nlicense_files =\n    LICENSE\n    NOTICE\n\n[options]\nlicense = no\n", "Apache-2.0", []string{"LICENSE", "NOTICE"}},
+	{"setup.cfg", "[options]\npackages = find:\n", "", nil},
+}
+
+func TestRead(t *testing.T) {
+	for _, tt := range readTests {
+		fsys := fstest.MapFS{"dir/" + tt.file: {Data: []byte(tt.data)}}
+		d, err := Read(fsys, "dir/"+tt.file)
+		if err != nil {
+			t.Errorf("Read(%s): %v", tt.data, err)
+			continue
+		}
+		if d.File != "dir/"+tt.file || d.License != tt.license || !reflect.DeepEqual(d.Files, tt.files) {
+			t.Errorf("Read(%s) = %+v, want License %q, Files %q", tt.data, d, tt.license, tt.files)
+		}
+	}
+
+	fsys := fstest.MapFS{"package.json": {Data: []byte("{")}, "x.json": {Data: []byte("{}")}}
+	if d, err := Read(fsys, "package.json"); err == nil {
+		t.Errorf("Read(bad JSON) = %+v, want error", d)
+	}
+	if d, err := Read(fsys, "x.json"); err == nil {
+		t.Errorf("Read(x.json) = %+v, want error", d)
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":                  {Data: []byte(`{"license": "MIT"}`)},
+		"node_modules/dep/package.json": {Data: []byte(`{"license": "GPL-3.0"}`)},
+		"rust/Cargo.toml":               {Data: []byte("[package]\nlicense = \"Apache-2.0\"\n")},
+		"py/setup.cfg":                  {Data: []byte("[options]\n")},
+		"README.md":                     {Data: []byte("MIT")},
+	}
+	list, err := ReadAll(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, d := range list {
+		files = append(files, d.File+"="+d.License)
+	}
+	if want := []string{"package.json=MIT", "rust/Cargo.toml=Apache-2.0"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("ReadAll = %v, want %v", files, want)
+	}
+}
+
+func TestUndeclared(t *testing.T) {
+	cov := licensecheck.Coverage{Match: []licensecheck.Match{
+		{ID: "MIT"},
+		{ID: "GPL-2.0"},
+		{ID: "Apache-2.0", IsURL: true},
+	}}
+	for _, tt := range []struct {
+		license string
+		want    []string
+	}{
+		{"MIT", []string{"GPL-2.0"}},
+		{"mit OR GPL-2.0-or-later", nil},
+		{"(MIT AND GPL-2.0+)", nil},
+		{"", nil},
+	} {
+		d := &Declaration{License: tt.license}
+		if ids := d.Undeclared(cov); !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("Undeclared(%q) = %v, want %v", tt.license, ids, tt.want)
+		}
+	}
+}
+
+const testPOM = `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <modelVersion>4.0.0</modelVersion>
+  <licenses>
+    <license>
+      <name>The Apache Software License, Version 2.0</name>
+      <url>http://www.apache.org/licenses/LICENSE-2.0.txt</url>
+      <distribution>repo</distribution>
+    </license>
+    <license>
+      <name>Eclipse Public License - v 1.0</name>
+    </license>
+    <license>
+      <name>Acme Corporate License</name>
+      <url>https://acme.example/license</url>
+    </license>
+  </licenses>
+</project>
+`
+
+func TestReadPOM(t *testing.T) {
+	d, err := Read(fstest.MapFS{"pom.xml": {Data: []byte(testPOM)}}, "pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.License != "Apache-2.0 OR EPL-1.0" || !reflect.DeepEqual(d.Unknown, []string{"Acme Corporate License"}) {
+		t.Errorf("Read(pom.xml) = %+v, want Apache-2.0 OR EPL-1.0 and unknown Acme", d)
+	}
+}
+
+func TestLicenseID(t *testing.T) {
+	for _, tt := range []struct {
+		name, url string
+		id        string
+	}{
+		{"", "https://www.apache.org/licenses/LICENSE-2.0", "Apache-2.0"},
+		{"anything", "http://opensource.org/licenses/MIT/", "MIT"},
+		{"mit", "", "MIT"},
+		{"Apache License, Version 2.0", "", "Apache-2.0"},
+		{"GPLv2", "", "GPL-2.0"},
+		{"GNU Lesser General Public License, version 2.1", "", "LGPL-2.1"},
+		{"Mozilla Public License Version 2.0", "", "MPL-2.0"},
+		{"BSD 3-clause New License", "", "BSD-3-Clause"},
+		{"Apache License 3.0", "", ""},
+		{"Acme License", "", ""},
+		{"", "", ""},
+	} {
+		id, ok := LicenseID(tt.name, tt.url)
+		if id != tt.id || ok != (tt.id != "") {
+			t.Errorf("LicenseID(%q, %q) = %q, %v, want %q", tt.name, tt.url, id, ok, tt.id)
+		}
+	}
+}
+
+func TestAliases(t *testing.T) {
+	nameTablesOnce.Do(initNameTables)
+	for alias, id := range aliases {
+		if spdxIDs[strings.ToLower(id)] != id {
+			t.Errorf("alias %q maps to unknown license %s", alias, id)
+		}
+	}
+}
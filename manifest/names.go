@@ -0,0 +1,281 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package manifest
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/google/licensecheck"
+)
+
+// LicenseID returns the SPDX ID of the license with the given name and URL,
+// as written in manifests like pom.xml that identify licenses informally,
+// such as "The Apache Software License, Version 2.0".
+// Either name or url may be empty.
+//
+// LicenseID tries, in order:
+// the URL, using the URLs listed with the built-in licenses
+// and licensecheck's URL matching;
+// the name as an SPDX ID;
+// the name as one of the built-in license names or common aliases;
+// and finally a fuzzy match of the name's words against those names,
+// which requires the same version numbers and most of the same words.
+// It reports false if none of these identifies a single license.
+func LicenseID(name, url string) (id string, ok bool) {
+	nameTablesOnce.Do(initNameTables)
+
+	if url != "" {
+		if id, ok := urlIDs[canonicalURL(url)]; ok {
+			return id, true
+		}
+		for _, m := range licensecheck.Scan([]byte(strings.TrimSpace(url))).Match {
+			if m.IsURL {
+				return m.ID, true
+			}
+		}
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", false
+	}
+	if id, ok := spdxIDs[strings.ToLower(name)]; ok {
+		return id, true
+	}
+	words := nameWords(name)
+	if len(words) == 0 {
+		return "", false
+	}
+	if id, ok := nameIDs[strings.Join(words, " ")]; ok {
+		return id, true
+	}
+	return fuzzyName(words)
+}
+
+// aliases maps license names that are common in manifests
+// but are not the full names of built-in licenses to SPDX IDs.
+// Names are compared after normalization (see nameWords),
+// so "Apache 2.0" also covers "Apache-2", "APACHE v2.0", and so on.
+var aliases = map[string]string{
+	"Apache 2.0": "Apache-2.0",
+	"The Apache Software License, Version 2.0": "Apache-2.0",
+	"ASL 2.0":                               "Apache-2.0",
+	"MIT":                                   "MIT",
+	"Expat":                                 "MIT",
+	"Bouncy Castle Licence":                 "MIT",
+	"New BSD License":                       "BSD-3-Clause",
+	"Revised BSD License":                   "BSD-3-Clause",
+	"Modified BSD License":                  "BSD-3-Clause",
+	"BSD 3-Clause":                          "BSD-3-Clause",
+	"Eclipse Distribution License 1.0":      "BSD-3-Clause",
+	"EDL 1.0":                               "BSD-3-Clause",
+	"Simplified BSD License":                "BSD-2-Clause",
+	"FreeBSD License":                       "BSD-2-Clause",
+	"BSD 2-Clause":                          "BSD-2-Clause",
+	"EPL 1.0":                               "EPL-1.0",
+	"Eclipse Public License 1.0":            "EPL-1.0",
+	"EPL 2.0":                               "EPL-2.0",
+	"Eclipse Public License 2.0":            "EPL-2.0",
+	"CDDL 1.0":                              "CDDL-1.0",
+	"CDDL 1.1":                              "CDDL-1.1",
+	"GPLv2":                                 "GPL-2.0",
+	"GNU General Public License v2":         "GPL-2.0",
+	"GPLv3":                                 "GPL-3.0",
+	"GNU General Public License v3":         "GPL-3.0",
+	"LGPL 2.1":                              "LGPL-2.1",
+	"GNU Lesser General Public License 2.1": "LGPL-2.1",
+	"LGPL 3.0":                              "LGPL-3.0",
+	"GNU Lesser General Public License 3":   "LGPL-3.0",
+	"GNU Affero General Public License 3":   "AGPL-3.0",
+	"MPL 1.1":                               "MPL-1.1",
+	"MPL 2.0":                               "MPL-2.0",
+	"CC0":                                   "CC0-1.0",
+	"CC0 1.0 Universal":                     "CC0-1.0",
+	"The Unlicense":                         "Unlicense",
+	"ISC":                                   "ISC",
+	"zlib":                                  "Zlib",
+}
+
+var (
+	nameTablesOnce sync.Once
+	spdxIDs        map[string]string // lower-case ID -> ID
+	nameIDs        map[string]string // normalized name -> ID
+	urlIDs         map[string]string // canonical URL -> ID
+	names          []nameID          // all of nameIDs, for fuzzy matching
+)
+
+type nameID struct {
+	words []string
+	id    string
+}
+
+// initNameTables builds the lookup tables from the built-in licenses.
+// The LRE of most built-in licenses begins with a comment
+// listing the license's full name and its URLs:
+//
+//	//**
+//	Apache License 2.0
+//	https://spdx.org/licenses/Apache-2.0.json
+//	http://www.apache.org/licenses/LICENSE-2.0
+//	**//
+func initNameTables() {
+	spdxIDs = make(map[string]string)
+	nameIDs = make(map[string]string)
+	urlIDs = make(map[string]string)
+	add := func(m map[string]string, key, id string) {
+		if _, ok := m[key]; !ok {
+			m[key] = id
+		}
+	}
+	for _, l := range licensecheck.BuiltinLicenses() {
+		if l.IsHeader {
+			continue
+		}
+		add(spdxIDs, strings.ToLower(l.ID), l.ID)
+		lre := strings.TrimSpace(l.LRE)
+		if !strings.HasPrefix(lre, "//**\n") {
+			continue
+		}
+		comment := lre[len("//**\n"):]
+		if i := strings.Index(comment, "**//"); i >= 0 {
+			comment = comment[:i]
+		}
+		for _, line := range strings.Split(comment, "\n") {
+			line = strings.TrimSpace(line)
+			switch {
+			case line == "":
+			case strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://"):
+				if !strings.Contains(line, "spdx.org/") {
+					add(urlIDs, canonicalURL(line), l.ID)
+				}
+			default:
+				if words := nameWords(line); len(words) > 0 {
+					add(nameIDs, strings.Join(words, " "), l.ID)
+				}
+			}
+		}
+	}
+	for alias, id := range aliases {
+		nameIDs[strings.Join(nameWords(alias), " ")] = id
+	}
+	for name, id := range nameIDs {
+		names = append(names, nameID{strings.Fields(name), id})
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i].id < names[j].id })
+}
+
+// canonicalURL returns url without its scheme, leading www.,
+// trailing slash, or .html, .htm, .php, or .txt suffix, in lower case.
+func canonicalURL(url string) string {
+	url = strings.ToLower(strings.TrimSpace(url))
+	url = strings.TrimPrefix(url, "http://")
+	url = strings.TrimPrefix(url, "https://")
+	url = strings.TrimPrefix(url, "www.")
+	url = strings.TrimSuffix(url, "/")
+	for _, ext := range []string{".html", ".htm", ".php", ".txt"} {
+		url = strings.TrimSuffix(url, ext)
+	}
+	return url
+}
+
+// stopWords are the words ignored in license names.
+var stopWords = map[string]bool{
+	"the":     true,
+	"license": true,
+	"licence": true,
+	"version": true,
+	"v":       true,
+	"only":    true,
+}
+
+// nameWords returns the normalized words of a license name:
+// runs of letters and runs of digits, in lower case,
+// without stop words or the zeros of version numbers like 2.0.
+func nameWords(name string) []string {
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		// Split GPLv2 into gpl v 2.
+		for w != "" {
+			i := strings.IndexFunc(w, unicode.IsDigit)
+			if i == 0 {
+				i = strings.IndexFunc(w, func(r rune) bool { return !unicode.IsDigit(r) })
+			}
+			if i < 0 {
+				i = len(w)
+			}
+			words = append(words, w[:i])
+			w = w[i:]
+		}
+	}
+	out := words[:0]
+	for i, w := range words {
+		if stopWords[w] || w == "0" && i > 0 && isNumber(words[i-1]) {
+			continue
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
+func isNumber(w string) bool {
+	return w != "" && strings.Trim(w, "0123456789") == ""
+}
+
+// fuzzyName returns the ID of the single known name most similar to words.
+// A candidate must have exactly the same numbers as words, in order,
+// and share at least two thirds of the two names' distinct words.
+func fuzzyName(words []string) (string, bool) {
+	nums := numbers(words)
+	best, bestID, tie := 0.0, "", false
+	for _, n := range names {
+		if numbers(n.words) != nums {
+			continue
+		}
+		s := similarity(words, n.words)
+		switch {
+		case s > best:
+			best, bestID, tie = s, n.id, false
+		case s == best && n.id != bestID:
+			tie = true
+		}
+	}
+	if best < 2.0/3 || tie {
+		return "", false
+	}
+	return bestID, true
+}
+
+func numbers(words []string) string {
+	var nums []string
+	for _, w := range words {
+		if isNumber(w) {
+			nums = append(nums, w)
+		}
+	}
+	return strings.Join(nums, " ")
+}
+
+// similarity returns the Jaccard similarity of the sets of words in a and b.
+func similarity(a, b []string) float64 {
+	set := make(map[string]int)
+	for _, w := range a {
+		set[w] |= 1
+	}
+	for _, w := range b {
+		set[w] |= 2
+	}
+	both := 0
+	for _, v := range set {
+		if v == 3 {
+			both++
+		}
+	}
+	return float64(both) / float64(len(set))
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"sort"
+
+	"github.com/google/licensecheck/internal/match"
+)
+
+// A Matcher finds license texts in a text, given the text's normalized words.
+// It is the part of a scan that can be replaced (see Options.Matcher),
+// so that other matching engines, such as automata or hash-based indexes,
+// can be tried and benchmarked against the Scanner's own.
+//
+// The Scanner itself is the default Matcher.
+// Whatever the Matcher, the Scanner finds license URLs,
+// extends each match to whole lines and to a preceding copyright notice,
+// fills in the metadata of each license, and computes Percent.
+//
+// A Matcher must be safe to call from multiple goroutines at once.
+type Matcher interface {
+	// MatchWords returns the matches found in text,
+	// whose words, split and normalized as for Coverage.Words, are words.
+	// The matches need not be sorted, but they should not overlap:
+	// of overlapping matches, the Scanner keeps the one beginning first,
+	// or the longest of those beginning at the same word.
+	// Matches of licenses the Scanner does not know,
+	// or with ranges outside words, are ignored.
+	// A match of a license with a standard header (see License.IsHeader)
+	// is taken to be of the license text, not the header.
+	MatchWords(text []byte, words []Word) []Candidate
+}
+
+// A Word is a normalized word of a text, as passed to a Matcher.
+type Word struct {
+	Text  string // normalized word, as in Coverage.Words
+	Start int    // byte offset of start of word in text
+	End   int    // byte offset of end of word in text
+}
+
+// A Candidate is a match found by a Matcher.
+type Candidate struct {
+	ID    string // ID of the license matched
+	Start int    // index in words of the first word matched
+	End   int    // index in words just past the last word matched
+}
+
+// MatchWords implements Matcher using the Scanner's license patterns,
+// with the leftmost-longest matching used when Options.Matcher is nil,
+// so that a Scanner can serve as the baseline for another Matcher.
+// It ignores words: the Scanner splits text itself,
+// so that it can allow for misspellings in the original text.
+func (s *Scanner) MatchWords(text []byte, words []Word) []Candidate {
+	s.initBuiltin()
+	matches := s.re.MatchBytesChoose(text, false, nil)
+	defer matches.Free()
+	var list []Candidate
+	for _, m := range matches.List {
+		list = append(list, Candidate{ID: s.licenses[m.ID].ID, Start: m.Start, End: m.End})
+	}
+	return list
+}
+
+// matchWith returns the matches that m finds in text,
+// in the form returned by s.re.MatchBytesChoose.
+func (s *Scanner) matchWith(m Matcher, text []byte) *match.Matches {
+	// Use a new dictionary, so that every word gets its normalized form,
+	// including words that no license uses.
+	d := s.newDict()
+	split := d.InsertSplit(string(text))
+	dict := d.Words()
+	words := make([]Word, len(split))
+	for i, w := range split {
+		words[i] = Word{Text: dict[w.ID], Start: int(w.Lo), End: int(w.Hi)}
+		split[i].ID = s.re.Dict().Lookup(dict[w.ID])
+	}
+
+	var list []match.Match
+	for _, c := range m.MatchWords(text, words) {
+		id := s.licenseIndex(c.ID)
+		if id < 0 || c.Start < 0 || c.End > len(words) || c.Start >= c.End {
+			continue
+		}
+		list = append(list, match.Match{ID: id, Start: c.Start, End: c.End})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Start != list[j].Start {
+			return list[i].Start < list[j].Start
+		}
+		return list[i].End > list[j].End
+	})
+	keep := list[:0]
+	end := 0
+	for _, lm := range list {
+		if lm.Start >= end {
+			keep = append(keep, lm)
+			end = lm.End
+		}
+	}
+	return &match.Matches{Words: split, List: keep}
+}
+
+// licenseIndex returns the index in s.licenses of the license with the given ID,
+// or -1 if there is none. If there is more than one, as for a license
+// with a standard header, it returns the first that is not a header.
+func (s *Scanner) licenseIndex(id string) int {
+	index := -1
+	for i, l := range s.licenses {
+		if l.ID == id {
+			if !l.IsHeader {
+				return i
+			}
+			if index < 0 {
+				index = i
+			}
+		}
+	}
+	return index
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"reflect"
+	"testing"
+)
+
+// A listMatcher is a Matcher returning a fixed list of candidates
+// and recording the words it was given.
+type listMatcher struct {
+	cands []Candidate
+	words []Word
+}
+
+func (m *listMatcher) MatchWords(text []byte, words []Word) []Candidate {
+	m.words = words
+	return m.cands
+}
+
+func TestMatcher(t *testing.T) {
+	text := []byte("one\nTWO three\nfour\nfive six\n")
+	m := &listMatcher{cands: []Candidate{
+		{ID: "MIT", Start: 2, End: 4},             // overlaps the next
+		{ID: "MIT", Start: 1, End: 3},             // kept
+		{ID: "MIT", Start: 1, End: 2},             // shorter than the one above
+		{ID: "Apache-2.0", Start: 0, End: 1},      // kept
+		{ID: "No-Such-License", Start: 3, End: 4}, // unknown license
+		{ID: "BSD-3-Clause", Start: 4, End: 9},    // out of range
+		{ID: "ISC", Start: 4, End: 6},             // kept
+	}}
+	c, err := ScanWithOptions(text, Options{Matcher: m})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := m.words; len(w) != 6 || w[1] != (Word{"two", 4, 7}) || w[5] != (Word{"six", 24, 27}) {
+		t.Errorf("Matcher given words %+v", w)
+	}
+	type span struct {
+		id         string
+		start, end int
+	}
+	var have []span
+	for _, m := range c.Match {
+		have = append(have, span{m.ID, m.Start, m.End})
+		if m.Type != licenseType(m.ID) {
+			t.Errorf("%s: Type=%v, want %v", m.ID, m.Type, licenseType(m.ID))
+		}
+	}
+	want := []span{{"Apache-2.0", 0, 4}, {"MIT", 4, 14}, {"ISC", 19, 28}}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("matches:\nhave %v\nwant %v", have, want)
+	}
+	if !matchPercent(c.Percent, 500.0/6) {
+		t.Errorf("Percent = %.1f, want %.1f", c.Percent, 500.0/6)
+	}
+}
+
+func TestScannerMatcher(t *testing.T) {
+	// The Scanner as Matcher finds the same matches as the Scanner alone.
+	mit, _ := Text("MIT")
+	apache, _ := Text("Apache-2.0")
+	for _, text := range []string{
+		mitText,
+		"Copyright 2020 The Go Authors\n\n" + mit + "\n\n" + apache,
+		"Licensed under https://www.apache.org/licenses/LICENSE-2.0.\n",
+		"nothing to see here",
+	} {
+		want, wantErr := ScanWithOptions([]byte(text), Options{})
+		have, err := ScanWithOptions([]byte(text), Options{Matcher: builtinScanner})
+		if err != wantErr || !reflect.DeepEqual(have.Match, want.Match) || have.Percent != want.Percent {
+			t.Errorf("ScanWithOptions(%.20q, Matcher: Scanner):\nhave %+v, %v\nwant %+v, %v", text, have, err, want, wantErr)
+		}
+	}
+}
+
+func BenchmarkMatcher(b *testing.B) {
+	// Compare the Scanner's own matching with the same matching
+	// run through the Matcher interface, as a baseline for other Matchers.
+	Scan(nil) // initialize built-in scanner
+	for _, in := range benchInputs(b) {
+		text := []byte(in.text)
+		for _, bm := range []struct {
+			name    string
+			matcher Matcher
+		}{
+			{"Default", nil},
+			{"Scanner", builtinScanner},
+		} {
+			opts := Options{Matcher: bm.matcher}
+			b.Run(in.name+"/"+bm.name, func(b *testing.B) {
+				b.SetBytes(int64(len(text)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					ScanWithOptions(text, opts)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/google/licensecheck/internal/match"
+)
+
+// A Deviation is a part of the text inside a match
+// that is not part of the matched license,
+// such as a paragraph inserted into the middle of it.
+// Deviations are reported only for matches found by Options.MergeGapWords.
+type Deviation struct {
+	Match int `json:"match"` // index of the match in Coverage.Match
+	Start int `json:"start"` // the inserted text is text[Start:End]
+	End   int `json:"end"`
+	Words int `json:"words"` // number of words inserted
+}
+
+// Thresholds for Options.MergeGapWords.
+const (
+	// mergeMinWords is the fewest words in an unmatched region
+	// for mergeGaps to consider it.
+	mergeMinWords = 20
+
+	// mergeMinLicensePercent is the smallest percentage of a license's words
+	// that must appear, in order, in a region for mergeGaps to match it.
+	// The rest may be in other matches, like the GPL's notice of how
+	// to apply it, or missing.
+	mergeMinLicensePercent = 75
+
+	// mergeMinPercent is the smallest percentage of the words
+	// in the part of the license from the first word matched to the last
+	// that must appear in the region.
+	mergeMinPercent = 90
+
+	// mergeSlopWords is the most unmatched words in a row
+	// that mergeGaps treats as small edits rather than a deviation.
+	mergeSlopWords = 5
+)
+
+// A merged is a match found by mergeGaps or approxGaps.
+type merged struct {
+	m     Match
+	devs  []Deviation // deviations, with Match unset
+	words int         // number of text words matched
+	score int         // words matched less license words skipped
+}
+
+// mergeGaps looks for licenses in the parts of text not covered by list,
+// which must be sorted by Start, allowing unmatched runs of up to maxGap words
+// inside a license (see Options.MergeGapWords).
+func (s *Scanner) mergeGaps(text []byte, list []Match, maxGap int) []merged {
+	d := s.newDict()
+	words := d.InsertSplit(string(text))
+	var found []merged
+	unmatched(words, list, func(lo, hi int) {
+		found = append(found, s.mergeRegion(d, text, words[lo:hi], lo == 0, hi == len(words), maxGap)...)
+	})
+	return found
+}
+
+// rescanGaps looks again at the parts of text not covered by list,
+// which must be sorted by Start, for the licenses that
+// opts.MergeGapWords and opts.ApproxPercent allow it to find.
+// It returns the matches found, sorted by Start.
+func (s *Scanner) rescanGaps(text []byte, list []Match, opts Options) []merged {
+	var found []merged
+	if opts.MergeGapWords > 0 {
+		found = s.mergeGaps(text, list, opts.MergeGapWords)
+	}
+	if opts.ApproxPercent > 0 {
+		if len(found) > 0 {
+			list = append([]Match(nil), list...)
+			for _, mg := range found {
+				list = append(list, mg.m)
+			}
+			sort.SliceStable(list, func(i, j int) bool { return list[i].Start < list[j].Start })
+		}
+		found = append(found, s.approxGaps(text, list, opts.ApproxPercent)...)
+		sort.SliceStable(found, func(i, j int) bool { return found[i].m.Start < found[j].m.Start })
+	}
+	return found
+}
+
+// unmatched calls f(lo, hi) for each unmatched region of words:
+// each maximal run words[lo:hi] of at least mergeMinWords words
+// outside the matches in list, which must be sorted by Start.
+func unmatched(words []match.Word, list []Match, f func(lo, hi int)) {
+	lo, k := 0, 0
+	for i := 0; i <= len(words); i++ {
+		inMatch := false
+		if i < len(words) {
+			for k < len(list) && list[k].End <= int(words[i].Lo) {
+				k++
+			}
+			inMatch = k < len(list) && list[k].Start <= int(words[i].Lo)
+		}
+		if i == len(words) || inMatch {
+			if i-lo >= mergeMinWords {
+				f(lo, i)
+			}
+			lo = i + 1
+		}
+	}
+}
+
+// mergeRegion looks for a license in the unmatched words of text,
+// which were inserted in d, allowing unmatched runs of up to maxGap words.
+// The words begin and end the text if initial and final are set.
+// Of the licenses most similar to the region, it chooses the one
+// that aligns with the most words, less the words of the license
+// that the alignment skips. Otherwise a license like JSON,
+// which is MIT with a sentence added, would win over MIT itself.
+func (s *Scanner) mergeRegion(d *match.Dict, text []byte, words []match.Word, initial, final bool, maxGap int) []merged {
+	region := text[words[0].Lo:words[len(words)-1].Hi]
+	var best *merged
+	for _, sug := range s.Nearest(region, 3) {
+		if sug.Similarity.BPercent < mergeMinLicensePercent {
+			continue
+		}
+		if mg := s.mergeLicense(d, text, words, sug.ID, maxGap); mg != nil && (best == nil || mg.score > best.score) {
+			best = mg
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	extendLines(&best.m, text, words, initial, final)
+	return []merged{*best}
+}
+
+// extendLines extends m, which spans some of words, to whole lines of text,
+// as scanWindow does. The words begin and end the text
+// if initial and final are set.
+func extendLines(m *Match, text []byte, words []match.Word, initial, final bool) {
+	start, end := m.Start, m.End
+	if initial && start == int(words[0].Lo) {
+		start = 0
+		if bytes.HasPrefix(text, utf8BOM) {
+			start = len(utf8BOM)
+		}
+	} else if i := bytes.LastIndexAny(text[:start], "\r\n"); i >= 0 && len(bytes.TrimSpace(text[i+1:start])) == 0 {
+		start = i + 1
+	}
+	if final && end == int(words[len(words)-1].Hi) {
+		end = len(text)
+	} else if i := bytes.IndexAny(text[end:], "\r\n"); i >= 0 && len(bytes.TrimSpace(text[end:end+i])) == 0 {
+		end += i + 1
+		if text[end-1] == '\r' && end < len(text) && text[end] == '\n' {
+			end++
+		}
+	}
+	m.Start, m.End = start, end
+}
+
+// mergeLicense aligns the words of text, which were inserted in d,
+// with the license id, allowing unmatched runs of up to maxGap words.
+// It returns the match, with offsets spanning the aligned words,
+// or nil if the license does not align well enough.
+func (s *Scanner) mergeLicense(d *match.Dict, text []byte, words []match.Word, id string, maxGap int) *merged {
+	l, ok := s.license(id)
+	if !ok {
+		return nil
+	}
+	ltext, ok := s.Text(id)
+	if !ok {
+		return nil
+	}
+	lwords := d.InsertSplit(ltext)
+	lw := wordIDsOf(lwords)
+	pairs := diffWords(lw, wordIDsOf(words), 0, 0, nil)
+	if len(pairs) == 0 || 100*len(pairs) < mergeMinLicensePercent*len(lw) {
+		return nil
+	}
+	// The part of the license the text covers must be nearly all there.
+	first, last := pairs[0], pairs[len(pairs)-1]
+	if 100*len(pairs) < mergeMinPercent*(last[0]-first[0]+1) {
+		return nil
+	}
+
+	mg := &merged{words: len(pairs), score: 2*len(pairs) - (last[0] - first[0] + 1)}
+	for i := 1; i < len(pairs); i++ {
+		gap := pairs[i][1] - pairs[i-1][1] - 1
+		if gap > maxGap {
+			return nil
+		}
+		if gap > mergeSlopWords {
+			lo, hi := pairs[i-1][1]+1, pairs[i][1]
+			mg.devs = append(mg.devs, Deviation{
+				Start: int(words[lo].Lo),
+				End:   int(words[hi-1].Hi),
+				Words: gap,
+			})
+		}
+	}
+	mg.m = l.match()
+	mg.m.Start, mg.m.End = int(words[first[1]].Lo), int(words[last[1]].Hi)
+	mg.m.LicenseStart, mg.m.LicenseEnd = int(lwords[first[0]].Lo), int(lwords[last[0]].Hi)
+	mg.m.MatchedWords, mg.m.LicenseWords = len(pairs), len(lw)
+	return mg
+}
+
+// addMerged adds the matches found by rescanGaps to c.Match,
+// keeping it sorted by Start, and records their deviations.
+func addMerged(c *Coverage, found []merged) {
+	for _, mg := range found {
+		for _, dev := range mg.devs {
+			dev.Match = len(c.Match)
+			c.Deviations = append(c.Deviations, dev)
+		}
+		c.Match = append(c.Match, mg.m)
+	}
+	c.Sort()
+}
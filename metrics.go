@@ -0,0 +1,32 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import "time"
+
+// A Metrics receives a ScanEvent for each scan done with Options naming it,
+// so that services scanning many texts can monitor their work.
+// The prometheus package implements Metrics by exporting
+// scan counts, durations, and results as Prometheus metrics.
+//
+// Observe is called after each scan, in the scanning goroutine,
+// so it must be fast and safe to call concurrently.
+type Metrics interface {
+	Observe(ScanEvent)
+}
+
+// A ScanEvent describes one scan by ScanWithOptions, ScanFile, or ScanFS.
+type ScanEvent struct {
+	Bytes    int           // length of the scanned text
+	Duration time.Duration // time spent scanning; zero if Cached
+	Coverage Coverage      // result of the scan
+	Err      error         // error from the scan, such as ErrNoMatch
+
+	// Cached reports that the result was not computed
+	// but reused from an earlier scan of the same text,
+	// as ScanFS does for duplicate files (see FileCoverage.DuplicateOf).
+	// Coverage is then the result of the earlier scan.
+	Cached bool
+}
@@ -0,0 +1,36 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+type eventLog []ScanEvent
+
+func (l *eventLog) Observe(e ScanEvent) { *l = append(*l, e) }
+
+func TestMetrics(t *testing.T) {
+	var log eventLog
+	fsys := fstest.MapFS{
+		"LICENSE":   {Data: []byte(mitText)},
+		"a/LICENSE": {Data: []byte(mitText)},
+	}
+	if _, err := ScanFS(fsys, []string{"LICENSE", "a/LICENSE"}, Options{Metrics: &log}); err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 2 {
+		t.Fatalf("ScanFS reported %d events, want 2", len(log))
+	}
+	for i, e := range log {
+		if e.Bytes != len(mitText) || e.Err != nil || len(e.Coverage.Match) != 1 || e.Coverage.Match[0].ID != "MIT" || e.Cached != (i == 1) {
+			t.Errorf("event %d = %+v, want MIT with Cached=%v", i, e, i == 1)
+		}
+	}
+	if log[0].Duration <= 0 || log[1].Duration != 0 {
+		t.Errorf("durations %v, %v, want positive, zero", log[0].Duration, log[1].Duration)
+	}
+}
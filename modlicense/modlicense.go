@@ -0,0 +1,310 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package modlicense decides whether a Go module and its packages
+// are redistributable, using rules modeled on those pkg.go.dev uses
+// to decide whether it may show a module's documentation and source.
+// Other module proxies and registries can use it to make the same decisions.
+//
+// The rules are:
+//
+//  - Only files with the names of license files (see IsLicenseFile) count.
+//  - A license file is redistributable if it is no larger than MaxLicenseBytes,
+//    licenses cover at least CoverageThreshold percent of it,
+//    and every license found in it is redistributable (see Redistributable).
+//  - A module is redistributable if it has at least one license file
+//    in its root directory and all of them are redistributable.
+//  - A package is redistributable if its module is redistributable
+//    and so are all the license files in the package's directory
+//    and the directories between it and the module root.
+//
+// A Detector applies the rules to the files of a module,
+// such as the contents of a module zip file:
+//
+//	zr, err := zip.OpenReader(file)
+//	...
+//	sub, err := fs.Sub(zr, modulePath+"@"+version)
+//	...
+//	d, err := modlicense.NewDetector(sub)
+//	...
+//	if d.ModuleIsRedistributable() {
+//		...
+//	}
+package modlicense
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/google/licensecheck"
+)
+
+// CoverageThreshold is the smallest percentage of a license file
+// that licenses must cover for the file to be redistributable.
+const CoverageThreshold = 75
+
+// MaxLicenseBytes is the size of the largest license file that is scanned.
+// Larger files are never redistributable.
+const MaxLicenseBytes = 1 << 20
+
+// licenseFileNames are the lower-case names of license files.
+var licenseFileNames = map[string]bool{
+	"copying":                true,
+	"copying.md":             true,
+	"copying.markdown":       true,
+	"copying.txt":            true,
+	"licence":                true,
+	"licence.md":             true,
+	"licence.markdown":       true,
+	"licence.txt":            true,
+	"license":                true,
+	"license.md":             true,
+	"license.markdown":       true,
+	"license.txt":            true,
+	"license-2.0.txt":        true,
+	"licence-2.0.txt":        true,
+	"license-apache":         true,
+	"licence-apache":         true,
+	"license-apache-2.0.txt": true,
+	"licence-apache-2.0.txt": true,
+	"license-mit":            true,
+	"licence-mit":            true,
+	"license.mit":            true,
+	"licence.mit":            true,
+	"license.code":           true,
+	"licence.code":           true,
+	"license.docs":           true,
+	"licence.docs":           true,
+	"license.rst":            true,
+	"licence.rst":            true,
+	"mit-license":            true,
+	"mit-licence":            true,
+	"mit-license.md":         true,
+	"mit-licence.md":         true,
+	"mit-license.markdown":   true,
+	"mit-licence.markdown":   true,
+	"mit-license.txt":        true,
+	"mit-licence.txt":        true,
+	"mit_license":            true,
+	"mit_licence":            true,
+	"unlicense":              true,
+	"unlicence":              true,
+}
+
+// IsLicenseFile reports whether the file with the given base name,
+// such as "LICENSE" or "COPYING.md", is a license file.
+// Names are compared without regard to case.
+func IsLicenseFile(name string) bool {
+	return licenseFileNames[strings.ToLower(name)]
+}
+
+// redistributable lists the IDs of the redistributable licenses.
+var redistributable = map[string]bool{
+	"0BSD":                true,
+	"AFL-3.0":             true,
+	"AGPL-3.0":            true,
+	"Apache-1.1":          true,
+	"Apache-2.0":          true,
+	"Artistic-2.0":        true,
+	"BlueOak-1.0.0":       true,
+	"BSD-1-Clause":        true,
+	"BSD-2-Clause":        true,
+	"BSD-2-Clause-Patent": true,
+	"BSD-2-Clause-Views":  true,
+	"BSD-3-Clause":        true,
+	"BSD-3-Clause-Clear":  true,
+	"BSD-4-Clause":        true,
+	"BSL-1.0":             true,
+	"CC-BY-3.0":           true,
+	"CC-BY-4.0":           true,
+	"CC-BY-SA-3.0":        true,
+	"CC-BY-SA-4.0":        true,
+	"CC0-1.0":             true,
+	"CDDL-1.0":            true,
+	"CDDL-1.1":            true,
+	"EPL-1.0":             true,
+	"EPL-2.0":             true,
+	"EUPL-1.2":            true,
+	"GPL-2.0":             true,
+	"GPL-3.0":             true,
+	"ISC":                 true,
+	"LGPL-2.1":            true,
+	"LGPL-3.0":            true,
+	"MIT":                 true,
+	"MIT-0":               true,
+	"MPL-2.0":             true,
+	"NCSA":                true,
+	"OFL-1.1":             true,
+	"OpenSSL":             true,
+	"PostgreSQL":          true,
+	"Unicode-DFS-2016":    true,
+	"Unlicense":           true,
+	"UPL-1.0":             true,
+	"X11":                 true,
+	"Zlib":                true,
+}
+
+// Redistributable reports whether the license with the given ID
+// allows redistribution of the module's documentation and source.
+// The -only and -or-later forms of an ID are redistributable
+// if the ID is.
+func Redistributable(id string) bool {
+	id = strings.TrimSuffix(id, "-only")
+	id = strings.TrimSuffix(id, "-or-later")
+	return redistributable[id]
+}
+
+// A License is a license file in a module.
+type License struct {
+	File     string                // path of the file in the module, such as "LICENSE" or "sub/COPYING"
+	Coverage licensecheck.Coverage // result of scanning the file
+	IDs      []string              // IDs of the licenses found, not counting URLs, without duplicates
+
+	// Redistributable reports whether the license file
+	// is redistributable (see the package documentation).
+	Redistributable bool
+
+	// Err is the error from scanning the file, if any,
+	// such as its being larger than MaxLicenseBytes.
+	Err error
+}
+
+// A Detector determines the licenses of a module and its packages.
+type Detector struct {
+	licenses []*License
+	dirs     map[string][]*License // directory -> licenses in it
+}
+
+// NewDetector returns a Detector for the module whose files are in fsys,
+// with the module root at the root of fsys.
+// It scans all the license files in the module,
+// skipping vendor directories and nested modules,
+// that is, directories other than the root that contain a go.mod file.
+// It returns an error only if the files cannot be read.
+func NewDetector(fsys fs.FS) (*Detector, error) {
+	d := &Detector{dirs: make(map[string][]*License)}
+	err := fs.WalkDir(fsys, ".", func(file string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if de.IsDir() {
+			if file == "." {
+				return nil
+			}
+			if de.Name() == "vendor" {
+				return fs.SkipDir
+			}
+			if _, err := fs.Stat(fsys, path.Join(file, "go.mod")); err == nil {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !de.Type().IsRegular() || !IsLicenseFile(de.Name()) {
+			return nil
+		}
+		l, err := scan(fsys, file, de)
+		if err != nil {
+			return err
+		}
+		d.licenses = append(d.licenses, l)
+		dir := path.Dir(file)
+		d.dirs[dir] = append(d.dirs[dir], l)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("modlicense: %v", err)
+	}
+	return d, nil
+}
+
+// scan scans the license file.
+func scan(fsys fs.FS, file string, de fs.DirEntry) (*License, error) {
+	l := &License{File: file}
+	info, err := de.Info()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > MaxLicenseBytes {
+		l.Err = fmt.Errorf("%w: %d bytes exceeds limit of %d", licensecheck.ErrInputTooLarge, info.Size(), MaxLicenseBytes)
+		return l, nil
+	}
+	text, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	l.Coverage, l.Err = licensecheck.ScanWithOptions(text, licensecheck.Options{MaxInputBytes: MaxLicenseBytes})
+	if errors.Is(l.Err, licensecheck.ErrNoMatch) || errors.Is(l.Err, licensecheck.ErrEmptyInput) {
+		l.Err = nil
+	}
+
+	seen := make(map[string]bool)
+	for _, m := range l.Coverage.Match {
+		if !m.IsURL && !seen[m.ID] {
+			seen[m.ID] = true
+			l.IDs = append(l.IDs, m.ID)
+		}
+	}
+	l.Redistributable = l.Err == nil && len(l.IDs) > 0 && l.Coverage.Percent >= CoverageThreshold
+	for _, id := range l.IDs {
+		if !Redistributable(id) {
+			l.Redistributable = false
+		}
+	}
+	return l, nil
+}
+
+// Licenses returns all the license files in the module, in path order.
+func (d *Detector) Licenses() []*License {
+	return d.licenses
+}
+
+// ModuleLicenses returns the license files in the module root.
+func (d *Detector) ModuleLicenses() []*License {
+	return d.dirs["."]
+}
+
+// ModuleIsRedistributable reports whether the module is redistributable:
+// it must have at least one license file in its root,
+// and all of them must be redistributable.
+func (d *Detector) ModuleIsRedistributable() bool {
+	return len(d.dirs["."]) > 0 && allRedistributable(d.dirs["."])
+}
+
+// PackageLicenses returns the license files that apply to the package
+// in the directory dir, a slash-separated path relative to the module root:
+// those in dir and in each directory between it and the module root,
+// starting with the module root.
+func (d *Detector) PackageLicenses(dir string) []*License {
+	dir = path.Clean(dir)
+	var dirs []string
+	for ; dir != "." && dir != "/" && !strings.HasPrefix(dir, "../"); dir = path.Dir(dir) {
+		dirs = append(dirs, dir)
+	}
+	dirs = append(dirs, ".")
+	var list []*License
+	for i := len(dirs) - 1; i >= 0; i-- {
+		list = append(list, d.dirs[dirs[i]]...)
+	}
+	return list
+}
+
+// PackageIsRedistributable reports whether the package in the directory dir,
+// a slash-separated path relative to the module root, is redistributable:
+// the module must be redistributable,
+// and so must all the license files returned by PackageLicenses.
+func (d *Detector) PackageIsRedistributable(dir string) bool {
+	return d.ModuleIsRedistributable() && allRedistributable(d.PackageLicenses(dir))
+}
+
+func allRedistributable(list []*License) bool {
+	for _, l := range list {
+		if !l.Redistributable {
+			return false
+		}
+	}
+	return true
+}
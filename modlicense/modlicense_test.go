@@ -0,0 +1,122 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modlicense
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+)
+
+func TestDetector(t *testing.T) {
+	text := func(id string) *fstest.MapFile {
+		s, ok := licensecheck.Text(id)
+		if !ok {
+			// Not in a build profile that embeds only some licenses.
+			t.Skipf("no text for %s", id)
+		}
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	mit := text("MIT")
+	fsys := fstest.MapFS{
+		"go.mod":              {Data: []byte("module example.com/m\n")},
+		"LICENSE":             mit,
+		"COPYING.md":          text("Apache-2.0"),
+		"README.md":           {Data: []byte("See LICENSE.\n")},
+		"a/a.go":              {Data: []byte("package a\n")},
+		"b/LICENSE.txt":       text("BSD-3-Clause"),
+		"b/c/c.go":            {Data: []byte("package c\n")},
+		"nc/LICENSE":          text("CC-BY-NC-4.0"),
+		"nc/nc.go":            {Data: []byte("package nc\n")},
+		"short/license":       {Data: []byte(mit.Data[:len(mit.Data)/2])},
+		"big/LICENSE":         {Data: []byte(strings.Repeat("x ", MaxLicenseBytes))},
+		"vendor/x/LICENSE":    text("CC-BY-NC-4.0"),
+		"nested/go.mod":       {Data: []byte("module example.com/m/nested\n")},
+		"nested/LICENSE":      text("CC-BY-NC-4.0"),
+		"prose/LICENSE":       {Data: append([]byte("This is my own license, which you should read very carefully, since it is long.\n"+strings.Repeat("Words words words. ", 100)), mit.Data...)},
+		"unknown/LICENSE.rst": {Data: []byte("All rights reserved.\n")},
+	}
+	d, err := NewDetector(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var files []string
+	redist := make(map[string]bool)
+	for _, l := range d.Licenses() {
+		files = append(files, l.File)
+		redist[l.File] = l.Redistributable
+	}
+	wantFiles := []string{"COPYING.md", "LICENSE", "b/LICENSE.txt", "big/LICENSE", "nc/LICENSE", "prose/LICENSE", "short/license", "unknown/LICENSE.rst"}
+	if !reflect.DeepEqual(files, wantFiles) {
+		t.Errorf("Licenses = %q, want %q", files, wantFiles)
+	}
+	wantRedist := map[string]bool{
+		"COPYING.md":          true,
+		"LICENSE":             true,
+		"b/LICENSE.txt":       true,
+		"big/LICENSE":         false,
+		"nc/LICENSE":          false,
+		"prose/LICENSE":       false,
+		"short/license":       false,
+		"unknown/LICENSE.rst": false,
+	}
+	if !reflect.DeepEqual(redist, wantRedist) {
+		t.Errorf("Redistributable = %v, want %v", redist, wantRedist)
+	}
+
+	if len(d.ModuleLicenses()) != 2 || !d.ModuleIsRedistributable() {
+		t.Errorf("ModuleIsRedistributable = false with %d licenses, want true with 2", len(d.ModuleLicenses()))
+	}
+	for dir, want := range map[string]bool{
+		".":       true,
+		"a":       true,
+		"b/c":     true,
+		"nc":      false,
+		"nc/sub":  false,
+		"prose":   false,
+		"unknown": false,
+	} {
+		if got := d.PackageIsRedistributable(dir); got != want {
+			t.Errorf("PackageIsRedistributable(%q) = %v, want %v", dir, got, want)
+		}
+	}
+	var pkgFiles []string
+	for _, l := range d.PackageLicenses("b/c") {
+		pkgFiles = append(pkgFiles, l.File)
+	}
+	if want := []string{"COPYING.md", "LICENSE", "b/LICENSE.txt"}; !reflect.DeepEqual(pkgFiles, want) {
+		t.Errorf("PackageLicenses(b/c) = %q, want %q", pkgFiles, want)
+	}
+
+	// A module without a license in its root is not redistributable.
+	delete(fsys, "LICENSE")
+	delete(fsys, "COPYING.md")
+	if d, err := NewDetector(fsys); err != nil || d.ModuleIsRedistributable() || d.PackageIsRedistributable("b") {
+		t.Errorf("without root license: ModuleIsRedistributable or PackageIsRedistributable(b) = true, want false")
+	}
+}
+
+func TestIsLicenseFile(t *testing.T) {
+	for name, want := range map[string]bool{
+		"LICENSE":      true,
+		"license.md":   true,
+		"COPYING":      true,
+		"MIT-LICENSE":  true,
+		"UNLICENSE":    true,
+		"LICENSE.html": false,
+		"README":       false,
+	} {
+		if got := IsLicenseFile(name); got != want {
+			t.Errorf("IsLicenseFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if !Redistributable("GPL-2.0-or-later") || Redistributable("CC-BY-NC-4.0") {
+		t.Errorf("Redistributable(GPL-2.0-or-later), Redistributable(CC-BY-NC-4.0) wrong")
+	}
+}
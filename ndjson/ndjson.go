@@ -0,0 +1,100 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ndjson writes scan results as newline-delimited JSON,
+// one Record per line, as each file is scanned.
+//
+// Encoding the results of a scan of millions of files as a single JSON value
+// means holding them all in memory first. A Writer instead emits each result
+// when it is produced, so memory use does not grow with the number of files,
+// and a slow consumer of the output slows the scan rather than filling memory.
+// It fits the callback of licensecheck.ScanFSFunc:
+//
+//	w := ndjson.NewWriter(os.Stdout)
+//	err := licensecheck.ScanFSFunc(fsys, files, opts, w.Write)
+//
+// or drains a channel filled by concurrent scanners:
+//
+//	results := make(chan licensecheck.FileCoverage)
+//	go func() {
+//		defer close(results)
+//		for _, file := range files {
+//			...
+//			results <- licensecheck.FileCoverage{File: file, Coverage: cov, Err: err}
+//		}
+//	}()
+//	err := ndjson.NewWriter(os.Stdout).WriteAll(results)
+//
+// Each output line can be decoded into a Record.
+package ndjson
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/google/licensecheck"
+)
+
+// A Record is a single line of output: the result of scanning one file.
+type Record struct {
+	File     string
+	Coverage licensecheck.Coverage
+
+	// Error is the error from scanning the file, if any,
+	// such as the file being larger than Options.MaxInputBytes.
+	// Finding no license is not an error.
+	Error string `json:",omitempty"`
+
+	// DuplicateOf is copied from licensecheck.FileCoverage.
+	DuplicateOf string `json:",omitempty"`
+}
+
+// A Writer writes results to an underlying writer, one Record per line.
+// It is safe to call the methods of a Writer from multiple goroutines;
+// each line is written with a single call to the underlying writer,
+// so lines are never interleaved.
+type Writer struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewWriter returns a Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{enc: json.NewEncoder(w)}
+}
+
+// Write writes fc as a single line.
+// After the underlying writer returns an error,
+// Write does nothing and returns that error.
+func (w *Writer) Write(fc licensecheck.FileCoverage) error {
+	r := Record{File: fc.File, Coverage: fc.Coverage, DuplicateOf: fc.DuplicateOf}
+	if fc.Err != nil && !errors.Is(fc.Err, licensecheck.ErrNoMatch) && !errors.Is(fc.Err, licensecheck.ErrEmptyInput) {
+		r.Error = fc.Err.Error()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = w.enc.Encode(&r)
+	}
+	return w.err
+}
+
+// WriteAll writes each result received from c until c is closed.
+// It receives the next result only after writing the last one,
+// so senders on c proceed no faster than the underlying writer.
+// If writing fails, WriteAll keeps receiving from c until it is closed,
+// so that senders are not blocked forever, and then returns the error.
+func (w *Writer) WriteAll(c <-chan licensecheck.FileCoverage) error {
+	var err error
+	for fc := range c {
+		if err == nil {
+			err = w.Write(fc)
+		}
+	}
+	return err
+}
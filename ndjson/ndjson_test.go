@@ -0,0 +1,102 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ndjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+)
+
+func TestWriter(t *testing.T) {
+	mit, _ := licensecheck.Text("MIT")
+	fsys := fstest.MapFS{
+		"LICENSE":   {Data: []byte(mit)},
+		"a/LICENSE": {Data: []byte(mit)},
+		"README":    {Data: []byte("hello\n")},
+		"big":       {Data: bytes.Repeat([]byte("x "), 10000)},
+	}
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	files := []string{"LICENSE", "a/LICENSE", "README", "big"}
+	if err := licensecheck.ScanFSFunc(fsys, files, licensecheck.Options{MaxInputBytes: 10000}, w.Write); err != nil {
+		t.Fatal(err)
+	}
+
+	var recs []Record
+	s := bufio.NewScanner(&buf)
+	for s.Scan() {
+		var r Record
+		if err := json.Unmarshal(s.Bytes(), &r); err != nil {
+			t.Fatalf("line %d: %v\n%s", len(recs)+1, err, s.Bytes())
+		}
+		recs = append(recs, r)
+	}
+	if len(recs) != len(files) {
+		t.Fatalf("got %d lines, want %d", len(recs), len(files))
+	}
+	for i, r := range recs {
+		if r.File != files[i] {
+			t.Errorf("line %d: File=%q, want %q", i+1, r.File, files[i])
+		}
+	}
+	if m := recs[0].Coverage.Match; len(m) != 1 || m[0].ID != "MIT" || recs[0].Error != "" {
+		t.Errorf("LICENSE: %+v", recs[0])
+	}
+	if recs[1].DuplicateOf != "LICENSE" {
+		t.Errorf("a/LICENSE: DuplicateOf=%q, want LICENSE", recs[1].DuplicateOf)
+	}
+	if recs[2].Error != "" {
+		t.Errorf("README: Error=%q, want none", recs[2].Error)
+	}
+	if recs[3].Error == "" {
+		t.Errorf("big: no Error, want input too large")
+	}
+}
+
+type failWriter struct{ n int }
+
+func (w *failWriter) Write(b []byte) (int, error) {
+	if w.n == 0 {
+		return 0, errors.New("disk full")
+	}
+	w.n--
+	return len(b), nil
+}
+
+func TestWriteAll(t *testing.T) {
+	c := make(chan licensecheck.FileCoverage)
+	go func() {
+		defer close(c)
+		for i := 0; i < 10; i++ {
+			c <- licensecheck.FileCoverage{File: fmt.Sprint(i)}
+		}
+	}()
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).WriteAll(c); err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(buf.Bytes(), []byte("\n")); n != 10 {
+		t.Errorf("WriteAll wrote %d lines, want 10", n)
+	}
+
+	// After an error, WriteAll still drains the channel.
+	c = make(chan licensecheck.FileCoverage)
+	go func() {
+		defer close(c)
+		for i := 0; i < 10; i++ {
+			c <- licensecheck.FileCoverage{File: fmt.Sprint(i)}
+		}
+	}()
+	if err := NewWriter(&failWriter{n: 3}).WriteAll(c); err == nil || err.Error() != "disk full" {
+		t.Errorf("WriteAll = %v, want disk full", err)
+	}
+}
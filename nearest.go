@@ -0,0 +1,125 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"sort"
+)
+
+// A Suggestion is a known license that is similar to a text, found by Nearest.
+type Suggestion struct {
+	ID string // license ID
+
+	// Percent is the percentage of the words of the text and the license,
+	// taken together, that the two have in common, in order:
+	// 100 for the same text, and typically below 50 for unrelated texts.
+	Percent float64
+
+	// Similarity is the result of comparing the text (A) with the
+	// license's canonical text (B), as reported by Compare.
+	Similarity Similarity
+}
+
+// nearestMinHashes is the MinHash signature length used by Nearest.
+const nearestMinHashes = 64
+
+// Nearest returns the n known licenses whose canonical texts are most similar
+// to text, most similar first.
+// It is meant for texts in which Scan finds no license,
+// to give a person classifying the text a place to start,
+// as in "72% similar to BSD-3-Clause".
+//
+// Only licenses whose canonical text is available (see Text) are considered.
+// Nearest uses MinHash signatures to choose a few candidates
+// and then compares the text with each candidate's canonical text using Compare,
+// so it may miss a license that is similar word by word but not phrase by phrase.
+func Nearest(text []byte, n int) []Suggestion {
+	return builtinScanner.Nearest(text, n)
+}
+
+// Nearest is like the top-level function Nearest,
+// but it uses the set of licenses in the Scanner instead of the built-in license set.
+func (s *Scanner) Nearest(text []byte, n int) []Suggestion {
+	s.initBuiltin()
+	s.nearestOnce.Do(s.initNearest)
+	if n <= 0 {
+		return nil
+	}
+
+	// Rank all licenses by MinHash similarity
+	// and compare the text with the best few.
+	sig := MinHash(text, nearestMinHashes)
+	type candidate struct {
+		l   *nearestLicense
+		sim float64
+	}
+	var cands []candidate
+	for i := range s.nearest {
+		l := &s.nearest[i]
+		cands = append(cands, candidate{l, MinHashSimilarity(sig, l.sig)})
+	}
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].sim > cands[j].sim })
+	if max := 3*n + 5; len(cands) > max {
+		cands = cands[:max]
+	}
+
+	var list []Suggestion
+	for _, c := range cands {
+		sim := Compare(text, []byte(c.l.text))
+		list = append(list, Suggestion{ID: c.l.id, Percent: sim.percent(), Similarity: sim})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Percent != list[j].Percent {
+			return list[i].Percent > list[j].Percent
+		}
+		return list[i].ID < list[j].ID
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// A nearestLicense is a license considered by Nearest.
+type nearestLicense struct {
+	id   string
+	text string
+	sig  []uint64 // MinHash signature of text
+}
+
+// initNearest initializes s.nearest with the licenses
+// whose canonical texts are available, in ID order.
+func (s *Scanner) initNearest() {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, l := range s.licenses {
+		if !seen[l.ID] {
+			seen[l.ID] = true
+			ids = append(ids, l.ID)
+		}
+	}
+	for _, l := range s.urls {
+		if !seen[l.ID] {
+			seen[l.ID] = true
+			ids = append(ids, l.ID)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if text, ok := s.Text(id); ok {
+			s.nearest = append(s.nearest, nearestLicense{id, text, MinHash([]byte(text), nearestMinHashes)})
+		}
+	}
+}
+
+// percent returns the percentage of the words of both texts,
+// taken together, that the texts have in common, as in Suggestion.Percent.
+func (sim Similarity) percent() float64 {
+	common := sim.APercent * float64(sim.AWords) / 100
+	if total := sim.AWords + sim.BWords; total > 0 {
+		return 100 * 2 * common / float64(total)
+	}
+	return 0
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNearest(t *testing.T) {
+	bsd, ok := Text("BSD-3-Clause")
+	if !ok {
+		t.Fatal("no text for BSD-3-Clause")
+	}
+	// Reword the license enough that Scan no longer recognizes it.
+	text := strings.NewReplacer(
+		"Redistribution and use", "Sharing and using",
+		"are permitted provided that", "is fine as long as",
+		"Neither the name of the copyright holder nor the names of its", "Do not use our name or the names of our",
+		"IN NO EVENT", "UNDER NO CIRCUMSTANCES",
+	).Replace(bsd)
+	if cov := Scan([]byte(text)); len(cov.Match) > 0 {
+		t.Fatalf("Scan(reworded BSD-3-Clause) = %+v, want no match", cov)
+	}
+
+	list := Nearest([]byte(text), 3)
+	if len(list) != 3 {
+		t.Fatalf("Nearest returned %d suggestions, want 3", len(list))
+	}
+	if list[0].ID != "BSD-3-Clause" || list[0].Percent < 70 || list[0].Percent >= 100 {
+		t.Errorf("Nearest[0] = %+v, want BSD-3-Clause, 70-100%%", list[0])
+	}
+	for i := 1; i < len(list); i++ {
+		if list[i].Percent > list[i-1].Percent {
+			t.Errorf("Nearest not sorted: %+v", list)
+		}
+	}
+
+	if list := Nearest([]byte(bsd), 1); len(list) != 1 || list[0].ID != "BSD-3-Clause" || list[0].Percent != 100 {
+		t.Errorf("Nearest(BSD-3-Clause) = %+v, want BSD-3-Clause at 100%%", list)
+	}
+	if list := Nearest([]byte(bsd), 0); list != nil {
+		t.Errorf("Nearest(n=0) = %+v, want nil", list)
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package oci scans the layers of OCI and Docker container images
+// for license files, attributing each result to a layer and path.
+//
+// Images carry the licenses of everything installed in them:
+// license files copied in with applications, and the copyright files
+// that Debian-based images keep in /usr/share/doc/PACKAGE/copyright.
+// A Scanner finds and scans those files in a single layer,
+// read as a tar stream from a registry or a file,
+// or in all the layers of an image laid out in a file system,
+// such as the extracted output of "docker save":
+//
+//	var s oci.Scanner
+//	list, err := s.ScanImage(os.DirFS(dir))
+//	if err != nil {
+//		...
+//	}
+//	for _, f := range list {
+//		if !f.Hidden {
+//			fmt.Printf("%s %s: %v\n", f.Layer, f.Path, f.Coverage.Match)
+//		}
+//	}
+package oci
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/internal/strs"
+)
+
+// A Scanner scans container image layers for license files.
+// The zero Scanner is ready to use.
+type Scanner struct {
+	// Scanner is the scanner to use.
+	// If nil, the built-in license set is used.
+	Scanner *licensecheck.Scanner
+
+	// Options are the options for scanning each file.
+	Options licensecheck.Options
+
+	// IsLicense reports whether the file at the given path,
+	// relative to the image root and without a leading slash,
+	// is a license file to scan.
+	// If nil, IsLicensePath is used.
+	IsLicense func(path string) bool
+
+	// MaxFileBytes limits the size of the files scanned.
+	// Larger files are reported with an error wrapping
+	// licensecheck.ErrInputTooLarge and are not read.
+	// If zero, the limit is DefaultMaxFileBytes.
+	MaxFileBytes int64
+}
+
+// DefaultMaxFileBytes is the default limit on the size of a scanned file.
+const DefaultMaxFileBytes = 1 << 20
+
+// A Finding is the result of scanning one license file in an image.
+type Finding struct {
+	Layer    string                // layer digest, such as "sha256:…", or other layer name
+	Path     string                // path of the file in the image, without a leading slash
+	Coverage licensecheck.Coverage // result of scanning the file
+	Err      error                 // error from scanning the file, such as ErrNoMatch
+
+	// Hidden reports that a later layer of the image
+	// deletes or replaces the file, so that it is not
+	// in the image's final file system.
+	// It is set only by ScanImage.
+	Hidden bool
+}
+
+// IsLicensePath reports whether the file at the given path
+// is a license file: one whose base name is LICENSE, LICENCE, COPYING,
+// COPYRIGHT, NOTICE, or UNLICENSE, ignoring case, optionally followed
+// by a suffix beginning with punctuation, as in LICENSE.txt or COPYING-LIB;
+// or a package's copyright or license file in /usr/share/doc or /usr/share/licenses.
+func IsLicensePath(p string) bool {
+	p = strings.TrimPrefix(p, "/")
+	dir, base := path.Split(p)
+	if strings.HasPrefix(dir, "usr/share/licenses/") {
+		return true
+	}
+	if strings.HasPrefix(dir, "usr/share/doc/") && base == "copyright" {
+		return true
+	}
+	base = strings.ToLower(base)
+	for _, prefix := range []string{"license", "licence", "copying", "copyright", "notice", "unlicense", "mit-license", "mit_license"} {
+		if strings.HasPrefix(base, prefix) && (len(base) == len(prefix) || strings.IndexByte(".-_", base[len(prefix)]) >= 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanLayer scans the license files in a single layer,
+// read from r as an uncompressed or gzip-compressed tar stream,
+// and returns a Finding for each, in the order they appear.
+// The layer name is recorded in each Finding.
+// Whiteout files, which delete files from earlier layers, are ignored.
+func (s *Scanner) ScanLayer(r io.Reader, layer string) ([]Finding, error) {
+	list, _, err := s.scanLayer(r, layer)
+	return list, err
+}
+
+// A layerChanges records the files a layer adds and deletes,
+// to find which files of earlier layers it hides.
+type layerChanges struct {
+	files  map[string]bool // files and directories written by the layer
+	wh     map[string]bool // files deleted by whiteouts
+	opaque map[string]bool // directories whose earlier content is deleted
+}
+
+// hides reports whether the changes hide the file p of an earlier layer.
+func (c *layerChanges) hides(p string) bool {
+	if c.files[p] || c.wh[p] {
+		return true
+	}
+	for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+		if c.wh[dir] || c.opaque[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// scanLayer implements ScanLayer, also returning the layer's changes.
+func (s *Scanner) scanLayer(r io.Reader, layer string) ([]Finding, *layerChanges, error) {
+	br := bufio.NewReader(r)
+	var rd io.Reader = br
+	switch magic, _ := br.Peek(4); {
+	case bytes.HasPrefix(magic, []byte("\x1f\x8b")):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, fmt.Errorf("oci: layer %s: %v", layer, err)
+		}
+		rd = zr
+	case bytes.Equal(magic, []byte("\x28\xb5\x2f\xfd")):
+		return nil, nil, fmt.Errorf("oci: layer %s: zstd compression not supported", layer)
+	}
+
+	isLicense := s.IsLicense
+	if isLicense == nil {
+		isLicense = IsLicensePath
+	}
+	maxBytes := s.MaxFileBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxFileBytes
+	}
+
+	changes := &layerChanges{files: make(map[string]bool), wh: make(map[string]bool), opaque: make(map[string]bool)}
+	var list []Finding
+	tr := tar.NewReader(rd)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("oci: layer %s: %v", layer, err)
+		}
+		p := path.Clean("/" + hdr.Name)[1:]
+		if p == "" {
+			continue
+		}
+		dir, base := path.Split(p)
+		if strings.HasPrefix(base, ".wh.") {
+			if base == ".wh..wh..opq" {
+				changes.opaque[path.Clean(dir)] = true
+			} else {
+				changes.wh[dir+strings.TrimPrefix(base, ".wh.")] = true
+			}
+			continue
+		}
+		changes.files[p] = true
+		if !hdr.FileInfo().Mode().IsRegular() || !isLicense(p) {
+			continue
+		}
+
+		f := Finding{Layer: layer, Path: p}
+		if hdr.Size > maxBytes {
+			f.Err = fmt.Errorf("%w: %d bytes exceeds limit of %d", licensecheck.ErrInputTooLarge, hdr.Size, maxBytes)
+		} else {
+			data, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return nil, nil, fmt.Errorf("oci: layer %s: %s: %v", layer, p, err)
+			}
+			if s.Scanner != nil {
+				f.Coverage, f.Err = s.Scanner.ScanWithOptions(data, s.Options)
+			} else {
+				f.Coverage, f.Err = licensecheck.ScanWithOptions(data, s.Options)
+			}
+		}
+		list = append(list, f)
+	}
+	return list, changes, nil
+}
+
+// ScanImage scans the license files in all the layers of the image in fsys,
+// which holds either an OCI image layout (with an index.json file)
+// or the output of "docker save" (with a manifest.json file).
+// If the image has several manifests, such as one for each platform,
+// ScanImage scans the first.
+// The Findings are in layer order, and Finding.Hidden marks
+// the files that later layers delete or replace.
+func (s *Scanner) ScanImage(fsys fs.FS) ([]Finding, error) {
+	layers, err := imageLayers(fsys)
+	if err != nil {
+		return nil, err
+	}
+	var list []Finding
+	var changes []*layerChanges
+	var firsts []int // firsts[i] is the index in list of layer i's first Finding
+	for _, l := range layers {
+		f, err := fsys.Open(l.file)
+		if err != nil {
+			return nil, fmt.Errorf("oci: %v", err)
+		}
+		fl, c, err := s.scanLayer(f, l.name)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		firsts = append(firsts, len(list))
+		list = append(list, fl...)
+		changes = append(changes, c)
+	}
+	firsts = append(firsts, len(list))
+	for i := range layers {
+		for j := firsts[i]; j < firsts[i+1]; j++ {
+			for _, c := range changes[i+1:] {
+				if c.hides(list[j].Path) {
+					list[j].Hidden = true
+					break
+				}
+			}
+		}
+	}
+	return list, nil
+}
+
+// A layerFile is a layer in an image file system.
+type layerFile struct {
+	name string // digest, or file name if the digest is unknown
+	file string // path of the layer's tar file
+}
+
+// imageLayers returns the layers of the image in fsys, bottom layer first.
+func imageLayers(fsys fs.FS) ([]layerFile, error) {
+	if data, err := fs.ReadFile(fsys, "index.json"); err == nil {
+		return ociLayers(fsys, data)
+	}
+	data, err := fs.ReadFile(fsys, "manifest.json")
+	if err != nil {
+		return nil, errors.New("oci: neither index.json nor manifest.json found")
+	}
+	var m []struct{ Layers []string }
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("oci: manifest.json: %v", err)
+	}
+	if len(m) == 0 {
+		return nil, errors.New("oci: manifest.json: no images")
+	}
+	var list []layerFile
+	for _, l := range m[0].Layers {
+		name := l
+		if strings.HasPrefix(l, "blobs/") {
+			// Newer versions of docker save use the OCI blob layout.
+			name = strings.Replace(strings.TrimPrefix(l, "blobs/"), "/", ":", 1)
+		}
+		list = append(list, layerFile{name, l})
+	}
+	return list, nil
+}
+
+// A descriptor is an OCI content descriptor.
+type descriptor struct {
+	Digest string
+}
+
+// ociLayers returns the layers of the OCI image layout in fsys,
+// whose index.json holds data.
+func ociLayers(fsys fs.FS, data []byte) ([]layerFile, error) {
+	file := "index.json"
+	// Follow the first manifest of each index, to depth 8.
+	for depth := 0; depth < 8; depth++ {
+		var m struct {
+			Manifests []descriptor
+			Layers    []descriptor
+		}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("oci: %s: %v", file, err)
+		}
+		if m.Manifests == nil {
+			var list []layerFile
+			for _, l := range m.Layers {
+				f, err := blobFile(l.Digest)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, layerFile{l.Digest, f})
+			}
+			return list, nil
+		}
+		if len(m.Manifests) == 0 {
+			return nil, fmt.Errorf("oci: %s: no manifests", file)
+		}
+		f, err := blobFile(m.Manifests[0].Digest)
+		if err != nil {
+			return nil, err
+		}
+		file = f
+		if data, err = fs.ReadFile(fsys, file); err != nil {
+			return nil, fmt.Errorf("oci: %v", err)
+		}
+	}
+	return nil, errors.New("oci: image indexes nested too deeply")
+}
+
+// blobFile returns the path of the blob with the given digest.
+func blobFile(digest string) (string, error) {
+	alg, hex, _ := strs.Cut(digest, ":")
+	if alg == "" || hex == "" || strings.ContainsAny(digest, "/\\.") {
+		return "", fmt.Errorf("oci: invalid digest %q", digest)
+	}
+	return "blobs/" + alg + "/" + hex, nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+)
+
+// layer returns a tar stream holding the files, given as name, content pairs.
+// Names ending in a slash are directories.
+func layer(gz bool, files ...string) []byte {
+	var buf bytes.Buffer
+	var gw *gzip.Writer
+	tw := tar.NewWriter(&buf)
+	if gz {
+		gw = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gw)
+	}
+	for i := 0; i < len(files); i += 2 {
+		name, text := files[i], files[i+1]
+		if name[len(name)-1] == '/' {
+			tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeDir, Mode: 0755})
+			continue
+		}
+		tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(text))})
+		tw.Write([]byte(text))
+	}
+	tw.Close()
+	if gw != nil {
+		gw.Close()
+	}
+	return buf.Bytes()
+}
+
+func ids(cov licensecheck.Coverage) []string {
+	var list []string
+	for _, m := range cov.Match {
+		list = append(list, m.ID)
+	}
+	return list
+}
+
+func TestIsLicensePath(t *testing.T) {
+	for _, tt := range []struct {
+		path string
+		want bool
+	}{
+		{"LICENSE", true},
+		{"/opt/app/License.txt", true},
+		{"opt/app/COPYING.LIB", true},
+		{"usr/share/doc/libc6/copyright", true},
+		{"usr/share/doc/libc6/changelog.gz", false},
+		{"usr/share/licenses/zlib/README", true},
+		{"usr/bin/licensecheck", false},
+		{"etc/passwd", false},
+	} {
+		if got := IsLicensePath(tt.path); got != tt.want {
+			t.Errorf("IsLicensePath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestScanLayer(t *testing.T) {
+	mit, _ := licensecheck.Text("MIT")
+	isc, _ := licensecheck.Text("ISC")
+	for _, gz := range []bool{false, true} {
+		data := layer(gz,
+			"./usr/", "",
+			"./usr/share/doc/pkg/copyright", isc,
+			"./usr/bin/tool", "binary",
+			"./opt/app/LICENSE", mit,
+			"./opt/app/.wh.NOTICE", "",
+			"./opt/app/README", "hello",
+		)
+		s := &Scanner{MaxFileBytes: int64(len(isc))}
+		list, err := s.ScanLayer(bytes.NewReader(data), "sha256:abc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 2 {
+			t.Fatalf("gz=%v: ScanLayer found %d files, want 2: %+v", gz, len(list), list)
+		}
+		if f := list[0]; f.Layer != "sha256:abc" || f.Path != "usr/share/doc/pkg/copyright" || f.Err != nil || !reflect.DeepEqual(ids(f.Coverage), []string{"ISC"}) {
+			t.Errorf("gz=%v: list[0] = %s %s %v %v", gz, f.Layer, f.Path, ids(f.Coverage), f.Err)
+		}
+		if f := list[1]; f.Path != "opt/app/LICENSE" || !errors.Is(f.Err, licensecheck.ErrInputTooLarge) {
+			t.Errorf("gz=%v: list[1] = %s %v, want ErrInputTooLarge", gz, f.Path, f.Err)
+		}
+	}
+
+	if _, err := new(Scanner).ScanLayer(bytes.NewReader([]byte("\x28\xb5\x2f\xfd...")), "z"); err == nil {
+		t.Errorf("ScanLayer(zstd) succeeded, want error")
+	}
+}
+
+func TestScanImage(t *testing.T) {
+	mit, _ := licensecheck.Text("MIT")
+	isc, _ := licensecheck.Text("ISC")
+	layers := [][]byte{
+		layer(true,
+			"usr/share/doc/a/copyright", mit,
+			"usr/share/doc/b/copyright", mit,
+			"opt/x/LICENSE", mit,
+			"opt/y/LICENSE", mit,
+		),
+		layer(false,
+			"usr/share/doc/.wh.a", "",
+			"opt/x/.wh..wh..opq", "",
+			"opt/y/LICENSE", isc,
+		),
+	}
+	want := []struct {
+		layer, path string
+		hidden      bool
+	}{
+		{"sha256:l0", "usr/share/doc/a/copyright", true},
+		{"sha256:l0", "usr/share/doc/b/copyright", false},
+		{"sha256:l0", "opt/x/LICENSE", true},
+		{"sha256:l0", "opt/y/LICENSE", true},
+		{"sha256:l1", "opt/y/LICENSE", false},
+	}
+
+	oci := fstest.MapFS{
+		"index.json":        {Data: []byte(`{"manifests": [{"digest": "sha256:idx"}]}`)},
+		"blobs/sha256/idx":  {Data: []byte(`{"manifests": [{"digest": "sha256:man"}, {"digest": "sha256:other"}]}`)},
+		"blobs/sha256/man":  {Data: []byte(`{"config": {"digest": "sha256:cfg"}, "layers": [{"digest": "sha256:l0"}, {"digest": "sha256:l1"}]}`)},
+		"blobs/sha256/l0":   {Data: layers[0]},
+		"blobs/sha256/l1":   {Data: layers[1]},
+		"blobs/sha256/cfg":  {Data: []byte(`{}`)},
+		"oci-layout":        {Data: []byte(`{"imageLayoutVersion": "1.0.0"}`)},
+		"blobs/sha256/junk": {Data: []byte(`junk`)},
+	}
+	docker := fstest.MapFS{
+		"manifest.json":   {Data: []byte(`[{"Config": "cfg.json", "Layers": ["blobs/sha256/l0", "blobs/sha256/l1"]}]`)},
+		"blobs/sha256/l0": {Data: layers[0]},
+		"blobs/sha256/l1": {Data: layers[1]},
+	}
+
+	for name, fsys := range map[string]fstest.MapFS{"oci": oci, "docker": docker} {
+		list, err := new(Scanner).ScanImage(fsys)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(list) != len(want) {
+			t.Fatalf("%s: ScanImage found %d files, want %d: %+v", name, len(list), len(want), list)
+		}
+		for i, f := range list {
+			w := want[i]
+			if f.Layer != w.layer || f.Path != w.path || f.Hidden != w.hidden {
+				t.Errorf("%s: list[%d] = %s %s hidden=%v, want %s %s hidden=%v", name, i, f.Layer, f.Path, f.Hidden, w.layer, w.path, w.hidden)
+			}
+		}
+		if got := ids(list[4].Coverage); !reflect.DeepEqual(got, []string{"ISC"}) {
+			t.Errorf("%s: top layer license = %v, want [ISC]", name, got)
+		}
+	}
+
+	bad := fstest.MapFS{"index.json": {Data: []byte(`{"manifests": [{"digest": "sha256:../../etc"}]}`)}}
+	if _, err := new(Scanner).ScanImage(bad); err == nil {
+		t.Errorf("ScanImage with invalid digest succeeded, want error")
+	}
+	if _, err := new(Scanner).ScanImage(fstest.MapFS{}); err == nil {
+		t.Errorf("ScanImage of empty directory succeeded, want error")
+	}
+}
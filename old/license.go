@@ -14,7 +14,11 @@ import (
 )
 
 // Options allow us to adjust parameters for the matching algorithm.
-// TODO: Delete this once the package has been fine-tuned.
+//
+// Deprecated: Use licensecheck.ScanWithOptions with licensecheck.Options.
+// Threshold becomes a check of Coverage.Percent or Match.Confidence
+// by the caller, and Slop becomes Options.MergeGapWords.
+// MinLength has no replacement.
 type Options struct {
 	MinLength int // Minimum length of run, in words, to count as a matching substring.
 	Threshold int // Percentage threshold to report a match.
@@ -72,6 +76,8 @@ type document struct {
 }
 
 // A Checker matches a set of known licenses.
+//
+// Deprecated: Use licensecheck.Scanner.
 type Checker struct {
 	licenses []license
 	urls     map[string]string
@@ -94,6 +100,8 @@ type License struct {
 }
 
 // New returns a new Checker that recognizes the given list of licenses.
+//
+// Deprecated: Use licensecheck.NewScanner.
 func New(licenses []License) *Checker {
 	c := &Checker{
 		licenses: make([]license, 0, len(licenses)),
@@ -193,12 +201,15 @@ func (c *Checker) updateIndex(id int32, words []int32) {
 // is chosen so the returned coverage describes at most
 // one match for each section of the input.
 //
+// Deprecated: Use licensecheck.Scan, or licensecheck.ScanWithOptions.
 func Cover(input []byte, opts Options) (Coverage, bool) {
 	return builtin.Cover(input, opts)
 }
 
 // Cover is like the top-level function Cover, but it uses the
 // set of licenses in the Checker instead of the built-in license set.
+//
+// Deprecated: Use licensecheck.Scanner.Scan.
 func (c *Checker) Cover(input []byte, opts Options) (Coverage, bool) {
 	doc := c.normalize(input, false)
 
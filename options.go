@@ -0,0 +1,333 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/google/licensecheck/internal/strs"
+)
+
+// Options controls the scanning done by ScanWithOptions.
+// The zero Options gives the same results as Scan.
+type Options struct {
+	// InvalidUTF8 says how to treat input that is not valid UTF-8,
+	// such as binary files or text in a legacy encoding.
+	// The default is UTF8Replace.
+	InvalidUTF8 UTF8Policy
+
+	// MaxInputBytes is the maximum length of text to scan.
+	// Scanning a longer text fails with an error wrapping ErrInputTooLarge,
+	// unless Truncate is set.
+	// If zero, there is no limit.
+	MaxInputBytes int
+
+	// Truncate says to scan only the first MaxInputBytes of a longer text
+	// instead of failing. The resulting Coverage has Truncated set,
+	// and its Percent describes only the scanned prefix.
+	Truncate bool
+
+	// ChunkBytes, if positive, bounds the memory needed to scan a long text.
+	// A text longer than ChunkBytes is scanned in overlapping windows
+	// of at most ChunkBytes each, and the matches in the windows are merged.
+	// The results are the same as for scanning the text all at once,
+	// provided each license in the text is shorter than half of ChunkBytes.
+	// The longest built-in licenses are under 48 kB,
+	// so ChunkBytes should be at least 128 kB.
+	// Values smaller than 1 kB are treated as 1 kB.
+	ChunkBytes int
+
+	// Source, if set, is the name of the source file being scanned,
+	// such as "main.go". If its extension names a programming language
+	// known to ScanSource, only the comments in the text are scanned,
+	// and Percent is computed over the comments alone,
+	// so that the code in a file does not dilute it.
+	// Match offsets are still offsets in the full text.
+	Source string
+
+	// OCR says that the text was produced by optical character recognition,
+	// such as a license extracted from a scanned PDF,
+	// and so is likely to contain systematic recognition errors:
+	// "rn" read as "m", "l" as "1", "e" as "c", words broken in two, and so on.
+	// Within a match, words are compared allowing for those confusions.
+	// A match must still begin with two correctly recognized words,
+	// and the errors are tolerated only for the words the license expects,
+	// so clean text scans the same with or without OCR.
+	OCR bool
+
+	// Segment says to split the text into segments at separator lines
+	// and scan each segment separately, so that no match spans two segments.
+	// It suits bundled notice files, such as THIRD_PARTY_NOTICES,
+	// that concatenate the licenses of many components:
+	// each license is attributed to its own component's block,
+	// and the slack allowed within a match cannot bridge two components.
+	// A separator line is a horizontal rule made of four or more
+	// of the characters - = * # ~ +, a form feed, or a banner such as
+	// "The following software may be included in this product".
+	// A separator line begins a new segment, as does a heading
+	// underlined by a rule. Separator lines inside a license
+	// that has them itself, such as the LPPL, are ignored.
+	Segment bool
+
+	// MergeGapWords, if positive, says to look again at the parts
+	// of the text not covered by any match for licenses interrupted
+	// by inserted text, such as a paragraph added in the middle,
+	// which keeps the usual matching from finding them at all.
+	// A license is matched if nearly all its words appear in order
+	// with no run of more than MergeGapWords other words among them.
+	// Each run of more than a few inserted words is reported
+	// in Coverage.Deviations instead of breaking the match.
+	// Only licenses whose canonical text is available (see Text)
+	// can be matched this way.
+	MergeGapWords int
+
+	// ApproxPercent, if positive, says to look again at the parts
+	// of the text not covered by any match for licenses with
+	// scattered small edits, such as words changed throughout,
+	// which keep the usual matching from finding them.
+	// A run of words in such a part matches a license, chosen from the
+	// few whose canonical texts the part most nearly contains,
+	// if it can be turned into the license's canonical text
+	// by inserting, deleting, or replacing at most 100-ApproxPercent percent
+	// of the license's words. Such matches have IsApprox set,
+	// and one part can hold several of them.
+	// ApproxPercent must be at most 100; values of 90 or more
+	// keep the false positives rare.
+	ApproxPercent int
+
+	// Overlap says which license to report when more than one
+	// matches text beginning at the same place.
+	// The default, OverlapLongest, reports the one matching the most words.
+	Overlap OverlapPolicy
+
+	// Submatches says to report, in Coverage.Submatches,
+	// how each match lines up with the canonical text of its license:
+	// the runs of words the two share, with their offsets in both texts.
+	// The gaps between the runs are where the text differs from the license,
+	// as in a wildcard filled in, a word changed, or text inserted,
+	// so the runs let a reviewer audit a match word by word.
+	Submatches bool
+
+	// Words says to report the text's normalized words in Coverage.Words
+	// and the offsets of each match in that list in Match.WordStart and Match.WordEnd,
+	// for programs that work with words rather than bytes,
+	// such as for diffing or fingerprinting,
+	// so that they need not split and normalize the text again.
+	// Match.Start and Match.End are still set too.
+	Words bool
+
+	// EULA says to check whether a text not mostly covered
+	// by known licenses reads like an end-user license agreement
+	// or terms of service, and report it in Coverage.EULA.
+	// Scan does not check, since the check reads the whole text again.
+	EULA bool
+
+	// Restrictions says to look in the text not covered by any match
+	// for clauses restricting the use of the work, such as
+	// "non-commercial use only", and report them in Coverage.Restrictions,
+	// so that Coverage.Restricted flags unknown licenses with such clauses.
+	// Scan does not look for them, since few callers need them
+	// and searching all the unmatched text for them is slow.
+	Restrictions bool
+
+	// Matcher, if non-nil, replaces the Scanner's own matching
+	// of license texts (see Matcher), for trying other matching engines.
+	// Matches are reported only for licenses known to the Scanner,
+	// with their metadata. OCR and Overlap have no effect on the Matcher,
+	// and Coverage.Alternatives is not set for its matches.
+	Matcher Matcher
+
+	// Metrics, if non-nil, receives a ScanEvent describing each scan,
+	// for monitoring. It does not affect the results.
+	Metrics Metrics
+}
+
+// minChunkBytes is the smallest window used for Options.ChunkBytes.
+const minChunkBytes = 1 << 10
+
+// A UTF8Policy says how to treat invalid UTF-8 in scanned text.
+type UTF8Policy int
+
+const (
+	// UTF8Replace treats each invalid byte as the replacement character U+FFFD,
+	// which, like punctuation, separates words but is otherwise ignored.
+	// It is the default.
+	UTF8Replace UTF8Policy = iota
+
+	// UTF8Skip ignores invalid bytes entirely,
+	// so that the text on either side of them can form a single word.
+	UTF8Skip
+
+	// UTF8Reject rejects text containing invalid UTF-8 with ErrInvalidUTF8.
+	UTF8Reject
+)
+
+// Errors returned by ScanWithOptions.
+// The returned error may wrap one of these with more detail;
+// use errors.Is to check for them.
+var (
+	// ErrNoMatch reports that the text was scanned successfully
+	// but no licenses were found. The returned Coverage is still valid.
+	ErrNoMatch = errors.New("licensecheck: no license found")
+
+	// ErrEmptyInput reports that the text has no words at all
+	// (see Coverage.Empty). It is returned instead of ErrNoMatch.
+	ErrEmptyInput = errors.New("licensecheck: empty input")
+
+	// ErrInputTooLarge reports that the text is longer than Options.MaxInputBytes.
+	ErrInputTooLarge = errors.New("licensecheck: input too large")
+
+	// ErrInvalidOptions reports that the Options are invalid.
+	ErrInvalidOptions = errors.New("licensecheck: invalid options")
+
+	// ErrInvalidUTF8 reports that the text is not valid UTF-8
+	// and Options.InvalidUTF8 is UTF8Reject.
+	ErrInvalidUTF8 = errors.New("licensecheck: invalid UTF-8 in input")
+)
+
+// scanChunks scans text according to opts.ChunkBytes, opts.OCR, opts.Overlap, and opts.Segment.
+// If opts.Segment is set, it scans each segment of the text separately.
+// If opts.ChunkBytes is positive, it scans each segment
+// in overlapping windows of at most opts.ChunkBytes;
+// otherwise it scans each segment all at once.
+func (s *Scanner) scanChunks(text []byte, opts Options) Coverage {
+	chunk := opts.ChunkBytes
+	if chunk > 0 && chunk < minChunkBytes {
+		chunk = minChunkBytes
+	}
+	bounds := []int{0, len(text)}
+	if opts.Segment {
+		bounds = s.segmentBounds(text, opts)
+	}
+
+	var c Coverage
+	var found []merged
+	matched, words := 0, 0
+	for i := 0; i+1 < len(bounds); i++ {
+		seg := text[bounds[i]:bounds[i+1]]
+		n := len(c.Match)
+		// Each window reports the matches starting in its first half
+		// (or in the rest of the segment, for the final window),
+		// so any license shorter than half a window
+		// lies entirely inside the window that reports it.
+		for lo := 0; lo < len(seg); {
+			window := seg[lo:]
+			limit := len(window)
+			if chunk > 0 && len(window) > chunk {
+				window = window[:breakBefore(window, chunk)]
+				limit = breakBefore(window, chunk/2)
+			}
+			wc, st := s.scanWindow(window, limit, opts)
+			for _, a := range wc.Alternatives {
+				a.Match += len(c.Match)
+				a.End += bounds[i] + lo
+				c.Alternatives = append(c.Alternatives, a)
+			}
+			for _, m := range wc.Match {
+				m.Start += bounds[i] + lo
+				m.End += bounds[i] + lo
+				c.Match = append(c.Match, m)
+			}
+			matched += st.matched
+			words += st.words
+			lo += st.next
+		}
+		if opts.MergeGapWords > 0 || opts.ApproxPercent > 0 {
+			segMatch := make([]Match, len(c.Match)-n)
+			for j, m := range c.Match[n:] {
+				m.Start -= bounds[i]
+				m.End -= bounds[i]
+				segMatch[j] = m
+			}
+			for _, mg := range s.rescanGaps(seg, segMatch, opts) {
+				mg.m.Start += bounds[i]
+				mg.m.End += bounds[i]
+				for j := range mg.devs {
+					mg.devs[j].Start += bounds[i]
+					mg.devs[j].End += bounds[i]
+				}
+				found = append(found, mg)
+				matched += mg.words
+			}
+		}
+	}
+	if len(found) > 0 {
+		addMerged(&c, found)
+	}
+	if words == 0 {
+		c.Empty = true
+	} else {
+		c.Percent = 100.0 * float64(matched) / float64(words)
+	}
+	return c
+}
+
+// breakBefore returns a good offset at which to split text, at most max.
+// It prefers the end of a line, then a space, in the second half of text[:max],
+// so that the split does not break up a word.
+func breakBefore(text []byte, max int) int {
+	if i := bytes.LastIndexByte(text[max/2:max], '\n'); i >= 0 {
+		return max/2 + i + 1
+	}
+	if i := bytes.LastIndexByte(text[max/2:max], ' '); i >= 0 {
+		return max/2 + i + 1
+	}
+	return len(strs.Truncate(text, max))
+}
+
+// An offsetMap maps byte offsets in text with invalid UTF-8 removed
+// back to offsets in the original text.
+type offsetMap struct {
+	at      []int // at[i] is the offset in the cleaned text of the i'th removed run
+	removed []int // removed[i] is the total bytes removed by runs 0 through i
+}
+
+// removeInvalidUTF8 returns text with all invalid UTF-8 bytes removed,
+// along with the map from the returned text's offsets to text's offsets.
+func removeInvalidUTF8(text []byte) ([]byte, *offsetMap) {
+	out := make([]byte, 0, len(text))
+	m := new(offsetMap)
+	total := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRune(text[i:])
+		if r == utf8.RuneError && size == 1 {
+			if n := len(m.at); n > 0 && m.at[n-1] == len(out) {
+				m.removed[n-1]++
+			} else {
+				m.at = append(m.at, len(out))
+				m.removed = append(m.removed, total+1)
+			}
+			total++
+			i++
+			continue
+		}
+		out = append(out, text[i:i+size]...)
+		i += size
+	}
+	return out, m
+}
+
+// start maps the offset of the start of a span in the cleaned text
+// to the original text. Bytes removed just before the span are not included.
+func (m *offsetMap) start(off int) int {
+	i := sort.Search(len(m.at), func(i int) bool { return m.at[i] > off })
+	if i == 0 {
+		return off
+	}
+	return off + m.removed[i-1]
+}
+
+// end maps the offset of the end of a span in the cleaned text
+// to the original text. Bytes removed just after the span are not included.
+func (m *offsetMap) end(off int) int {
+	i := sort.Search(len(m.at), func(i int) bool { return m.at[i] >= off })
+	if i == 0 {
+		return off
+	}
+	return off + m.removed[i-1]
+}
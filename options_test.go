@@ -0,0 +1,485 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInvalidUTF8(t *testing.T) {
+	// Invalid bytes between paragraphs, as in a mis-encoded file.
+	text := "Binary \xff\xfe header\n\n" + strings.Replace(mitText, "\n\n", "\n\xa9\n", 1) + "\xc0\n"
+
+	cov, err := ScanWithOptions([]byte(text), Options{})
+	if err != nil || len(cov.Match) != 1 || cov.Match[0].ID != "MIT" {
+		t.Errorf("ScanWithOptions(UTF8Replace) = %+v, %v, want MIT", cov, err)
+	}
+	if want := Scan([]byte(text)); err == nil && (cov.Percent != want.Percent || cov.Match[0] != want.Match[0]) {
+		t.Errorf("ScanWithOptions(UTF8Replace) = %+v, want same as Scan: %+v", cov, want)
+	}
+
+	cov, err = ScanWithOptions([]byte(text), Options{InvalidUTF8: UTF8Skip})
+	if err != nil || len(cov.Match) != 1 || cov.Match[0].ID != "MIT" {
+		t.Fatalf("ScanWithOptions(UTF8Skip) = %+v, %v, want MIT", cov, err)
+	}
+	if m := cov.Match[0]; m.Start != strings.Index(text, "copyright") || !strings.HasPrefix(text[m.End-1:], "\n\xc0") && m.End != len(text) {
+		t.Errorf("ScanWithOptions(UTF8Skip) = [%d:%d], want license text", m.Start, m.End)
+	}
+
+	// With UTF8Skip, invalid bytes inside a word do not split it.
+	word := strings.Replace(mitText, "Permission", "Permis\xffsion", 1)
+	if cov, err := ScanWithOptions([]byte(word), Options{InvalidUTF8: UTF8Skip}); err != nil || len(cov.Match) != 1 || cov.Percent != 100 || cov.Match[0].End != len(word) {
+		t.Errorf("ScanWithOptions(split word, UTF8Skip) = %+v, %v, want MIT", cov, err)
+	}
+
+	if _, err := ScanWithOptions([]byte(text), Options{InvalidUTF8: UTF8Reject}); err != ErrInvalidUTF8 {
+		t.Errorf("ScanWithOptions(UTF8Reject) error = %v, want ErrInvalidUTF8", err)
+	}
+	if _, err := ScanWithOptions([]byte(mitText), Options{InvalidUTF8: UTF8Reject}); err != nil {
+		t.Errorf("ScanWithOptions(valid text, UTF8Reject): %v", err)
+	}
+	if _, err := ScanWithOptions([]byte(mitText), Options{InvalidUTF8: -1}); err == nil {
+		t.Errorf("ScanWithOptions(InvalidUTF8: -1) succeeded, want error")
+	}
+}
+
+func TestOffsetMap(t *testing.T) {
+	text := []byte("a\xffbc\xfe\xfdd\xff")
+	clean, m := removeInvalidUTF8(text)
+	if string(clean) != "abcd" {
+		t.Fatalf("removeInvalidUTF8(%q) = %q, want %q", text, clean, "abcd")
+	}
+	for _, tt := range []struct {
+		off, start, end int
+	}{
+		{0, 0, 0},
+		{1, 2, 1},
+		{2, 3, 3},
+		{3, 6, 4},
+		{4, 8, 7},
+	} {
+		if start, end := m.start(tt.off), m.end(tt.off); start != tt.start || end != tt.end {
+			t.Errorf("offsetMap(%d) = start %d, end %d, want %d, %d", tt.off, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestMaxInputBytes(t *testing.T) {
+	text := mitText + strings.Repeat("Unrelated trailing text.\n", 100)
+
+	cov, err := ScanWithOptions([]byte(text), Options{MaxInputBytes: len(text)})
+	if err != nil || len(cov.Match) != 1 || cov.Truncated {
+		t.Errorf("ScanWithOptions(MaxInputBytes: len) = %+v, %v, want MIT, not truncated", cov, err)
+	}
+
+	_, err = ScanWithOptions([]byte(text), Options{MaxInputBytes: len(mitText)})
+	if !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("ScanWithOptions(MaxInputBytes: short) error = %v, want ErrInputTooLarge", err)
+	}
+
+	cov, err = ScanWithOptions([]byte(text), Options{MaxInputBytes: len(mitText), Truncate: true})
+	if err != nil || len(cov.Match) != 1 || cov.Match[0].ID != "MIT" || cov.Match[0].End > len(mitText) || !cov.Truncated || cov.Percent != 100 {
+		t.Errorf("ScanWithOptions(MaxInputBytes: short, Truncate) = %+v, %v, want truncated MIT", cov, err)
+	}
+
+	if _, err := ScanWithOptions([]byte(text), Options{MaxInputBytes: -1}); err == nil {
+		t.Errorf("ScanWithOptions(MaxInputBytes: -1) succeeded, want error")
+	}
+}
+
+func TestScanErrors(t *testing.T) {
+	text := []byte("This text has no license.\xff")
+	for _, tt := range []struct {
+		opts Options
+		err  error
+	}{
+		{Options{}, ErrNoMatch},
+		{Options{MaxInputBytes: 5}, ErrInputTooLarge},
+		{Options{MaxInputBytes: 5, Truncate: true}, ErrNoMatch},
+		{Options{MaxInputBytes: -1}, ErrInvalidOptions},
+		{Options{InvalidUTF8: 99}, ErrInvalidOptions},
+		{Options{InvalidUTF8: UTF8Reject}, ErrInvalidUTF8},
+	} {
+		if _, err := ScanWithOptions(text, tt.opts); !errors.Is(err, tt.err) {
+			t.Errorf("ScanWithOptions(%q, %+v) error = %v, want %v", text, tt.opts, err, tt.err)
+		}
+	}
+
+	cov, err := ScanWithOptions([]byte(mitText), Options{})
+	if err != nil || len(cov.Match) != 1 {
+		t.Errorf("ScanWithOptions(MIT) = %+v, %v, want MIT", cov, err)
+	}
+}
+
+func TestEmptyInput(t *testing.T) {
+	for _, text := range []string{"", " \r\n\t ", "<p></p> --- !!! <br/>", "\uFEFF\n"} {
+		cov := Scan([]byte(text))
+		if !cov.Empty || cov.Percent != 0 || len(cov.Match) != 0 {
+			t.Errorf("Scan(%q) = %+v, want Empty", text, cov)
+		}
+		if _, err := ScanWithOptions([]byte(text), Options{}); err != ErrEmptyInput {
+			t.Errorf("ScanWithOptions(%q) error = %v, want ErrEmptyInput", text, err)
+		}
+	}
+	for _, text := range []string{"hello", "1.", mitText} {
+		if cov := Scan([]byte(text)); cov.Empty {
+			t.Errorf("Scan(%q) = %+v, want not Empty", text, cov)
+		}
+	}
+}
+
+func TestChunkBytes(t *testing.T) {
+	var b strings.Builder
+	filler := func(n int) {
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&b, "Line %d of filler text that is not part of any license.\n", i)
+		}
+	}
+	for _, id := range []string{"MIT", "Apache-2.0", "BSD-3-Clause", "ISC", "Zlib"} {
+		text, ok := Text(id)
+		if !ok {
+			t.Fatalf("missing text for %s", id)
+		}
+		filler(50)
+		b.WriteString(text + "\n")
+	}
+	filler(10)
+	b.WriteString("See https://www.opensource.org/licenses/mit for details.\n")
+	filler(50)
+	text := []byte(b.String())
+
+	want := Scan(text)
+	if len(want.Match) != 6 {
+		t.Fatalf("Scan found %d matches, want 6: %+v", len(want.Match), want)
+	}
+	for _, chunk := range []int{0, 24 << 10, 32 << 10, len(text)} {
+		cov, err := ScanWithOptions(text, Options{ChunkBytes: chunk})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(cov, want) {
+			t.Errorf("ScanWithOptions(ChunkBytes: %d) = %+v, want %+v", chunk, cov, want)
+		}
+	}
+
+	// Small windows may miss the long Apache license but must still find the others.
+	cov, err := ScanWithOptions(text, Options{ChunkBytes: 4 << 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]bool)
+	for _, m := range cov.Match {
+		found[m.ID] = true
+	}
+	for _, id := range []string{"MIT", "BSD-3-Clause", "ISC", "Zlib"} {
+		if !found[id] {
+			t.Errorf("ScanWithOptions(ChunkBytes: 4k) did not find %s: %+v", id, cov)
+		}
+	}
+	if _, err := ScanWithOptions(text, Options{ChunkBytes: 1}); err != nil && err != ErrNoMatch {
+		t.Errorf("ScanWithOptions(ChunkBytes: 1) error = %v", err)
+	}
+
+	if _, err := ScanWithOptions(text, Options{ChunkBytes: -1}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("ScanWithOptions(ChunkBytes: -1) error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestSourceOption(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("package p\n\n" + commented("//", mitText) + "\n")
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&b, "func f%d(x int) int { return x * %d }\n", i, i)
+	}
+	text := []byte(b.String())
+
+	cov, err := ScanWithOptions(text, Options{})
+	if err != nil || len(cov.Match) != 1 || cov.Percent > 50 {
+		t.Fatalf("ScanWithOptions = %+v, %v, want MIT diluted by code", cov, err)
+	}
+	code := cov.Percent
+
+	cov, err = ScanWithOptions(text, Options{Source: "p.go"})
+	if err != nil || len(cov.Match) != 1 || cov.Match[0].ID != "MIT" || cov.Percent < 80 || cov.Percent < 2*code {
+		t.Fatalf("ScanWithOptions(Source) = %+v, %v, want MIT with code ignored", cov, err)
+	}
+	if m := cov.Match[0]; !strings.HasPrefix(string(text[m.Start:]), "// ") || !strings.HasSuffix(string(text[:m.End]), "software.\n") {
+		t.Errorf("ScanWithOptions(Source): match at [%d:%d] = %q, want commented license", m.Start, m.End, text[m.Start:m.End])
+	}
+	if want := ScanSource("p.go", text); !reflect.DeepEqual(cov, want) {
+		t.Errorf("ScanWithOptions(Source) = %+v, want same as ScanSource: %+v", cov, want)
+	}
+
+	// Chunked scans and unknown languages work too.
+	if cov2, err := ScanWithOptions(text, Options{Source: "p.go", ChunkBytes: 64 << 10}); err != nil || !reflect.DeepEqual(cov2, cov) {
+		t.Errorf("ScanWithOptions(Source, ChunkBytes) = %+v, %v, want %+v", cov2, err, cov)
+	}
+	if cov, err := ScanWithOptions(text, Options{Source: "p.txt"}); err != nil || cov.Percent != code {
+		t.Errorf("ScanWithOptions(Source: p.txt) = %+v, %v, want Percent %.1f", cov, err, code)
+	}
+}
+
+func TestOCROption(t *testing.T) {
+	// Damage typical of OCR: rn for m, 1 for l or i, 0 for o, c for e,
+	// and words broken in two.
+	damage := strings.NewReplacer(
+		"merchantability", "rnerchantabi1ity",
+		"permission notice", "perrnissi0n notice",
+		"all ", "a1l ",
+		"limitation", "1irnitation",
+		"modify", "rn0dify",
+		"furnished", "furn ished",
+		"the ", "thc ",
+	)
+	text := []byte(damage.Replace(mitText))
+
+	cov, err := ScanWithOptions(text, Options{})
+	if err == nil && len(cov.Match) == 1 && cov.Percent > 90 {
+		t.Fatalf("ScanWithOptions = %+v, want OCR damage to prevent a full match", cov)
+	}
+
+	cov, err = ScanWithOptions(text, Options{OCR: true})
+	if err != nil || len(cov.Match) != 1 || cov.Match[0].ID != "MIT" || cov.Percent < 90 {
+		t.Fatalf("ScanWithOptions(OCR) = %+v, %v, want MIT", cov, err)
+	}
+	if m := cov.Match[0]; m.End != len(text) {
+		t.Errorf("ScanWithOptions(OCR): match at [%d:%d], want end of text at %d", m.Start, m.End, len(text))
+	}
+
+	// Clean text scans the same with OCR.
+	clean := []byte(mitText)
+	if cov, err := ScanWithOptions(clean, Options{OCR: true}); err != nil || !reflect.DeepEqual(cov, Scan(clean)) {
+		t.Errorf("ScanWithOptions(clean, OCR) = %+v, %v, want same as Scan", cov, err)
+	}
+}
+
+func TestSegmentOption(t *testing.T) {
+	skipProfile(t)
+	body := mitText[strings.Index(mitText, "\n\n")+2:] // without the copyright line
+	lppl, _ := Text("LPPL-1.3c")
+	text := "Third-party software notices\n\n" +
+		"foo, Copyright 2019 Foo Inc., is distributed without a license.\n\n" +
+		"================================\n\n" +
+		"bar 1.0\n-------\n\n" + body + "\n" +
+		"The following software may be included in this product: baz.\n\n" + lppl
+	bar := strings.Index(text, "bar 1.0")
+	baz := strings.Index(text, "The following software")
+
+	cov, err := ScanWithOptions([]byte(text), Options{})
+	if err != nil || len(cov.Match) != 2 || cov.Match[0].ID != "MIT" {
+		t.Fatalf("ScanWithOptions = %+v, %v, want MIT and LPPL-1.3c", cov, err)
+	}
+	if m := cov.Match[0]; m.Start >= bar {
+		t.Fatalf("ScanWithOptions: MIT match at [%d:%d], want it to reach back to foo's copyright before %d", m.Start, m.End, bar)
+	}
+
+	for _, chunk := range []int{0, 32 << 10} {
+		cov, err := ScanWithOptions([]byte(text), Options{Segment: true, ChunkBytes: chunk})
+		if err != nil || len(cov.Match) != 2 || cov.Match[0].ID != "MIT" || cov.Match[1].ID != "LPPL-1.3c" {
+			t.Fatalf("ScanWithOptions(Segment, ChunkBytes: %d) = %+v, %v, want MIT and LPPL-1.3c", chunk, cov, err)
+		}
+		if m := cov.Match[0]; m.Start < bar || m.End > baz {
+			t.Errorf("ScanWithOptions(Segment, ChunkBytes: %d): MIT match at [%d:%d], want within bar's block [%d:%d]", chunk, m.Start, m.End, bar, baz)
+		}
+		if m := cov.Match[1]; m.Start < baz || m.End != len(text) {
+			t.Errorf("ScanWithOptions(Segment, ChunkBytes: %d): LPPL match at [%d:%d], want [%d:%d] or later start", chunk, m.Start, m.End, baz, len(text))
+		}
+	}
+
+	// Without separators, Segment changes nothing.
+	if cov, err := ScanWithOptions([]byte(mitText), Options{Segment: true}); err != nil || !reflect.DeepEqual(cov, Scan([]byte(mitText))) {
+		t.Errorf("ScanWithOptions(mitText, Segment) = %+v, %v, want same as Scan", cov, err)
+	}
+}
+
+func TestMergeGapWords(t *testing.T) {
+	bsd, _ := Text("BSD-3-Clause")
+	i := strings.Index(bsd, "THIS SOFTWARE IS PROVIDED")
+	insert := strings.Repeat("The widget module is maintained by the platform team and must not be changed without review. ", 8) + "\n\n"
+	text := bsd[:i] + insert + bsd[i:]
+	words := len(strings.Fields(insert))
+
+	if cov := Scan([]byte(text)); len(cov.Match) != 0 {
+		t.Errorf("Scan = %+v, want no matches", cov.Match)
+	}
+	if cov, _ := ScanWithOptions([]byte(text), Options{MergeGapWords: words / 2}); len(cov.Match) != 0 {
+		t.Errorf("ScanWithOptions(MergeGapWords: %d) = %+v, want no matches", words/2, cov.Match)
+	}
+
+	for _, opts := range []Options{{MergeGapWords: 200}, {MergeGapWords: 200, ChunkBytes: 1 << 10}} {
+		cov, err := ScanWithOptions([]byte(text), opts)
+		if err != nil || len(cov.Match) != 1 || cov.Match[0].ID != "BSD-3-Clause" {
+			t.Fatalf("ScanWithOptions(%+v) = %+v, %v, want BSD-3-Clause", opts, cov.Match, err)
+		}
+		if m := cov.Match[0]; m.Start != 0 || m.End != len(text) {
+			t.Errorf("ScanWithOptions(%+v): match at [%d:%d], want [0:%d]", opts, m.Start, m.End, len(text))
+		}
+		if len(cov.Deviations) != 1 {
+			t.Fatalf("ScanWithOptions(%+v): Deviations = %+v, want one", opts, cov.Deviations)
+		}
+		// The alignment may pair a word at either edge of the insertion
+		// with the same word in the license, so allow a word of slack.
+		end := i + len(strings.TrimSpace(insert))
+		if d := cov.Deviations[0]; d.Match != 0 || d.Words < words-2 || d.Words > words+2 || abs(d.Start-i) > 8 || abs(d.End-end) > 8 {
+			t.Errorf("ScanWithOptions(%+v): Deviation = %+v, want about %d words at [%d:%d]", opts, d, words, i, end)
+		}
+	}
+
+	// Text without insertions scans the same.
+	if cov, err := ScanWithOptions([]byte(bsd), Options{MergeGapWords: 200}); err != nil || !reflect.DeepEqual(cov, Scan([]byte(bsd))) {
+		t.Errorf("ScanWithOptions(BSD-3-Clause, MergeGapWords) = %+v, %v, want same as Scan", cov, err)
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func TestOverlapOption(t *testing.T) {
+	const (
+		a = "the quick brown fox jumps over the lazy dog while the old cat sleeps"
+		c = "in the warm afternoon sun"
+		d = "and nobody minds at all"
+		b = "this optional part of the license is rarely seen in practice by anyone at all"
+	)
+	s, err := NewScanner([]License{
+		{ID: "Foo", LRE: a + " " + c + " " + d + "\n((" + b + "))??", Text: a + " " + c + " " + d + " " + b},
+		{ID: "Foo-Lite", LRE: a + " " + c, Text: a + " " + c},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		text   string
+		policy OverlapPolicy
+		want   string
+	}{
+		{a + " " + c + " " + d, OverlapLongest, "Foo"},
+		{a + " " + c + " " + d, OverlapCoverage, "Foo-Lite"},
+		{a + " " + c + " " + d, OverlapSpecific, "Foo-Lite"},
+		{a + " " + c + " " + d, OverlapExact, "Foo"},
+		{a + " " + c + " " + d + " " + b, OverlapCoverage, "Foo"},
+		{a + " " + c + " and nobbody minds at all", OverlapLongest, "Foo"},
+		{a + " " + c + " and nobbody minds at all", OverlapExact, "Foo-Lite"},
+		{a + " " + c + " " + d, OverlapCompare, "Foo-Lite"},
+		{a + " " + c + " " + d + " " + b, OverlapCompare, "Foo"},
+	} {
+		cov, err := s.ScanWithOptions([]byte(tt.text), Options{Overlap: tt.policy})
+		if err != nil || len(cov.Match) == 0 || cov.Match[0].ID != tt.want {
+			t.Errorf("ScanWithOptions(%q, Overlap: %d) = %+v, %v, want %s", tt.text, tt.policy, cov.Match, err, tt.want)
+		}
+	}
+
+	if _, err := ScanWithOptions([]byte(mitText), Options{Overlap: -1}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("ScanWithOptions(Overlap: -1) = %v, want ErrInvalidOptions", err)
+	}
+	for _, p := range []OverlapPolicy{OverlapCoverage, OverlapSpecific, OverlapExact, OverlapCompare} {
+		if cov, err := ScanWithOptions([]byte(mitText), Options{Overlap: p}); err != nil || !reflect.DeepEqual(cov, Scan([]byte(mitText))) {
+			t.Errorf("ScanWithOptions(mitText, Overlap: %d) = %+v, %v, want same as Scan", p, cov, err)
+		}
+	}
+}
+
+func TestApproxPercent(t *testing.T) {
+	// Change one word in every sentence or so of the MIT license.
+	text := mitText
+	for _, r := range [][2]string{
+		{"hereby granted", "herewith granted"},
+		{"free of charge", "free of cost"},
+		{"persons to whom", "people to whom"},
+		{"shall be included", "must be included"},
+		{"without warranty of any kind", "without guarantee of any kind"},
+		{"in no event", "in no case"},
+	} {
+		if !strings.Contains(text, r[0]) {
+			t.Fatalf("mitText lacks %q", r[0])
+		}
+		text = strings.Replace(text, r[0], r[1], 1)
+	}
+	text = "This file is part of the widget project.\n\n" + text
+	start := strings.Index(text, "copyright")
+
+	if cov, _ := ScanWithOptions([]byte(text), Options{}); len(cov.Match) != 0 {
+		t.Fatalf("ScanWithOptions = %+v, want no matches", cov.Match)
+	}
+	if cov, _ := ScanWithOptions([]byte(text), Options{ApproxPercent: 99}); len(cov.Match) != 0 {
+		t.Errorf("ScanWithOptions(ApproxPercent: 99) = %+v, want no matches", cov.Match)
+	}
+	cov, err := ScanWithOptions([]byte(text), Options{ApproxPercent: 90})
+	if err != nil || len(cov.Match) != 1 {
+		t.Fatalf("ScanWithOptions(ApproxPercent: 90) = %+v, %v, want one match", cov.Match, err)
+	}
+	if m := cov.Match[0]; m.ID != "MIT" || !m.IsApprox || m.Start != start || m.End != len(text) {
+		t.Errorf("ScanWithOptions(ApproxPercent: 90) = %+v, want approximate MIT at [%d:%d]", m, start, len(text))
+	}
+
+	// Exact matches are not affected.
+	if cov, err := ScanWithOptions([]byte(mitText), Options{ApproxPercent: 90}); err != nil || !reflect.DeepEqual(cov, Scan([]byte(mitText))) {
+		t.Errorf("ScanWithOptions(mitText, ApproxPercent: 90) = %+v, %v, want same as Scan", cov, err)
+	}
+	if _, err := ScanWithOptions([]byte(mitText), Options{ApproxPercent: 101}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("ScanWithOptions(ApproxPercent: 101) = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestApproxPercentEmbedded(t *testing.T) {
+	// Replace every 12th word of the MIT license
+	// and surround it with 300 lines of unrelated code,
+	// which share many common words with other licenses.
+	f := strings.Fields(mitText)
+	for i := 11; i < len(f); i += 12 {
+		f[i] = "zzz"
+	}
+	var code strings.Builder
+	for i := 0; i < 75; i++ {
+		fmt.Fprintf(&code, "// f%d returns the number of items in the list that are not yet used by any of the other parts of the program.\nfunc f%d(list []int) int { return len(list) - %d }\n", i, i, i)
+	}
+	license := strings.Join(f, " ") + "\n"
+	text := code.String() + license + code.String()
+	start := len(code.String())
+	end := start + strings.LastIndex(license, "software") + len("software")
+
+	cov, err := ScanWithOptions([]byte(text), Options{ApproxPercent: 85})
+	if err != nil || len(cov.Match) != 1 {
+		t.Fatalf("ScanWithOptions(ApproxPercent: 85) = %+v, %v, want one match", cov.Match, err)
+	}
+	if m := cov.Match[0]; m.ID != "MIT" || !m.IsApprox || m.Start != start || m.End != end {
+		t.Errorf("ScanWithOptions(ApproxPercent: 85) = %+v, want approximate MIT at [%d:%d]", m, start, end)
+	}
+}
+
+func TestAlternatives(t *testing.T) {
+	skipProfile(t)
+	text, _ := Text("BSD-3-Clause-LBNL")
+	cov := Scan([]byte(text))
+	if len(cov.Match) != 1 || cov.Match[0].ID != "BSD-3-Clause-LBNL" {
+		t.Fatalf("Scan(BSD-3-Clause-LBNL) = %+v, want BSD-3-Clause-LBNL", cov.Match)
+	}
+	if len(cov.Alternatives) != 1 {
+		t.Fatalf("Scan(BSD-3-Clause-LBNL).Alternatives = %+v, want one", cov.Alternatives)
+	}
+	if a := cov.Alternatives[0]; a.Match != 0 || a.ID != "BSD-3-Clause" || a.End <= cov.Match[0].Start || a.End >= cov.Match[0].End || a.Words < 200 {
+		t.Errorf("Scan(BSD-3-Clause-LBNL).Alternatives[0] = %+v, want BSD-3-Clause inside %+v", a, cov.Match[0])
+	}
+
+	// Alternatives survive windowing.
+	cov2, err := ScanWithOptions([]byte(strings.Repeat("Not a license.\n", 1000)+text), Options{ChunkBytes: 8 << 10})
+	if err != nil || len(cov2.Match) != 1 || len(cov2.Alternatives) != 1 || cov2.Alternatives[0].ID != "BSD-3-Clause" {
+		t.Errorf("ScanWithOptions(..., ChunkBytes) = %+v, %v, want BSD-3-Clause-LBNL with BSD-3-Clause alternative", cov2, err)
+	}
+
+	// Uncontested matches have none.
+	if cov := Scan([]byte(mitText)); len(cov.Alternatives) != 0 {
+		t.Errorf("Scan(mitText).Alternatives = %+v, want none", cov.Alternatives)
+	}
+}
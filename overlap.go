@@ -0,0 +1,200 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"strings"
+
+	"github.com/google/licensecheck/internal/match"
+)
+
+// An Alternative is a license that matched text beginning
+// where a reported match begins but lost to it (see Options.Overlap).
+// Alternatives let a program audit a contested result
+// without scanning again.
+type Alternative struct {
+	Match int    `json:"match"` // index of the reported match in Coverage.Match
+	ID    string `json:"id"`    // license identifier
+	End   int    `json:"end"`   // end byte offset of the alternative match
+	Words int    `json:"words"` // number of words the alternative matched
+}
+
+// An OverlapPolicy says which license to report
+// when more than one matches text beginning at the same place,
+// each ending somewhere different.
+// Whichever is chosen, the scan continues after its end,
+// so the text left over by a shorter choice can match another license.
+type OverlapPolicy int
+
+const (
+	// OverlapLongest reports the license matching the most words.
+	// It is the default.
+	OverlapLongest OverlapPolicy = iota
+
+	// OverlapCoverage reports the license whose match covers
+	// the largest fraction of its canonical text (see Text),
+	// so that a license matched in full wins over a longer one
+	// matched without its optional parts.
+	// A license without canonical text counts as covered in full.
+	// Fractions within a few percent of each other are ties,
+	// which go to the license matching the most words.
+	OverlapCoverage
+
+	// OverlapSpecific reports the license with the most specific ID:
+	// a license whose ID extends another's with a suffix,
+	// as BSD-3-Clause-Clear extends BSD-3-Clause, wins over the other
+	// even if it matches fewer words.
+	// Otherwise the license matching the most words wins.
+	OverlapSpecific
+
+	// OverlapExact reports the license matched with the fewest
+	// spelling corrections, so that a license matching the text exactly
+	// wins over a longer one matching it only with misspellings.
+	// Ties go to the license matching the most words.
+	OverlapExact
+
+	// OverlapCompare compares the text from the start of the matches
+	// through the end of the longest with the canonical text (see Text)
+	// of each matched license, word by word, as Compare does,
+	// and reports the license sharing the largest percentage of words
+	// with it, as in Suggestion.Percent.
+	// It is slower than the other policies but picks the license
+	// the text is most like, where they pick by a single measure.
+	// Licenses without canonical text lose to those with it.
+	// Text longer than 20,000 words is not compared;
+	// the license matching the most words is reported instead.
+	// Ties go to the license matching the most words.
+	OverlapCompare
+)
+
+const (
+	// coverageSlack is the percentage by which one license's coverage
+	// must exceed another's for OverlapCoverage to prefer it.
+	coverageSlack = 5
+
+	// maxCompareWords is the most words that OverlapCompare compares.
+	maxCompareWords = 20000
+)
+
+// overlapChooser returns the function that chooses among
+// overlapping matches in text according to p,
+// for match.MultiLRE.MatchChoose, or nil to choose the longest.
+func (s *Scanner) overlapChooser(p OverlapPolicy, text []byte) func([]match.Word, int, []match.Candidate) int {
+	if p == OverlapLongest {
+		return nil
+	}
+	if p == OverlapCoverage {
+		s.licenseWordsOnce.Do(s.initLicenseWords)
+	}
+	return func(words []match.Word, start int, cands []match.Candidate) int {
+		if len(cands) == 1 {
+			return 0
+		}
+		// Work back from the longest, so that ties go to it.
+		best := len(cands) - 1
+		if p == OverlapCompare {
+			score := s.compareScores(text, words, start, cands)
+			for i := best - 1; i >= 0 && score != nil; i-- {
+				if score[i] > score[best] {
+					best = i
+				}
+			}
+			return best
+		}
+		if p == OverlapCoverage {
+			// Count the copyright line that scanWindow adds to the match,
+			// as the canonical texts do.
+			start = s.copyrightStart(words, start)
+		}
+		for i := best - 1; i >= 0; i-- {
+			if s.prefer(p, start, cands[i], cands[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+}
+
+// prefer reports whether policy p prefers the candidate match a to b.
+// Both begin at the word index start,
+// or for OverlapCoverage, at their copyright line.
+func (s *Scanner) prefer(p OverlapPolicy, start int, a, b match.Candidate) bool {
+	switch p {
+	case OverlapCoverage:
+		// Compare the fractions wa/la and wb/lb of the licenses covered.
+		wa, la := a.End-start, s.licenseWords[a.ID]
+		wb, lb := b.End-start, s.licenseWords[b.ID]
+		if la == 0 || wa > la {
+			la = wa
+		}
+		if lb == 0 || wb > lb {
+			lb = wb
+		}
+		return 100*wa*lb > (100+coverageSlack)*wb*la
+	case OverlapSpecific:
+		return strings.HasPrefix(s.licenses[a.ID].ID, s.licenses[b.ID].ID+"-")
+	case OverlapExact:
+		return a.Fixed < b.Fixed
+	}
+	return false
+}
+
+// copyrightStart returns the index of the word "copyright"
+// to which scanWindow extends a match beginning at words[start],
+// or start if there is none.
+func (s *Scanner) copyrightStart(words []match.Word, start int) int {
+	copyright := s.re.Dict().Lookup("copyright")
+	if copyright < 0 {
+		return start
+	}
+	i := start - maxCopyrightWords
+	if i < 0 {
+		i = 0
+	}
+	for ; i < start; i++ {
+		if words[i].ID == copyright {
+			return i
+		}
+	}
+	return start
+}
+
+// initLicenseWords initializes s.licenseWords.
+func (s *Scanner) initLicenseWords() {
+	s.licenseWords = make([]int, len(s.licenses))
+	d := s.newDict()
+	for i, l := range s.licenses {
+		if text, ok := s.Text(l.ID); ok {
+			s.licenseWords[i] = len(d.Split(text))
+		}
+	}
+}
+
+// compareScores returns the percentage of words that the text
+// from words[start] through the end of the longest candidate
+// has in common with the canonical text of each candidate's license,
+// or -1 for a license without canonical text.
+// It returns nil if the text is too long to compare.
+func (s *Scanner) compareScores(text []byte, words []match.Word, start int, cands []match.Candidate) []float64 {
+	end := cands[len(cands)-1].End
+	if end-start > maxCompareWords {
+		return nil
+	}
+	region := text[words[start].Lo:words[end-1].Hi]
+	score := make([]float64, len(cands))
+	byID := make(map[int]float64)
+	for i, c := range cands {
+		pct, ok := byID[c.ID]
+		if !ok {
+			pct = -1
+			if ltext, ok := s.Text(s.licenses[c.ID].ID); ok {
+				pct = Compare(region, []byte(ltext)).percent()
+			}
+			byID[c.ID] = pct
+		}
+		score[i] = pct
+	}
+	return score
+}
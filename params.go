@@ -0,0 +1,144 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A Parameter is the value a matched license text gives
+// to one of the license's parameters, such as the Change Date
+// of the Business Source License (BUSL-1.1),
+// which says when the licensed work becomes available
+// under the Change License.
+type Parameter struct {
+	Match int    `json:"match"` // index of the match in Coverage.Match
+	Name  string `json:"name"`  // name of the parameter, as in the license text, such as "Change Date"
+	Value string `json:"value"` // value of the parameter, with lines joined by spaces
+}
+
+// licenseParams maps the ID of each license with parameters
+// to a function returning the parameters set in the text of a match,
+// with their Match fields unset.
+var licenseParams = map[string]func(text string) []Parameter{
+	"BUSL-1.1": namedParams("Licensor", "Licensed Work", "Additional Use Grant", "Change Date", "Change License"),
+	"OFL-1.0":  reservedFontName,
+	"OFL-1.1":  reservedFontName,
+}
+
+// paramLineRE matches a line of the form "Name: value",
+// possibly after comment markers or other punctuation,
+// as in the parameters block of the Business Source License.
+var paramLineRE = regexp.MustCompile(`^[^\pL\pN]*([\pL][\pL ]*?)[ \t]*:[ \t]*(.*)$`)
+
+// setParameters sets c.Parameters from the text of each match in c
+// of a license with parameters. The text must be the text scanned,
+// in which the matches' offsets are given.
+func (c *Coverage) setParameters(text []byte) {
+	c.Parameters = nil
+	for i, m := range c.Match {
+		params := licenseParams[m.ID]
+		if params == nil || m.IsURL || m.IsTag || m.Start < 0 || m.End > len(text) {
+			continue
+		}
+		for _, p := range params(string(text[m.Start:m.End])) {
+			p.Match = i
+			c.Parameters = append(c.Parameters, p)
+		}
+	}
+}
+
+// namedParams returns a function returning the parameters with the given names
+// set in a text by lines of the form "Name: value".
+// A value continues on the following lines until a blank line
+// or the next parameter, as in:
+//
+//	Licensed Work:        Gopher Server 1.2.0
+//	                      The Licensed Work is (c) 2023 Go Gopher, Inc.
+//	Change Date:          2027-01-01
+//
+// Each name is reported at most once, for its first line.
+func namedParams(names ...string) func(text string) []Parameter {
+	isName := make(map[string]bool)
+	for _, name := range names {
+		isName[strings.ToLower(name)] = true
+	}
+	return func(text string) []Parameter {
+		return parseNamedParams(text, isName)
+	}
+}
+
+// parseNamedParams implements the functions returned by namedParams.
+func parseNamedParams(text string, isName map[string]bool) []Parameter {
+	var list []Parameter
+	seen := make(map[string]bool)
+	cur := -1 // index in list of the parameter being continued, or -1
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if m := paramLineRE.FindStringSubmatch(line); m != nil && isName[strings.ToLower(m[1])] {
+			cur = -1
+			if key := strings.ToLower(m[1]); !seen[key] {
+				seen[key] = true
+				cur = len(list)
+				list = append(list, Parameter{Name: m[1], Value: m[2]})
+			}
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimLeft(line, "/#*;-!% \t"))
+		if line == "" {
+			cur = -1
+			continue
+		}
+		if cur >= 0 {
+			if list[cur].Value != "" {
+				list[cur].Value += " "
+			}
+			list[cur].Value += line
+		}
+	}
+	return list
+}
+
+// reservedFontRE matches the Reserved Font Name clause of the SIL Open Font License,
+// which follows the copyright notice, as in
+//
+//	Copyright (c) 2010, Jane Doe (https://example.com/),
+//	with Reserved Font Name "Gopher Sans".
+//
+// The name ends at the first period followed by white space.
+var reservedFontRE = regexp.MustCompile(`(?is)\bwith\s+Reserved\s+Font\s+Names?\s+(.+?)\.(?:\s|$)`)
+
+// reservedFontName returns the Reserved Font Name set in text
+// by the copyright notice of the SIL Open Font License, if any,
+// with white space collapsed and, if it is a single quoted name, the quotation marks trimmed,
+// as the parameter "Reserved Font Name".
+// The placeholder in the license's own text is not reported.
+func reservedFontName(text string) []Parameter {
+	m := reservedFontRE.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	name := strings.Join(strings.Fields(m[1]), " ")
+	if t := strings.Trim(name, `"“”`); !strings.ContainsAny(t, `"“”`) {
+		name = t // a single quoted name
+	}
+	if name == "" || name == "<Reserved Font Name>" {
+		return nil
+	}
+	return []Parameter{{Name: "Reserved Font Name", Value: name}}
+}
+
+// Parameter returns the value given in c to the parameter with the given name
+// of the match c.Match[i], such as c.Parameter(i, "Change Date"),
+// or the empty string if there is none. The name is compared without regard to case.
+func (c Coverage) Parameter(i int, name string) string {
+	for _, p := range c.Parameters {
+		if p.Match == i && strings.EqualFold(p.Name, name) {
+			return p.Value
+		}
+	}
+	return ""
+}
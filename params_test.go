@@ -0,0 +1,93 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParameters(t *testing.T) {
+	skipProfile(t)
+	data, err := os.ReadFile("testdata/BUSL-1.1.t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	text = text[strings.Index(text, "Business Source License"):]
+
+	want := []Parameter{
+		{0, "Licensor", "Go Gopher, Inc."},
+		{0, "Licensed Work", "Gopher Server 1.2.0 The Licensed Work is (c) 2023 Go Gopher, Inc."},
+		{0, "Additional Use Grant", "You may make production use of the Licensed Work, " +
+			"provided Your use does not include offering the Licensed Work to third parties " +
+			"on a hosted or embedded basis in order to compete with Go Gopher's paid version(s) of the Licensed Work."},
+		{0, "Change Date", "Four years from the date the Licensed Work is published."},
+		{0, "Change License", "MPL 2.0"},
+	}
+	cov := Scan([]byte(text))
+	if len(cov.Match) != 1 || cov.Match[0].ID != "BUSL-1.1" {
+		t.Fatalf("Scan(BUSL) = %+v, want BUSL-1.1 match", cov.Match)
+	}
+	if !reflect.DeepEqual(cov.Parameters, want) {
+		t.Errorf("Scan(BUSL).Parameters:\nhave %q\nwant %q", cov.Parameters, want)
+	}
+	if have := cov.Parameter(0, "change date"); have != want[3].Value {
+		t.Errorf("Parameter(0, change date) = %q, want %q", have, want[3].Value)
+	}
+	if have := cov.Parameter(1, "Change Date"); have != "" {
+		t.Errorf("Parameter(1, Change Date) = %q, want empty", have)
+	}
+
+	// Parameters in a comment, preceded by another license.
+	mit, _ := Text("MIT")
+	src := "/*\n" + mit + "*/\n\n" + "// " + strings.ReplaceAll(strings.TrimSpace(text), "\n", "\n// ") + "\n"
+	cov, err = ScanWithOptions([]byte(src), Options{Source: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cov.Match) != 2 || cov.Match[1].ID != "BUSL-1.1" {
+		t.Fatalf("ScanWithOptions(Go source) = %+v, want MIT, BUSL-1.1", cov.Match)
+	}
+	for i := range want {
+		want[i].Match = 1
+	}
+	if !reflect.DeepEqual(cov.Parameters, want) {
+		t.Errorf("ScanWithOptions(Go source).Parameters:\nhave %q\nwant %q", cov.Parameters, want)
+	}
+
+	// The Reserved Font Name of the SIL Open Font License.
+	ofl, _ := Text("OFL-1.1")
+	for _, tt := range []struct {
+		clause string
+		name   string
+	}{
+		{"with Reserved Font Name \"Gopher Sans\".", "Gopher Sans"},
+		{"with Reserved Font Names \"Gopher\" and \"Gopher Mono\".", `"Gopher" and "Gopher Mono"`},
+		{"with Reserved Font Name Go 1.0.", "Go 1.0"},
+		{"", ""},
+	} {
+		text := strings.Replace(ofl, "Copyright (c) <dates>, <Copyright Holder> (<URL|email>),\n\nwith Reserved Font Name <Reserved Font Name>.",
+			"Copyright (c) 2010, Jane Doe (https://example.com/),\n"+tt.clause, 1)
+		cov := Scan([]byte(text))
+		if len(cov.Match) != 1 || cov.Match[0].ID != "OFL-1.1" || !cov.Match[0].IsExact {
+			t.Errorf("Scan(OFL with %q) = %+v, want exact OFL-1.1 match", tt.clause, cov.Match)
+			continue
+		}
+		if have := cov.Parameter(0, "Reserved Font Name"); have != tt.name {
+			t.Errorf("Scan(OFL with %q): Reserved Font Name = %q, want %q", tt.clause, have, tt.name)
+		}
+	}
+	if cov := Scan([]byte(ofl)); cov.Parameters != nil {
+		t.Errorf("Scan(OFL-1.1 text).Parameters = %+v, want nil", cov.Parameters)
+	}
+
+	// Other licenses have no parameters.
+	if cov := Scan([]byte(mit)); cov.Parameters != nil {
+		t.Errorf("Scan(MIT).Parameters = %+v, want nil", cov.Parameters)
+	}
+}
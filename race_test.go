@@ -0,0 +1,12 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build race
+// +build race
+
+package licensecheck
+
+// raceEnabled reports whether the race detector is on,
+// which drops sync.Pool items and so makes allocation counts meaningless.
+const raceEnabled = true
@@ -0,0 +1,242 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package readme finds the license section of a README file
+// and identifies the licenses it mentions.
+//
+// Many projects declare their license only in a README,
+// under a heading like "## License", with a sentence such as
+// "This project is licensed under the MIT License."
+// Scanning the whole README for license text finds nothing,
+// and the surrounding prose would dilute any match.
+// Scan instead isolates the license section and reports
+// both the license texts and notices that licensecheck recognizes in it
+// and the licenses it refers to by name or by URL.
+//
+// Headings are recognized in Markdown (# and underlined styles),
+// reStructuredText (underlined), and AsciiDoc (=) syntax,
+// using the word for license in several languages.
+package readme
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/manifest"
+)
+
+// A Result describes the license section of a README.
+type Result struct {
+	Start, End int // byte offsets of the section in the README, including its heading
+
+	// Coverage is the result of scanning the section with licensecheck.Scan.
+	// Offsets in Coverage.Match are offsets in the README, not the section.
+	Coverage licensecheck.Coverage
+
+	// Refs lists the licenses that the section refers to by name or URL,
+	// in the order they appear, such as "the MIT License" in
+	// "This project is licensed under the MIT License."
+	Refs []Ref
+}
+
+// A Ref is a reference to a license by name or by URL.
+type Ref struct {
+	ID         string // SPDX ID of the license (see manifest.LicenseID)
+	Start, End int    // byte offsets of the name or URL in the README
+	IsURL      bool   // whether the reference is a URL
+}
+
+// Scan finds the license section in the README text and scans it.
+// It reports false if the README has no license section.
+func Scan(text []byte) (*Result, bool) {
+	start, end, ok := Section(text)
+	if !ok {
+		return nil, false
+	}
+	r := &Result{Start: start, End: end}
+	r.Coverage = licensecheck.Scan(text[start:end])
+	for i := range r.Coverage.Match {
+		r.Coverage.Match[i].Start += start
+		r.Coverage.Match[i].End += start
+	}
+	r.Refs = refs(text, start, end)
+	return r, true
+}
+
+// licenseWords are the words for license, in lower case,
+// that mark a heading as a license section.
+var licenseWords = []string{
+	"license", "licence", "licensing", // English
+	"lizenz",            // German
+	"licencia",          // Spanish
+	"licença",           // Portuguese
+	"licenza",           // Italian
+	"licentie",          // Dutch
+	"licens",            // Danish, Norwegian, Swedish
+	"licencja",          // Polish
+	"лицензия",          // Russian
+	"许可证", "许可协议", "授权", // Simplified Chinese
+	"授權",           // Traditional Chinese
+	"ライセンス",        // Japanese
+	"라이선스", "라이센스", // Korean
+}
+
+// maxHeadingWords is the most words a license section heading can have.
+// Longer headings, like "How to contribute to the license list",
+// are about something else.
+const maxHeadingWords = 5
+
+// A heading is a section heading in a README.
+type heading struct {
+	start int    // offset of the heading's first line
+	end   int    // offset just past the heading, including any underline
+	level int    // 1 for top-level headings, 2 for their subsections, and so on
+	title string // heading text
+}
+
+var (
+	atxRE       = regexp.MustCompile(`^ {0,3}(#{1,6})[ \t]+(.*?)[ \t#]*$`)
+	asciidocRE  = regexp.MustCompile(`^(={1,6})[ \t]+(.*?)[ \t]*$`)
+	underlineRE = regexp.MustCompile(`^([=\-~^*+#"'` + "`" + `])+[ \t]*$`)
+)
+
+// Section returns the offsets of the license section in the README text:
+// the first section whose heading is a word for license,
+// up to the next heading at the same or a higher level.
+// It reports false if there is no such section.
+func Section(text []byte) (start, end int, ok bool) {
+	hs := headings(text)
+	for i, h := range hs {
+		if !isLicenseTitle(h.title) {
+			continue
+		}
+		end := len(text)
+		for _, next := range hs[i+1:] {
+			if next.level <= h.level {
+				end = next.start
+				break
+			}
+		}
+		return h.start, end, true
+	}
+	return 0, 0, false
+}
+
+// headings returns the headings in text, skipping fenced code blocks.
+func headings(text []byte) []heading {
+	var hs []heading
+	var lines []string
+	var offs []int
+	for off := 0; off < len(text); {
+		line := text[off:]
+		n := len(line)
+		if i := bytes.IndexByte(line, '\n'); i >= 0 {
+			line, n = line[:i], i+1
+		}
+		lines = append(lines, strings.TrimRight(string(line), "\r"))
+		offs = append(offs, off)
+		off += n
+	}
+	offs = append(offs, len(text))
+
+	underlineLevel := make(map[byte]int) // reStructuredText levels, in order of appearance
+	fence := ""
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		if m := atxRE.FindStringSubmatch(line); m != nil {
+			hs = append(hs, heading{offs[i], offs[i+1], len(m[1]), m[2]})
+			continue
+		}
+		if m := asciidocRE.FindStringSubmatch(line); m != nil {
+			hs = append(hs, heading{offs[i], offs[i+1], len(m[1]), m[2]})
+			continue
+		}
+		if trimmed != "" && i+1 < len(lines) && !underlineRE.MatchString(line) {
+			u := strings.TrimSpace(lines[i+1])
+			if underlineRE.MatchString(u) && len(u) >= 3 {
+				level, ok := underlineLevel[u[0]]
+				if !ok {
+					switch {
+					case len(underlineLevel) == 0 && u[0] == '-':
+						// Markdown: - is the second level, even if it comes first.
+						level = 2
+					default:
+						level = len(underlineLevel) + 1
+					}
+					underlineLevel[u[0]] = level
+				}
+				hs = append(hs, heading{offs[i], offs[i+2], level, trimmed})
+				i++
+			}
+		}
+	}
+	return hs
+}
+
+// isLicenseTitle reports whether title is the heading of a license section.
+func isLicenseTitle(title string) bool {
+	title = strings.ToLower(strings.Trim(title, " \t*_`:#"))
+	if len(strings.Fields(title)) > maxHeadingWords {
+		return false
+	}
+	for _, w := range licenseWords {
+		if strings.Contains(title, w) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// underRE matches phrases like "licensed under the terms of the MIT License",
+	// with the license name, up to the end of the sentence, in the first submatch.
+	underRE = regexp.MustCompile(`(?i)\b(?:licen[sc]ed|released|distributed|available|published|provided)\s+under\s+(?:the\s+)?(?:terms\s+of\s+(?:the\s+)?)?\[?([^\n;()\[\]]+?)\]?(?:\.(?:\s|$)|[\n;()\[]|$)`)
+
+	urlRE = regexp.MustCompile(`https?://[^\s<>()\[\]"']+`)
+)
+
+// refs returns the license references in text[start:end].
+func refs(text []byte, start, end int) []Ref {
+	var list []Ref
+	section := text[start:end]
+	for _, m := range underRE.FindAllSubmatchIndex(section, -1) {
+		// The name may be followed by more of the sentence,
+		// as in "the MIT License, see LICENSE for details",
+		// but a comma can also be part of the name,
+		// as in "the Apache License, Version 2.0".
+		name := string(section[m[2]:m[3]])
+		id, ok := manifest.LicenseID(name, "")
+		if !ok {
+			if i := strings.Index(name, ","); i >= 0 {
+				name = name[:i]
+				id, ok = manifest.LicenseID(name, "")
+			}
+		}
+		if ok {
+			list = append(list, Ref{ID: id, Start: start + m[2], End: start + m[2] + len(name)})
+		}
+	}
+	for _, m := range urlRE.FindAllIndex(section, -1) {
+		url := strings.TrimRight(string(section[m[0]:m[1]]), ".,;:")
+		if id, ok := manifest.LicenseID("", url); ok {
+			list = append(list, Ref{ID: id, Start: start + m[0], End: start + m[0] + len(url), IsURL: true})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Start < list[j].Start })
+	return list
+}
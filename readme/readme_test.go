@@ -0,0 +1,182 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readme
+
+import (
+	"strings"
+	"testing"
+)
+
+var sectionTests = []struct {
+	name string
+	text string
+	want string // section text; "" for none
+}{
+	{
+		"markdown",
+		"# Project\n\nIt does things.\n\n## Usage\n\nRun it.\n\n## License\n\nMIT.\n\n### Details\n\nMore.\n\n## Contributing\n\nPlease.\n",
+		"## License\n\nMIT.\n\n### Details\n\nMore.\n\n",
+	},
+	{
+		"last",
+		"# Project\n\n## Licence:\n\nMIT.\n",
+		"## Licence:\n\nMIT.\n",
+	},
+	{
+		"setext",
+		"Project\n=======\n\nLicense\n-------\n\nMIT.\n\nThanks\n------\n",
+		"License\n-------\n\nMIT.\n\n",
+	},
+	{
+		"rst",
+		"Project\n#######\n\nUsage\n*****\n\nRun.\n\nLicense\n*******\n\nMIT.\n\nAuthors\n*******\n",
+		"License\n*******\n\nMIT.\n\n",
+	},
+	{
+		"asciidoc",
+		"= Project\n\n== License\n\nMIT.\n\n== Authors\n",
+		"== License\n\nMIT.\n\n",
+	},
+	{
+		"german",
+		"# Projekt\n\n## Lizenz\n\nMIT.\n",
+		"## Lizenz\n\nMIT.\n",
+	},
+	{
+		"japanese",
+		"# プロジェクト\n\n## ライセンス\n\nMIT\n\n## 作者\n",
+		"## ライセンス\n\nMIT\n\n",
+	},
+	{
+		"bold",
+		"# Project\n\n## **License and Copyright**\n\nMIT.\n",
+		"## **License and Copyright**\n\nMIT.\n",
+	},
+	{
+		"fenced",
+		"# Project\n\n```\n# License\n```\n\nNo license here.\n",
+		"",
+	},
+	{
+		"long heading",
+		"# Project\n\n## How to add a new license to the list\n\nEdit it.\n",
+		"",
+	},
+	{
+		"none",
+		"# Project\n\nThis project is licensed under the MIT License.\n",
+		"",
+	},
+}
+
+func TestSection(t *testing.T) {
+	for _, tt := range sectionTests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := Section([]byte(tt.text))
+			if tt.want == "" {
+				if ok {
+					t.Fatalf("Section = %q, want none", tt.text[start:end])
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("Section found none, want %q", tt.want)
+			}
+			if got := tt.text[start:end]; got != tt.want {
+				t.Fatalf("Section = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScan(t *testing.T) {
+	var tests = []struct {
+		name  string
+		text  string
+		refs  []string // ID:referring text
+		match string   // ID of a license in Coverage, if any
+	}{
+		{
+			"name",
+			"# Project\n\n## License\n\nThis project is licensed under the MIT License. See LICENSE.\n",
+			[]string{"MIT:MIT License"},
+			"",
+		},
+		{
+			"apache",
+			"# Project\n\n## License\n\nReleased under the terms of the Apache License, Version 2.0.\n",
+			[]string{"Apache-2.0:Apache License, Version 2.0"},
+			"",
+		},
+		{
+			"comma",
+			"# Project\n\n## License\n\nDistributed under the BSD 3-Clause License, see LICENSE for details.\n",
+			[]string{"BSD-3-Clause:BSD 3-Clause License"},
+			"",
+		},
+		{
+			"link",
+			"# Project\n\n## License\n\nLicensed under [Apache 2.0](https://www.apache.org/licenses/LICENSE-2.0).\n",
+			[]string{"Apache-2.0:Apache 2.0", "Apache-2.0:https://www.apache.org/licenses/LICENSE-2.0"},
+			"",
+		},
+		{
+			"unknown",
+			"# Project\n\n## License\n\nReleased under a license of my own devising.\n",
+			nil,
+			"",
+		},
+		{
+			"text",
+			"# Project\n\n## License\n\n" + strings.TrimSpace(mit) + "\n\n## Authors\n\nMe.\n",
+			nil,
+			"MIT",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := Scan([]byte(tt.text))
+			if !ok {
+				t.Fatalf("Scan found no license section")
+			}
+			var refs []string
+			for _, ref := range r.Refs {
+				refs = append(refs, ref.ID+":"+tt.text[ref.Start:ref.End])
+			}
+			if strings.Join(refs, "\n") != strings.Join(tt.refs, "\n") {
+				t.Errorf("Refs = %q, want %q", refs, tt.refs)
+			}
+			if tt.match != "" {
+				if len(r.Coverage.Match) == 0 || r.Coverage.Match[0].ID != tt.match {
+					t.Fatalf("Coverage.Match = %+v, want %s", r.Coverage.Match, tt.match)
+				}
+				m := r.Coverage.Match[0]
+				if got := tt.text[m.Start:m.End]; !strings.HasPrefix(got, "Permission is hereby granted") {
+					t.Errorf("match text begins %q, want license text", got[:20])
+				}
+			}
+		})
+	}
+}
+
+const mit = `
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+`
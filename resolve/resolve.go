@@ -0,0 +1,300 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package resolve fetches the content behind URLs in scanned text
+// and matches it, for files that refer to a license only by a URL
+// that licensecheck does not know.
+//
+// A file saying just "Licensed under https://example.org/LICENSE.txt"
+// has no license text to match. A Resolver fetches each such URL,
+// scans what it finds there, and adds a match for the URL
+// to the file's coverage:
+//
+//	r := &resolve.Resolver{AllowHosts: []string{"example.org"}}
+//	cov, _ := licensecheck.ScanWithOptions(text, opts)
+//	cov, res := r.Resolve(ctx, text, cov)
+//	for _, x := range res {
+//		if x.Err != nil {
+//			fmt.Printf("%s: %v\n", x.URL, x.Err)
+//		}
+//	}
+//
+// Only URLs on allowed hosts are fetched, each only once per Resolver.
+//
+// This package makes network requests.
+package resolve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/licensecheck"
+)
+
+// DefaultAllowHosts are the hosts fetched from when Resolver.AllowHosts is nil:
+// the sites of the organizations that publish common licenses.
+var DefaultAllowHosts = []string{
+	"apache.org",
+	"creativecommons.org",
+	"eclipse.org",
+	"gnu.org",
+	"mozilla.org",
+	"opensource.org",
+	"spdx.org",
+}
+
+// Default limits for a Resolver.
+const (
+	DefaultTimeout  = 10 * time.Second
+	DefaultMaxBytes = 1 << 20
+)
+
+// A Resolver fetches and matches the content behind URLs.
+// The zero Resolver is ready to use.
+// A Resolver is safe for use by multiple goroutines
+// and must not be copied after first use.
+type Resolver struct {
+	// Client is the HTTP client to use.
+	// If nil, http.DefaultClient is used.
+	// Redirects to hosts that are not allowed are refused
+	// whatever the client's CheckRedirect function.
+	Client *http.Client
+
+	// AllowHosts lists the hosts whose URLs may be fetched.
+	// An entry also allows its subdomains: "gnu.org" allows "www.gnu.org".
+	// If nil, DefaultAllowHosts is used.
+	// A non-nil empty list allows nothing.
+	AllowHosts []string
+
+	// Timeout limits the time taken by each fetch.
+	// If zero, the limit is DefaultTimeout.
+	Timeout time.Duration
+
+	// MaxBytes limits the size of fetched content.
+	// Longer content is an error.
+	// If zero, the limit is DefaultMaxBytes.
+	MaxBytes int64
+
+	// Scanner is the scanner to use for fetched content.
+	// If nil, the built-in license set is used.
+	Scanner *licensecheck.Scanner
+
+	mu    sync.Mutex
+	cache map[string]*fetch
+}
+
+// A fetch is a single fetch and scan of a URL, shared by all callers.
+type fetch struct {
+	done chan struct{}
+	cov  licensecheck.Coverage
+	err  error
+}
+
+// A Resolution is the result of resolving one URL.
+type Resolution struct {
+	URL        string
+	Start, End int // byte offsets of the URL in the text
+
+	// Coverage is the result of scanning the content behind the URL.
+	Coverage licensecheck.Coverage
+
+	// ID is the license the content matches, if any:
+	// the one whose match covers the most of it.
+	ID string
+
+	// Err is the error from fetching or scanning the content, if any,
+	// such as licensecheck.ErrNoMatch if the content holds no license,
+	// or ErrNotAllowed if the URL's host is not allowed.
+	Err error
+}
+
+// ErrNotAllowed is the Resolution.Err for a URL on a host
+// that is not allowed by Resolver.AllowHosts.
+var ErrNotAllowed = errors.New("resolve: host not allowed")
+
+// urlRE matches the URLs in a text.
+var urlRE = regexp.MustCompile(`https?://[^\s<>"'()\[\]{}]+`)
+
+// Resolve finds the URLs in text that no match in cov covers,
+// fetches and scans the content of each, and returns
+// cov with a match added for each URL whose content matches a license,
+// along with a Resolution for each URL found.
+// The added matches have IsURL set and span the URL in text;
+// Percent is left unchanged.
+// Fetches are shared among concurrent calls and cached,
+// failures included: a Resolver fetches each URL only once,
+// unless the context of the call fetching it is canceled.
+func (r *Resolver) Resolve(ctx context.Context, text []byte, cov licensecheck.Coverage) (licensecheck.Coverage, []Resolution) {
+	var list []Resolution
+	added := false
+	match := append([]licensecheck.Match(nil), cov.Match...)
+	for _, loc := range urlRE.FindAllIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		for end > start && strings.IndexByte(".,;:!?", text[end-1]) >= 0 {
+			end--
+		}
+		if covered(cov.Match, start, end) {
+			continue
+		}
+		res := Resolution{URL: string(text[start:end]), Start: start, End: end}
+		res.Coverage, res.Err = r.fetch(ctx, res.URL)
+		size := 0
+		for _, m := range res.Coverage.Match {
+			if !m.IsURL && m.End-m.Start > size {
+				res.ID, size = m.ID, m.End-m.Start
+			}
+		}
+		if res.ID != "" {
+			match = append(match, licensecheck.Match{ID: res.ID, Start: start, End: end, IsURL: true})
+			added = true
+		}
+		list = append(list, res)
+	}
+	if added {
+		cov.Match = match
+		cov.Deviations = append([]licensecheck.Deviation(nil), cov.Deviations...)
+		cov.Alternatives = append([]licensecheck.Alternative(nil), cov.Alternatives...)
+		cov.Sort()
+	}
+	return cov, list
+}
+
+// covered reports whether any match overlaps text[start:end].
+func covered(list []licensecheck.Match, start, end int) bool {
+	for _, m := range list {
+		if m.Start < end && start < m.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed reports whether the Resolver may fetch the URL.
+func (r *Resolver) Allowed(u *url.URL) bool {
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return false
+	}
+	hosts := r.AllowHosts
+	if hosts == nil {
+		hosts = DefaultAllowHosts
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, h := range hosts {
+		h = strings.ToLower(h)
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetch returns the result of fetching and scanning the URL,
+// fetching it only if no earlier call has.
+func (r *Resolver) fetch(ctx context.Context, rawURL string) (licensecheck.Coverage, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return licensecheck.Coverage{}, fmt.Errorf("resolve: %v", err)
+	}
+	if !r.Allowed(u) {
+		return licensecheck.Coverage{}, ErrNotAllowed
+	}
+	u.Fragment = ""
+	key := u.String()
+
+	r.mu.Lock()
+	if r.cache == nil {
+		r.cache = make(map[string]*fetch)
+	}
+	f := r.cache[key]
+	if f == nil {
+		f = &fetch{done: make(chan struct{})}
+		r.cache[key] = f
+		r.mu.Unlock()
+		f.cov, f.err = r.get(ctx, key)
+		close(f.done)
+		if errors.Is(f.err, context.Canceled) {
+			// Let a later caller try again.
+			r.mu.Lock()
+			delete(r.cache, key)
+			r.mu.Unlock()
+		}
+	} else {
+		r.mu.Unlock()
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return licensecheck.Coverage{}, ctx.Err()
+		}
+	}
+	return f.cov, f.err
+}
+
+// get fetches and scans the URL.
+func (r *Resolver) get(ctx context.Context, u string) (licensecheck.Coverage, error) {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client := http.DefaultClient
+	if r.Client != nil {
+		client = r.Client
+	}
+	c := *client
+	check := c.CheckRedirect
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !r.Allowed(req.URL) {
+			return fmt.Errorf("redirect to %s: %w", req.URL.Host, ErrNotAllowed)
+		}
+		if check != nil {
+			return check(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return licensecheck.Coverage{}, fmt.Errorf("resolve: %v", err)
+	}
+	resp, err := c.Do(req.WithContext(ctx))
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return licensecheck.Coverage{}, err
+		}
+		return licensecheck.Coverage{}, fmt.Errorf("resolve: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return licensecheck.Coverage{}, fmt.Errorf("resolve: fetching %s: %s", u, resp.Status)
+	}
+	max := r.MaxBytes
+	if max <= 0 {
+		max = DefaultMaxBytes
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, max+1))
+	if err != nil {
+		return licensecheck.Coverage{}, fmt.Errorf("resolve: fetching %s: %v", u, err)
+	}
+	if int64(len(data)) > max {
+		return licensecheck.Coverage{}, fmt.Errorf("resolve: fetching %s: content larger than %d bytes", u, max)
+	}
+	if r.Scanner != nil {
+		return r.Scanner.ScanWithOptions(data, licensecheck.Options{})
+	}
+	return licensecheck.ScanWithOptions(data, licensecheck.Options{})
+}
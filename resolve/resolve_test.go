@@ -0,0 +1,110 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package resolve
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/licensecheck"
+)
+
+func TestResolve(t *testing.T) {
+	mit, _ := licensecheck.Text("MIT")
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		switch r.URL.Path {
+		case "/LICENSE.txt":
+			w.Write([]byte(mit))
+		case "/license.html":
+			w.Write([]byte("<html><body><h1>License</h1><p>" + strings.ReplaceAll(mit, "\n\n", "</p><p>") + "</p></body></html>"))
+		case "/README":
+			w.Write([]byte("Nothing to see here.\n"))
+		case "/elsewhere":
+			http.Redirect(w, r, "http://example.com/LICENSE", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	text := "This code is licensed under " + srv.URL + "/LICENSE.txt.\n" +
+		"See also " + srv.URL + "/license.html#terms and " + srv.URL + "/README,\n" +
+		"or " + srv.URL + "/elsewhere and " + srv.URL + "/missing.\n" +
+		"Never https://example.com/LICENSE, though.\n"
+
+	r := &Resolver{AllowHosts: []string{"127.0.0.1"}}
+	for i := 0; i < 2; i++ {
+		cov, list := r.Resolve(context.Background(), []byte(text), licensecheck.Coverage{})
+		if len(list) != 6 {
+			t.Fatalf("Resolve found %d URLs, want 6: %+v", len(list), list)
+		}
+		want := []struct {
+			path string
+			id   string
+		}{
+			{"/LICENSE.txt", "MIT"},
+			{"/license.html#terms", "MIT"},
+			{"/README", ""},
+			{"/elsewhere", ""},
+			{"/missing", ""},
+		}
+		for j, w := range want {
+			res := list[j]
+			if res.URL != srv.URL+w.path || text[res.Start:res.End] != res.URL || res.ID != w.id {
+				t.Errorf("list[%d] = %q %q ID=%q err=%v, want %q ID=%q", j, res.URL, text[res.Start:res.End], res.ID, res.Err, srv.URL+w.path, w.id)
+			}
+			if w.id == "" && res.Err == nil {
+				t.Errorf("%s: no error", res.URL)
+			}
+		}
+		if !errors.Is(list[2].Err, licensecheck.ErrNoMatch) {
+			t.Errorf("%s: err=%v, want ErrNoMatch", list[2].URL, list[2].Err)
+		}
+		if !strings.Contains(list[3].Err.Error(), "not allowed") {
+			t.Errorf("%s: err=%v, want redirect not allowed", list[3].URL, list[3].Err)
+		}
+		if !errors.Is(list[5].Err, ErrNotAllowed) {
+			t.Errorf("%s: err=%v, want ErrNotAllowed", list[5].URL, list[5].Err)
+		}
+		if len(cov.Match) != 2 || cov.Match[0].ID != "MIT" || !cov.Match[0].IsURL || cov.Match[0].Start != list[0].Start {
+			t.Errorf("Resolve coverage = %+v, want two MIT URL matches", cov.Match)
+		}
+	}
+	if hits != 5 {
+		t.Errorf("server received %d requests, want 5 (each URL fetched once)", hits)
+	}
+
+	// URLs covered by existing matches are not fetched.
+	cov := licensecheck.Coverage{Match: []licensecheck.Match{{ID: "Apache-2.0", Start: 0, End: len(text)}}}
+	if _, list := r.Resolve(context.Background(), []byte(text), cov); len(list) != 0 {
+		t.Errorf("Resolve of covered text found %d URLs, want 0", len(list))
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	var r Resolver
+	for _, tt := range []struct {
+		url  string
+		want bool
+	}{
+		{"https://www.gnu.org/licenses/gpl-3.0.txt", true},
+		{"http://opensource.org/licenses/MIT", true},
+		{"https://notgnu.org/x", false},
+		{"ftp://gnu.org/x", false},
+		{"https://example.com/LICENSE", false},
+	} {
+		u, _ := http.NewRequest("GET", tt.url, nil)
+		if got := r.Allowed(u.URL); got != tt.want {
+			t.Errorf("Allowed(%s) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"regexp"
+	"sort"
+)
+
+// A Restriction is a clause restricting how a work may be used,
+// such as “for non-commercial use only”, found in text
+// not covered by any match, as in an unknown license.
+// Restrictions are found by phrase, not by matching known licenses,
+// so they flag licenses that no LRE describes.
+// They are reported only when Options.Restrictions is set.
+type Restriction struct {
+	Start int  `json:"start"` // start byte offset of the clause in the text
+	End   int  `json:"end"`   // end byte offset of the clause in the text
+	Type  Type `json:"type"`  // NonCommercial or UseRestrictions
+}
+
+// restrictionClauses lists the patterns of the clauses reported as Restrictions,
+// with the Type of each. Of overlapping clauses, the first to start is reported,
+// and of those starting at the same place, the one with the first pattern.
+// A “may not be used” clause runs to the end of its sentence,
+// so that the restricted use is part of it.
+var restrictionClauses = []struct {
+	re  *regexp.Regexp
+	typ Type
+}{
+	{regexp.MustCompile(`(?i)\b(?:solely|only|exclusively|strictly)\s+for\s+(?:personal\s+(?:and|or)\s+)?non[-\s]?commercial\s+(?:use|usage|purposes?)\b`), NonCommercial},
+	{regexp.MustCompile(`(?i)\bnon[-\s]?commercial\s+(?:use|usage|purposes?)\s+only\b`), NonCommercial},
+	{regexp.MustCompile(`(?i)\b(?:(?:may|shall|must|can)\s+)?not\s+(?:be\s+used\s+)?for\s+(?:any\s+)?commercial\s+(?:use|usage|purposes?)\b`), NonCommercial},
+	{regexp.MustCompile(`(?i)\bcommercial\s+use\s+is\s+(?:not\s+(?:permitted|allowed)|prohibited|forbidden)\b`), NonCommercial},
+	{regexp.MustCompile(`(?i)\b(?:academic|research|educational|personal|evaluation|internal)(?:\s+(?:and|or|/)\s+(?:academic|research|educational|personal|evaluation|internal))?\s+(?:use|usage|purposes?)\s+only\b`), UseRestrictions},
+	{regexp.MustCompile(`(?i)\b(?:solely|only|exclusively|strictly)\s+for\s+(?:academic|research|educational|personal|evaluation|internal)\s+(?:use|usage|purposes?)\b`), UseRestrictions},
+	{regexp.MustCompile(`(?i)\b(?:may|shall|must|can)\s+not\s+be\s+used\s+(?:for|in)\b[^.;]{0,200}`), UseRestrictions},
+}
+
+// notRestrictionRE matches the clauses that look like restrictions on use
+// but only restrict the use of a name, as in the BSD licenses' “may not be used
+// to endorse or promote products” and the X11 license's “shall not be used
+// in advertising”.
+var notRestrictionRE = regexp.MustCompile(`(?i)\b(?:endorse|promote|advertising|publicity)`)
+
+// setRestrictions sets c.Restrictions from the restriction clauses
+// in the parts of text, which must be the text scanned, not covered by c.Match.
+// It is like ranging over c.Unmatched(text) but assumes
+// that c.Match is sorted by Start, as after scanning,
+// to avoid allocating when there is nothing to report.
+func (c *Coverage) setRestrictions(text []byte) {
+	c.Restrictions = nil
+	pos := 0
+	for i := 0; i <= len(c.Match); i++ {
+		end := len(text)
+		if i < len(c.Match) {
+			end = c.Match[i].Start
+		}
+		if end > len(text) {
+			end = len(text)
+		}
+		if pos < end {
+			c.Restrictions = append(c.Restrictions, findRestrictions(text[pos:end], pos)...)
+		}
+		if i < len(c.Match) && c.Match[i].End > pos {
+			pos = c.Match[i].End
+		}
+	}
+}
+
+// findRestrictions returns the restriction clauses in text,
+// in order and not overlapping, with offsets increased by off.
+func findRestrictions(text []byte, off int) []Restriction {
+	var all []Restriction
+	for _, rc := range restrictionClauses {
+		for _, loc := range rc.re.FindAllIndex(text, -1) {
+			if notRestrictionRE.Match(text[loc[0]:loc[1]]) {
+				continue
+			}
+			all = append(all, Restriction{Start: loc[0], End: loc[1], Type: rc.typ})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Start < all[j].Start })
+
+	var list []Restriction
+	end := 0
+	for _, r := range all {
+		if r.Start < end {
+			continue
+		}
+		end = r.End
+		r.Start += off
+		r.End += off
+		list = append(list, r)
+	}
+	return list
+}
+
+// Restricted returns the restrictions on the use of the scanned text:
+// the NonCommercial, Discouraged, SourceAvailable, and UseRestrictions
+// type bits of all the matches in c and the types of c.Restrictions,
+// which are found only when Options.Restrictions is set.
+// A non-zero result means that the text is not an open-source license,
+// or not only one, even if no match says so.
+func (c Coverage) Restricted() Type {
+	var t Type
+	for _, m := range c.Match {
+		t |= m.Type & restrictions
+	}
+	for _, r := range c.Restrictions {
+		t |= r.Type
+	}
+	return t
+}
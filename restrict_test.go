@@ -0,0 +1,82 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"testing"
+)
+
+func TestRestrictions(t *testing.T) {
+	skipProfile(t)
+	mit, _ := Text("MIT")
+	tests := []struct {
+		name    string
+		text    string
+		clauses []string // text of each Restriction
+		typ     Type     // result of Restricted
+	}{
+		{
+			"NonCommercial",
+			"Gopher Public License\n\nYou may copy and modify this software\nfor non-commercial use only.\n",
+			[]string{"non-commercial use only"},
+			NonCommercial,
+		},
+		{
+			"Academic",
+			"This dataset is provided for academic use only. It may not be used for\nmilitary purposes or surveillance; all other rights reserved.\n",
+			[]string{"academic use only", "may not be used for\nmilitary purposes or surveillance"},
+			UseRestrictions,
+		},
+		{
+			"NotCommercial",
+			"The model shall not be used for commercial purposes.",
+			[]string{"shall not be used for commercial purposes"},
+			NonCommercial,
+		},
+		{
+			"AfterMIT",
+			mit + "\nThe Software may be used solely for research purposes.\n",
+			[]string{"solely for research purposes"},
+			UseRestrictions,
+		},
+		{
+			"Endorse",
+			"The name of the author may not be used to endorse products. It shall not be used in advertising.",
+			nil,
+			Unknown,
+		},
+		{"MIT", mit, nil, Unknown},
+	}
+	for _, tt := range tests {
+		text := []byte(tt.text)
+		if cov := Scan(text); cov.Restrictions != nil {
+			t.Errorf("%s: Scan found Restrictions %v, want none", tt.name, cov.Restrictions)
+		}
+		cov, _ := ScanWithOptions(text, Options{Restrictions: true})
+		var clauses []string
+		for _, r := range cov.Restrictions {
+			clauses = append(clauses, string(text[r.Start:r.End]))
+		}
+		if len(clauses) != len(tt.clauses) {
+			t.Errorf("%s: Restrictions = %q, want %q", tt.name, clauses, tt.clauses)
+		} else {
+			for i := range clauses {
+				if clauses[i] != tt.clauses[i] {
+					t.Errorf("%s: Restrictions = %q, want %q", tt.name, clauses, tt.clauses)
+					break
+				}
+			}
+		}
+		if typ := cov.Restricted(); typ != tt.typ {
+			t.Errorf("%s: Restricted() = %v, want %v", tt.name, typ, tt.typ)
+		}
+	}
+
+	cc, _ := Text("CommonsClause")
+	apache, _ := Text("Apache-2.0")
+	if typ := Scan([]byte(apache + "\n\n" + cc)).Restricted(); typ != NonCommercial {
+		t.Errorf("Scan(Apache-2.0 + CommonsClause).Restricted() = %v, want NonCommercial", typ)
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package reuse checks a source tree against the REUSE specification
+// (https://reuse.software/spec/), which asks that every file in a project
+// carry its own copyright and licensing information.
+//
+// A file's information comes from SPDX tags in the file itself:
+//
+//	// SPDX-FileCopyrightText: 2020 The Go Authors
+//	// SPDX-License-Identifier: BSD-3-Clause
+//
+// or, for files that cannot hold comments, such as images,
+// from the same tags in a companion file named FILE.license.
+// The text of every license used must be in the LICENSES directory,
+// in a file named for its SPDX identifier, such as LICENSES/BSD-3-Clause.txt.
+//
+// Check reports the files that lack information
+// and the license texts that are missing, unused, or wrong:
+//
+//	r, err := reuse.Check(os.DirFS(dir))
+//	if err != nil {
+//		...
+//	}
+//	if !r.Compliant() {
+//		for _, file := range r.MissingLicense {
+//			fmt.Printf("%s: no license information\n", file)
+//		}
+//	}
+//
+// The .reuse/dep5 file, which can declare licenses for many files at once,
+// is not read.
+package reuse
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/internal/spdxid"
+)
+
+// A File is the licensing information for a single file.
+type File struct {
+	Path       string   // slash-separated path of the file in the tree
+	Source     string   // path of the file holding the information: Path or Path.license
+	Licenses   []string // SPDX license expressions from SPDX-License-Identifier tags
+	Copyrights []string // copyright notices, without the leading SPDX-FileCopyrightText: or Copyright
+}
+
+// A Report is the result of checking a tree.
+// All lists are sorted.
+type Report struct {
+	Files []*File // information for every file checked
+
+	MissingLicense     []string // paths of files with no SPDX-License-Identifier tag
+	MissingCopyright   []string // paths of files with no copyright notice
+	MissingLicenseText []string // license IDs used by files but missing from LICENSES
+	UnusedLicenseText  []string // paths of files in LICENSES not used by any file
+	BadLicenseText     []string // paths of files in LICENSES whose text is a different license
+}
+
+// Compliant reports whether the tree follows the REUSE specification:
+// every file has copyright and licensing information,
+// and LICENSES holds exactly the texts of the licenses used.
+func (r *Report) Compliant() bool {
+	return len(r.MissingLicense) == 0 &&
+		len(r.MissingCopyright) == 0 &&
+		len(r.MissingLicenseText) == 0 &&
+		len(r.UnusedLicenseText) == 0 &&
+		len(r.BadLicenseText) == 0
+}
+
+// Check checks the tree in fsys against the REUSE specification.
+// It skips the LICENSES, .git, and .reuse directories,
+// the companion .license files themselves,
+// and top-level license files like LICENSE and COPYING.
+// The license texts in LICENSES are checked using licensecheck.Scan:
+// a text is reported as bad if it does not match the built-in
+// license with the same ID. Texts of licenses that are not built in,
+// such as LicenseRef-* licenses, are not checked.
+func Check(fsys fs.FS) (*Report, error) {
+	r := new(Report)
+	used := make(map[string]bool)
+	err := fs.WalkDir(fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", ".reuse", "LICENSES":
+				if file == d.Name() {
+					return fs.SkipDir
+				}
+			}
+			return nil
+		}
+		if strings.HasSuffix(file, ".license") || isLicenseFile(file) {
+			return nil
+		}
+
+		f := &File{Path: file, Source: file}
+		if _, err := fs.Stat(fsys, file+".license"); err == nil {
+			f.Source = file + ".license"
+		}
+		data, err := fs.ReadFile(fsys, f.Source)
+		if err != nil {
+			return err
+		}
+		f.parse(string(data))
+		for _, expr := range f.Licenses {
+			for _, id := range IDs(expr) {
+				used[strings.TrimSuffix(id, "+")] = true
+			}
+		}
+		if len(f.Licenses) == 0 {
+			r.MissingLicense = append(r.MissingLicense, file)
+		}
+		if len(f.Copyrights) == 0 {
+			r.MissingCopyright = append(r.MissingCopyright, file)
+		}
+		r.Files = append(r.Files, f)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reuse: %v", err)
+	}
+
+	texts, err := fs.ReadDir(fsys, "LICENSES")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("reuse: %v", err)
+	}
+	have := make(map[string]bool)
+	for _, d := range texts {
+		if d.IsDir() {
+			continue
+		}
+		file := path.Join("LICENSES", d.Name())
+		id := strings.TrimSuffix(d.Name(), path.Ext(d.Name()))
+		have[id] = true
+		if !used[id] {
+			r.UnusedLicenseText = append(r.UnusedLicenseText, file)
+			continue
+		}
+		if isBuiltin(id) {
+			data, err := fs.ReadFile(fsys, file)
+			if err != nil {
+				return nil, fmt.Errorf("reuse: %v", err)
+			}
+			if !matches(data, id) {
+				r.BadLicenseText = append(r.BadLicenseText, file)
+			}
+		}
+	}
+	for id := range used {
+		if !have[id] {
+			r.MissingLicenseText = append(r.MissingLicenseText, id)
+		}
+	}
+
+	sort.Strings(r.MissingLicenseText)
+	sort.Strings(r.UnusedLicenseText)
+	sort.Strings(r.BadLicenseText)
+	return r, nil
+}
+
+var (
+	builtinOnce sync.Once
+	builtin     map[string]bool // IDs of the built-in licenses
+)
+
+// isBuiltin reports whether id is the ID of a built-in license.
+func isBuiltin(id string) bool {
+	builtinOnce.Do(func() {
+		builtin = make(map[string]bool)
+		for _, l := range licensecheck.BuiltinLicenses() {
+			builtin[l.ID] = true
+		}
+	})
+	return builtin[id]
+}
+
+// matches reports whether text is the license with the given ID.
+// The text of a license does not say whether later versions may be used,
+// so the text for GPL-2.0-only or GPL-2.0-or-later is the same as for GPL-2.0.
+func matches(text []byte, id string) bool {
+	cov := licensecheck.Scan(text)
+	for _, m := range cov.Match {
+		if !m.IsURL && !m.IsHeader && spdxid.Same(m.ID, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLicenseFile reports whether file is a top-level
+// license file like LICENSE, LICENSE.md, or COPYING,
+// which the specification exempts from needing its own information.
+func isLicenseFile(file string) bool {
+	if strings.Contains(file, "/") {
+		return false
+	}
+	base := strings.ToUpper(strings.TrimSuffix(file, path.Ext(file)))
+	switch base {
+	case "LICENSE", "LICENCE", "COPYING", "COPYRIGHT":
+		return true
+	}
+	return false
+}
+
+var (
+	licenseTagRE   = regexp.MustCompile(`SPDX-License-Identifier:[ \t]*([^\r\n]*)`)
+	copyrightTagRE = regexp.MustCompile(`(?:SPDX-(?:File|Snippet)CopyrightText:|Copyright(?:[ \t]*\([cC]\))?|©)[ \t]*([^\r\n]*)`)
+)
+
+// parse sets f.Licenses and f.Copyrights from the tags in text.
+func (f *File) parse(text string) {
+	for _, m := range licenseTagRE.FindAllStringSubmatch(text, -1) {
+		if expr := trimComment(m[1]); expr != "" {
+			f.Licenses = append(f.Licenses, expr)
+		}
+	}
+	for _, m := range copyrightTagRE.FindAllStringSubmatch(text, -1) {
+		if c := trimComment(m[1]); c != "" {
+			f.Copyrights = append(f.Copyrights, c)
+		}
+	}
+}
+
+// trimComment trims spaces and any closing comment marker from the end of s,
+// as in "/* SPDX-License-Identifier: MIT */".
+func trimComment(s string) string {
+	s = strings.TrimSpace(s)
+	for _, end := range []string{"*/", "-->", "-}", "*)"} {
+		s = strings.TrimSpace(strings.TrimSuffix(s, end))
+	}
+	return s
+}
+
+// IDs returns the license and exception identifiers
+// in the SPDX license expression expr,
+// omitting the operators AND, OR, and WITH and the parentheses.
+// A trailing + on an identifier is kept.
+func IDs(expr string) []string {
+	var ids []string
+	for _, f := range strings.FieldsFunc(expr, func(r rune) bool { return r == ' ' || r == '\t' || r == '(' || r == ')' }) {
+		switch strings.ToUpper(f) {
+		case "AND", "OR", "WITH":
+			continue
+		}
+		ids = append(ids, f)
+	}
+	return ids
+}
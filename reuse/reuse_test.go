@@ -0,0 +1,99 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reuse
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+)
+
+func text(id string) *fstest.MapFile {
+	t, ok := licensecheck.Text(id)
+	if !ok {
+		panic("no text for " + id)
+	}
+	return &fstest.MapFile{Data: []byte(t)}
+}
+
+func TestCheck(t *testing.T) {
+	fsys := fstest.MapFS{
+		"LICENSE":                      text("MIT"),
+		"LICENSES/MIT.txt":             text("MIT"),
+		"LICENSES/Apache-2.0.txt":      text("MIT"),
+		"LICENSES/BSD-2-Clause.txt":    text("BSD-2-Clause"),
+		"LICENSES/LicenseRef-Corp.txt": {Data: []byte("Corporate license.\n")},
+		"main.go":                      {Data: []byte("// SPDX-FileCopyrightText: 2020 The Go Authors\n// SPDX-License-Identifier: MIT OR Apache-2.0\n\npackage main\n")},
+		"util.c":                       {Data: []byte("/* Copyright (c) 2020 Someone */\n/* SPDX-License-Identifier: LicenseRef-Corp */\n")},
+		"logo.png":                     {Data: []byte("\x89PNG")},
+		"logo.png.license":             {Data: []byte("SPDX-FileCopyrightText: 2020 Artist\nSPDX-License-Identifier: CC-BY-4.0\n")},
+		"doc/notes.txt":                {Data: []byte("Notes without any tags.\n")},
+		".git/config":                  {Data: []byte("[core]\n")},
+	}
+	r, err := Check(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, f := range r.Files {
+		paths = append(paths, f.Path)
+	}
+	if want := []string{"doc/notes.txt", "logo.png", "main.go", "util.c"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Files = %v, want %v", paths, want)
+	}
+	if f := r.Files[1]; f.Source != "logo.png.license" || !reflect.DeepEqual(f.Licenses, []string{"CC-BY-4.0"}) || !reflect.DeepEqual(f.Copyrights, []string{"2020 Artist"}) {
+		t.Errorf("logo.png = %+v, want information from logo.png.license", f)
+	}
+	if f := r.Files[3]; !reflect.DeepEqual(f.Licenses, []string{"LicenseRef-Corp"}) || !reflect.DeepEqual(f.Copyrights, []string{"2020 Someone"}) {
+		t.Errorf("util.c = %+v, want LicenseRef-Corp, 2020 Someone", f)
+	}
+
+	check := func(name string, have, want []string) {
+		t.Helper()
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("%s = %v, want %v", name, have, want)
+		}
+	}
+	check("MissingLicense", r.MissingLicense, []string{"doc/notes.txt"})
+	check("MissingCopyright", r.MissingCopyright, []string{"doc/notes.txt"})
+	check("MissingLicenseText", r.MissingLicenseText, []string{"CC-BY-4.0"})
+	check("UnusedLicenseText", r.UnusedLicenseText, []string{"LICENSES/BSD-2-Clause.txt"})
+	check("BadLicenseText", r.BadLicenseText, []string{"LICENSES/Apache-2.0.txt"})
+	if r.Compliant() {
+		t.Errorf("Compliant() = true, want false")
+	}
+
+	// A compliant tree.
+	fsys = fstest.MapFS{
+		"LICENSES/GPL-2.0-or-later.txt": text("GPL-2.0"),
+		"x.py":                          {Data: []byte("# Copyright 2020 Someone\n# SPDX-License-Identifier: GPL-2.0-or-later\n")},
+	}
+	r, err = Check(fsys)
+	if err != nil || !r.Compliant() {
+		t.Errorf("Check(compliant) = %+v, %v, want compliant", r, err)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	gpl, _ := licensecheck.Text("GPL-2.0")
+	for _, id := range []string{"GPL-2.0", "GPL-2.0-only", "GPL-2.0-or-later", "GPL-2.0+"} {
+		if !matches([]byte(gpl), id) {
+			t.Errorf("matches(GPL-2.0 text, %q) = false, want true", id)
+		}
+	}
+	if matches([]byte(gpl), "GPL-3.0+") {
+		t.Errorf("matches(GPL-2.0 text, %q) = true, want false", "GPL-3.0+")
+	}
+}
+
+func TestIDs(t *testing.T) {
+	ids := IDs("(MIT OR Apache-2.0) AND GPL-2.0+ WITH Classpath-exception-2.0")
+	if want := []string{"MIT", "Apache-2.0", "GPL-2.0+", "Classpath-exception-2.0"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("IDs = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+// applyRiders marks the matches in c restricted by a rider
+// (see License.IsRider) matched elsewhere in the same text.
+// A rider like the Commons Clause refers only to “the License”,
+// so it restricts every other license in the text.
+// If there are several riders, Match.Rider names the first,
+// but the restriction type bits of all of them are added.
+func (c *Coverage) applyRiders() {
+	var riders []*Match
+	for i := range c.Match {
+		if c.Match[i].IsRider {
+			riders = append(riders, &c.Match[i])
+		}
+	}
+	for i := range c.Match {
+		m := &c.Match[i]
+		if m.IsRider {
+			continue
+		}
+		for _, r := range riders {
+			if m.Rider == "" {
+				m.Rider = r.ID
+			}
+			m.Type |= r.Type & restrictions
+		}
+	}
+}
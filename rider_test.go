@@ -0,0 +1,53 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"testing"
+)
+
+func TestRiders(t *testing.T) {
+	skipProfile(t)
+	apache, _ := Text("Apache-2.0")
+	cc, _ := Text("CommonsClause")
+	bsd, _ := Text("BSD-3-Clause")
+	gne, _ := Text("GoodNotEvil")
+
+	tests := []struct {
+		name  string
+		text  string
+		id    string
+		rider string
+		typ   Type
+	}{
+		{"CommonsClause", "\"Commons Clause\" License Condition v1.0\n\n" + cc + "\n\nSoftware: Foo\nLicense: Apache 2.0\nLicensor: Bar\n\n" + apache, "Apache-2.0", "CommonsClause", NonCommercial},
+		{"CommonsClauseAfter", apache + "\n\n" + cc, "Apache-2.0", "CommonsClause", NonCommercial},
+		{"GoodNotEvil", bsd + "\n" + gne, "BSD-3-Clause", "GoodNotEvil", Discouraged},
+		{"None", apache, "Apache-2.0", "", Unknown},
+	}
+	for _, tt := range tests {
+		cov := Scan([]byte(tt.text))
+		found := false
+		for _, m := range cov.Match {
+			if m.IsRider {
+				if m.ID != tt.rider || m.Rider != "" {
+					t.Errorf("%s: rider match %s has IsRider, Rider=%q; want %s with no Rider", tt.name, m.ID, m.Rider, tt.rider)
+				}
+				continue
+			}
+			if m.ID != tt.id {
+				t.Errorf("%s: unexpected match %+v", tt.name, m)
+				continue
+			}
+			found = true
+			if m.Rider != tt.rider || m.Type != tt.typ {
+				t.Errorf("%s: %s match has Rider=%q, Type=%v; want %q, %v", tt.name, m.ID, m.Rider, m.Type, tt.rider, tt.typ)
+			}
+		}
+		if !found {
+			t.Errorf("%s: Scan = %+v, want %s match", tt.name, cov.Match, tt.id)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/google/licensecheck/internal/match"
+)
+
+// savedScannerVersion is the version of the format written by Save.
+// It must change whenever the format changes incompatibly,
+// including when the word splitting changes, since the saved
+// dictionary and DFA assume the splitting that built them.
+// Version 2 added the typo corrections in match.Dict.
+// Version 3 stopped joining split words across punctuation.
+const savedScannerVersion = 3
+
+// A savedScanner is the form of a Scanner written by Save.
+type savedScanner struct {
+	Version  int
+	Licenses []License // licenses with LREs, in match ID order
+	URLs     []License // licenses with URLs, sorted by URL
+	RE       *match.MultiLRE
+}
+
+// Save writes a precompiled form of s to w,
+// which LoadScanner can read back much faster than
+// NewScanner can compile the original licenses.
+// The LRE fields of the licenses are not saved.
+// Save fails for a Scanner created with a Tokenizer.
+func (s *Scanner) Save(w io.Writer) error {
+	s.initBuiltin()
+	if s.tokenizer != nil {
+		return errors.New("saving scanner: cannot save custom Tokenizer")
+	}
+
+	saved := &savedScanner{
+		Version:  savedScannerVersion,
+		Licenses: s.licenses,
+		RE:       s.re,
+	}
+	for _, l := range s.urls {
+		saved.URLs = append(saved.URLs, l)
+	}
+	sort.Slice(saved.URLs, func(i, j int) bool { return saved.URLs[i].URL < saved.URLs[j].URL })
+	return gob.NewEncoder(w).Encode(saved)
+}
+
+// LoadScanner reads a Scanner written by Save.
+// It fails if the data was written by an incompatible version of this package.
+func LoadScanner(r io.Reader) (*Scanner, error) {
+	var saved savedScanner
+	if err := gob.NewDecoder(r).Decode(&saved); err != nil {
+		return nil, fmt.Errorf("loading scanner: %v", err)
+	}
+	if saved.Version != savedScannerVersion {
+		return nil, fmt.Errorf("loading scanner: unsupported version %d", saved.Version)
+	}
+	if saved.RE == nil || saved.RE.Len() != len(saved.Licenses) {
+		return nil, errors.New("loading scanner: corrupt data")
+	}
+	s := &Scanner{
+		licenses: saved.Licenses,
+		urls:     make(map[string]License),
+		re:       saved.RE,
+	}
+	for _, l := range saved.URLs {
+		s.urls[l.URL] = l
+	}
+	return s, nil
+}
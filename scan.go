@@ -8,11 +8,17 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io/fs"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/licensecheck/internal/match"
+	"github.com/google/licensecheck/internal/strs"
 )
 
 var (
@@ -25,20 +31,32 @@ var (
 )
 
 // BuiltinLicenses returns the list of licenses built into the package.
-// That is, the built-in checker is equivalent to New(BuiltinLicenses()).
+// That is, the built-in scanner is equivalent to NewScanner(BuiltinLicenses()).
+// Programs built with the licensecheck_top50 tag have fewer built-in licenses
+// (see the package documentation).
+//
+// Each call returns a new list, which the caller may modify freely.
+// Each call also loads the licenses anew, since they are not kept
+// in memory after the built-in scanner is compiled,
+// so a caller that needs the list more than once should save it.
+// To recognize additional licenses along with the built-in ones,
+// append them to the list and call NewScanner.
 func BuiltinLicenses() []License {
-	// Return a copy so caller cannot change list entries.
-	list := append([]License{}, builtinLREs...)
-	m := make(map[string]Type)
+	list := builtinLREs()
+	m := make(map[string]License)
 	for _, l := range list {
-		m[l.ID] = l.Type
+		m[l.ID] = l
 	}
 	for _, l := range builtinURLs {
-		// Fill in Type from builtinLREs.
-		if typ, ok := m[l.ID]; ok {
-			l.Type = typ
+		// Fill in Type, Category, Wraps, and ListVersion from builtinLREs().
+		if lre, ok := m[l.ID]; ok {
+			l.Type, l.Category, l.Wraps, l.ListVersion = lre.Type, lre.Category, lre.Wraps, lre.ListVersion
+		} else if builtinProfile != nil {
+			// Not in this build profile.
+			continue
 		} else {
 			l.Type = Unknown
+			l.ListVersion = SPDXVersion
 		}
 		list = append(list, l)
 	}
@@ -46,14 +64,41 @@ func BuiltinLicenses() []License {
 }
 
 // A Scanner matches a set of known licenses.
+//
+// A Scanner is safe for concurrent use by multiple goroutines.
+// Its set of licenses is fixed when it is created,
+// and scanning does not lock: the indexes that a Scanner
+// builds lazily, such as those for Nearest and Match.LicenseStart,
+// are built once and then only read,
+// or are replaced by an updated copy rather than modified.
 type Scanner struct {
-	licenses []License
-	urls     map[string]License
-	re       *match.MultiLRE
+	licenses  []License
+	urls      map[string]License
+	re        *match.MultiLRE
+	tokenizer Tokenizer // nil for the built-in word splitting
+
+	nearestOnce sync.Once
+	nearest     []nearestLicense // for Nearest; see initNearest
+
+	separatedOnce sync.Once
+	separated     map[string]bool // IDs of licenses with separator lines; see initSeparated
+
+	licenseWordsOnce sync.Once
+	licenseWords     []int // words in canonical text of each license; see initLicenseWords
+
+	runsMu sync.Mutex   // serializes updates to runs
+	runs   atomic.Value // map[int]*textRuns: index of canonical text of each license; see textRuns
 }
 
 // NewScanner returns a new Scanner that recognizes the given set of licenses.
 // See the description of Scan more information.
+//
+// The order of the licenses does not matter:
+// the Scanner's results depend only on the set of licenses.
+// When more than one license matches the same text,
+// the one with the alphabetically first ID is reported,
+// except that a few specific licenses, such as BSD-4-Clause-UC,
+// are preferred over their generalizations, such as BSD-4-Clause.
 func NewScanner(licenses []License) (*Scanner, error) {
 	s := new(Scanner)
 	err := s.init(licenses)
@@ -63,23 +108,148 @@ func NewScanner(licenses []License) (*Scanner, error) {
 	return s, nil
 }
 
+// NewScannerFS returns a new Scanner that recognizes the licenses
+// defined by the files in the root directory of fsys.
+//
+// Each file named ID.lre holds the LRE for the license ID.
+// Each file named ID.txt holds the plain text of the license ID,
+// which is matched literally (see QuoteLRE).
+// Each file named ID.template.txt holds an SPDX license template
+// for the license ID (see SPDXTemplateLRE).
+// The contents of .txt files are also returned by the Scanner's Text method.
+// An optional file named "urls" lists identifying URLs,
+// one per line, each followed by the ID of its license.
+// Blank lines and lines beginning with # are ignored.
+// Other files are ignored.
+//
+// To read licenses from a subdirectory, use fs.Sub.
+// For example, to use licenses embedded in a program:
+//
+//	//go:embed licenses
+//	var licenseFS embed.FS
+//
+//	sub, _ := fs.Sub(licenseFS, "licenses")
+//	s, err := licensecheck.NewScannerFS(sub)
+func NewScannerFS(fsys fs.FS) (*Scanner, error) {
+	files, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	var list []License
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() {
+			continue
+		}
+		var id string
+		var convert func(string) (string, error)
+		switch {
+		case strings.HasSuffix(name, ".lre"):
+			id = strings.TrimSuffix(name, ".lre")
+			convert = func(s string) (string, error) { return s, nil }
+		case strings.HasSuffix(name, ".template.txt"):
+			id = strings.TrimSuffix(name, ".template.txt")
+			convert = SPDXTemplateLRE
+		case strings.HasSuffix(name, ".txt"):
+			id = strings.TrimSuffix(name, ".txt")
+			convert = func(s string) (string, error) { return QuoteLRE(s), nil }
+		default:
+			continue
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.TrimPrefix(data, utf8BOM)
+		lre, err := convert(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		l := License{ID: id, LRE: lre}
+		if !strings.HasSuffix(name, ".lre") {
+			l.Text = string(data)
+		}
+		list = append(list, l)
+	}
+
+	data, err := fs.ReadFile(fsys, "urls")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		f := strings.Fields(line)
+		if len(f) == 0 || strings.HasPrefix(f[0], "#") {
+			continue
+		}
+		if len(f) != 2 {
+			return nil, fmt.Errorf("urls:%d: invalid line: want URL and license ID", i+1)
+		}
+		list = append(list, License{URL: canonicalURL(f[0]), ID: f[1]})
+	}
+
+	return NewScanner(list)
+}
+
+// licenseLess reports whether a sorts before b in the canonical license order,
+// which decides which license is reported when more than one
+// matches the same text: the one earlier in the order.
+// The order is by ID, then Language, then LRE and URL,
+// except for some special cases listed below.
+func licenseLess(a, b *License) bool {
+	na, nb := a.ID, b.ID
+
+	// Special case: BSD-4-Clause is a generalization of BSD-4-Clause-UC.
+	// In case of multiple matches, licensecheck always returns the one earlier in the list.
+	// Make BSD-4-Clause-UC the one earlier in the list.
+	if strings.HasPrefix(na, "BSD-4-Clause") && strings.HasPrefix(nb, "BSD-4-Clause") {
+		na, nb = nb, na
+	}
+
+	// Special case: GFDL-1.[123]-invariants-* is a generalization of GFDL-1.[123]-no-invariants-*.
+	// Reverse that order too.
+	if strings.HasPrefix(na, "GFDL-") && strings.HasPrefix(nb, "GFDL-") {
+		na, nb = nb, na
+	}
+
+	if na != nb {
+		return na < nb
+	}
+	// Translations of a license follow the original.
+	if a.Language != b.Language {
+		return a.Language < b.Language
+	}
+	if a.LRE != b.LRE {
+		return a.LRE < b.LRE
+	}
+	return a.URL < b.URL
+}
+
 func (s *Scanner) init(licenses []License) error {
-	d := new(match.Dict)
+	// Sort a copy of the list, so that the results
+	// do not depend on the order of the input list.
+	licenses = append([]License(nil), licenses...)
+	sort.SliceStable(licenses, func(i, j int) bool { return licenseLess(&licenses[i], &licenses[j]) })
+
+	d := s.newDict()
 	d.Insert("copyright")
 	d.Insert("http")
 	var list []*match.LRE
 	s.urls = make(map[string]License)
 	for _, l := range licenses {
-		if l.URL != "" {
+		if _, dup := s.urls[l.URL]; l.URL != "" && !dup {
 			s.urls[l.URL] = l
 		}
 		if l.LRE != "" {
-			s.licenses = append(s.licenses, l)
 			re, err := match.ParseLRE(d, l.ID, l.LRE)
 			if err != nil {
 				return fmt.Errorf("parsing %v: %v", l.ID, err)
 			}
 			list = append(list, re)
+
+			// Once compiled, the LRE is no longer needed,
+			// and it can be large, so do not keep it around.
+			l.LRE = ""
+			s.licenses = append(s.licenses, l)
 		}
 	}
 	re, err := match.NewMultiLRE(list)
@@ -93,8 +263,25 @@ func (s *Scanner) init(licenses []License) error {
 	return nil
 }
 
+// initBuiltin initializes s if it is the built-in scanner.
+// It must be called at the start of any exported method
+// that uses the Scanner's state.
+func (s *Scanner) initBuiltin() {
+	if s == builtinScanner {
+		builtinScannerOnce.Do(func() {
+			if err := builtinScanner.init(BuiltinLicenses()); err != nil {
+				panic("licensecheck: initializing Scan: " + err.Error())
+			}
+		})
+	}
+}
+
 const maxCopyrightWords = 50
 
+// utf8BOM is the UTF-8 byte order mark, which some editors
+// write at the start of text files.
+var utf8BOM = []byte("\uFEFF")
+
 // Scan computes the coverage of the text according to the license set compiled
 // into the package. The design aims never to give a false positive.
 //
@@ -111,37 +298,230 @@ const maxCopyrightWords = 50
 // the earliest match is chosen so the returned coverage describes at most one
 // match for each section of the input.
 //
+// Scan results are reproducible: scanning the same text with the same
+// license set and options always returns identical Coverage,
+// down to the Match order, offsets, and Percent.
+//
 func Scan(text []byte) Coverage {
 	return builtinScanner.Scan(text)
 }
 
+// ScanWithOptions is like Scan but takes options controlling the scan.
+// It returns an error if the options are invalid or reject the text,
+// or if the text contains no licenses (see ErrNoMatch).
+func ScanWithOptions(text []byte, opts Options) (Coverage, error) {
+	return builtinScanner.ScanWithOptions(text, opts)
+}
+
 var urlScanRE = regexp.MustCompile(`^(?i)https?://[-a-z0-9_.]+\.(org|com)(/[-a-z0-9_.#?=]+)+/?`)
 
 // Scan is like the top-level function Scan,
 // but it uses the set of licenses in the Scanner instead of the built-in license set.
 func (s *Scanner) Scan(text []byte) Coverage {
-	if s == builtinScanner {
-		builtinScannerOnce.Do(func() {
-			if err := builtinScanner.init(BuiltinLicenses()); err != nil {
-				panic("licensecheck: initializing Scan: " + err.Error())
-			}
-		})
+	s.initBuiltin()
+	return s.scan(text, false)
+}
+
+// ScanWithOptions is like the top-level function ScanWithOptions,
+// but it uses the set of licenses in the Scanner instead of the built-in license set.
+func (s *Scanner) ScanWithOptions(text []byte, opts Options) (Coverage, error) {
+	s.initBuiltin()
+	if opts.Metrics == nil {
+		return s.scanWithOptions(text, opts)
 	}
+	start := time.Now()
+	c, err := s.scanWithOptions(text, opts)
+	opts.Metrics.Observe(ScanEvent{Bytes: len(text), Duration: time.Since(start), Coverage: c, Err: err})
+	return c, err
+}
 
-	matches := s.re.Match(string(text)) // TODO remove conversion
+// scanWithOptions implements ScanWithOptions.
+func (s *Scanner) scanWithOptions(text []byte, opts Options) (Coverage, error) {
+	truncated := false
+	switch {
+	case opts.MaxInputBytes < 0:
+		return Coverage{}, fmt.Errorf("%w: negative MaxInputBytes %d", ErrInvalidOptions, opts.MaxInputBytes)
+	case opts.ChunkBytes < 0:
+		return Coverage{}, fmt.Errorf("%w: negative ChunkBytes %d", ErrInvalidOptions, opts.ChunkBytes)
+	case opts.ApproxPercent < 0 || opts.ApproxPercent > 100:
+		return Coverage{}, fmt.Errorf("%w: ApproxPercent %d out of range", ErrInvalidOptions, opts.ApproxPercent)
+	case opts.Overlap < OverlapLongest || opts.Overlap > OverlapCompare:
+		return Coverage{}, fmt.Errorf("%w: unknown OverlapPolicy %d", ErrInvalidOptions, opts.Overlap)
+	case opts.MaxInputBytes > 0 && len(text) > opts.MaxInputBytes:
+		if !opts.Truncate {
+			return Coverage{}, fmt.Errorf("%w: %d bytes exceeds limit of %d", ErrInputTooLarge, len(text), opts.MaxInputBytes)
+		}
+		text = strs.Truncate(text, opts.MaxInputBytes)
+		truncated = true
+	}
 
 	var c Coverage
+	var err error
+	if cs := sourceSyntax(opts.Source); cs != nil {
+		comments := cs.comments(text)
+		c, err = s.scanUTF8(comments, opts)
+		clipMatches(c.Match, text, comments)
+	} else {
+		c, err = s.scanUTF8(text, opts)
+	}
+	if err != nil {
+		return Coverage{}, err
+	}
+	c.Truncated = truncated
+	if opts.Submatches {
+		s.setSubmatches(&c, text)
+	}
+	if opts.Words {
+		c.setWords(s, text)
+	}
+	if opts.EULA {
+		c.setEULA(text)
+	}
+	if opts.Restrictions {
+		c.setRestrictions(text)
+	}
+	c.setParameters(text)
+	c.applyRiders()
+	c.setConfidence()
+	if c.Empty {
+		return c, ErrEmptyInput
+	}
+	if len(c.Match) == 0 {
+		return c, ErrNoMatch
+	}
+	return c, nil
+}
+
+// scanUTF8 scans text according to opts,
+// handling invalid UTF-8 according to opts.InvalidUTF8.
+func (s *Scanner) scanUTF8(text []byte, opts Options) (Coverage, error) {
+	switch policy := opts.InvalidUTF8; policy {
+	case UTF8Replace:
+		// Handled by the word splitter.
+	case UTF8Skip:
+		if !utf8.Valid(text) {
+			clean, m := removeInvalidUTF8(text)
+			c := s.scanChunks(clean, opts)
+			for i := range c.Match {
+				c.Match[i].Start = m.start(c.Match[i].Start)
+				c.Match[i].End = m.end(c.Match[i].End)
+			}
+			for i := range c.Deviations {
+				c.Deviations[i].Start = m.start(c.Deviations[i].Start)
+				c.Deviations[i].End = m.end(c.Deviations[i].End)
+			}
+			for i := range c.Alternatives {
+				c.Alternatives[i].End = m.end(c.Alternatives[i].End)
+			}
+			return c, nil
+		}
+	case UTF8Reject:
+		if !utf8.Valid(text) {
+			return Coverage{}, ErrInvalidUTF8
+		}
+	default:
+		return Coverage{}, fmt.Errorf("%w: unknown UTF8Policy %d", ErrInvalidOptions, policy)
+	}
+	return s.scanChunks(text, opts), nil
+}
+
+// scan implements Scan. The caller must have called s.initBuiltin.
+// If ocr is true, scan tolerates OCR errors (see Options.OCR).
+func (s *Scanner) scan(text []byte, ocr bool) Coverage {
+	c, st := s.scanWindow(text, len(text), Options{OCR: ocr})
+	if st.words == 0 {
+		c.Empty = true
+	} else {
+		c.Percent = 100.0 * float64(st.matched) / float64(st.words)
+	}
+	c.setParameters(text)
+	c.applyRiders()
+	c.setConfidence()
+	return c
+}
+
+// windowStats describes the part of a text window scanned by scanWindow.
+type windowStats struct {
+	next    int // byte offset in window where the next window should start
+	words   int // number of words before next
+	matched int // number of those words covered by matches
+}
+
+// scanWindow scans text, which may be only a window into a larger text,
+// leaving c.Percent and c.Empty unset.
+// If limit < len(text), the text is not the final window:
+// scanWindow reports only the matches that start before limit,
+// and it reports the offset where scanning should resume in st.next.
+// Otherwise scanWindow scans all of text.
+// Of the options, scanWindow uses only OCR, Overlap, and Matcher.
+func (s *Scanner) scanWindow(text []byte, limit int, opts Options) (c Coverage, st windowStats) {
+	// Record the candidates that lose to each match, for c.Alternatives.
+	type alt struct {
+		start int // word index of start of match
+		cand  match.Candidate
+	}
+	var alts []alt
+	var matches *match.Matches
+	if opts.Matcher != nil {
+		matches = s.matchWith(opts.Matcher, text)
+	} else {
+		choose := s.overlapChooser(opts.Overlap, text)
+		matches = s.re.MatchBytesChoose(text, opts.OCR, func(words []match.Word, start int, cands []match.Candidate) int {
+			best := len(cands) - 1
+			if choose != nil {
+				best = choose(words, start, cands)
+			}
+		Cands:
+			for i, cand := range cands {
+				if cand.ID == cands[best].ID {
+					continue
+				}
+				// Report only the longest match of each license.
+				for _, later := range cands[i+1:] {
+					if later.ID == cand.ID {
+						continue Cands
+					}
+				}
+				alts = append(alts, alt{start, cand})
+			}
+			return best
+		})
+	}
+	defer matches.Free()
+
 	words := matches.Words
+	n := len(words) // number of words to report on
+	if limit < len(text) {
+		// Keep the matches that start before limit.
+		// Scanning resumes after the last match, or else at limit.
+		next := limit
+		for i, m := range matches.List {
+			if int(words[m.Start].Lo) >= limit {
+				matches.List = matches.List[:i]
+				break
+			}
+			if hi := int(words[m.End-1].Hi); next < hi {
+				next = hi
+			}
+		}
+		n = sort.Search(len(words), func(i int) bool { return int(words[i].Lo) >= next })
+	}
+	st.next = len(text)
+	if n < len(words) {
+		st.next = int(words[n].Lo)
+	}
+
 	total := 0
 	lastEnd := 0
 	copyright := s.re.Dict().Lookup("copyright")
-	http := s.re.Dict().Lookup("http")
 
 	// Add sentinel match trigger URL scan from last match to end of text.
-	matches.List = append(matches.List, match.Match{Start: len(words), ID: -1})
+	matches.List = append(matches.List, match.Match{Start: n, ID: -1})
 
+	k := 0 // next entry in alts
 	for _, m := range matches.List {
-		if m.Start < len(words) && lastEnd < m.Start && copyright >= 0 {
+		wordStart := m.Start // before extension to copyright line
+		if m.Start < n && lastEnd < m.Start && copyright >= 0 {
 			limit := m.Start - maxCopyrightWords
 			if limit < lastEnd {
 				limit = lastEnd
@@ -155,43 +535,29 @@ func (s *Scanner) Scan(text []byte) Coverage {
 		}
 
 		// Pick up any URLs before m.Start.
-		for i := lastEnd; i < m.Start; i++ {
-			w := &words[i]
-			if w.ID == http {
-				// Potential URL match.
-				// urlRE only considers a match at the start of the input string.
-				// Only accept URLs that end before the next scan match.
-				if u := urlScanRE.FindIndex(text[w.Lo:]); u != nil && (m.Start == len(words) || int(w.Lo)+u[1] <= int(words[m.Start].Lo)) {
-					u0, u1 := int(w.Lo)+u[0], int(w.Lo)+u[1]
-					if l, ok := s.licenseURL(string(text[u0:u1])); ok {
-						c.Match = append(c.Match, Match{
-							ID:    l.ID,
-							Type:  l.Type,
-							Start: u0,
-							End:   u1,
-							IsURL: true,
-						})
-						start := i
-						for i < m.Start && int(words[i].Hi) <= u1 {
-							i++
-						}
-						total += i - start
-						i-- // counter loop i++
-					}
-				}
+		if len(s.urls) > 0 && lastEnd < m.Start {
+			end := len(text)
+			if m.Start < len(words) {
+				end = int(words[m.Start].Lo)
 			}
+			total += s.scanURLs(&c, text, words[lastEnd:m.Start], end)
 		}
 
 		if m.ID < 0 { // sentinel added above
 			break
 		}
 
+		// Extend the match to whole lines when possible.
+		// Lines may end in \n, \r\n, or \r.
 		start := int(words[m.Start].Lo) // byte offset (unlike m.Start)
 		if m.Start == 0 {
 			start = 0
+			if bytes.HasPrefix(text, utf8BOM) {
+				start = len(utf8BOM)
+			}
 		} else {
 			prev := int(words[m.Start-1].Hi)
-			if i := bytes.LastIndexByte(text[prev:start], '\n'); i >= 0 {
+			if i := bytes.LastIndexAny(text[prev:start], "\r\n"); i >= 0 {
 				start = prev + i + 1
 			}
 		}
@@ -200,38 +566,87 @@ func (s *Scanner) Scan(text []byte) Coverage {
 			end = len(text)
 		} else {
 			next := int(words[m.End].Lo)
-			if i := bytes.IndexByte(text[end:next], '\n'); i >= 0 {
+			if i := bytes.IndexAny(text[end:next], "\r\n"); i >= 0 {
 				end = end + i + 1
+				if text[end-1] == '\r' && end < next && text[end] == '\n' {
+					end++
+				}
 			}
 		}
-		l := &s.licenses[m.ID]
-		c.Match = append(c.Match, Match{
-			ID:    l.ID,
-			Type:  l.Type,
-			Start: start,
-			End:   end,
-		})
+		lm := s.licenses[m.ID].match()
+		lm.Start, lm.End = start, end
+		lm.LicenseStart, lm.LicenseEnd = s.licenseSpan(m.ID, words[m.Start:m.End])
+		lm.MatchedWords = m.End - m.Start
+		if r := s.textRuns(m.ID); r != nil {
+			lm.LicenseWords = len(r.words)
+			lm.IsExact = s.isExact(m.ID, text, words[m.Start:m.End])
+		}
+		c.Match = append(c.Match, lm)
 		total += m.End - m.Start
 		lastEnd = m.End
-	}
 
-	if len(words) > 0 { // len(words)==0 should be impossible, but avoid NaN
-		c.Percent = 100.0 * float64(total) / float64(len(words))
+		for ; k < len(alts) && alts[k].start <= wordStart; k++ {
+			if a := alts[k]; a.start == wordStart {
+				c.Alternatives = append(c.Alternatives, Alternative{
+					Match: len(c.Match) - 1,
+					ID:    s.licenses[a.cand.ID].ID,
+					End:   int(words[a.cand.End-1].Hi),
+					Words: a.cand.End - a.start,
+				})
+			}
+		}
 	}
 
-	return c
+	st.words = n
+	st.matched = total
+	return c, st
+}
+
+// scanURLs appends to c.Match a match for each known license URL
+// in text that starts at one of words and ends by the byte offset end.
+// It returns the number of words covered by the URLs.
+func (s *Scanner) scanURLs(c *Coverage, text []byte, words []match.Word, end int) int {
+	http := s.re.Dict().Lookup("http")
+	total := 0
+	for i := 0; i < len(words); i++ {
+		w := &words[i]
+		if w.ID != http {
+			continue
+		}
+		// Potential URL match.
+		// urlRE only considers a match at the start of the input string.
+		// Only accept URLs that end before the next scan match.
+		// A URL cannot contain spaces, so stop the regexp at the first one,
+		// which keeps it from considering the entire rest of the text.
+		rest := text[w.Lo:end]
+		if j := bytes.IndexAny(rest, " \t\r\n"); j >= 0 {
+			rest = rest[:j]
+		}
+		u := urlScanRE.FindIndex(rest)
+		if u == nil {
+			continue
+		}
+		u0, u1 := int(w.Lo)+u[0], int(w.Lo)+u[1]
+		if l, ok := s.licenseURL(string(text[u0:u1])); ok {
+			um := l.match()
+			um.Start, um.End, um.IsURL = u0, u1, true
+			start := i
+			for i < len(words) && int(words[i].Hi) <= u1 {
+				i++
+			}
+			um.MatchedWords = i - start
+			c.Match = append(c.Match, um)
+			total += i - start
+			i-- // counter loop i++
+		}
+	}
+	return total
 }
 
 // licenseURL reports whether url is a known URL, and returns its name if it is.
 func (s *Scanner) licenseURL(url string) (License, bool) {
-	// We need to canonicalize the text for lookup.
-	// First, trim the leading http:// or https:// and the trailing /.
-	// Then we lower-case it.
-	url = strings.TrimPrefix(url, "http://")
-	url = strings.TrimPrefix(url, "https://")
-	url = strings.TrimSuffix(url, "/")
+	url = canonicalURL(url)
 	url = strings.TrimSuffix(url, "/legalcode") // Common for CC licenses.
-	url = strings.ToLower(url)
 	l, ok := s.urls[url]
 	if ok {
 		return l, true
@@ -249,3 +664,111 @@ func (s *Scanner) licenseURL(url string) (License, bool) {
 
 	return License{}, false
 }
+
+// LicensesOfType returns the sorted list of IDs of built-in licenses
+// whose type includes all the bits set in t.
+// If t is Unknown, LicensesOfType returns the licenses of Unknown type.
+func LicensesOfType(t Type) []string {
+	return builtinScanner.LicensesOfType(t)
+}
+
+// LicensesOfType is like the top-level function LicensesOfType,
+// but it uses the set of licenses in the Scanner instead of the built-in license set.
+func (s *Scanner) LicensesOfType(t Type) []string {
+	var ids []string
+	for typ, list := range s.LicensesByType() {
+		if t == Unknown && typ == Unknown || t != Unknown && typ&t == t {
+			ids = append(ids, list...)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// LicensesByType returns the IDs of the licenses recognized by s,
+// grouped by license type. Each list is sorted.
+func (s *Scanner) LicensesByType() map[Type][]string {
+	s.initBuiltin()
+
+	seen := make(map[string]bool)
+	m := make(map[Type][]string)
+	add := func(l License) {
+		if !seen[l.ID] {
+			seen[l.ID] = true
+			m[l.Type] = append(m[l.Type], l.ID)
+		}
+	}
+	for _, l := range s.licenses {
+		add(l)
+	}
+	for _, l := range s.urls {
+		add(l)
+	}
+	for _, list := range m {
+		sort.Strings(list)
+	}
+	return m
+}
+
+// match returns a Match of l, with the location left unset.
+func (l *License) match() Match {
+	return Match{
+		ID:          l.ID,
+		Type:        l.Type,
+		Category:    l.Category,
+		SPDXID:      l.SPDXID,
+		Name:        l.Name,
+		ListVersion: l.ListVersion,
+		Language:    l.Language,
+		IsHeader:    l.IsHeader,
+		IsRider:     l.IsRider,
+		Wraps:       l.Wraps,
+	}
+}
+
+// Text returns the canonical text of the built-in license with the given ID.
+// It reports false if the license is unknown or its text is not available.
+func Text(id string) (string, bool) {
+	return builtinScanner.Text(id)
+}
+
+// Text returns the canonical text of the license in s with the given ID.
+// The text is the Text field of the license, if set,
+// or else the text of the built-in license with the same ID.
+// Text reports false if s has no license with that ID
+// or the license's text is not available.
+func (s *Scanner) Text(id string) (string, bool) {
+	s.initBuiltin()
+
+	known := false
+	for _, l := range s.licenses {
+		if l.ID == id {
+			if l.Text != "" {
+				return l.Text, true
+			}
+			known = true
+		}
+	}
+	for _, l := range s.urls {
+		if l.ID == id {
+			if l.Text != "" {
+				return l.Text, true
+			}
+			known = true
+		}
+	}
+	if !known {
+		return "", false
+	}
+	return builtinText(id)
+}
+
+// canonicalURL returns the canonical form of url used for lookup:
+// the leading http:// or https:// and the trailing / are trimmed,
+// and the result is lower-cased.
+func canonicalURL(url string) string {
+	url = strings.TrimPrefix(url, "http://")
+	url = strings.TrimPrefix(url, "https://")
+	url = strings.TrimSuffix(url, "/")
+	return strings.ToLower(url)
+}
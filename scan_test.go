@@ -0,0 +1,483 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"unicode/utf8"
+)
+
+func TestScannerMetadata(t *testing.T) {
+	s, err := NewScanner([]License{
+		{
+			ID:     "Corp",
+			Type:   Notice,
+			LRE:    "this software is licensed under the corporate license",
+			URL:    "example.com/corp",
+			SPDXID: "LicenseRef-Corp",
+			Name:   "Example Corporate License",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := "This software is licensed under the Corporate License.\nSee https://example.com/corp for details.\n"
+	cov := s.Scan([]byte(text))
+	if len(cov.Match) != 2 {
+		t.Fatalf("Scan: got %d matches, want 2: %+v", len(cov.Match), cov.Match)
+	}
+	for _, m := range cov.Match {
+		if m.ID != "Corp" || m.Type != Notice || m.SPDXID != "LicenseRef-Corp" || m.Name != "Example Corporate License" {
+			t.Errorf("Scan: match %+v missing license metadata", m)
+		}
+	}
+}
+
+func TestQuoteLRE(t *testing.T) {
+	text := "((Copyright)) __10__ you may || may not ?? use //** this **// code\n"
+	s, err := NewScanner([]License{{ID: "Q", LRE: QuoteLRE(text)}})
+	if err != nil {
+		t.Fatalf("NewScanner(QuoteLRE(%q)): %v", text, err)
+	}
+	cov := s.Scan([]byte(text))
+	if len(cov.Match) != 1 || cov.Match[0].ID != "Q" || cov.Percent != 100 {
+		t.Errorf("Scan(%q) = %+v, want 100%% Q", text, cov)
+	}
+}
+
+func TestNewScannerFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Corp-1.0.txt":          {Data: []byte("This software is licensed under the ((Corporate)) License, version 1.0.\n")},
+		"Corp-2.0.lre":          {Data: []byte("This software is licensed under the\n((Corporate || Company))\nLicense, version 2.0.\n")},
+		"Corp-3.0.template.txt": {Data: []byte("This software is licensed under the <<var;name=\"org\";original=\"Corporate\";match=\".+\">> License, version 3.0.\n")},
+		"urls":                  {Data: []byte("# corporate URLs\n\nhttps://Example.com/corp/1.0/ Corp-1.0\n")},
+		"README.md":             {Data: []byte("not a license")},
+	}
+	s, err := NewScannerFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		text string
+		id   string
+	}{
+		{"This software is licensed under the ((Corporate)) License, version 1.0.", "Corp-1.0"},
+		{"This software is licensed under the Company License, version 2.0.", "Corp-2.0"},
+		{"This software is licensed under the Example Company License, version 3.0.", "Corp-3.0"},
+		{"See http://example.com/corp/1.0 for details.", "Corp-1.0"},
+	} {
+		cov := s.Scan([]byte(tt.text))
+		if len(cov.Match) != 1 || cov.Match[0].ID != tt.id {
+			t.Errorf("Scan(%q) = %+v, want match %s", tt.text, cov.Match, tt.id)
+		}
+	}
+
+	fsys["urls"] = &fstest.MapFile{Data: []byte("https://example.com/corp\n")}
+	if _, err := NewScannerFS(fsys); err == nil {
+		t.Errorf("NewScannerFS with malformed urls succeeded, want error")
+	}
+}
+
+func TestBuiltinListVersion(t *testing.T) {
+	cov := Scan([]byte(license_MIT))
+	if len(cov.Match) != 1 || cov.Match[0].ListVersion != SPDXVersion {
+		t.Errorf("Scan(MIT) = %+v, want ListVersion %s", cov.Match, SPDXVersion)
+	}
+
+	skipProfile(t)
+	want := map[string]string{
+		"MIT":                        SPDXVersion,
+		"BUSL-1.1":                   "", // added to SPDX after 3.10
+		"SHL-2.1":                    "",
+		"Llama-2":                    "", // not an SPDX license
+		"GPL-2.0-only-or-Commercial": "",
+	}
+	for _, l := range BuiltinLicenses() {
+		if v, ok := want[l.ID]; ok {
+			if l.ListVersion != v {
+				t.Errorf("%s: ListVersion = %q, want %q", l.ID, l.ListVersion, v)
+			}
+			delete(want, l.ID)
+		}
+	}
+	for id := range want {
+		t.Errorf("%s: not a built-in license", id)
+	}
+}
+
+func TestBuiltinLicensesCopy(t *testing.T) {
+	list := BuiltinLicenses()
+	if len(list) == 0 {
+		t.Fatal("BuiltinLicenses() = empty list")
+	}
+	id := list[0].ID
+	list[0].ID = "Changed"
+	if list := BuiltinLicenses(); list[0].ID != id {
+		t.Errorf("BuiltinLicenses()[0].ID = %q after caller modified earlier result, want %q", list[0].ID, id)
+	}
+
+	list = append(list, License{ID: "Corp-1.0", LRE: "This software is licensed under the Corporate License, version 1.0."})
+	s, err := NewScanner(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		text string
+		id   string
+	}{
+		{"This software is licensed under the Corporate License, version 1.0.", "Corp-1.0"},
+		{license_MIT, "MIT"},
+	} {
+		cov := s.Scan([]byte(tt.text))
+		if len(cov.Match) != 1 || cov.Match[0].ID != tt.id {
+			t.Errorf("Scan(%.40q) = %+v, want match %s", tt.text, cov.Match, tt.id)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	text, ok := Text("MIT")
+	if !ok || !strings.Contains(text, "Permission is hereby granted") {
+		t.Errorf("Text(MIT) = %.40q, %v, want MIT license text", text, ok)
+	}
+	if cov := Scan([]byte(text)); len(cov.Match) != 1 || cov.Match[0].ID != "MIT" {
+		t.Errorf("Scan(Text(MIT)) = %+v, want MIT", cov)
+	}
+	for _, id := range []string{"", "Not-A-License", "../LICENSE", "*"} {
+		if text, ok := Text(id); ok {
+			t.Errorf("Text(%q) = %.40q, true, want false", id, text)
+		}
+	}
+
+	s, err := NewScanner([]License{
+		{ID: "MIT", LRE: "Permission is hereby granted, free of charge"},
+		{ID: "Corp-1.0", LRE: "This software is licensed under the Corporate License, version 1.0.", Text: "Corporate License 1.0"},
+		{ID: "Corp-2.0", LRE: "This software is licensed under the Corporate License, version 2.0."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		id   string
+		text string
+		ok   bool
+	}{
+		{"MIT", text, true},
+		{"Corp-1.0", "Corporate License 1.0", true},
+		{"Corp-2.0", "", false},
+		{"Apache-2.0", "", false},
+	} {
+		if text, ok := s.Text(tt.id); text != tt.text || ok != tt.ok {
+			t.Errorf("s.Text(%q) = %.40q, %v, want %.40q, %v", tt.id, text, ok, tt.text, tt.ok)
+		}
+	}
+}
+
+// mitText is license_MIT without its leading comment line.
+var mitText = license_MIT[strings.Index(license_MIT, "\n")+1:]
+
+var utf8Tests = []struct {
+	prefix string
+	text   string
+	suffix string
+}{
+	{"", mitText, ""},
+	{"Ünïcödé prefix ☃☃☃\n\n", mitText, "\n\n— Ende ☃ —\n"},
+	{"日本語のテキスト\n", "Copyright © 2020 Jörg Müller\n\n" + mitText, ""},
+	{"Ελληνικά\n", strings.NewReplacer(`"`, "“", "Software", "Soﬅware").Replace(mitText), "\nΤέλος\n"},
+}
+
+func TestUTF8Offsets(t *testing.T) {
+	for _, tt := range utf8Tests {
+		text := tt.prefix + tt.text + tt.suffix
+		cov := Scan([]byte(text))
+		if len(cov.Match) != 1 || cov.Match[0].ID != "MIT" {
+			t.Errorf("Scan(%.40q) = %+v, want MIT", text, cov)
+			continue
+		}
+		m := cov.Match[0]
+		if m.Start != len(tt.prefix) || m.End < len(tt.prefix)+len(strings.TrimRight(tt.text, "\n")) || m.End > len(text)-len(strings.TrimLeft(tt.suffix, "\n")) {
+			t.Errorf("Scan(%.40q) = [%d:%d], want [%d:%d]", text, m.Start, m.End, len(tt.prefix), len(tt.prefix)+len(tt.text))
+		}
+		if !utf8.ValidString(text[m.Start:m.End]) {
+			t.Errorf("Scan(%.40q) = [%d:%d], not at UTF-8 boundaries", text, m.Start, m.End)
+		}
+		start, end := m.RuneOffsets([]byte(text))
+		runes := []rune(text)
+		if string(runes[start:end]) != text[m.Start:m.End] {
+			t.Errorf("RuneOffsets(%.40q) = [%d:%d], want runes matching bytes [%d:%d]", text, start, end, m.Start, m.End)
+		}
+	}
+}
+
+func TestMatchWords(t *testing.T) {
+	mit, _ := Text("MIT")
+	n := len(strings.Fields(mit))
+	cov := Scan([]byte(mit))
+	if len(cov.Match) != 1 || cov.Match[0].MatchedWords != cov.Match[0].LicenseWords || cov.Match[0].LicenseWords < n/2 {
+		t.Errorf("Scan(MIT) = %+v, want MatchedWords = LicenseWords, about %d", cov.Match, n)
+	}
+
+	// A header is a small part of the license.
+	cov = Scan([]byte(headerTexts[0].text))
+	if len(cov.Match) != 1 || cov.Match[0].MatchedWords == 0 || 10*cov.Match[0].MatchedWords > cov.Match[0].LicenseWords {
+		t.Errorf("Scan(Apache-2.0 header) = %+v, want few MatchedWords of many LicenseWords", cov.Match)
+	}
+
+	cov = Scan([]byte(urlTests[0].text))
+	if len(cov.Match) != 1 || !cov.Match[0].IsURL || cov.Match[0].MatchedWords == 0 || cov.Match[0].LicenseWords != 0 {
+		t.Errorf("Scan(URL) = %+v, want URL match with MatchedWords but no LicenseWords", cov.Match)
+	}
+}
+
+func TestLineEndings(t *testing.T) {
+	crlf := strings.ReplaceAll(mitText, "\n", "\r\n")
+	cr := strings.ReplaceAll(mitText, "\n", "\r")
+	for _, tt := range []struct {
+		prefix string
+		text   string
+		suffix string
+	}{
+		{"\uFEFF", mitText, ""},
+		{"\uFEFF", crlf, ""},
+		{"Header text.\r\n\r\n", crlf, "\r\nFooter text.\r\n"},
+		{"\uFEFFHeader text.\r\n", crlf, "Footer text.\r\n"},
+		{"Header text.\r\r", cr, "\rFooter text.\r"},
+	} {
+		text := tt.prefix + tt.text + tt.suffix
+		cov := Scan([]byte(text))
+		if len(cov.Match) != 1 || cov.Match[0].ID != "MIT" {
+			t.Errorf("Scan(%.40q) = %+v, want MIT", text, cov)
+			continue
+		}
+		m := cov.Match[0]
+		if m.Start != len(tt.prefix) || m.End != len(tt.prefix)+len(tt.text) && tt.suffix != "" || m.End != len(text) && tt.suffix == "" {
+			t.Errorf("Scan(%.40q) = [%d:%d], want [%d:%d]", text, m.Start, m.End, len(tt.prefix), len(tt.prefix)+len(tt.text))
+		}
+	}
+}
+
+func TestNewScannerFSBOM(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Corp-1.0.lre": {Data: []byte("\uFEFF((\r\nThis || The\r\n))\r\nsoftware is licensed under the Corporate License, version 1.0.\r\n")},
+		"Corp-2.0.txt": {Data: []byte("\uFEFFThis software is licensed under the Corporate License, version 2.0.\r\n")},
+	}
+	s, err := NewScannerFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := "The software is licensed under the Corporate License, version 1.0."
+	if cov := s.Scan([]byte(text)); len(cov.Match) != 1 || cov.Match[0].ID != "Corp-1.0" {
+		t.Errorf("Scan(%q) = %+v, want Corp-1.0", text, cov)
+	}
+	if text, _ := s.Text("Corp-2.0"); strings.HasPrefix(text, "\uFEFF") {
+		t.Errorf("s.Text(Corp-2.0) = %q, want no byte order mark", text)
+	}
+}
+
+func TestReproducible(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if testing.Short() && len(files) > 50 {
+		files = files[:50]
+	}
+	var texts [][]byte
+	var want []Coverage
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		texts = append(texts, data)
+		want = append(want, Scan(data))
+	}
+
+	list := BuiltinLicenses()
+	r := rand.New(rand.NewSource(1))
+	r.Shuffle(len(list), func(i, j int) { list[i], list[j] = list[j], list[i] })
+	s, err := NewScanner(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, text := range texts {
+		for try := 0; try < 2; try++ {
+			if have := s.Scan(text); !reflect.DeepEqual(have, want[i]) {
+				t.Errorf("%s: Scan with shuffled licenses = %+v, want %+v", files[i], have, want[i])
+			}
+		}
+	}
+}
+
+// noticeLicenses returns n synthetic license notices,
+// like those imported from ScanCode, for scale benchmarks.
+// Like real notices, they share their first words
+// and contain optional phrases and wildcards.
+func noticeLicenses(n int) []License {
+	words := strings.Fields("the software license licensed under terms of this file is version see for details distributed you may use except in compliance with copy at and or any later public general free source code")
+	r := rand.New(rand.NewSource(1))
+	var list []License
+	for i := 0; i < n; i++ {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Licensed under the __3__ Notice License %d", i)
+		for j := 10 + r.Intn(30); j > 0; j-- {
+			b.WriteString(" " + words[r.Intn(len(words))])
+		}
+		if r.Intn(3) == 0 {
+			b.WriteString("\n((\nor any later version\n))??\n")
+		}
+		if r.Intn(2) == 0 {
+			b.WriteString(" __5__ " + words[r.Intn(len(words))] + " notice")
+		}
+		list = append(list, License{ID: fmt.Sprintf("Notice-%d", i), LRE: b.String()})
+	}
+	return list
+}
+
+func benchmarkLarge(b *testing.B, n int) {
+	list := append(BuiltinLicenses(), noticeLicenses(n)...)
+	b.Run("NewScanner", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := NewScanner(list); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Scan", func(b *testing.B) {
+		s, err := NewScanner(list)
+		if err != nil {
+			b.Fatal(err)
+		}
+		text := []byte(mitText + "\nLicensed under the terms of the Notice License 7 and more.\n")
+		b.SetBytes(int64(len(text)))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			s.Scan(text)
+		}
+	})
+}
+
+func BenchmarkLarge1000(b *testing.B) { benchmarkLarge(b, 1000) }
+func BenchmarkLarge5000(b *testing.B) { benchmarkLarge(b, 5000) }
+
+func TestScanAllocs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+	if raceEnabled {
+		t.Skip("skipping with race detector, which defeats sync.Pool")
+	}
+	text := []byte(mitText)
+	Scan(text) // initialize built-in scanner
+	// The only allocation should be the Coverage.Match slice.
+	if allocs := testing.AllocsPerRun(100, func() { Scan(text) }); allocs > 1 {
+		t.Errorf("Scan allocates %v times, want at most 1", allocs)
+	}
+}
+
+func TestScanFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "LICENSE")
+	if err := ioutil.WriteFile(file, []byte(mitText), 0666); err != nil {
+		t.Fatal(err)
+	}
+	cov, err := ScanFile(file, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Scan([]byte(mitText))
+	if !reflect.DeepEqual(cov, want) {
+		t.Errorf("ScanFile = %+v, want %+v", cov, want)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := ioutil.WriteFile(empty, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if cov, err := ScanFile(empty, Options{}); err != ErrEmptyInput {
+		t.Errorf("ScanFile(empty) = %+v, %v, want ErrEmptyInput", cov, err)
+	}
+
+	if _, err := ScanFile(filepath.Join(dir, "missing"), Options{}); !os.IsNotExist(err) {
+		t.Errorf("ScanFile(missing) error = %v, want not exist", err)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	list := []License{
+		{ID: "Corp-1.0", Type: Notice, LRE: "This software is licensed under the Corporate License, version 1.0.", Name: "Corporate License 1.0", Text: "text"},
+		{ID: "Corp-1.0", URL: "example.com/corp-1.0"},
+		{ID: "MIT", LRE: QuoteLRE(mitText)},
+	}
+	s, err := NewScanner(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := s.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	s2, err := LoadScanner(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := []byte("This software is licensed under the Corporate License, version 1.0.\n" +
+		"See https://example.com/corp-1.0 for details.\n\n" + mitText)
+	want := s.Scan(text)
+	if len(want.Match) != 3 {
+		t.Fatalf("Scan found %d matches, want 3: %+v", len(want.Match), want)
+	}
+	if have := s2.Scan(text); !reflect.DeepEqual(have, want) {
+		t.Errorf("loaded Scan = %+v, want %+v", have, want)
+	}
+	if text, ok := s2.Text("Corp-1.0"); !ok || text != "text" {
+		t.Errorf("loaded Text(Corp-1.0) = %q, %v, want %q, true", text, ok, "text")
+	}
+
+	for i := 0; i < len(data); i += len(data)/50 + 1 {
+		if _, err := LoadScanner(bytes.NewReader(data[:i])); err == nil {
+			t.Errorf("LoadScanner(data[:%d]) succeeded", i)
+		}
+	}
+}
+
+func TestSaveLoadBuiltin(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+	var buf bytes.Buffer
+	if err := builtinScanner.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadScanner(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob("testdata/*.t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have, want := s.Scan(data), Scan(data); !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: loaded Scan = %+v, want %+v", file, have, want)
+		}
+	}
+}
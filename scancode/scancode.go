@@ -0,0 +1,188 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package scancode converts ScanCode license detection rules
+// into licensecheck.License entries.
+//
+// ScanCode's rule corpus (src/licensedcode/data/rules in
+// github.com/nexB/scancode-toolkit) holds thousands of short texts,
+// each a license notice, reference, tag, or full license text.
+// The notices and references are the useful part for licensecheck:
+// they recognize phrases like "Licensed under the MIT license"
+// that the built-in license set, made of full license texts, does not.
+//
+// Each rule is a NAME.RULE file holding the rule text,
+// with its attributes either in a YAML front matter block
+// at the top of the file or in a separate NAME.yml file.
+// Only the simple "key: value" attributes are read.
+//
+//	rules, err := scancode.ReadRules(os.DirFS("scancode-toolkit/src/licensedcode/data/rules"))
+//	if err != nil {
+//		...
+//	}
+//	s, err := licensecheck.NewScanner(scancode.Licenses(rules))
+package scancode
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/licensecheck"
+)
+
+// A Rule is a single ScanCode license detection rule.
+type Rule struct {
+	Name       string // file name without .RULE
+	Expression string // ScanCode license expression, such as "mit" or "gpl-2.0-plus"
+	Text       string // rule text, without required-phrase markers
+
+	IsText          bool // the rule is a full license text
+	IsNotice        bool // the rule is a license notice
+	IsReference     bool // the rule is a reference to a license, such as its name or URL
+	IsTag           bool // the rule is a structured license tag
+	IsFalsePositive bool // the rule is a false positive that must not be reported
+
+	Relevance int // 0 to 100; 0 if not specified
+}
+
+// ReadRules reads the *.RULE files in the root directory of fsys
+// and returns the rules they define, sorted by name.
+func ReadRules(fsys fs.FS) ([]*Rule, error) {
+	files, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("scancode: %v", err)
+	}
+	var rules []*Rule
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, ".RULE") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("scancode: %v", err)
+		}
+		r := &Rule{Name: strings.TrimSuffix(name, ".RULE")}
+		text, attrs, ok := splitFrontMatter(string(data))
+		if !ok {
+			yml, err := fs.ReadFile(fsys, r.Name+".yml")
+			if err != nil {
+				return nil, fmt.Errorf("scancode: %s: no attributes: %v", name, err)
+			}
+			attrs = string(yml)
+		}
+		if err := r.parseAttrs(attrs); err != nil {
+			return nil, fmt.Errorf("scancode: %s: %v", name, err)
+		}
+		r.Text = stripMarkers(text)
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name < rules[j].Name })
+	return rules, nil
+}
+
+// splitFrontMatter splits data into the rule text and
+// the YAML front matter delimited by --- lines, if present.
+func splitFrontMatter(data string) (text, attrs string, ok bool) {
+	if !strings.HasPrefix(data, "---\n") {
+		return data, "", false
+	}
+	data = data[len("---\n"):]
+	i := strings.Index(data, "\n---\n")
+	if i < 0 {
+		return data, "", false
+	}
+	return data[i+len("\n---\n"):], data[:i+1], true
+}
+
+// parseAttrs sets the fields of r from the YAML attributes.
+// It handles only top-level scalar "key: value" lines;
+// lists and nested values are ignored.
+func (r *Rule) parseAttrs(attrs string) error {
+	sc := bufio.NewScanner(strings.NewReader(attrs))
+	for lineno := 1; sc.Scan(); lineno++ {
+		line := sc.Text()
+		if line == "" || line[0] == ' ' || line[0] == '-' || line[0] == '#' {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i < 0 {
+			return fmt.Errorf("line %d: malformed attribute", lineno)
+		}
+		key := strings.TrimSpace(line[:i])
+		val := strings.Trim(strings.TrimSpace(line[i+1:]), `"'`)
+		switch key {
+		case "license_expression":
+			r.Expression = val
+		case "is_license_text":
+			r.IsText = yamlBool(val)
+		case "is_license_notice":
+			r.IsNotice = yamlBool(val)
+		case "is_license_reference":
+			r.IsReference = yamlBool(val)
+		case "is_license_tag":
+			r.IsTag = yamlBool(val)
+		case "is_false_positive":
+			r.IsFalsePositive = yamlBool(val)
+		case "relevance":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid relevance %q", lineno, val)
+			}
+			r.Relevance = n
+		}
+	}
+	return nil
+}
+
+func yamlBool(s string) bool {
+	switch strings.ToLower(s) {
+	case "yes", "true", "on", "y":
+		return true
+	}
+	return false
+}
+
+// stripMarkers removes the {{ }} markers around required phrases.
+// The phrases themselves are kept.
+func stripMarkers(text string) string {
+	return strings.NewReplacer("{{", "", "}}", "").Replace(text)
+}
+
+// wordRE approximates licensecheck's definition of a word.
+var wordRE = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// Licenses returns licensecheck.License entries for the
+// notice and reference rules in rules,
+// using each rule's license expression as the license ID.
+// Rules of other kinds are skipped, as are rules with
+// no license expression and rules shorter than two words,
+// which are too short to match reliably.
+func Licenses(rules []*Rule) []licensecheck.License {
+	var list []licensecheck.License
+	for _, r := range rules {
+		if !r.IsNotice && !r.IsReference || r.IsFalsePositive || r.Expression == "" {
+			continue
+		}
+		if len(wordRE.FindAllStringIndex(r.Text, 2)) < 2 {
+			continue
+		}
+		list = append(list, r.License())
+	}
+	return list
+}
+
+// License returns the licensecheck.License for r,
+// matching the rule text literally.
+func (r *Rule) License() licensecheck.License {
+	return licensecheck.License{
+		ID:  r.Expression,
+		LRE: licensecheck.QuoteLRE(r.Text),
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scancode
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+)
+
+var testFS = fstest.MapFS{
+	"mit_12.RULE": {Data: []byte(`---
+license_expression: mit
+is_license_notice: yes
+relevance: 100
+notes: seen in many npm packages
+---
+This package is released under the {{MIT license}}.
+`)},
+	"apache-2.0_33.RULE": {Data: []byte("licensed under the Apache License, Version 2.0\n")},
+	"apache-2.0_33.yml": {Data: []byte(`license_expression: apache-2.0
+is_license_reference: yes
+ignorable_urls:
+    - http://www.apache.org/licenses/LICENSE-2.0
+`)},
+	"mit_3.RULE":  {Data: []byte("---\nlicense_expression: mit\nis_license_tag: yes\n---\nMIT\n")},
+	"bsd_1.RULE":  {Data: []byte("---\nlicense_expression: bsd-new\nis_license_text: yes\n---\nRedistribution and use in source and binary forms\n")},
+	"mit.LICENSE": {Data: []byte("not a rule")},
+}
+
+func TestReadRules(t *testing.T) {
+	rules, err := ReadRules(testFS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 4 {
+		t.Fatalf("ReadRules: %d rules, want 4", len(rules))
+	}
+	r := rules[0]
+	if r.Name != "apache-2.0_33" || r.Expression != "apache-2.0" || !r.IsReference || r.IsNotice {
+		t.Errorf("rules[0] = %+v, want apache-2.0 reference", r)
+	}
+	r = rules[3]
+	if r.Name != "mit_3" || !r.IsTag {
+		t.Errorf("rules[3] = %+v, want mit tag", r)
+	}
+
+	list := Licenses(rules)
+	if len(list) != 2 {
+		t.Fatalf("Licenses: %d licenses, want 2: %+v", len(list), list)
+	}
+	s, err := licensecheck.NewScanner(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		text string
+		id   string
+	}{
+		{"This package is released under the MIT license.", "mit"},
+		{"This file is licensed under the Apache License, Version 2.0 (the License).", "apache-2.0"},
+	} {
+		cov := s.Scan([]byte(tt.text))
+		if len(cov.Match) != 1 || cov.Match[0].ID != tt.id {
+			t.Errorf("Scan(%q) = %+v, want %s", tt.text, cov, tt.id)
+		}
+	}
+}
+
+func TestReadRulesMissingAttrs(t *testing.T) {
+	fsys := fstest.MapFS{"x.RULE": {Data: []byte("some rule text\n")}}
+	if _, err := ReadRules(fsys); err == nil {
+		t.Errorf("ReadRules with no attributes succeeded, want error")
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io/fs"
+	"unicode/utf8"
+
+	"github.com/google/licensecheck/internal/match"
+	"github.com/google/licensecheck/internal/strs"
+)
+
+// A FileCoverage is the result of scanning one file with ScanFS.
+type FileCoverage struct {
+	File     string   // name of the file in the file system
+	Coverage Coverage // result of scanning the file; zero if DuplicateOf is set
+	Err      error    // error from extracting the text or from ScanWithOptions, such as ErrNoMatch
+
+	// DuplicateOf is the name of an earlier file in the list
+	// whose text is the same as this one's after normalization,
+	// so that scanning this file would give the same result,
+	// apart from the match offsets.
+	// When DuplicateOf is set, this file is not scanned:
+	// Coverage is left zero and Err is copied from the earlier file.
+	DuplicateOf string
+}
+
+// ScanFS is like ScanWithOptions but scans each of the named files in fsys,
+// returning one FileCoverage for each file, in the same order.
+// Files whose texts are the same after normalization,
+// that is, whose sequences of words are the same
+// ignoring case, punctuation, spacing, and markup,
+// are scanned only once: later copies are reported
+// as duplicates of the first, using FileCoverage.DuplicateOf.
+// The many copies of a standard LICENSE file in a large source tree
+// therefore cost only one scan and one review.
+//
+// When opts.Source or opts.Segment is set, comment and segment boundaries
+// can depend on punctuation, so only files with exactly the same bytes
+// are treated as duplicates.
+//
+// As with ScanFile, the text scanned is extracted from each file
+// by the Extractor registered for its extension.
+//
+// ScanFS returns an error only if a file cannot be read.
+// Errors from extracting or scanning the text are reported in FileCoverage.Err.
+func ScanFS(fsys fs.FS, files []string, opts Options) ([]FileCoverage, error) {
+	return builtinScanner.ScanFS(fsys, files, opts)
+}
+
+// ScanFS is like the top-level function ScanFS,
+// but it uses the set of licenses in the Scanner instead of the built-in license set.
+func (s *Scanner) ScanFS(fsys fs.FS, files []string, opts Options) ([]FileCoverage, error) {
+	list := make([]FileCoverage, 0, len(files))
+	err := s.ScanFSFunc(fsys, files, opts, func(fc FileCoverage) error {
+		list = append(list, fc)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ScanFSFunc is like ScanFS but passes each FileCoverage to fn
+// as soon as the file is scanned, instead of collecting them in a list,
+// so that scans of very many files need not hold all the results in memory.
+// ScanFSFunc scans no further file until fn returns.
+// If fn returns an error, ScanFSFunc stops and returns that error.
+func ScanFSFunc(fsys fs.FS, files []string, opts Options, fn func(FileCoverage) error) error {
+	return builtinScanner.ScanFSFunc(fsys, files, opts, fn)
+}
+
+// ScanFSFunc is like the top-level function ScanFSFunc,
+// but it uses the set of licenses in the Scanner instead of the built-in license set.
+func (s *Scanner) ScanFSFunc(fsys fs.FS, files []string, opts Options, fn func(FileCoverage) error) error {
+	s.initBuiltin()
+
+	d := s.newDict()
+	seen := make(map[[sha256.Size]byte]FileCoverage) // normalized text hash -> first file with that text
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		fc := FileCoverage{File: file}
+		text, err := ExtractText(file, data)
+		if err != nil {
+			fc.Err = err
+			if err := fn(fc); err != nil {
+				return err
+			}
+			continue
+		}
+		h, ok := normalizedHash(d, text, opts)
+		if first, dup := seen[h]; ok && dup {
+			fc.DuplicateOf = first.File
+			fc.Err = first.Err
+			if opts.Metrics != nil {
+				opts.Metrics.Observe(ScanEvent{Bytes: len(text), Coverage: first.Coverage, Err: first.Err, Cached: true})
+			}
+		} else {
+			fc.Coverage, fc.Err = s.ScanWithOptions(text, opts)
+			if ok {
+				seen[h] = fc
+			}
+		}
+		if err := fn(fc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizedHash returns a hash of the text as ScanWithOptions would see it,
+// adding the text's words to d.
+// Texts with the same hash scan the same, apart from match offsets.
+// normalizedHash reports false if the text must be scanned regardless,
+// because invalid UTF-8 in it can change its words.
+func normalizedHash(d *match.Dict, text []byte, opts Options) (h [sha256.Size]byte, ok bool) {
+	if opts.InvalidUTF8 != UTF8Replace && !utf8.Valid(text) {
+		return h, false
+	}
+	hash := sha256.New()
+	long := byte(0)
+	if opts.MaxInputBytes > 0 && len(text) > opts.MaxInputBytes {
+		// Keep texts that are too long apart from the others.
+		long = 1
+		if opts.Truncate {
+			text = strs.Truncate(text, opts.MaxInputBytes)
+		}
+	}
+	hash.Write([]byte{long})
+	if sourceSyntax(opts.Source) != nil || opts.Segment {
+		hash.Write(text)
+	} else {
+		var buf [binary.MaxVarintLen32]byte
+		for _, w := range d.InsertSplit(string(text)) {
+			hash.Write(buf[:binary.PutUvarint(buf[:], uint64(w.ID))])
+		}
+	}
+	hash.Sum(h[:0])
+	return h, true
+}
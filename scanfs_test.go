@@ -0,0 +1,96 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestScanFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"LICENSE":             {Data: []byte(mitText)},
+		"a/LICENSE":           {Data: []byte(strings.ReplaceAll(mitText, "\n", "\r\n"))},
+		"b/LICENSE.md":        {Data: []byte("# " + strings.ToUpper(mitText))},
+		"c/LICENSE":           {Data: []byte("Copyright 2020 Someone Else\n\n" + mitText)},
+		"README":              {Data: []byte("Nothing to see here.\n")},
+		"d/README":            {Data: []byte("NOTHING, to see here!\n")},
+		"vendor/x/LICENSE.md": {Data: []byte(mitText)},
+	}
+	files := []string{"LICENSE", "a/LICENSE", "b/LICENSE.md", "c/LICENSE", "README", "d/README", "vendor/x/LICENSE.md"}
+	list, err := ScanFS(fsys, files, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		dup string
+		id  string
+		err error
+	}{
+		{"", "MIT", nil},
+		{"LICENSE", "", nil},
+		{"LICENSE", "", nil},
+		{"", "MIT", nil},
+		{"", "", ErrNoMatch},
+		{"README", "", ErrNoMatch},
+		{"LICENSE", "", nil},
+	}
+	for i, fc := range list {
+		w := want[i]
+		if fc.File != files[i] || fc.DuplicateOf != w.dup || !errors.Is(fc.Err, w.err) && fc.Err != w.err {
+			t.Errorf("%s: File=%q DuplicateOf=%q Err=%v, want DuplicateOf=%q Err=%v", files[i], fc.File, fc.DuplicateOf, fc.Err, w.dup, w.err)
+		}
+		id := ""
+		if len(fc.Coverage.Match) > 0 {
+			id = fc.Coverage.Match[0].ID
+		}
+		if id != w.id {
+			t.Errorf("%s: Coverage.Match[0].ID = %q, want %q", files[i], id, w.id)
+		}
+	}
+
+	// With Source set, only identical bytes are duplicates.
+	list, err = ScanFS(fsys, files[:3], Options{Source: "x.c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fc := range list {
+		if fc.DuplicateOf != "" {
+			t.Errorf("%s: DuplicateOf=%q with Source set, want none", fc.File, fc.DuplicateOf)
+		}
+	}
+
+	if _, err := ScanFS(fsys, []string{"missing"}, Options{}); err == nil {
+		t.Errorf("ScanFS(missing) succeeded, want error")
+	}
+}
+
+func TestScanFSFunc(t *testing.T) {
+	fsys := fstest.MapFS{
+		"LICENSE":   {Data: []byte(mitText)},
+		"a/LICENSE": {Data: []byte(mitText)},
+		"b/LICENSE": {Data: []byte(mitText)},
+	}
+	stop := errors.New("stop")
+	var files []string
+	err := ScanFSFunc(fsys, []string{"LICENSE", "a/LICENSE", "b/LICENSE"}, Options{}, func(fc FileCoverage) error {
+		files = append(files, fc.File)
+		if fc.File == "a/LICENSE" {
+			if fc.DuplicateOf != "LICENSE" {
+				t.Errorf("%s: DuplicateOf=%q, want LICENSE", fc.File, fc.DuplicateOf)
+			}
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("ScanFSFunc = %v, want %v", err, stop)
+	}
+	if len(files) != 2 {
+		t.Errorf("ScanFSFunc called fn for %q, want LICENSE and a/LICENSE only", files)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"strings"
+)
+
+// segmentBanners are the lower-case beginnings of banner lines
+// that introduce a new component in a bundled notice file.
+var segmentBanners = []string{
+	"the following software may be included in this product",
+	"the following third-party software",
+	"the following third party software",
+	"this file contains the licenses of",
+	"license notice for",
+	"notices for file(s):",
+}
+
+// segmentBounds returns the byte offsets at which Options.Segment
+// splits text: 0, the start of each separator, and len(text).
+// Segments are text[b[i]:b[i+1]].
+//
+// Some licenses, such as the LPPL and ODbL, have separator lines
+// of their own. To keep from splitting them, segmentBounds scans
+// the whole text first and drops the separators that lie
+// inside matches of those licenses.
+func (s *Scanner) segmentBounds(text []byte, opts Options) []int {
+	b := separators(text)
+	if len(b) == 2 {
+		return b
+	}
+	s.separatedOnce.Do(s.initSeparated)
+	opts.Segment = false
+	opts.MergeGapWords = 0
+	opts.ApproxPercent = 0
+	c := s.scanChunks(text, opts)
+	keep := b[:1]
+Bounds:
+	for _, off := range b[1 : len(b)-1] {
+		for _, m := range c.Match {
+			if m.Start < off && off < m.End && s.separated[m.ID] {
+				continue Bounds
+			}
+		}
+		keep = append(keep, off)
+	}
+	return append(keep, len(text))
+}
+
+// initSeparated initializes s.separated.
+func (s *Scanner) initSeparated() {
+	s.separated = make(map[string]bool)
+	for _, l := range s.licenses {
+		if text, ok := s.Text(l.ID); ok && len(separators([]byte(text))) > 2 {
+			s.separated[l.ID] = true
+		}
+	}
+}
+
+// maxHeadingBytes is the length of the longest line
+// that separators treats as a heading.
+const maxHeadingBytes = 80
+
+// separators returns 0, the offset of each separator in text, and len(text).
+// A separator begins at the start of a separator line,
+// or, if the separator line underlines a heading,
+// at the start of the heading.
+func separators(text []byte) []int {
+	b := []int{0}
+	prev := -1 // offset of previous line, if it might be a heading
+	for lo := 0; lo < len(text); {
+		hi := bytes.IndexByte(text[lo:], '\n')
+		if hi < 0 {
+			hi = len(text)
+		} else {
+			hi += lo + 1
+		}
+		line := text[lo:hi]
+		if isSeparator(line) {
+			start := lo
+			if prev >= 0 && isRule(line) {
+				start = prev
+			}
+			if start > 0 && start > b[len(b)-1] {
+				b = append(b, start)
+			}
+		}
+		prev = lo
+		if t := bytes.TrimSpace(line); len(t) == 0 || len(t) > maxHeadingBytes {
+			prev = -1
+		}
+		lo = hi
+	}
+	return append(b, len(text))
+}
+
+// isSeparator reports whether line separates components
+// in a bundled notice file (see Options.Segment).
+func isSeparator(line []byte) bool {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return bytes.IndexByte(line, '\f') >= 0
+	}
+	if isRule(line) {
+		return true
+	}
+	if len(line) > 100 {
+		return false
+	}
+	lower := strings.ToLower(string(bytes.TrimSpace(line)))
+	for _, banner := range segmentBanners {
+		if strings.HasPrefix(lower, banner) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRule reports whether line is a horizontal rule:
+// four or more of the characters - = * # ~ +, possibly spaced out.
+func isRule(line []byte) bool {
+	n := 0
+	for _, c := range line {
+		switch c {
+		case '-', '=', '*', '#', '~', '+':
+			n++
+		case ' ', '\t', '\r', '\n':
+		default:
+			return false
+		}
+	}
+	return n >= 4
+}
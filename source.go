@@ -0,0 +1,252 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// ScanSource is like Scan but scans only the comments in a source file,
+// so that license notices anywhere in the file are found
+// without the words of the code diluting or interrupting them.
+// The name of the file selects the programming language by its extension:
+// Go, C and C++, Python, Ruby, shell, HTML, and Haskell are recognized.
+// For other files, ScanSource scans the whole text, like Scan.
+//
+// Match offsets are in text. Percent describes only the comments.
+// ScanWithOptions does the same when Options.Source is set.
+func ScanSource(name string, text []byte) Coverage {
+	return builtinScanner.ScanSource(name, text)
+}
+
+// ScanSource is like the top-level function ScanSource,
+// but it uses the set of licenses in the Scanner instead of the built-in license set.
+func (s *Scanner) ScanSource(name string, text []byte) Coverage {
+	s.initBuiltin()
+	cs := sourceSyntax(name)
+	if cs == nil {
+		return s.scan(text, false)
+	}
+	comments := cs.comments(text)
+	c := s.scan(comments, false)
+	clipMatches(c.Match, text, comments)
+	return c
+}
+
+// Comment syntaxes of the languages recognized by ScanSource.
+var (
+	goComments = &commentSyntax{
+		line:  []string{"//"},
+		block: [][2]string{{"/*", "*/"}},
+		quote: []string{`"`, "`", "'"},
+	}
+	cComments = &commentSyntax{
+		line:  []string{"//"},
+		block: [][2]string{{"/*", "*/"}},
+		quote: []string{`"`, "'"},
+	}
+	pythonComments = &commentSyntax{
+		line: []string{"#"},
+		// Docstrings are documentation, so they count as comments.
+		// So does any other triple-quoted string standing alone
+		// at the start of a line, which is often used as a block comment.
+		// Other triple-quoted strings are string literals (see markerAt).
+		block: [][2]string{{`"""`, `"""`}, {"'''", "'''"}},
+		quote: []string{`"""`, "'''", `"`, "'"},
+	}
+	rubyComments = &commentSyntax{
+		line:  []string{"#"},
+		block: [][2]string{{"=begin", "=end"}},
+		quote: []string{`"`, "'"},
+	}
+	shellComments = &commentSyntax{
+		line:  []string{"#"},
+		quote: []string{`"`, "'"},
+	}
+	htmlComments = &commentSyntax{
+		block: [][2]string{{"<!--", "-->"}},
+	}
+	haskellComments = &commentSyntax{
+		line:  []string{"--"},
+		block: [][2]string{{"{-", "-}"}},
+		quote: []string{`"`}, // ' also marks primed identifiers like x'
+	}
+)
+
+// sourceSyntaxes maps a lower-case file extension to the comment syntax of its language.
+var sourceSyntaxes = map[string]*commentSyntax{
+	".go":    goComments,
+	".c":     cComments,
+	".h":     cComments,
+	".cc":    cComments,
+	".cpp":   cComments,
+	".cxx":   cComments,
+	".hh":    cComments,
+	".hpp":   cComments,
+	".hxx":   cComments,
+	".py":    pythonComments,
+	".rb":    rubyComments,
+	".sh":    shellComments,
+	".bash":  shellComments,
+	".zsh":   shellComments,
+	".html":  htmlComments,
+	".htm":   htmlComments,
+	".xhtml": htmlComments,
+	".hs":    haskellComments,
+}
+
+// sourceSyntax returns the comment syntax for the file with the given name,
+// or nil if the language is not known.
+func sourceSyntax(name string) *commentSyntax {
+	return sourceSyntaxes[strings.ToLower(filepath.Ext(name))]
+}
+
+// comments returns a copy of text containing only the text of its comments.
+// Everything else, including the comment markers and string literals,
+// is replaced by spaces, except for line endings,
+// so that offsets in the copy are offsets in text.
+func (cs *commentSyntax) comments(text []byte) []byte {
+	out := blankCopy(text)
+	for i := 0; i < len(text); {
+		if m, ok := cs.blockStart(text[i:]); ok && cs.markerAt(text, i, m[0]) {
+			i += len(m[0])
+			j := bytes.Index(text[i:], []byte(m[1]))
+			if j < 0 {
+				j = len(text) - i
+			}
+			copy(out[i:], text[i:i+j])
+			i += j + len(m[1])
+			continue
+		}
+		if m := cs.lineMarker(text[i:]); m != "" && cs.markerAt(text, i, m) {
+			i += len(m)
+			j := bytes.IndexByte(text[i:], '\n')
+			if j < 0 {
+				j = len(text) - i
+			}
+			copy(out[i:], text[i:i+j])
+			i += j
+			continue
+		}
+		if q := cs.quoteStart(text[i:]); q != "" {
+			i = skipQuoted(text, i+len(q), q)
+			continue
+		}
+		i++
+	}
+	return out
+}
+
+// markerAt reports whether the comment marker m at text[i:] starts a comment.
+// Ruby's =begin counts only at the start of a line.
+// Python's triple quotes count only at the start of a line,
+// ignoring indentation, where a docstring would be.
+// The shell's # counts only at the start of a line or after a space,
+// so not in $#, ${#var}, or a URL like http://x/#frag.
+func (cs *commentSyntax) markerAt(text []byte, i int, m string) bool {
+	switch m {
+	case "=begin":
+		return i == 0 || text[i-1] == '\n'
+	case `"""`, "'''":
+		return cs != pythonComments || startsLine(text, i)
+	case "#":
+		return cs != shellComments || i == 0 || strings.IndexByte(" \t\r\n", text[i-1]) >= 0
+	}
+	return true
+}
+
+// startsLine reports whether only spaces and tabs precede text[i] on its line.
+func startsLine(text []byte, i int) bool {
+	for i > 0 && (text[i-1] == ' ' || text[i-1] == '\t') {
+		i--
+	}
+	return i == 0 || text[i-1] == '\n'
+}
+
+// lineMarker returns the line comment marker at the start of text, if any.
+func (cs *commentSyntax) lineMarker(text []byte) string {
+	for _, m := range cs.line {
+		if bytes.HasPrefix(text, []byte(m)) {
+			return m
+		}
+	}
+	return ""
+}
+
+// quoteStart returns the string literal quote at the start of text, if any.
+func (cs *commentSyntax) quoteStart(text []byte) string {
+	for _, q := range cs.quote {
+		if bytes.HasPrefix(text, []byte(q)) {
+			return q
+		}
+	}
+	return ""
+}
+
+// skipQuoted returns the offset in text just past the end of
+// the string literal beginning at text[i:] and ending with quote q.
+// A backslash escapes the next byte, except in Go's `raw strings`.
+// Raw strings and Python's triple-quoted strings may span lines.
+// Other literals end at a newline,
+// so that an unbalanced quote cannot hide the rest of the file.
+func skipQuoted(text []byte, i int, q string) int {
+	raw := q == "`"
+	multiline := raw || q == `"""` || q == "'''"
+	for i < len(text) {
+		switch {
+		case text[i] == '\\' && !raw:
+			i += 2
+			continue
+		case bytes.HasPrefix(text[i:], []byte(q)):
+			return i + len(q)
+		case text[i] == '\n' && !multiline:
+			return i
+		}
+		i++
+	}
+	return len(text)
+}
+
+// blankCopy returns a copy of text with every byte
+// other than the line endings \r and \n replaced by a space.
+func blankCopy(text []byte) []byte {
+	out := make([]byte, len(text))
+	for i, c := range text {
+		if c == '\n' || c == '\r' {
+			out[i] = c
+		} else {
+			out[i] = ' '
+		}
+	}
+	return out
+}
+
+// clipMatches trims the matches, found by scanning comments,
+// to the lines of text containing their first and last comment bytes.
+// Scanning extends matches to whole lines of comments,
+// which, once the code is blanked out, can reach the start or end
+// of the whole text.
+func clipMatches(list []Match, text, comments []byte) {
+	for i := range list {
+		m := &list[i]
+		if j := bytes.IndexFunc(comments[m.Start:m.End], notSpace); j >= 0 {
+			if k := bytes.LastIndexByte(text[:m.Start+j], '\n') + 1; k > m.Start {
+				m.Start = k
+			}
+		}
+		if j := bytes.LastIndexFunc(comments[m.Start:m.End], notSpace); j >= 0 {
+			j += m.Start
+			if k := bytes.IndexByte(text[j:m.End], '\n'); k >= 0 {
+				m.End = j + k + 1
+			}
+		}
+	}
+}
+
+func notSpace(r rune) bool {
+	return r != ' ' && r != '\n' && r != '\r'
+}
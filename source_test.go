@@ -0,0 +1,89 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"strings"
+	"testing"
+)
+
+var commentsTests = []struct {
+	name string
+	text string
+	out  string // comments(text), with runs of spaces collapsed
+}{
+	{"x.go", "package p // the package\n/* block\ncomment */ var s = \"// not a comment\" + `/* nor\nthis */` // end\n", "the package\nblock\ncomment\nend\n"},
+	{"x.go", "var r = '\"' // quote\n", "quote\n"},
+	{"x.c", "#include <x.h> /* inc */\nchar *s = \"a\\\"/*\"; // c\n", "inc\nc\n"},
+	{"x.py", "#!/usr/bin/python\n\"\"\"Doc string.\"\"\"\nx = '#' # real\n", "!/usr/bin/python\nDoc string.\nreal\n"},
+	{"x.py", "def f():\n    \"\"\"Doc.\"\"\"\n    s = '''not\n# a comment'''\n    g(\"\"\"nor\"\"\") # end\n", "Doc.\n\nend\n"},
+	{"x.rb", "x = 1 # one\n=begin\nblock\n=end\ny = \"#{x}\"\n", "one\n\nblock\n\n"},
+	{"x.sh", "echo $# ${#x} # count\n", "count\n"},
+	{"x.sh", "curl http://x/#frag # fetch\necho a#b '#' \"#\"\n#top\n", "fetch\ntop\n"},
+	{"x.html", "<p>Don't</p><!-- note --><b>\"</b>\n", "note\n"},
+	{"x.hs", "f x' = x' -- prime\n{- block -}\ns = \"--\"\n", "prime\nblock\n\n"},
+	{"X.GO", "x // upper\n", "upper\n"},
+}
+
+func TestComments(t *testing.T) {
+	for _, tt := range commentsTests {
+		cs := sourceSyntax(tt.name)
+		if cs == nil {
+			t.Errorf("sourceSyntax(%q) = nil", tt.name)
+			continue
+		}
+		out := cs.comments([]byte(tt.text))
+		if len(out) != len(tt.text) {
+			t.Errorf("comments(%q): len = %d, want %d", tt.text, len(out), len(tt.text))
+		}
+		var lines []string
+		for _, line := range strings.Split(string(out), "\n") {
+			lines = append(lines, strings.Join(strings.Fields(line), " "))
+		}
+		got := strings.Join(lines, "\n")
+		// Drop blank lines that are only code.
+		for strings.Contains(got, "\n\n\n") {
+			got = strings.Replace(got, "\n\n\n", "\n\n", -1)
+		}
+		got = strings.TrimLeft(got, "\n")
+		if want := tt.out; got != want && strings.Replace(got, "\n\n", "\n", -1) != want {
+			t.Errorf("%s: comments(%q) = %q, want %q", tt.name, tt.text, got, want)
+		}
+	}
+	if cs := sourceSyntax("README"); cs != nil {
+		t.Errorf("sourceSyntax(README) != nil")
+	}
+}
+
+func TestScanSource(t *testing.T) {
+	// A license notice interrupted by code is found in the comments.
+	lines := strings.SplitAfter(mitText, "\n")
+	var b strings.Builder
+	b.WriteString("package p\n\n")
+	for i, line := range lines {
+		b.WriteString("// " + line)
+		if i == len(lines)/2 {
+			b.WriteString("var x = \"some code\"\n")
+		}
+	}
+	b.WriteString("\nfunc main() {}\n")
+	text := b.String()
+
+	cov := ScanSource("main.go", []byte(text))
+	if len(cov.Match) != 1 || cov.Match[0].ID != "MIT" || cov.Percent < 90 {
+		t.Fatalf("ScanSource = %+v, want MIT", cov)
+	}
+	if m := cov.Match[0]; !strings.HasPrefix(text[m.Start:], "// ") {
+		t.Errorf("ScanSource: match at [%d:%d] = %q, want start of comment line", m.Start, m.End, text[m.Start:m.End])
+	}
+	if cov := Scan([]byte(text)); len(cov.Match) == 1 && cov.Match[0].ID == "MIT" && cov.Percent >= 90 {
+		t.Errorf("Scan = %+v, want code to interrupt MIT", cov)
+	}
+
+	// Unknown languages are scanned in full.
+	if cov := ScanSource("LICENSE", []byte(mitText)); len(cov.Match) != 1 || cov.Percent != 100 {
+		t.Errorf("ScanSource(LICENSE) = %+v, want MIT", cov)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/google/licensecheck/internal/match"
+)
+
+// anchorWords is the number of consecutive words that a match
+// must share with the canonical text of its license
+// for licenseSpan to locate the match in that text.
+const anchorWords = 5
+
+// A textRuns indexes the words of the canonical text of a license
+// by their runs of anchorWords words, for licenseSpan.
+type textRuns struct {
+	text  string
+	words []match.Word
+	body  int            // index in words of the first word after any copyright lines; see bodyStart
+	first map[uint64]int // index in words of the first run with each hash
+	last  map[uint64]int // index in words of the last run with each hash
+}
+
+// runHash returns a hash of the IDs of words.
+// It reports false if any word is not in the dictionary.
+func runHash(words []match.Word) (uint64, bool) {
+	h := uint64(14695981039346656037) // FNV-1a
+	for _, w := range words {
+		if w.ID < 0 {
+			return 0, false
+		}
+		h ^= uint64(w.ID)
+		h *= 1099511628211
+	}
+	return h, true
+}
+
+// textRuns returns the index of the canonical text of s.licenses[id],
+// or nil if the license has no canonical text.
+// The index is built the first time it is needed and then kept.
+// Looking up an index already built does not lock:
+// s.runs is never modified, only replaced by an updated copy.
+func (s *Scanner) textRuns(id int) *textRuns {
+	runs, _ := s.runs.Load().(map[int]*textRuns)
+	if r, ok := runs[id]; ok {
+		return r
+	}
+
+	// Build the index without holding the lock.
+	// If another goroutine builds it at the same time, the first one stored wins.
+	var r *textRuns
+	if text, ok := s.Text(s.licenses[id].ID); ok {
+		r = &textRuns{
+			text:  text,
+			words: s.re.Dict().Split(text),
+			first: make(map[uint64]int),
+			last:  make(map[uint64]int),
+		}
+		r.body = s.bodyStart([]byte(text), r.words)
+		for i := 0; i+anchorWords <= len(r.words); i++ {
+			if h, ok := runHash(r.words[i : i+anchorWords]); ok {
+				if _, ok := r.first[h]; !ok {
+					r.first[h] = i
+				}
+				r.last[h] = i
+			}
+		}
+	}
+
+	s.runsMu.Lock()
+	defer s.runsMu.Unlock()
+	runs, _ = s.runs.Load().(map[int]*textRuns)
+	if r, ok := runs[id]; ok {
+		return r
+	}
+	next := make(map[int]*textRuns, len(runs)+1)
+	for k, v := range runs {
+		next[k] = v
+	}
+	next[id] = r
+	s.runs.Store(next)
+	return r
+}
+
+// licenseSpan returns the byte offsets in the canonical text
+// of s.licenses[id] of the part that the matched words cover,
+// for Match.LicenseStart and Match.LicenseEnd.
+// The part begins at the first run of anchorWords words
+// that the match shares with the license and ends at the last,
+// each extended over any further words the two share.
+// If there is no such run, licenseSpan returns 0, 0.
+func (s *Scanner) licenseSpan(id int, words []match.Word) (start, end int) {
+	r := s.textRuns(id)
+	if r == nil {
+		return 0, 0
+	}
+	lw := r.words
+	same := func(i, j int) bool {
+		return lw[i].ID >= 0 && lw[i].ID == words[j].ID
+	}
+	sameRun := func(i, j int) bool {
+		for k := 0; k < anchorWords; k++ {
+			if !same(i+k, j+k) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Find the first run, and extend it back.
+	li, ti := -1, -1
+	for j := 0; j+anchorWords <= len(words); j++ {
+		h, ok := runHash(words[j : j+anchorWords])
+		if !ok {
+			continue
+		}
+		if i, ok := r.first[h]; ok && sameRun(i, j) {
+			li, ti = i, j
+			break
+		}
+	}
+	if li < 0 {
+		return 0, 0
+	}
+	le, te := li+anchorWords-1, ti+anchorWords-1
+	for li > 0 && ti > 0 && same(li-1, ti-1) {
+		li, ti = li-1, ti-1
+	}
+
+	// Find the last run, and extend it forward.
+	// If there is no later run, the first run is also the last.
+	for j := len(words) - anchorWords; j > te-anchorWords+1; j-- {
+		h, ok := runHash(words[j : j+anchorWords])
+		if !ok {
+			continue
+		}
+		if i, ok := r.last[h]; ok && i >= li && sameRun(i, j) {
+			le, te = i+anchorWords-1, j+anchorWords-1
+			break
+		}
+	}
+	for le+1 < len(lw) && te+1 < len(words) && same(le+1, te+1) {
+		le, te = le+1, te+1
+	}
+	return int(lw[li].Lo), int(lw[le].Hi)
+}
+
+// isExact reports whether the matched words, from text,
+// are a verbatim copy of the canonical text of s.licenses[id],
+// after normalization, for Match.IsExact.
+// Copyright lines at the start of either are not compared.
+func (s *Scanner) isExact(id int, text []byte, words []match.Word) bool {
+	r := s.textRuns(id)
+	if r == nil {
+		return false
+	}
+	lw := r.words[r.body:]
+	words = words[s.bodyStart(text, words):]
+	if len(words) != len(lw) {
+		return false
+	}
+	for i, w := range words {
+		if w.ID != lw[i].ID {
+			return false
+		}
+		// Words missing from the dictionary, which can appear
+		// where the license's LRE allows any words, all have the same ID.
+		if w.ID < 0 && !strings.EqualFold(string(text[w.Lo:w.Hi]), r.text[lw[i].Lo:lw[i].Hi]) {
+			return false
+		}
+	}
+	return true
+}
+
+// bodyStart returns the index of the first word of words, from text,
+// after any copyright lines at the start: lines beginning with
+// the word "copyright" and lines saying "all rights reserved",
+// along with any Reserved Font Name clause ending the copyright notice.
+func (s *Scanner) bodyStart(text []byte, words []match.Word) int {
+	d := s.re.Dict()
+	copyright := d.Lookup("copyright")
+	reserved := []match.WordID{d.Lookup("all"), d.Lookup("rights"), d.Lookup("reserved")}
+	i := 0
+	for i < len(words) {
+		// Find the end of the line starting at words[i].
+		j := i + 1
+		for j < len(words) && bytes.IndexByte(text[words[j-1].Hi:words[j].Lo], '\n') < 0 {
+			j++
+		}
+		line := words[i:j]
+		isReserved := len(line) == len(reserved)
+		for k := 0; isReserved && k < len(line); k++ {
+			isReserved = line[k].ID >= 0 && line[k].ID == reserved[k]
+		}
+		if (copyright < 0 || line[0].ID != copyright) && !isReserved {
+			break
+		}
+		i = j
+	}
+
+	// The copyright notice of the SIL Open Font License can end
+	// with a Reserved Font Name clause, which runs to the end of its sentence.
+	rfn := []match.WordID{d.Lookup("with"), d.Lookup("reserved"), d.Lookup("font")}
+	if i+len(rfn) > len(words) {
+		return i
+	}
+	for k, id := range rfn {
+		if id < 0 || words[i+k].ID != id {
+			return i
+		}
+	}
+	for j := i + len(rfn); j < len(words); j++ {
+		gap := text[words[j-1].Hi:words[j].Lo]
+		if k := bytes.IndexByte(gap, '.'); k >= 0 && bytes.ContainsAny(gap[k:], " \t\r\n") {
+			return j
+		}
+	}
+	return i
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLicenseOffsets(t *testing.T) {
+	text := func(id string) string {
+		t.Helper()
+		s, ok := Text(id)
+		if !ok {
+			t.Fatalf("missing text for %s", id)
+		}
+		return s
+	}
+	mit := text("MIT")
+	bsd := text("BSD-3-Clause")
+	apache := text("Apache-2.0")
+
+	tests := []struct {
+		name  string
+		input string
+		id    string
+		start string // license text at LicenseStart
+		end   string // license text ending at LicenseEnd
+	}{
+		{"MIT", mit, "MIT", "Copyright", "OTHER DEALINGS IN THE SOFTWARE"},
+		{"Title", "Software License\n\n" + bsd, "BSD-3-Clause", "Copyright", "OF THE POSSIBILITY OF SUCH DAMAGE"},
+		{"Header", headerTexts[0].text, "Apache-2.0", "Licensed under the Apache License", "limitations under the License"},
+	}
+	for _, tt := range tests {
+		cov := Scan([]byte(tt.input))
+		if len(cov.Match) != 1 || cov.Match[0].ID != tt.id {
+			t.Errorf("%s: Scan = %+v, want one %s match", tt.name, cov.Match, tt.id)
+			continue
+		}
+		m := cov.Match[0]
+		lt := text(tt.id)
+		if m.LicenseStart < 0 || m.LicenseStart >= m.LicenseEnd || m.LicenseEnd > len(lt) {
+			t.Errorf("%s: LicenseStart, LicenseEnd = %d, %d, want offsets in text of length %d", tt.name, m.LicenseStart, m.LicenseEnd, len(lt))
+			continue
+		}
+		if part := lt[m.LicenseStart:m.LicenseEnd]; !strings.HasPrefix(part, tt.start) || !strings.HasSuffix(part, tt.end) {
+			t.Errorf("%s: license text at LicenseStart, LicenseEnd = %q...%q, want %q...%q", tt.name,
+				part[:len(tt.start)], part[len(part)-len(tt.end):], tt.start, tt.end)
+		}
+	}
+
+	// The offsets of a full license text span nearly all of it.
+	cov := Scan([]byte(apache))
+	if len(cov.Match) != 1 || cov.Match[0].LicenseStart != 0 || cov.Match[0].LicenseEnd < len(apache)-2 {
+		t.Errorf("Scan(Apache-2.0) = %+v, want match of whole license", cov.Match)
+	}
+
+	// A URL match has no offsets.
+	cov = Scan([]byte(urlTests[0].text))
+	if len(cov.Match) != 1 || !cov.Match[0].IsURL || cov.Match[0].LicenseStart != 0 || cov.Match[0].LicenseEnd != 0 {
+		t.Errorf("Scan(URL) = %+v, want URL match without license offsets", cov.Match)
+	}
+}
+
+func TestIsExact(t *testing.T) {
+	for _, l := range BuiltinLicenses() {
+		text, ok := Text(l.ID)
+		if !ok || l.IsHeader || l.Language != "" {
+			continue
+		}
+		cov := Scan([]byte(text))
+		if len(cov.Match) != 1 || !cov.Match[0].IsExact {
+			t.Errorf("Scan(%s text) = %+v, want IsExact", l.ID, cov.Match)
+		}
+	}
+
+	mit, _ := Text("MIT")
+	bsd, _ := Text("BSD-3-Clause")
+	tests := []struct {
+		name  string
+		text  string
+		exact bool
+	}{
+		{"Copyright", strings.Replace(mit, "Copyright <YEAR> <HOLDER>", "Copyright (c) 2020 Jane Doe\nAll rights reserved.", 1), true},
+		{"Name", strings.Replace(bsd, "the copyright holder nor", "Google Inc. nor", 1), false},
+		{"Header", headerTexts[0].text, false},
+	}
+	for _, tt := range tests {
+		cov := Scan([]byte(tt.text))
+		if len(cov.Match) != 1 || cov.Match[0].IsExact != tt.exact {
+			t.Errorf("%s: Scan = %+v, want IsExact=%v", tt.name, cov.Match, tt.exact)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package spdx downloads license texts from the SPDX license list
+// and converts them into licensecheck.License entries.
+//
+// The built-in license set in licensecheck is pinned to a specific
+// SPDX license list version. Deployments that must track newer SPDX
+// releases between licensecheck versions can use a Fetcher to build
+// (or periodically rebuild) a Scanner from the live list:
+//
+//	f := &spdx.Fetcher{CacheDir: dir}
+//	list, err := f.Licenses(ctx, "MIT", "Apache-2.0")
+//	if err != nil {
+//		...
+//	}
+//	s, err := licensecheck.NewScanner(list)
+//
+// This package makes network requests.
+package spdx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/licensecheck"
+)
+
+// DefaultBaseURL is the location of the latest SPDX license list JSON data.
+const DefaultBaseURL = "https://spdx.org/licenses"
+
+// VersionBaseURL is the location of the JSON data for a specific
+// SPDX license list version, with the version replacing %s.
+const VersionBaseURL = "https://raw.githubusercontent.com/spdx/license-list-data/v%s/json"
+
+// DefaultMaxBytes is the default limit on the size of a downloaded file.
+// The largest file in the SPDX license list, licenses.json, is well under it.
+const DefaultMaxBytes = 16 << 20
+
+// versionRE matches the SPDX license list versions, such as "3.10".
+var versionRE = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+
+// A Fetcher downloads license data from the SPDX license list.
+// The zero Fetcher is ready to use, with no caching.
+type Fetcher struct {
+	// Client is the HTTP client to use.
+	// If nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// Version is the SPDX license list version to fetch, such as "3.10":
+	// a major and minor version number, with no "v" prefix.
+	// If empty, the latest version is fetched.
+	Version string
+
+	// BaseURL is the URL of the license data.
+	// If Version is empty, BaseURL is the directory holding
+	// licenses.json and the per-license id.json files,
+	// and it defaults to DefaultBaseURL.
+	// If Version is set, BaseURL is the json directory of a
+	// github.com/spdx/license-list-data checkout, holding
+	// licenses.json and details/id.json files,
+	// and it defaults to VersionBaseURL.
+	BaseURL string
+
+	// CacheDir is a directory in which to cache downloaded files.
+	// Each cached file is stored alongside its SHA-256 checksum,
+	// and a cached file that does not match its checksum,
+	// such as one left partly written, is downloaded again.
+	// The checksum only detects corruption of the cache;
+	// it says nothing about whether the download itself was genuine.
+	// Files for a specific Version are cached in the subdirectory "v"+Version.
+	// If empty, nothing is cached.
+	CacheDir string
+
+	// MaxAge is the maximum age of a cached file.
+	// Older cached files are downloaded again.
+	// If zero, cached files never expire.
+	// Files for a specific Version never change, so they never expire.
+	MaxAge time.Duration
+
+	// MaxBytes limits the size of a downloaded file.
+	// If zero, the limit is DefaultMaxBytes.
+	MaxBytes int64
+}
+
+// An Entry describes a single license in the SPDX license list.
+type Entry struct {
+	ID          string   `json:"licenseId"`
+	Name        string   `json:"name"`
+	Deprecated  bool     `json:"isDeprecatedLicenseId"`
+	OSIApproved bool     `json:"isOsiApproved"`
+	SeeAlso     []string `json:"seeAlso"`
+}
+
+// A List is the SPDX license list.
+type List struct {
+	Version  string  `json:"licenseListVersion"`
+	Licenses []Entry `json:"licenses"`
+}
+
+// A Details is the detailed SPDX data for a single license.
+type Details struct {
+	ID       string   `json:"licenseId"`
+	Name     string   `json:"name"`
+	Text     string   `json:"licenseText"`
+	Template string   `json:"standardLicenseTemplate"`
+	SeeAlso  []string `json:"seeAlso"`
+}
+
+// List returns the SPDX license list.
+func (f *Fetcher) List(ctx context.Context) (*List, error) {
+	data, err := f.get(ctx, "licenses.json")
+	if err != nil {
+		return nil, err
+	}
+	list := &List{Version: f.Version}
+	if err := json.Unmarshal(data, list); err != nil {
+		return nil, fmt.Errorf("spdx: parsing licenses.json: %v", err)
+	}
+	return list, nil
+}
+
+// Details returns the detailed SPDX data for the license with the given ID.
+func (f *Fetcher) Details(ctx context.Context, id string) (*Details, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return nil, fmt.Errorf("spdx: invalid license ID %q", id)
+	}
+	name := id + ".json"
+	if f.Version != "" {
+		name = "details/" + name
+	}
+	data, err := f.get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	d := new(Details)
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, fmt.Errorf("spdx: parsing %s.json: %v", id, err)
+	}
+	if d.Text == "" {
+		return nil, fmt.Errorf("spdx: %s.json has no license text", id)
+	}
+	return d, nil
+}
+
+// Licenses returns licensecheck.License entries for the given license IDs.
+// If no IDs are given, Licenses returns entries for all
+// non-deprecated licenses in the SPDX license list.
+// The ListVersion of each entry is the version of the fetched list.
+func (f *Fetcher) Licenses(ctx context.Context, ids ...string) ([]licensecheck.License, error) {
+	list, err := f.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		for _, e := range list.Licenses {
+			if !e.Deprecated {
+				ids = append(ids, e.ID)
+			}
+		}
+	}
+
+	var out []licensecheck.License
+	for _, id := range ids {
+		d, err := f.Details(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		l := d.License()
+		l.ListVersion = list.Version
+		out = append(out, l)
+	}
+	return out, nil
+}
+
+// License returns the licensecheck.License for d.
+// The license is matched by its SPDX template, which allows for
+// variable text like copyright lines and optional sections.
+// If the template is missing or cannot be parsed,
+// the license is matched by its literal text instead.
+func (d *Details) License() licensecheck.License {
+	lre, err := licensecheck.SPDXTemplateLRE(d.Template)
+	if d.Template == "" || err != nil {
+		lre = licensecheck.QuoteLRE(d.Text)
+	}
+	return licensecheck.License{
+		ID:     d.ID,
+		LRE:    lre,
+		SPDXID: d.ID,
+		Name:   d.Name,
+		Text:   d.Text,
+	}
+}
+
+// baseURL returns the base URL for fetching files.
+func (f *Fetcher) baseURL() string {
+	switch {
+	case f.BaseURL != "":
+		return strings.TrimSuffix(f.BaseURL, "/")
+	case f.Version != "":
+		return fmt.Sprintf(VersionBaseURL, f.Version)
+	}
+	return DefaultBaseURL
+}
+
+// cacheDir returns the directory for cached files, or "" for no caching.
+func (f *Fetcher) cacheDir() string {
+	if f.CacheDir == "" || f.Version == "" {
+		return f.CacheDir
+	}
+	return filepath.Join(f.CacheDir, "v"+f.Version)
+}
+
+// get returns the content of the named file,
+// from the cache if possible.
+func (f *Fetcher) get(ctx context.Context, name string) ([]byte, error) {
+	if f.Version != "" && !versionRE.MatchString(f.Version) {
+		return nil, fmt.Errorf("spdx: invalid Version %q", f.Version)
+	}
+	if data, ok := f.readCache(name); ok {
+		return data, nil
+	}
+
+	req, err := http.NewRequest("GET", f.baseURL()+"/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("spdx: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("spdx: fetching %s: %s", req.URL, resp.Status)
+	}
+	max := f.MaxBytes
+	if max <= 0 {
+		max = DefaultMaxBytes
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, max+1))
+	if err != nil {
+		return nil, fmt.Errorf("spdx: fetching %s: %v", req.URL, err)
+	}
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("spdx: fetching %s: content larger than %d bytes", req.URL, max)
+	}
+
+	if err := f.writeCache(name, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// readCache returns the cached content of the named file, if any.
+// It reports false for a missing, expired, or corrupt cache entry.
+func (f *Fetcher) readCache(name string) ([]byte, bool) {
+	dir := f.cacheDir()
+	if dir == "" {
+		return nil, false
+	}
+	file := filepath.Join(dir, filepath.FromSlash(name))
+	info, err := os.Stat(file)
+	if err != nil || f.Version == "" && f.MaxAge > 0 && time.Since(info.ModTime()) > f.MaxAge {
+		return nil, false
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+	sum, err := ioutil.ReadFile(file + ".sha256")
+	if err != nil || checksum(data) != strings.TrimSpace(string(sum)) {
+		return nil, false
+	}
+	return data, true
+}
+
+// writeCache saves data as the cached content of the named file.
+func (f *Fetcher) writeCache(name string, data []byte) error {
+	dir := f.cacheDir()
+	if dir == "" {
+		return nil
+	}
+	file := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		return fmt.Errorf("spdx: %v", err)
+	}
+	if err := ioutil.WriteFile(file, data, 0666); err != nil {
+		return fmt.Errorf("spdx: %v", err)
+	}
+	if err := ioutil.WriteFile(file+".sha256", []byte(checksum(data)+"\n"), 0666); err != nil {
+		return fmt.Errorf("spdx: %v", err)
+	}
+	return nil
+}
+
+// checksum returns the hex SHA-256 checksum of data.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
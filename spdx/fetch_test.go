@@ -0,0 +1,162 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spdx
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/licensecheck"
+)
+
+const testText = `Permission to use, copy, modify, and distribute this software
+for any purpose with or without fee is hereby granted, provided that
+the above notice appears in all copies. ((This)) text has no warranty.
+`
+
+var testFiles = map[string]string{
+	"licenses.json": `{"licenseListVersion": "9.99", "licenses": [
+		{"licenseId": "Test-1.0", "name": "Test License 1.0"},
+		{"licenseId": "Old-1.0", "name": "Old License", "isDeprecatedLicenseId": true}
+	]}`,
+	"Test-1.0.json":         testDetails,
+	"details/Test-1.0.json": testDetails,
+}
+
+var testDetails = `{"licenseId": "Test-1.0", "name": "Test License 1.0", "licenseText": ` + quote(testText) + `}`
+
+func quote(s string) string {
+	js, err := json.Marshal(s)
+	if err != nil {
+		panic(err)
+	}
+	return string(js)
+}
+
+func newServer(hits map[string]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path[1:]
+		hits[name]++
+		data, ok := testFiles[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(data))
+	}))
+}
+
+func TestFetcher(t *testing.T) {
+	hits := make(map[string]int)
+	srv := newServer(hits)
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "spdx-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	f := &Fetcher{BaseURL: srv.URL, CacheDir: dir}
+	list, err := f.Licenses(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != "Test-1.0" || list[0].SPDXID != "Test-1.0" || list[0].Name != "Test License 1.0" {
+		t.Fatalf("Licenses() = %+v, want only Test-1.0", list)
+	}
+
+	s, err := licensecheck.NewScanner(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cov := s.Scan([]byte(testText))
+	if len(cov.Match) != 1 || cov.Match[0].ID != "Test-1.0" || cov.Percent != 100 {
+		t.Errorf("Scan(testText) = %+v, want 100%% Test-1.0", cov)
+	}
+
+	// Second fetch should come from the cache.
+	if _, err := f.Licenses(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if hits["licenses.json"] != 1 || hits["Test-1.0.json"] != 1 {
+		t.Errorf("cache not used: hits = %v", hits)
+	}
+
+	// Corrupt cache entry must be refetched.
+	if err := ioutil.WriteFile(filepath.Join(dir, "Test-1.0.json"), []byte("{}"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	d, err := f.Details(ctx, "Test-1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Text != testText || hits["Test-1.0.json"] != 2 {
+		t.Errorf("corrupt cache not refetched: hits = %v", hits)
+	}
+
+	if _, err := f.Details(ctx, "Missing"); err == nil {
+		t.Errorf("Details(Missing) succeeded, want error")
+	}
+	if _, err := f.Details(ctx, "../etc/passwd"); err == nil {
+		t.Errorf("Details(../etc/passwd) succeeded, want error")
+	}
+
+	small := &Fetcher{BaseURL: srv.URL, MaxBytes: 10}
+	if _, err := small.List(ctx); err == nil {
+		t.Errorf("List with MaxBytes 10 succeeded, want error")
+	}
+}
+
+func TestFetcherVersion(t *testing.T) {
+	hits := make(map[string]int)
+	srv := newServer(hits)
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "spdx-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	f := &Fetcher{Version: "9.99", BaseURL: srv.URL, CacheDir: dir}
+	list, err := f.Licenses(ctx, "Test-1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ListVersion != "9.99" {
+		t.Fatalf("Licenses(Test-1.0) = %+v, want ListVersion 9.99", list)
+	}
+	if hits["details/Test-1.0.json"] != 1 {
+		t.Errorf("versioned fetch did not use details/: hits = %v", hits)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "v9.99", "details", "Test-1.0.json")); err != nil {
+		t.Errorf("versioned file not cached: %v", err)
+	}
+
+	s, err := licensecheck.NewScanner(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cov := s.Scan([]byte(testText))
+	if len(cov.Match) != 1 || cov.Match[0].ListVersion != "9.99" {
+		t.Errorf("Scan(testText) = %+v, want ListVersion 9.99", cov)
+	}
+
+	for _, v := range []string{"v3.10", "3", "3.10.1", "1/../../x", ".."} {
+		f := &Fetcher{Version: v, BaseURL: srv.URL, CacheDir: dir}
+		if _, err := f.List(ctx); err == nil {
+			t.Errorf("List with Version %q succeeded, want error", v)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+// A Submatch is a run of consecutive words that a match shares
+// with the canonical text of its license (see Text),
+// reported only when Options.Submatches is set.
+// The submatches of a match are in order in both texts,
+// and together they are a longest sequence of words the two have in common.
+type Submatch struct {
+	Match        int `json:"match"` // index of the match in Coverage.Match
+	Start        int `json:"start"` // the run is text[Start:End] in the scanned text
+	End          int `json:"end"`
+	LicenseStart int `json:"licenseStart"` // and text[LicenseStart:LicenseEnd] in the license's canonical text
+	LicenseEnd   int `json:"licenseEnd"`
+	Words        int `json:"words"` // number of words in the run
+}
+
+// setSubmatches sets c.Submatches for the matches in c,
+// which must come from scanning text.
+// Matches of URLs, tags, headers, and translations, which are not
+// copies of the license's canonical text, have no submatches,
+// nor do matches of licenses with no canonical text.
+func (s *Scanner) setSubmatches(c *Coverage, text []byte) {
+	c.Submatches = nil
+	d := s.re.Dict()
+	for i, m := range c.Match {
+		if m.IsURL || m.IsTag || m.IsHeader || m.Language != "" || m.Start < 0 || m.End > len(text) {
+			continue
+		}
+		lt, ok := s.Text(m.ID)
+		if !ok {
+			continue
+		}
+		words := d.Split(string(text[m.Start:m.End]))
+		lw := d.Split(lt)
+		pairs := diffWords(wordIDsOf(words), wordIDsOf(lw), 0, 0, nil)
+		for k := 0; k < len(pairs); {
+			// Extend the run over the pairs that follow on in both texts.
+			n := 1
+			for k+n < len(pairs) && pairs[k+n][0] == pairs[k][0]+n && pairs[k+n][1] == pairs[k][1]+n {
+				n++
+			}
+			first, last := pairs[k], pairs[k+n-1]
+			c.Submatches = append(c.Submatches, Submatch{
+				Match:        i,
+				Start:        m.Start + int(words[first[0]].Lo),
+				End:          m.Start + int(words[last[0]].Hi),
+				LicenseStart: int(lw[first[1]].Lo),
+				LicenseEnd:   int(lw[last[1]].Hi),
+				Words:        n,
+			})
+			k += n
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubmatches(t *testing.T) {
+	mit, _ := Text("MIT")
+
+	// Without the option, there are no submatches.
+	cov, err := ScanWithOptions([]byte(mit), Options{})
+	if err != nil || len(cov.Submatches) != 0 {
+		t.Fatalf("ScanWithOptions(MIT) = %d submatches, %v, want none", len(cov.Submatches), err)
+	}
+
+	// An exact copy is a single run covering the whole license.
+	cov, err = ScanWithOptions([]byte(mit), Options{Submatches: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cov.Submatches) != 1 {
+		t.Fatalf("ScanWithOptions(MIT).Submatches = %+v, want one", cov.Submatches)
+	}
+	if sm, m := cov.Submatches[0], cov.Match[0]; sm.Match != 0 || sm.Words != m.LicenseWords || sm.LicenseStart != 0 || sm.LicenseEnd != strings.LastIndex(mit, "SOFTWARE")+len("SOFTWARE") {
+		t.Errorf("ScanWithOptions(MIT).Submatches[0] = %+v, want whole license of %d words", sm, m.LicenseWords)
+	}
+
+	// A filled-in copyright line splits the runs,
+	// and the runs hold the same words in both texts.
+	text := "Prologue.\n\n" + strings.Replace(mit, "<YEAR> <HOLDER>", "2020 The Go Authors", 1)
+	cov, err = ScanWithOptions([]byte(text), Options{Submatches: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cov.Submatches) != 2 {
+		t.Fatalf("ScanWithOptions(MIT with copyright).Submatches = %+v, want two", cov.Submatches)
+	}
+	d := builtinScanner.re.Dict()
+	for _, sm := range cov.Submatches {
+		have := wordIDsOf(d.Split(text[sm.Start:sm.End]))
+		want := wordIDsOf(d.Split(mit[sm.LicenseStart:sm.LicenseEnd]))
+		if len(have) != sm.Words || !reflect.DeepEqual(have, want) {
+			t.Errorf("submatch %+v: %d words in text, %d in license, want %d", sm, len(have), len(want), sm.Words)
+		}
+	}
+	if sm := cov.Submatches[1]; !strings.HasPrefix(text[sm.Start:], "Permission") {
+		t.Errorf("second submatch starts at %q, want Permission", text[sm.Start:sm.End])
+	}
+}
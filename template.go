@@ -0,0 +1,261 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/licensecheck/internal/match"
+)
+
+// SPDX license templates are license texts marked up with
+//
+//	<<var;name="copyright";original="Copyright (c) <year>";match=".{0,500}">>
+//	<<beginOptional>> ... <<endOptional>>
+//
+// as described in Appendix V of the SPDX specification.
+// SPDXTemplateLRE converts such a template into an LRE,
+// turning each var into a wildcard and each optional section
+// into an optional group, so that the placeholder information
+// is kept instead of being flattened into the original text.
+
+// maxTemplateWild is the largest wildcard generated for a template variable.
+// It is large enough for copyright lines but not for whole licenses.
+const maxTemplateWild = 70
+
+// SPDXTemplateLRE returns an LRE equivalent to the SPDX license template tmpl.
+//
+// An LRE can neither begin nor end with a wildcard or an optional group,
+// but SPDX templates often begin with an optional title or a copyright variable.
+// Those leading and trailing sections are dropped from the result.
+// They do not affect whether a license is recognized,
+// only the exact extent of the reported match.
+func SPDXTemplateLRE(tmpl string) (string, error) {
+	root, err := parseSPDXTemplate(tmpl)
+	if err != nil {
+		return "", err
+	}
+	root.sub = trimTemplate(root.sub)
+	if len(root.sub) == 0 {
+		return "", fmt.Errorf("spdx template: no required text")
+	}
+	var b strings.Builder
+	root.writeLRE(&b)
+	return b.String(), nil
+}
+
+// A tmplNode is a node in a parsed SPDX template.
+type tmplNode struct {
+	kind tmplKind
+	text string      // tmplText
+	n    int         // tmplVar: wildcard size in words
+	sub  []*tmplNode // tmplOptional
+}
+
+type tmplKind int
+
+const (
+	tmplText tmplKind = iota
+	tmplVar
+	tmplOptional
+)
+
+// parseSPDXTemplate parses tmpl into a tree,
+// returned as the children of a tmplOptional root node.
+func parseSPDXTemplate(tmpl string) (*tmplNode, error) {
+	root := &tmplNode{kind: tmplOptional}
+	stack := []*tmplNode{root}
+	add := func(n *tmplNode) {
+		top := stack[len(stack)-1]
+		top.sub = append(top.sub, n)
+	}
+	for tmpl != "" {
+		i := strings.Index(tmpl, "<<")
+		if i < 0 {
+			add(&tmplNode{kind: tmplText, text: tmpl})
+			break
+		}
+		if i > 0 {
+			add(&tmplNode{kind: tmplText, text: tmpl[:i]})
+		}
+		tmpl = tmpl[i+2:]
+		j := tagEnd(tmpl)
+		if j < 0 {
+			return nil, fmt.Errorf("spdx template: unterminated <<")
+		}
+		name, attrs, err := parseTag(tmpl[:j])
+		if err != nil {
+			return nil, err
+		}
+		tmpl = tmpl[j+2:]
+
+		switch name {
+		case "beginOptional":
+			n := &tmplNode{kind: tmplOptional}
+			add(n)
+			stack = append(stack, n)
+		case "endOptional":
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("spdx template: endOptional without beginOptional")
+			}
+			stack = stack[:len(stack)-1]
+		case "var":
+			add(&tmplNode{kind: tmplVar, n: varWords(attrs["original"], attrs["match"])})
+		default:
+			return nil, fmt.Errorf("spdx template: unknown tag <<%s>>", name)
+		}
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("spdx template: beginOptional without endOptional")
+	}
+	return root, nil
+}
+
+// tagEnd returns the index of the >> ending the tag at the start of s,
+// ignoring any >> inside quoted attribute values, or -1 if there is none.
+func tagEnd(s string) int {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quoted && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(s[i:], ">>"):
+			return i
+		}
+	}
+	return -1
+}
+
+// parseTag parses the tag text name;key="value";key="value".
+func parseTag(tag string) (name string, attrs map[string]string, err error) {
+	attrs = make(map[string]string)
+	i := strings.Index(tag, ";")
+	if i < 0 {
+		return strings.TrimSpace(tag), attrs, nil
+	}
+	name, tag = strings.TrimSpace(tag[:i]), tag[i+1:]
+	for tag != "" {
+		eq := strings.Index(tag, "=")
+		if eq < 0 {
+			return "", nil, fmt.Errorf("spdx template: malformed <<%s>> attribute", name)
+		}
+		key := strings.TrimSpace(tag[:eq])
+		rest := strings.TrimLeft(tag[eq+1:], " \t")
+		if rest == "" || rest[0] != '"' {
+			return "", nil, fmt.Errorf("spdx template: unquoted <<%s>> attribute %s", name, key)
+		}
+		end := 1
+		for end < len(rest) && rest[end] != '"' {
+			if rest[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(rest) {
+			return "", nil, fmt.Errorf("spdx template: unterminated <<%s>> attribute %s", name, key)
+		}
+		val, err := strconv.Unquote(rest[:end+1])
+		if err != nil {
+			// Regexp escapes like \( are not valid Go escapes; keep the raw text.
+			val = rest[1:end]
+		}
+		attrs[key] = val
+		tag = strings.TrimLeft(rest[end+1:], " \t")
+		tag = strings.TrimPrefix(tag, ";")
+	}
+	return name, attrs, nil
+}
+
+// boundedRE matches a bounded any-character repetition like .{0,500}.
+var boundedRE = regexp.MustCompile(`\.\{\d*,(\d+)\}`)
+
+// varWords returns the wildcard size to use for a variable
+// with the given original text and match regexp.
+func varWords(original, matchRE string) int {
+	n := countWords(original)
+	switch {
+	case strings.Contains(matchRE, ".+") || strings.Contains(matchRE, ".*"):
+		n = maxTemplateWild
+	case boundedRE.MatchString(matchRE):
+		max := 0
+		for _, m := range boundedRE.FindAllStringSubmatch(matchRE, -1) {
+			k, _ := strconv.Atoi(m[1])
+			max += k
+		}
+		// Assume about five bytes per word.
+		if w := max/5 + 1; w > n {
+			n = w
+		}
+	}
+	if n > maxTemplateWild {
+		n = maxTemplateWild
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// countWords returns the number of words in text, as split for matching.
+func countWords(text string) int {
+	return len(new(match.Dict).Split(text))
+}
+
+// trimTemplate removes leading and trailing nodes from list
+// until it begins with a phrase of at least two words
+// and ends with at least one word.
+func trimTemplate(list []*tmplNode) []*tmplNode {
+	for len(list) > 0 && (list[0].kind != tmplText || countWords(list[0].text) < 2) {
+		list = list[1:]
+	}
+	for len(list) > 0 && (list[len(list)-1].kind != tmplText || countWords(list[len(list)-1].text) < 1) {
+		list = list[:len(list)-1]
+	}
+	return list
+}
+
+// writeLRE writes the LRE form of the children of n to b.
+func (n *tmplNode) writeLRE(b *strings.Builder) {
+	for _, sub := range n.sub {
+		switch sub.kind {
+		case tmplText:
+			b.WriteString(QuoteLRE(sub.text))
+		case tmplVar:
+			fmt.Fprintf(b, " __%d__ ", sub.n)
+		case tmplOptional:
+			if !sub.hasWords() {
+				// Only variables and punctuation,
+				// which are optional already.
+				sub.writeLRE(b)
+				continue
+			}
+			b.WriteString("\n((\n")
+			sub.writeLRE(b)
+			b.WriteString("\n))??\n")
+		}
+	}
+}
+
+// hasWords reports whether the optional section n contains any literal words.
+func (n *tmplNode) hasWords() bool {
+	for _, sub := range n.sub {
+		switch sub.kind {
+		case tmplText:
+			if countWords(sub.text) > 0 {
+				return true
+			}
+		case tmplOptional:
+			if sub.hasWords() {
+				return true
+			}
+		}
+	}
+	return false
+}
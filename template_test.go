@@ -0,0 +1,74 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"strings"
+	"testing"
+)
+
+// templateMIT is the SPDX template for the MIT license,
+// in the format of the SPDX license-list-data .template.txt files.
+const templateMIT = `<<beginOptional>> MIT License<<endOptional>>
+
+<<var;name="copyright";original="Copyright (c) <year> <copyright holders>";match=".{0,5000}">>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice<<beginOptional>> (including the next paragraph)<<endOptional>> shall be included in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE <<var;name="copyrightHolder";original="AUTHORS OR COPYRIGHT HOLDERS";match=".+">> BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+<<beginOptional>> <<var;name="note";original="";match=".*">> <<endOptional>>
+`
+
+const textMIT = `Copyright 2020 Example Corp.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice (including the next paragraph) shall be included in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OF EXAMPLE CORP. BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+`
+
+func TestSPDXTemplateLRE(t *testing.T) {
+	lre, err := SPDXTemplateLRE(templateMIT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(lre, "<<") {
+		t.Errorf("SPDXTemplateLRE(MIT) left template markup:\n%s", lre)
+	}
+	s, err := NewScanner([]License{{ID: "MIT", LRE: lre}})
+	if err != nil {
+		t.Fatalf("NewScanner: %v\nLRE:\n%s", err, lre)
+	}
+	for _, text := range []string{
+		textMIT,
+		strings.Replace(textMIT, " (including the next paragraph)", "", 1),
+	} {
+		cov := s.Scan([]byte(text))
+		if len(cov.Match) != 1 || cov.Match[0].ID != "MIT" || cov.Percent < 90 {
+			t.Errorf("Scan(%.40q...) = %+v, want MIT", text, cov)
+		}
+	}
+}
+
+var badTemplates = []string{
+	"",
+	"<<var;name=\"x\";original=\"y\";match=\".+\">>",
+	"Some license text <<beginOptional>> more text",
+	"Some license text <<endOptional>> more text",
+	"Some license text <<var;name=\"x\" more text",
+	"Some license text <<var;name=x>> more text",
+	"Some license text <<unknown>> more text",
+}
+
+func TestSPDXTemplateLREErrors(t *testing.T) {
+	for _, tmpl := range badTemplates {
+		if lre, err := SPDXTemplateLRE(tmpl); err == nil {
+			t.Errorf("SPDXTemplateLRE(%q) = %q, want error", tmpl, lre)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import "github.com/google/licensecheck/internal/match"
+
+// A Tokenizer splits text into words, replacing the built-in word splitting
+// in a Scanner created by NewScannerTokenizer.
+//
+// Tokenize returns the words of text in order. Each Word's Text is the
+// word as it should be compared, already normalized (for example, folded
+// to lower case), and Start and End are its byte offsets in text.
+// Words with empty Text, or with offsets out of range or overlapping
+// the previous word, are ignored.
+//
+// The Scanner uses the same Tokenizer for the license patterns and for
+// the texts it scans, so the two are always split consistently.
+// The literal words of each LRE are split by calling Tokenize
+// on each run of literal text in the pattern, so a Tokenizer
+// must not need LRE syntax such as (( )) or ?? to be part of a word.
+type Tokenizer interface {
+	Tokenize(text []byte) []Word
+}
+
+// NewScannerTokenizer is like NewScanner but splits both the licenses
+// and the scanned texts into words using t.
+//
+// The built-in word splitting, used when t is nil, folds case,
+// ignores punctuation and HTML and Markdown markup,
+// and corrects some common spelling variations;
+// a Tokenizer takes over all of that.
+// Top-level functions that do not use a Scanner, such as Compare,
+// Cluster, SimHash, MinHash, and InferLRE, always use the built-in splitting,
+// as does the Scanner's Nearest method.
+// A Scanner with a Tokenizer cannot be saved with Save.
+func NewScannerTokenizer(licenses []License, t Tokenizer) (*Scanner, error) {
+	s := &Scanner{tokenizer: t}
+	err := s.init(licenses)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// newDict returns a new, empty dictionary
+// that splits words the way s does.
+func (s *Scanner) newDict() *match.Dict {
+	d := new(match.Dict)
+	if t := s.tokenizer; t != nil {
+		d.SetTokenizer(func(text string) []match.Token {
+			words := t.Tokenize([]byte(text))
+			toks := make([]match.Token, len(words))
+			for i, w := range words {
+				toks[i] = match.Token{Text: w.Text, Lo: w.Start, Hi: w.End}
+			}
+			return toks
+		})
+	}
+	return d
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A camelTokenizer splits text into runs of letters,
+// also splitting each run before an upper-case letter
+// that follows a lower-case one, as in identifiers like "FreeOfCharge".
+type camelTokenizer struct{}
+
+func (camelTokenizer) Tokenize(text []byte) []Word {
+	var words []Word
+	start := -1
+	prev := rune(0)
+	flush := func(end int) {
+		if start >= 0 {
+			words = append(words, Word{strings.ToLower(string(text[start:end])), start, end})
+			start = -1
+		}
+	}
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRune(text[i:])
+		switch {
+		case !unicode.IsLetter(r):
+			flush(i)
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			flush(i)
+			start = i
+		case start < 0:
+			start = i
+		}
+		prev = r
+		i += size
+	}
+	flush(len(text))
+	return words
+}
+
+func TestTokenizer(t *testing.T) {
+	licenses := []License{{
+		ID:  "Camel",
+		LRE: "Permission is hereby granted, free of charge, to any person obtaining this software.",
+	}}
+	text := []byte("// PermissionIsHerebyGrantedFreeOfChargeToAnyPersonObtainingThisSoftware\n")
+
+	plain, err := NewScanner(licenses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := plain.Scan(text); len(c.Match) != 0 {
+		t.Errorf("built-in splitting: Scan = %+v, want no matches", c.Match)
+	}
+
+	s, err := NewScannerTokenizer(licenses, camelTokenizer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.ScanWithOptions(text, Options{Words: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Match) != 1 || c.Match[0].ID != "Camel" || c.Match[0].Start != 0 || c.Match[0].End != len(text) {
+		t.Errorf("tokenizer: Scan = %+v, want Camel at [0,%d)", c.Match, len(text))
+	}
+	want := strings.Fields("permission is hereby granted free of charge to any person obtaining this software")
+	if !reflect.DeepEqual(c.Words, want) {
+		t.Errorf("tokenizer: Words = %q, want %q", c.Words, want)
+	}
+
+	if err := s.Save(new(bytes.Buffer)); err == nil {
+		t.Errorf("Save of Scanner with Tokenizer succeeded, want error")
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tune measures how well licensecheck's results, filtered by a
+// coverage threshold, agree with a labeled corpus, so that a program can
+// choose its threshold and Options with evidence instead of by guesswork.
+//
+// A program using licensecheck typically accepts a result only if
+// Coverage.Percent is at least some threshold. A high threshold rejects
+// license files with extra text, such as a preamble naming the project;
+// a low one accepts files that merely quote part of a license.
+// Sweep scans a corpus of texts labeled with the licenses they hold,
+// once for each Options to try, and reports the precision and recall
+// at each threshold:
+//
+//	samples, err := tune.ReadSamples(os.DirFS("corpus"), "*")
+//	if err != nil {
+//		...
+//	}
+//	for _, p := range tune.Sweep(samples, tune.Config{}) {
+//		fmt.Printf("%3.0f%% OCR=%v: precision %.3f recall %.3f\n",
+//			p.MinPercent, p.Options.OCR, p.Precision, p.Recall)
+//	}
+//
+// The older matcher's MinLength, Slop, and Threshold settings
+// have no counterpart in the current matcher, whose only tuning
+// is the choice of threshold and Options.
+package tune
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/google/licensecheck"
+)
+
+// A Sample is a labeled text.
+type Sample struct {
+	Name string   // name of the sample, for reports
+	Text []byte   // text to scan
+	IDs  []string // IDs of the licenses the text holds; empty if none
+}
+
+// A Config says which settings Sweep tries.
+type Config struct {
+	// Scanner is the scanner to use.
+	// If nil, Sweep uses licensecheck.ScanWithOptions.
+	Scanner *licensecheck.Scanner
+
+	// Options lists the options to try.
+	// If empty, Sweep tries only the zero Options.
+	Options []licensecheck.Options
+
+	// MinPercents lists the thresholds on Coverage.Percent to try.
+	// If empty, Sweep tries 0, 5, 10, ..., 100.
+	MinPercents []float64
+}
+
+// A Point is the result of scoring a corpus with one setting.
+type Point struct {
+	Options    licensecheck.Options
+	MinPercent float64
+
+	// TP counts the expected licenses that were found,
+	// FP the licenses found that were not expected,
+	// and FN the expected licenses that were not found.
+	// A sample's licenses are found only if its Coverage.Percent
+	// is at least MinPercent; URL matches are ignored.
+	TP, FP, FN int
+
+	Precision float64 // TP / (TP + FP), or 1 if nothing was found
+	Recall    float64 // TP / (TP + FN), or 1 if nothing was expected
+	F1        float64 // harmonic mean of Precision and Recall
+}
+
+// Sweep scans each sample once for each of cfg.Options
+// and scores the results at each of cfg.MinPercents.
+// It returns one Point for each combination,
+// ordered by Options (in the order given) and then by MinPercent.
+// Errors from scanning, such as licensecheck.ErrNoMatch,
+// are treated as finding no licenses.
+func Sweep(samples []Sample, cfg Config) []Point {
+	opts := cfg.Options
+	if len(opts) == 0 {
+		opts = []licensecheck.Options{{}}
+	}
+	mins := cfg.MinPercents
+	if len(mins) == 0 {
+		for p := 0; p <= 100; p += 5 {
+			mins = append(mins, float64(p))
+		}
+	}
+	scan := licensecheck.ScanWithOptions
+	if cfg.Scanner != nil {
+		scan = cfg.Scanner.ScanWithOptions
+	}
+
+	var points []Point
+	for _, o := range opts {
+		covs := make([]licensecheck.Coverage, len(samples))
+		for i, s := range samples {
+			covs[i], _ = scan(s.Text, o)
+		}
+		for _, min := range mins {
+			p := Point{Options: o, MinPercent: min}
+			for i, s := range samples {
+				var found []string
+				if covs[i].Percent >= min {
+					found = ids(covs[i])
+				}
+				tp, fp, fn := score(s.IDs, found)
+				p.TP += tp
+				p.FP += fp
+				p.FN += fn
+			}
+			p.Precision = ratio(p.TP, p.TP+p.FP)
+			p.Recall = ratio(p.TP, p.TP+p.FN)
+			if p.Precision+p.Recall > 0 {
+				p.F1 = 2 * p.Precision * p.Recall / (p.Precision + p.Recall)
+			}
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// Best returns the point with the highest F1 score,
+// preferring the higher threshold and then the earlier Options in a tie.
+// It returns the zero Point if points is empty.
+func Best(points []Point) Point {
+	var best Point
+	for i, p := range points {
+		if i == 0 || p.F1 > best.F1 || p.F1 == best.F1 && p.MinPercent > best.MinPercent {
+			best = p
+		}
+	}
+	return best
+}
+
+// ids returns the sorted, distinct IDs of the non-URL matches in cov.
+func ids(cov licensecheck.Coverage) []string {
+	var list []string
+	for _, m := range cov.Match {
+		if !m.IsURL {
+			list = append(list, m.ID)
+		}
+	}
+	return dedup(list)
+}
+
+// score compares the sets of expected and found IDs.
+func score(want, found []string) (tp, fp, fn int) {
+	want = dedup(want)
+	found = dedup(found)
+	for len(want) > 0 || len(found) > 0 {
+		switch {
+		case len(found) == 0 || len(want) > 0 && want[0] < found[0]:
+			fn++
+			want = want[1:]
+		case len(want) == 0 || found[0] < want[0]:
+			fp++
+			found = found[1:]
+		default:
+			tp++
+			want, found = want[1:], found[1:]
+		}
+	}
+	return tp, fp, fn
+}
+
+// dedup returns a sorted copy of list without duplicates.
+func dedup(list []string) []string {
+	list = append([]string(nil), list...)
+	sort.Strings(list)
+	out := list[:0]
+	for i, s := range list {
+		if i == 0 || s != list[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 1
+	}
+	return float64(n) / float64(d)
+}
+
+// ReadSamples reads the samples in the files in fsys matching pattern,
+// in the format of licensecheck's own testdata files (see testdata/README):
+// optional # comment lines, a line giving the expected percentage,
+// one line for each expected match, "ID start,end" or "ID start,end URL",
+// and a blank line, followed by the text.
+// The expected percentage and the offsets are ignored, as are URL matches.
+// Files named README are skipped.
+func ReadSamples(fsys fs.FS, pattern string) ([]Sample, error) {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("tune: %v", err)
+	}
+	var samples []Sample
+	for _, file := range files {
+		if strings.HasSuffix(file, "README") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("tune: %v", err)
+		}
+		s, err := ParseSample(file, data)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
+
+// ParseSample parses a single sample in the format read by ReadSamples.
+func ParseSample(name string, data []byte) (Sample, error) {
+	i := bytes.Index(data, []byte("\n\n"))
+	if i < 0 {
+		return Sample{}, fmt.Errorf("tune: %s: no blank line terminating header", name)
+	}
+	hdr, text := strings.Split(string(data[:i]), "\n"), data[i+2:]
+	for len(hdr) > 0 && strings.HasPrefix(hdr[0], "#") {
+		hdr = hdr[1:]
+	}
+	if len(hdr) == 0 || !strings.HasSuffix(hdr[0], "%") {
+		return Sample{}, fmt.Errorf("tune: %s: missing percentage line", name)
+	}
+	s := Sample{Name: name, Text: text}
+	for _, line := range hdr[1:] {
+		f := strings.Fields(line)
+		if len(f) != 2 && len(f) != 3 {
+			return Sample{}, fmt.Errorf("tune: %s: bad match line %q", name, line)
+		}
+		if len(f) == 3 {
+			continue // URL
+		}
+		s.IDs = append(s.IDs, f[0])
+	}
+	return s, nil
+}
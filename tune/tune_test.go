@@ -0,0 +1,84 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tune
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+)
+
+func TestSweep(t *testing.T) {
+	mit, ok := licensecheck.Text("MIT")
+	if !ok {
+		t.Fatal("no text for MIT")
+	}
+	preamble := strings.Repeat("This project is a tool for doing useful things with files.\n", 20)
+	samples := []Sample{
+		{Name: "plain", Text: []byte(mit), IDs: []string{"MIT"}},
+		{Name: "preamble", Text: []byte(preamble + "\n" + mit), IDs: []string{"MIT"}},
+		{Name: "none", Text: []byte(preamble)},
+		{Name: "wrong", Text: []byte(preamble + "\n" + mit)}, // mislabeled: no license expected
+	}
+	points := Sweep(samples, Config{MinPercents: []float64{0, 50, 100}})
+	type result struct {
+		min        float64
+		tp, fp, fn int
+	}
+	var got []result
+	for _, p := range points {
+		got = append(got, result{p.MinPercent, p.TP, p.FP, p.FN})
+	}
+	want := []result{
+		{0, 2, 1, 0},
+		{50, 1, 0, 1},
+		{100, 1, 0, 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Sweep = %+v, want %+v", got, want)
+	}
+	if p := points[0]; p.Precision != 2.0/3 || p.Recall != 1 || p.F1 != 0.8 {
+		t.Errorf("Sweep[0] = %+v, want precision 2/3, recall 1, F1 0.8", p)
+	}
+	if best := Best(points); best.MinPercent != 0 {
+		t.Errorf("Best = %+v, want MinPercent 0", best)
+	}
+
+	// Options multiply the points.
+	points = Sweep(samples, Config{Options: []licensecheck.Options{{}, {OCR: true}}})
+	if len(points) != 2*21 || points[21].Options.OCR != true || points[21].MinPercent != 0 {
+		t.Errorf("Sweep with two Options returned %d points, want 42 in Options order", len(points))
+	}
+}
+
+func TestReadSamples(t *testing.T) {
+	fsys := fstest.MapFS{
+		"corpus/MIT.t1":  {Data: []byte("# comment\n100%\nMIT 0,$\n\nthe text\n")},
+		"corpus/none.t1": {Data: []byte("0%\n\nno license\n")},
+		"corpus/url.t1":  {Data: []byte("5%\nMIT 10,20 URL\n\nsee https://opensource.org/licenses/MIT\n")},
+		"corpus/README":  {Data: []byte("about the corpus\n")},
+	}
+	samples, err := ReadSamples(fsys, "corpus/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Sample{
+		{Name: "corpus/MIT.t1", Text: []byte("the text\n"), IDs: []string{"MIT"}},
+		{Name: "corpus/none.t1", Text: []byte("no license\n")},
+		{Name: "corpus/url.t1", Text: []byte("see https://opensource.org/licenses/MIT\n")},
+	}
+	if !reflect.DeepEqual(samples, want) {
+		t.Errorf("ReadSamples = %+v, want %+v", samples, want)
+	}
+
+	for _, bad := range []string{"100%\nMIT 0,$", "MIT 0,$\n\ntext", "100%\nMIT\n\ntext"} {
+		if _, err := ParseSample("bad", []byte(bad)); err == nil {
+			t.Errorf("ParseSample(%q) succeeded, want error", bad)
+		}
+	}
+}
@@ -4,7 +4,12 @@
 
 package licensecheck
 
-import "testing"
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+)
 
 func TestTypeString(t *testing.T) {
 	for _, b := range typeBits {
@@ -15,7 +20,7 @@ func TestTypeString(t *testing.T) {
 	}
 
 	numError := 0
-	for typ := Type(0); typ < Discouraged+100; typ++ {
+	for typ := Type(0); typ < UseRestrictions+100; typ++ {
 		s := typ.String()
 		ptyp, err := ParseType(s)
 		if err != nil {
@@ -35,6 +40,27 @@ func TestTypeString(t *testing.T) {
 	}
 }
 
+func TestTypeMarshalText(t *testing.T) {
+	for _, typ := range []Type{Unknown, Notice, ShareServer | NonCommercial, Discouraged | 0x1000} {
+		js, err := json.Marshal(typ)
+		if err != nil {
+			t.Fatalf("json.Marshal(%v): %v", typ, err)
+		}
+		var back Type
+		if err := json.Unmarshal(js, &back); err != nil {
+			t.Fatalf("json.Unmarshal(%s): %v", js, err)
+		}
+		if back != typ {
+			t.Errorf("json round trip %v: got %s -> %v", typ, js, back)
+		}
+	}
+
+	var typ Type
+	if err := typ.UnmarshalText([]byte("Notice|Bogus")); err == nil {
+		t.Errorf("UnmarshalText(Notice|Bogus) = nil, want error")
+	}
+}
+
 var typeMergeTests = []struct {
 	t, u Type
 	out  Type
@@ -54,17 +80,48 @@ func TestTypeMerge(t *testing.T) {
 	}
 }
 
+var (
+	licenseTypesOnce sync.Once
+	licenseTypes     map[string]Type // built-in license types, by ID; see licenseType
+)
+
+// licenseType returns the type of the built-in license with the given ID.
 func licenseType(id string) Type {
-	for _, l := range builtinLREs {
-		if l.ID == id {
-			return l.Type
+	licenseTypesOnce.Do(func() {
+		licenseTypes = make(map[string]Type)
+		for _, l := range builtinLREs() {
+			if _, ok := licenseTypes[l.ID]; !ok {
+				licenseTypes[l.ID] = l.Type
+			}
 		}
+	})
+	if t, ok := licenseTypes[id]; ok {
+		return t
 	}
 	return Unknown
 }
 
 var licenseTypeTests = map[string]Type{
-	"WTFPL": Discouraged,
+	"Beerware":        Discouraged,
+	"BUSL-1.1":        SourceAvailable,
+	"CERN-OHL-P-2.0":  Notice,
+	"CERN-OHL-S-2.0":  ShareProgram,
+	"CERN-OHL-W-2.0":  ShareChanges,
+	"CommonsClause":   NonCommercial,
+	"Elastic-2.0":     SourceAvailable,
+	"GoodNotEvil":     Discouraged,
+	"Hippocratic-2.1": UseRestrictions,
+	"JSON":            Discouraged,
+	"Llama-2":         UseRestrictions,
+	"Llama-3":         UseRestrictions,
+	"NLPL":            Discouraged,
+	"OpenRAIL":        UseRestrictions,
+	"SHL-0.5":         Notice,
+	"SHL-0.51":        Notice,
+	"SHL-2.0":         Notice,
+	"SHL-2.1":         Notice,
+	"SSPL-1.0":        SourceAvailable,
+	"WTFPL":           Discouraged,
 }
 
 func TestLicenseType(t *testing.T) {
@@ -81,3 +138,36 @@ func TestLicenseType(t *testing.T) {
 		}
 	}
 }
+
+func TestLicensesOfType(t *testing.T) {
+	skipProfile(t)
+	ids := LicensesOfType(Discouraged)
+	if strings.Join(ids, " ") != "Beerware GoodNotEvil JSON NLPL WTFPL" {
+		t.Errorf("LicensesOfType(Discouraged) = %q, want [Beerware GoodNotEvil JSON NLPL WTFPL]", ids)
+	}
+
+	s, err := NewScanner([]License{
+		{ID: "A", Type: Notice, LRE: "this is license a"},
+		{ID: "B", Type: Notice | NonCommercial, LRE: "this is license b"},
+		{ID: "C", Type: ShareProgram, URL: "example.com/c"},
+		{ID: "D", LRE: "this is license d"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		t   Type
+		ids string
+	}{
+		{Notice, "A B"},
+		{NonCommercial, "B"},
+		{ShareProgram, "C"},
+		{Unknown, "D"},
+		{ShareServer, ""},
+	} {
+		ids := strings.Join(s.LicensesOfType(tt.t), " ")
+		if ids != tt.ids {
+			t.Errorf("LicensesOfType(%v) = %q, want %q", tt.t, ids, tt.ids)
+		}
+	}
+}
@@ -32,6 +32,7 @@ var urlTests = []urlTest{
 }
 
 func TestURLMatch(t *testing.T) {
+	skipProfile(t)
 	for _, test := range urlTests {
 		cov := Scan([]byte(test.text))
 		if len(cov.Match) != len(test.ids) {
@@ -52,8 +53,9 @@ func TestURLMatch(t *testing.T) {
 }
 
 func TestURLIDs(t *testing.T) {
+	skipProfile(t)
 	have := make(map[string]bool)
-	for _, l := range builtinLREs {
+	for _, l := range builtinLREs() {
 		have[l.ID] = true
 	}
 	for _, l := range builtinURLs {
@@ -99,3 +101,20 @@ func rot13(s string) string {
 	}
 	return string(b)
 }
+
+func TestURLCoverage(t *testing.T) {
+	// A URL match covers only the URL, not the text around it.
+	text := urlTests[0].text
+	cov := Scan([]byte(text))
+	if len(cov.Match) != 1 || !cov.Match[0].IsURL {
+		t.Fatalf("Scan(%q) = %+v, want one URL match", text, cov)
+	}
+	m := cov.Match[0]
+	if url := "https://creativecommons.org/licenses/BY/4.0/"; text[m.Start:m.End] != url {
+		t.Errorf("URL match is %q, want %q", text[m.Start:m.End], url)
+	}
+	words := len(builtinScanner.re.Dict().Split(text))
+	if want := 100 * float64(m.MatchedWords) / float64(words); !matchPercent(cov.Percent, want) {
+		t.Errorf("Scan(%q).Percent = %.1f%%, want %.1f%% (%d of %d words)", text, cov.Percent, want, m.MatchedWords, words)
+	}
+}
@@ -72,6 +72,10 @@ var builtinURLs = []License{
 	{URL: "opensource.org/licenses/upl", ID: "UPL-1.0"},
 	{URL: "opensource.org/licenses/xnet", ID: "Xnet"},
 	{URL: "opensource.org/licenses/zpl-2.0", ID: "ZPL-2.0"},
+	{URL: "solderpad.org/licenses/shl-0.5", ID: "SHL-0.5"},
+	{URL: "solderpad.org/licenses/shl-0.51", ID: "SHL-0.51"},
+	{URL: "solderpad.org/licenses/shl-2.0", ID: "SHL-2.0"},
+	{URL: "solderpad.org/licenses/shl-2.1", ID: "SHL-2.1"},
 	{URL: "www.apache.org/licenses/license-2.0", ID: "Apache-2.0"},
 	{URL: "www.gnu.org/licenses/agpl.txt", ID: "AGPL-3.0"},
 	// {URL: "www.gnu.org/licenses/autoconf-exception-3.0.html", ID: "GPL-3.0-with-autoconf-exception"},
@@ -0,0 +1,30 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import "sort"
+
+// setWords sets c.Words to the normalized words of text,
+// which must be the text scanned by s, and sets the WordStart and WordEnd
+// of each match in c to the match's offsets in c.Words.
+// A match covers the words that begin inside it.
+func (c *Coverage) setWords(s *Scanner, text []byte) {
+	// Use a new dictionary, so that every word gets its normalized form,
+	// including words that no license uses.
+	d := s.newDict()
+	words := d.InsertSplit(string(text))
+	dict := d.Words()
+	c.Words = make([]string, len(words))
+	for i, w := range words {
+		c.Words[i] = dict[w.ID]
+	}
+	index := func(off int) int {
+		return sort.Search(len(words), func(i int) bool { return int(words[i].Lo) >= off })
+	}
+	for i := range c.Match {
+		m := &c.Match[i]
+		m.WordStart, m.WordEnd = index(m.Start), index(m.End)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	mit, _ := Text("MIT")
+	text := []byte("Prologue: THIS is the MIT license.\n\n" + mit)
+
+	cov, err := ScanWithOptions(text, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cov.Words != nil || cov.Match[0].WordStart != 0 || cov.Match[0].WordEnd != 0 {
+		t.Errorf("ScanWithOptions without Words: Words = %d words, match at words %d:%d, want none", len(cov.Words), cov.Match[0].WordStart, cov.Match[0].WordEnd)
+	}
+
+	cov, err = ScanWithOptions(text, Options{Words: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := strings.Join(cov.Words[:6], " "), "prologue the is the mit license"; have != want {
+		t.Errorf("Words[:6] = %q, want %q", have, want)
+	}
+	if len(cov.Match) != 1 {
+		t.Fatalf("ScanWithOptions(MIT) = %+v, want one match", cov.Match)
+	}
+	m := cov.Match[0]
+	if m.WordStart != 6 || m.WordEnd != len(cov.Words) || m.WordEnd-m.WordStart != m.MatchedWords {
+		t.Errorf("match at words %d:%d, want 6:%d (%d words)", m.WordStart, m.WordEnd, len(cov.Words), m.MatchedWords)
+	}
+	if cov.Words[m.WordStart] != "copyright" || cov.Words[m.WordEnd-1] != "software" {
+		t.Errorf("match covers words %q...%q, want copyright...software", cov.Words[m.WordStart], cov.Words[m.WordEnd-1])
+	}
+}